@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+	"MCPWeaver/internal/parser"
+	"MCPWeaver/internal/transformer"
+)
+
+// batchItem is the outcome of generating the server of one specification
+type batchItem struct {
+	spec     string
+	output   string
+	result   *generator.GenerationResult
+	err      error
+	duration time.Duration
+}
+
+// discoverSpecs walks dir for YAML and JSON files declaring an OpenAPI or
+// Swagger version, in lexical order. Other files are returned as skipped.
+func discoverSpecs(dir string) ([]string, []string, error) {
+	var specs, skipped []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if parser.IsSpecification(data) {
+			specs = append(specs, path)
+		} else {
+			skipped = append(skipped, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, common.NewError(common.ErrorTypeParse, "failed to read specification directory", err).WithFile(dir)
+	}
+	return specs, skipped, nil
+}
+
+// generateBatch generates a server for every specification under specDir
+// with a pool of workers. Each server is written to a directory named after
+// it, the slug of the API title, inside outputDir.
+func generateBatch(ctx context.Context, out, errOut io.Writer, specDir, outputDir string, workers int, opts generator.Options) error {
+	if jsonOutput {
+		errOut = io.Discard
+	}
+	specs, skipped, err := discoverSpecs(specDir)
+	if err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return common.NewError(common.ErrorTypeParse, "no OpenAPI specifications found", nil).
+			WithFile(specDir).
+			WithSuggestion("Specifications are .yaml, .yml or .json files with an openapi or swagger field")
+	}
+	fmt.Fprintf(progressOutput(out), "Generating %d servers from %s...\n", len(specs), specDir)
+	if verbose {
+		for _, file := range skipped {
+			fmt.Fprintf(progressOutput(out), "  skipped %s: not an OpenAPI specification\n", file)
+		}
+	}
+
+	items := make([]batchItem, len(specs))
+	servers := make([]*transformer.MCPServer, len(specs))
+	progress := &batchProgress{out: progressOutput(out), total: len(specs)}
+	runWorkers(workers, len(specs), func(i int) {
+		start := time.Now()
+		items[i].spec = specs[i]
+		servers[i], items[i].err = loadServer(ctx, specs[i])
+		items[i].duration = time.Since(start)
+		if items[i].err != nil {
+			progress.report(specs[i], "", items[i].err, items[i].duration)
+		}
+	})
+
+	// Output directories are assigned in specification order, so the same
+	// specification of two with one title fails on every run
+	used := map[string]string{}
+	for i := range items {
+		item := &items[i]
+		if item.err != nil {
+			continue
+		}
+		item.output = filepath.Join(outputDir, servers[i].Name)
+		if other, ok := used[item.output]; ok {
+			item.err = common.NewError(common.ErrorTypeGeneration,
+				fmt.Sprintf("%s is already generated from %s", item.output, other), nil).
+				WithFile(item.spec).
+				WithSuggestion("Give the APIs distinct titles")
+			progress.report(item.spec, "", item.err, item.duration)
+			continue
+		}
+		used[item.output] = item.spec
+	}
+
+	runWorkers(workers, len(specs), func(i int) {
+		item := &items[i]
+		if item.err != nil {
+			return
+		}
+		start := time.Now()
+		specOpts := opts
+		specOpts.OutputDir = item.output
+		item.result, item.err = generator.NewService().Generate(servers[i], specOpts)
+		item.duration += time.Since(start)
+		progress.report(item.spec, item.output, item.err, item.duration)
+	})
+	if verbose {
+		for _, item := range items {
+			if item.result == nil {
+				continue
+			}
+			for _, warning := range item.result.Warnings {
+				fmt.Fprintf(errOut, "Warning: %s: %s\n", item.spec, warning)
+			}
+		}
+	}
+
+	if jsonOutput {
+		generations := make([]jsonGeneration, len(items))
+		for i, item := range items {
+			generations[i] = newJSONGeneration(item.spec, item.result, opts.DryRun, item.err)
+			generations[i].OutputDir = item.output
+		}
+		if err := writeJSON(out, generations); err != nil {
+			return err
+		}
+		return batchError(items)
+	}
+	return printBatchSummary(out, items)
+}
+
+// loadServer parses, validates and maps a specification
+func loadServer(ctx context.Context, specPath string) (*transformer.MCPServer, error) {
+	spec, err := parser.NewService().ParseFile(specPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := parser.NewService().Validate(ctx, spec); err != nil {
+		return nil, err
+	}
+	return newTransformer().Transform(spec)
+}
+
+// printBatchSummary tabulates the batch, followed by why specifications
+// failed, and returns an error when any did
+func printBatchSummary(out io.Writer, items []batchItem) error {
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SPEC\tSERVER\tTOOLS\tWARNINGS\tSTATUS")
+	failed := 0
+	for _, item := range items {
+		server, tools, warnings := "-", "-", "-"
+		if item.output != "" {
+			server = item.output
+		}
+		if item.result != nil {
+			tools = fmt.Sprint(item.result.ToolCount)
+			warnings = fmt.Sprint(len(item.result.Warnings))
+		}
+		status := "ok"
+		if item.err != nil {
+			failed++
+			status = "failed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.spec, server, tools, warnings, status)
+	}
+	w.Flush()
+
+	var failures []batchFailure
+	for _, item := range items {
+		if item.err != nil {
+			failures = append(failures, batchFailure{name: item.spec, reason: firstLine(item.err.Error())})
+		}
+	}
+	printFailures(out, failures)
+	fmt.Fprintf(out, "\n%d generated, %d failed\n", len(items)-failed, failed)
+	return batchError(items)
+}
+
+// batchError reports the failed specifications of a batch. It is a
+// validation error when only specifications were invalid. A generated
+// server that did not compile is kept as the cause, so CI mode exits with
+// ExitBuild when any did.
+func batchError(items []batchItem) error {
+	failed := 0
+	errType := common.ErrorTypeValidation
+	var cause error
+	for _, item := range items {
+		if item.err == nil {
+			continue
+		}
+		failed++
+		if ExitCode(item.err) != ExitValidation {
+			errType = common.ErrorTypeGeneration
+		}
+		var buildErr *generator.BuildError
+		if cause == nil && errors.As(item.err, &buildErr) {
+			cause = fmt.Errorf("%s: %w", item.spec, buildErr)
+		}
+	}
+	if failed > 0 {
+		return common.NewError(errType, fmt.Sprintf("%d of %d specifications failed to generate", failed, len(items)), cause)
+	}
+	return nil
+}
+
+// firstLine returns s up to its first line break
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
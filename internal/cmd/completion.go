@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+// specExtensions are the file extensions completed for specifications
+var specExtensions = []string{"yaml", "yml", "json"}
+
+// completeSpecs completes specification files
+func completeSpecs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return specExtensions, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeDirs completes directories
+func completeDirs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// completeTemplates completes the built-in template sets, described by their
+// manifests
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, set := range generator.TemplateSets() {
+		names = append(names, cobra.CompletionWithDesc(set.Name, set.Description))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles completes the generation profiles with what they enable
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, profile := range generator.Profiles {
+		names = append(names, cobra.CompletionWithDesc(profile.Name, profile.Description))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeValues completes a fixed set of flag values
+func completeValues(values ...string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// registerCompletions attaches completion functions to flags of cmd
+func registerCompletions(cmd *cobra.Command, completions map[string]cobra.CompletionFunc) {
+	for name, complete := range completions {
+		if err := cmd.RegisterFlagCompletionFunc(name, complete); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// completeLanguages completes --lang with the languages of the --template
+// and --template-dir package of the command
+func completeLanguages(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var opts generator.Options
+	opts.Template, _ = cmd.Flags().GetString("template")
+	opts.TemplateDir, _ = cmd.Flags().GetString("template-dir")
+	languages, err := generator.NewService().Languages(opts)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return languages, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeVariables completes --var with the variables declared by the
+// --template and --template-dir package of the command
+func completeVariables(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var opts generator.Options
+	opts.Template, _ = cmd.Flags().GetString("template")
+	opts.TemplateDir, _ = cmd.Flags().GetString("template-dir")
+	declared, err := generator.NewService().TemplateVariables(opts)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, v := range declared {
+		names = append(names, cobra.CompletionWithDesc(v.Name+"=", v.Description))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigFile is read from the working directory
+const projectConfigFile = ".mcpweaver.yaml"
+
+// configFile names a configuration file to use instead of the default ones
+var configFile string
+
+// cliConfig holds defaults for command flags. Flags given on the command
+// line override it.
+type cliConfig struct {
+	Spec              string   `yaml:"spec"`
+	Output            string   `yaml:"output"`
+	Template          string   `yaml:"template"`
+	TemplateDir       string   `yaml:"template_dir"`
+	Profile           string   `yaml:"profile"`
+	IncludeTags       []string `yaml:"include_tags"`
+	ExcludeTags       []string `yaml:"exclude_tags"`
+	ValidationProfile string   `yaml:"validation_profile"`
+	FailOn            string   `yaml:"fail_on"`
+	Proxy             string   `yaml:"proxy"`
+	NoProxy           string   `yaml:"no_proxy"`
+	Registry          string   `yaml:"registry"`
+}
+
+// flags pairs the flag names with their configured values. Lists are
+// joined with commas, as their flags take them.
+func (c cliConfig) flags() [][2]string {
+	return [][2]string{
+		{"spec", c.Spec},
+		{"output", c.Output},
+		{"template", c.Template},
+		{"template-dir", c.TemplateDir},
+		{"profile", c.Profile},
+		{"include-tags", strings.Join(c.IncludeTags, ",")},
+		{"exclude-tags", strings.Join(c.ExcludeTags, ",")},
+		{"validation-profile", c.ValidationProfile},
+		{"fail-on", c.FailOn},
+		{"proxy", c.Proxy},
+		{"no-proxy", c.NoProxy},
+		{"registry", c.Registry},
+	}
+}
+
+// merge returns c with the values set in override replacing its own
+func (c cliConfig) merge(override cliConfig) cliConfig {
+	merged := c
+	set := func(dst *string, value string) {
+		if value != "" {
+			*dst = value
+		}
+	}
+	set(&merged.Spec, override.Spec)
+	set(&merged.Output, override.Output)
+	set(&merged.Template, override.Template)
+	set(&merged.TemplateDir, override.TemplateDir)
+	set(&merged.Profile, override.Profile)
+	if len(override.IncludeTags) > 0 {
+		merged.IncludeTags = override.IncludeTags
+	}
+	if len(override.ExcludeTags) > 0 {
+		merged.ExcludeTags = override.ExcludeTags
+	}
+	set(&merged.ValidationProfile, override.ValidationProfile)
+	set(&merged.FailOn, override.FailOn)
+	set(&merged.Proxy, override.Proxy)
+	set(&merged.NoProxy, override.NoProxy)
+	set(&merged.Registry, override.Registry)
+	return merged
+}
+
+// configPaths lists the default configuration files from lowest to highest
+// precedence: the user's, then the project's
+func configPaths() []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", "mcpweaver", "config.yaml"))
+	}
+	return append(paths, projectConfigFile)
+}
+
+// loadConfig reads the configuration given with --config or, without it,
+// merges the default configuration files that exist
+func loadConfig(errOut io.Writer) (cliConfig, error) {
+	if configFile != "" {
+		return readConfig(configFile)
+	}
+
+	var config cliConfig
+	for _, path := range configPaths() {
+		fileConfig, err := readConfig(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return cliConfig{}, err
+		}
+		if verbose && !jsonOutput {
+			fmt.Fprintf(errOut, "Using configuration from %s\n", path)
+		}
+		config = config.merge(fileConfig)
+	}
+	return config, nil
+}
+
+// readConfig parses one configuration file, rejecting unknown keys so that
+// typos do not go unnoticed. Relative paths in it are resolved against the
+// directory of the file, so a configuration means the same wherever the
+// command runs.
+func readConfig(path string) (cliConfig, error) {
+	var config cliConfig
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return config, fmt.Errorf("invalid configuration %s: %w", path, err)
+	}
+	for _, value := range []*string{&config.Spec, &config.Output, &config.TemplateDir} {
+		if *value != "" && !filepath.IsAbs(*value) {
+			*value = filepath.Join(filepath.Dir(path), *value)
+		}
+	}
+	return config, nil
+}
+
+// applyConfig sets the flags of cmd that were not given on the command line
+// to their configured values. Flags a command does not have are ignored.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig(cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	for _, pair := range config.flags() {
+		name, value := pair[0], pair[1]
+		if value == "" {
+			continue
+		}
+		// A specification given as an argument replaces the configured one,
+		// and a batch takes neither a specification nor an output directory
+		if name == "spec" && len(args) > 0 {
+			continue
+		}
+		if (name == "spec" || name == "output") && cmd.Flags().Changed("spec-dir") {
+			continue
+		}
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s in configuration: %w", name, err)
+		}
+	}
+	return applyProxy()
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chdir changes the working directory for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// writeConfig writes a configuration file, creating its directory
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestReadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "conf", "mcpweaver.yaml")
+	writeConfig(t, path, `spec: ../api/openapi.yaml
+output: server
+template_dir: /opt/templates
+template: go-default
+include_tags: [users, admin]
+validation_profile: strict
+`)
+	config, err := readConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "api", "openapi.yaml"), config.Spec, "paths are relative to the file")
+	assert.Equal(t, filepath.Join(dir, "conf", "server"), config.Output)
+	assert.Equal(t, "/opt/templates", config.TemplateDir, "absolute paths are kept")
+	assert.Equal(t, "go-default", config.Template, "names are not paths")
+	assert.Equal(t, []string{"users", "admin"}, config.IncludeTags)
+	assert.Equal(t, "strict", config.ValidationProfile)
+
+	writeConfig(t, path, "")
+	config, err = readConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, cliConfig{}, config, "an empty file configures nothing")
+
+	writeConfig(t, path, "outptu: server\n")
+	_, err = readConfig(path)
+	assert.ErrorContains(t, err, "field outptu not found", "unknown keys are rejected")
+
+	_, err = readConfig(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMergeConfig(t *testing.T) {
+	user := cliConfig{Spec: "/api.yaml", Template: "go-default", IncludeTags: []string{"a"}, ExcludeTags: []string{"b"}, FailOn: "warning"}
+	project := cliConfig{Template: "go-rich", IncludeTags: []string{"c", "d"}, ValidationProfile: "strict"}
+	assert.Equal(t, cliConfig{
+		Spec:              "/api.yaml",
+		Template:          "go-rich",
+		IncludeTags:       []string{"c", "d"},
+		ExcludeTags:       []string{"b"},
+		ValidationProfile: "strict",
+		FailOn:            "warning",
+	}, user.merge(project))
+	assert.Equal(t, user, user.merge(cliConfig{}), "unset values keep the lower configuration")
+}
+
+// configCommand is a command with some of the configurable flags
+func configCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "config-test"}
+	cmd.SetErr(&bytes.Buffer{})
+	flags := cmd.Flags()
+	flags.String("spec", "", "")
+	flags.String("output", ".", "")
+	flags.String("template", "go-default", "")
+	flags.StringSlice("include-tags", nil, "")
+	flags.String("spec-dir", "", "")
+	return cmd
+}
+
+func TestApplyConfig(t *testing.T) {
+	savedFile, savedProxy := configFile, proxyFlags
+	defer func() { configFile, proxyFlags = savedFile, savedProxy }()
+	configFile = ""
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeConfig(t, filepath.Join(home, ".config", "mcpweaver", "config.yaml"), `spec: user.yaml
+output: user-server
+template: go-user
+include_tags: [users]
+`)
+	project := t.TempDir()
+	chdir(t, project)
+	writeConfig(t, filepath.Join(project, projectConfigFile), "output: project-server\ninclude_tags: [orders, billing]\n")
+
+	get := func(cmd *cobra.Command, name string) string {
+		return cmd.Flags().Lookup(name).Value.String()
+	}
+
+	// The project configuration takes precedence over the user's
+	cmd := configCommand()
+	require.NoError(t, applyConfig(cmd, nil))
+	assert.Equal(t, filepath.Join(home, ".config", "mcpweaver", "user.yaml"), get(cmd, "spec"))
+	assert.Equal(t, filepath.Join(".", "project-server"), get(cmd, "output"))
+	assert.Equal(t, "go-user", get(cmd, "template"))
+	assert.Equal(t, "[orders,billing]", get(cmd, "include-tags"))
+
+	// Flags given on the command line take precedence over both
+	cmd = configCommand()
+	require.NoError(t, cmd.Flags().Set("template", "go-flag"))
+	require.NoError(t, cmd.Flags().Set("include-tags", "admin"))
+	require.NoError(t, applyConfig(cmd, nil))
+	assert.Equal(t, "go-flag", get(cmd, "template"))
+	assert.Equal(t, "[admin]", get(cmd, "include-tags"))
+	assert.Equal(t, filepath.Join(".", "project-server"), get(cmd, "output"))
+
+	// A specification argument replaces the configured one, and a batch
+	// takes neither a specification nor an output directory
+	cmd = configCommand()
+	require.NoError(t, applyConfig(cmd, []string{"api.yaml"}))
+	assert.Empty(t, get(cmd, "spec"))
+	cmd = configCommand()
+	require.NoError(t, cmd.Flags().Set("spec-dir", "apis"))
+	require.NoError(t, applyConfig(cmd, nil))
+	assert.Empty(t, get(cmd, "spec"))
+	assert.Equal(t, ".", get(cmd, "output"))
+
+	// --config reads only the given file
+	configFile = filepath.Join(t.TempDir(), "ci.yaml")
+	writeConfig(t, configFile, "template: go-ci\n")
+	cmd = configCommand()
+	require.NoError(t, applyConfig(cmd, nil))
+	assert.Equal(t, "go-ci", get(cmd, "template"))
+	assert.Equal(t, ".", get(cmd, "output"))
+	assert.Empty(t, get(cmd, "spec"))
+
+	writeConfig(t, configFile, "template: [go-ci]\n")
+	err := applyConfig(configCommand(), nil)
+	assert.ErrorContains(t, err, "invalid configuration")
+}
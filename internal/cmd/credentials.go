@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/parser"
+)
+
+// sourceCredentials authenticate downloads of specifications below a URL.
+// A value written ${NAME} is read from the environment variable NAME, so
+// secrets need not be stored in the file.
+type sourceCredentials struct {
+	URL      string            `yaml:"url"`
+	Token    string            `yaml:"token,omitempty"`
+	Username string            `yaml:"username,omitempty"`
+	Password string            `yaml:"password,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
+	Cookies  map[string]string `yaml:"cookies,omitempty"`
+}
+
+// credentialsStore is the per-user credentials file
+type credentialsStore struct {
+	Sources []sourceCredentials `yaml:"sources"`
+}
+
+// envReference matches a value that names an environment variable
+var envReference = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// credentialsPath is the file credentials are stored in, beside the user
+// configuration
+func credentialsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the credentials file: %w", err)
+	}
+	return filepath.Join(home, ".config", "mcpweaver", "credentials.yaml"), nil
+}
+
+// loadCredentials reads the credentials file, which may not exist. Like SSH
+// keys, it is refused when other users can read it.
+func loadCredentials() (credentialsStore, error) {
+	var store credentialsStore
+	path, err := credentialsPath()
+	if err != nil {
+		return store, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return store, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return store, common.NewError(common.ErrorTypeValidation, "credentials file is accessible by other users", nil).
+			WithFile(path).
+			WithSuggestion(fmt.Sprintf("Restrict it to your user with: chmod 600 %s", path))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return store, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if err := yaml.Unmarshal(content, &store); err != nil {
+		return store, fmt.Errorf("invalid credentials file %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// save writes the credentials file readable by the user only
+func (s credentialsStore) save() (string, error) {
+	path, err := credentialsPath()
+	if err != nil {
+		return "", err
+	}
+	content, err := yaml.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("failed to save credentials: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".credentials-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to save credentials: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return path, nil
+}
+
+// set stores credentials, replacing any stored for the same URL
+func (s *credentialsStore) set(credentials sourceCredentials) {
+	for i, stored := range s.Sources {
+		if stored.URL == credentials.URL {
+			s.Sources[i] = credentials
+			return
+		}
+	}
+	s.Sources = append(s.Sources, credentials)
+}
+
+// lookup returns the credentials with the longest URL the source is below
+func (s credentialsStore) lookup(source string) (sourceCredentials, bool) {
+	target, err := url.Parse(source)
+	if err != nil {
+		return sourceCredentials{}, false
+	}
+	var best sourceCredentials
+	found := false
+	for _, stored := range s.Sources {
+		prefix, err := url.Parse(stored.URL)
+		if err != nil || !strings.EqualFold(prefix.Scheme, target.Scheme) || !strings.EqualFold(prefix.Host, target.Host) {
+			continue
+		}
+		path := strings.TrimSuffix(prefix.Path, "/")
+		if target.Path != path && !strings.HasPrefix(target.Path, path+"/") {
+			continue
+		}
+		if !found || len(stored.URL) > len(best.URL) {
+			best, found = stored, true
+		}
+	}
+	return best, found
+}
+
+// fetchOptions resolves the stored values to request credentials
+func (c sourceCredentials) fetchOptions() (parser.FetchOptions, error) {
+	var opts parser.FetchOptions
+	resolve := func(value string) (string, error) {
+		match := envReference.FindStringSubmatch(value)
+		if match == nil {
+			return value, nil
+		}
+		if resolved, ok := os.LookupEnv(match[1]); ok {
+			return resolved, nil
+		}
+		return "", fmt.Errorf("environment variable %s, used by the credentials for %s, is not set", match[1], c.URL)
+	}
+	var err error
+	if opts.Token, err = resolve(c.Token); err != nil {
+		return opts, err
+	}
+	if opts.Username, err = resolve(c.Username); err != nil {
+		return opts, err
+	}
+	if opts.Password, err = resolve(c.Password); err != nil {
+		return opts, err
+	}
+	if len(c.Headers) > 0 {
+		opts.Header = http.Header{}
+		for name, value := range c.Headers {
+			if value, err = resolve(value); err != nil {
+				return opts, err
+			}
+			opts.Header.Set(name, value)
+		}
+	}
+	for name, value := range c.Cookies {
+		if value, err = resolve(value); err != nil {
+			return opts, err
+		}
+		opts.Cookies = append(opts.Cookies, &http.Cookie{Name: name, Value: value})
+	}
+	return opts, nil
+}
+
+// sourceFetchOptions returns the stored credentials for a URL source
+func sourceFetchOptions(source string) (parser.FetchOptions, error) {
+	if !parser.IsURL(source) {
+		return parser.FetchOptions{}, nil
+	}
+	store, err := loadCredentials()
+	if err != nil {
+		return parser.FetchOptions{}, err
+	}
+	credentials, ok := store.lookup(source)
+	if !ok {
+		return parser.FetchOptions{}, nil
+	}
+	return credentials.fetchOptions()
+}
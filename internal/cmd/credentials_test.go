@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialsLookup(t *testing.T) {
+	store := credentialsStore{Sources: []sourceCredentials{
+		{URL: "https://api.example.com", Token: "host"},
+		{URL: "https://api.example.com/specs/", Token: "specs"},
+		{URL: "https://api.example.com/specs/internal", Token: "internal"},
+		{URL: "http://plain.example.com/specs", Token: "plain"},
+	}}
+	tests := []struct {
+		source string
+		token  string
+	}{
+		{"https://api.example.com/openapi.yaml", "host"},
+		{"https://API.example.com/specs/users.yaml", "specs"},
+		{"https://api.example.com/specs/internal/orders.yaml", "internal"},
+		{"https://api.example.com/specs/internal", "internal"},
+		// A path prefix matches whole segments only
+		{"https://api.example.com/specs/internal-v2/orders.yaml", "specs"},
+		{"http://plain.example.com/specs/users.yaml", "plain"},
+		// Credentials are never sent to another scheme or host
+		{"http://api.example.com/specs/users.yaml", ""},
+		{"https://plain.example.com/specs/users.yaml", ""},
+		{"https://api.example.com.evil.test/specs/users.yaml", ""},
+		{"::not a url", ""},
+	}
+	for _, tt := range tests {
+		credentials, ok := store.lookup(tt.source)
+		assert.Equal(t, tt.token != "", ok, tt.source)
+		assert.Equal(t, tt.token, credentials.Token, tt.source)
+	}
+}
+
+func TestCredentialsFetchOptions(t *testing.T) {
+	t.Setenv("MCPWEAVER_TEST_TOKEN", "secret")
+	credentials := sourceCredentials{
+		URL:     "https://api.example.com",
+		Token:   "${MCPWEAVER_TEST_TOKEN}",
+		Headers: map[string]string{"x-api-key": "literal"},
+		Cookies: map[string]string{"session": "${MCPWEAVER_TEST_TOKEN}"},
+	}
+	opts, err := credentials.fetchOptions()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", opts.Token)
+	assert.Equal(t, "literal", opts.Header.Get("X-Api-Key"))
+	require.Len(t, opts.Cookies, 1)
+	assert.Equal(t, "secret", opts.Cookies[0].Value)
+
+	credentials.Password = "${MCPWEAVER_TEST_UNSET}"
+	_, err = credentials.fetchOptions()
+	assert.ErrorContains(t, err, "MCPWEAVER_TEST_UNSET")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/parser"
+)
+
+// diffFlags holds the flags of the diff command
+var diffFlags struct {
+	failOnBreaking bool
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-spec> <new-spec>",
+	Short: "Compare two versions of an OpenAPI specification",
+	Long: `Diff lists the operations added to, removed from and changed in the new
+version of a specification, and the changes that break clients of the old
+one: removed operations, parameters and properties, new required inputs,
+type changes and renamed operation IDs, which rename the generated tools.
+
+With --fail-on-breaking, the command exits with status 2 when there are
+breaking changes, to stop a pipeline before a regenerated server breaks its
+clients.`,
+	Example: `  mcpweaver diff api-v1.yaml api-v2.yaml
+  mcpweaver diff api-v1.yaml api-v2.yaml --json
+  mcpweaver diff api-v1.yaml api-v2.yaml --ci --fail-on-breaking`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSpecs,
+	RunE:              runDiff,
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffFlags.failOnBreaking, "fail-on-breaking", false, "exit with status 2 when there are breaking changes")
+	rootCmd.AddCommand(diffCmd)
+}
+
+// specDiffReport is the JSON output of the diff command
+type specDiffReport struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+	*parser.SpecDiff
+	Breaking []string `json:"breaking"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	old, err := parser.NewService().ParseFile(args[0])
+	if err != nil {
+		return err
+	}
+	updated, err := parser.NewService().ParseFile(args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := parser.Compare(old, updated)
+	report := specDiffReport{Old: args[0], New: args[1], SpecDiff: diff, Breaking: diff.Breaking()}
+	if report.Breaking == nil {
+		report.Breaking = []string{}
+	}
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		if err := writeJSON(out, report); err != nil {
+			return err
+		}
+	} else {
+		printSpecDiff(out, report)
+	}
+	if !diffFlags.failOnBreaking || len(report.Breaking) == 0 {
+		return nil
+	}
+	return common.NewError(common.ErrorTypeValidation, fmt.Sprintf("%d breaking changes", len(report.Breaking)), nil).
+		WithFile(report.New).
+		WithSuggestion(fmt.Sprintf("Review the changes marked ! against %s, or compare without --fail-on-breaking", report.Old))
+}
+
+// printSpecDiff writes the differences for the terminal, marking breaking
+// changes with "!"
+func printSpecDiff(out io.Writer, report specDiffReport) {
+	if report.Empty() {
+		fmt.Fprintf(out, "No differences in operations between %s and %s\n", report.Old, report.New)
+		return
+	}
+	fmt.Fprintf(out, "Comparing %s with %s\n", report.Old, report.New)
+	if len(report.Added) > 0 {
+		fmt.Fprintln(out, "\nAdded operations:")
+		for _, op := range report.Added {
+			fmt.Fprintf(out, "  + %s\n", op)
+		}
+	}
+	if len(report.Removed) > 0 {
+		fmt.Fprintln(out, "\nRemoved operations:")
+		for _, op := range report.Removed {
+			fmt.Fprintf(out, "  - %s (breaking)\n", op)
+		}
+	}
+	if len(report.Changed) > 0 {
+		fmt.Fprintln(out, "\nChanged operations:")
+		for _, op := range report.Changed {
+			fmt.Fprintf(out, "  ~ %s\n", op.Operation)
+			for _, change := range op.Changes {
+				mark := " "
+				if change.Breaking {
+					mark = "!"
+				}
+				fmt.Fprintf(out, "    %s %s\n", mark, change.Description)
+			}
+		}
+	}
+	fmt.Fprintf(out, "\n%d added, %d removed, %d changed; %d breaking changes\n",
+		len(report.Added), len(report.Removed), len(report.Changed), len(report.Breaking))
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runDiffCommand compares two specifications written to files
+func runDiffCommand(t *testing.T, old, updated string, failOnBreaking bool) (string, error) {
+	t.Helper()
+	defer func(saved bool) { diffFlags.failOnBreaking = saved }(diffFlags.failOnBreaking)
+	diffFlags.failOnBreaking = failOnBreaking
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "v1.yaml"), filepath.Join(dir, "v2.yaml")
+	require.NoError(t, os.WriteFile(oldPath, []byte(old), 0644))
+	require.NoError(t, os.WriteFile(newPath, []byte(updated), 0644))
+	var out bytes.Buffer
+	diffCmd.SetOut(&out)
+	err := runDiff(diffCmd, []string{oldPath, newPath})
+	return out.String(), err
+}
+
+func TestDiffExitCode(t *testing.T) {
+	defer func(ci, output bool) { ciMode, jsonOutput = ci, output }(ciMode, jsonOutput)
+	const spec = "openapi: 3.0.3\ninfo: {title: Items, version: '1'}\npaths:\n  /items:\n"
+	v1 := spec + "    get: {operationId: listItems, responses: {'200': {description: ok}}}\n"
+	added := v1 + "    post: {operationId: createItem, responses: {'201': {description: created}}}\n"
+	removed := spec + "    post: {operationId: createItem, responses: {'201': {description: created}}}\n"
+
+	out, err := runDiffCommand(t, v1, removed, false)
+	require.NoError(t, err, "breaking changes are only reported without --fail-on-breaking")
+	assert.Contains(t, out, "- GET /items (breaking)")
+
+	for _, ci := range []bool{false, true} {
+		ciMode = ci
+		_, err = runDiffCommand(t, v1, removed, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 breaking changes")
+		assert.Equal(t, ExitValidation, ExitCode(err), "ci %v", ci)
+
+		_, err = runDiffCommand(t, v1, added, true)
+		assert.Equal(t, ExitOK, ExitCode(err), "added operations do not fail")
+	}
+
+	jsonOutput = true
+	out, err = runDiffCommand(t, v1, removed, true)
+	assert.Equal(t, ExitValidation, ExitCode(err))
+	var report struct {
+		Removed  []string `json:"removed"`
+		Breaking []string `json:"breaking"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &report), "the report is written before failing")
+	assert.Equal(t, []string{"GET /items"}, report.Removed)
+	assert.Equal(t, []string{"GET /items: operation removed"}, report.Breaking)
+}
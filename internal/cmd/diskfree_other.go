@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd)
+
+package cmd
+
+// diskFree is not implemented on this platform
+func diskFree(path string) (uint64, bool) {
+	return 0, false
+}
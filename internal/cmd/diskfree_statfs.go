@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd
+
+package cmd
+
+import "syscall"
+
+// diskFree returns the bytes available to unprivileged users on the file
+// system holding path
+func diskFree(path string) (uint64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, false
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true
+}
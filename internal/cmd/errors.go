@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+// Exit codes of the CLI. ExitBuild and ExitTest are only used in CI mode;
+// otherwise those failures exit with ExitGeneration and ExitValidation.
+const (
+	ExitOK         = 0
+	ExitError      = 1
+	ExitValidation = 2
+	ExitGeneration = 3
+	ExitBuild      = 4
+	ExitTest       = 5
+)
+
+// ExitCode maps an error returned by Execute to the process exit code:
+// invalid specifications exit with ExitValidation, failed generations with
+// ExitGeneration and everything else, such as missing files, with ExitError.
+// CI mode tells generated servers that do not compile and failed tests
+// apart with ExitBuild and ExitTest.
+func ExitCode(err error) int {
+	var pipelineErr *common.Error
+	var buildErr *generator.BuildError
+	if err == nil {
+		return ExitOK
+	}
+	if !errors.As(err, &pipelineErr) {
+		return ExitError
+	}
+	if ciMode {
+		switch {
+		case errors.As(err, &buildErr):
+			return ExitBuild
+		case pipelineErr.Type == common.ErrorTypeTest:
+			return ExitTest
+		}
+	}
+	switch pipelineErr.Type {
+	case common.ErrorTypeParse:
+		// A specification that cannot be read is not an invalid one
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			return ExitError
+		}
+		return ExitValidation
+	case common.ErrorTypeValidation, common.ErrorTypeTest:
+		return ExitValidation
+	case common.ErrorTypeTransformation, common.ErrorTypeGeneration:
+		return ExitGeneration
+	}
+	return ExitError
+}
+
+// FormatError renders an error for the terminal, with the file, line and
+// suggestion of pipeline errors on their own lines, or as a JSON document
+// in JSON mode
+func FormatError(err error) string {
+	if jsonOutput {
+		content, _ := json.Marshal(struct {
+			Error *jsonError `json:"error"`
+		}{newJSONError(err)})
+		return string(content) + "\n"
+	}
+
+	var pipelineErr *common.Error
+	if !errors.As(err, &pipelineErr) {
+		return fmt.Sprintf("Error: %v\n", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Error: %s\n", pipelineErr.Message)
+	if pipelineErr.File != "" {
+		fmt.Fprintf(&b, "  File: %s\n", pipelineErr.File)
+	}
+	if pipelineErr.Line > 0 {
+		fmt.Fprintf(&b, "  Line: %d\n", pipelineErr.Line)
+	}
+	if pipelineErr.Err != nil {
+		fmt.Fprintf(&b, "  Issue: %v\n", pipelineErr.Err)
+	}
+	if pipelineErr.Suggestion != "" {
+		fmt.Fprintf(&b, "\nSuggestion: %s\n", pipelineErr.Suggestion)
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+func TestExitCode(t *testing.T) {
+	defer func(ci bool) { ciMode = ci }(ciMode)
+
+	invalid := common.NewError(common.ErrorTypeValidation, "invalid specification", nil)
+	missing := common.NewError(common.ErrorTypeParse, "failed to read specification", os.ErrNotExist)
+	build := common.NewError(common.ErrorTypeGeneration, "generated server does not compile",
+		&generator.BuildError{Diagnostics: []generator.Finding{{Check: generator.CheckGoBuild, File: "main.go", Line: 3, Message: "undefined: x"}}})
+	tests := common.NewError(common.ErrorTypeTest, "1 of 2 servers failed testing", nil)
+	batch := func(errs ...error) error {
+		items := make([]batchItem, len(errs))
+		for i, err := range errs {
+			items[i] = batchItem{spec: fmt.Sprintf("specs/%d.yaml", i), err: err}
+		}
+		return batchError(items)
+	}
+
+	for _, ci := range []bool{false, true} {
+		ciMode = ci
+		buildCode, testCode := ExitGeneration, ExitValidation
+		if ci {
+			buildCode, testCode = ExitBuild, ExitTest
+		}
+		assert.Equal(t, ExitOK, ExitCode(nil))
+		assert.Equal(t, ExitError, ExitCode(fmt.Errorf("unknown flag")))
+		assert.Equal(t, ExitError, ExitCode(missing))
+		assert.Equal(t, ExitValidation, ExitCode(invalid))
+		assert.Equal(t, buildCode, ExitCode(build), "ci %v", ci)
+		assert.Equal(t, testCode, ExitCode(tests), "ci %v", ci)
+
+		assert.Equal(t, ExitOK, ExitCode(batch(nil, nil)))
+		assert.Equal(t, ExitValidation, ExitCode(batch(nil, invalid)))
+		assert.Equal(t, buildCode, ExitCode(batch(invalid, build, nil)), "ci %v", ci)
+	}
+}
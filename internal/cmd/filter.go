@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/transformer"
+)
+
+// mappingFilter selects the operations mapped to tools, from the
+// --include-tags and --exclude-tags flags
+var mappingFilter transformer.Filter
+
+// addFilterFlags adds the flags of mappingFilter to cmd
+func addFilterFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVar(&mappingFilter.IncludeTags, "include-tags", nil, "map only the operations with one of these tags")
+	flags.StringSliceVar(&mappingFilter.ExcludeTags, "exclude-tags", nil, "skip the operations with any of these tags")
+}
+
+// newTransformer returns a transformer applying mappingFilter
+func newTransformer() *transformer.Service {
+	return transformer.NewService().WithFilter(mappingFilter)
+}
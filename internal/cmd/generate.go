@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+	"MCPWeaver/internal/parser"
+	"MCPWeaver/internal/transformer"
+)
+
+// generateFlags holds the flags of the generate command
+var generateFlags struct {
+	spec        string
+	output      string
+	template    string
+	templateDir string
+	profile     string
+	dryRun      bool
+	specDir     string
+	outputDir   string
+	workers     int
+	watch       bool
+	debounce    time.Duration
+	build       []string
+	vars        []string
+	language    string
+	findings    string
+	findingsOut string
+	scan        bool
+}
+
+// Formats of --findings-format; findingsStdout is the --findings-file
+// writing the SARIF log to stdout
+const (
+	findingsText   = "text"
+	findingsSARIF  = "sarif"
+	findingsStdout = "-"
+)
+
+// buildAll is the --build value selecting generator.DefaultBuildTargets
+const buildAll = "all"
+
+var generateCmd = &cobra.Command{
+	Use:   "generate [openapi-spec]",
+	Short: "Generate an MCP server from an OpenAPI specification",
+	Long: `Generate parses and validates an OpenAPI specification, maps its operations
+to MCP tools and writes a ready-to-build Go MCP server.
+
+The specification is given as an argument or with --spec. With --spec-dir,
+every specification found below the directory is generated into its own
+directory inside --output-dir, named after the API title, by --workers
+specifications at a time; a line is printed as each finishes, then a summary
+table and the failures. With --ci, progress is not printed and the generated
+server must compile. With --build, the server is then compiled for each
+os/arch target, or for the common platforms with "all", into its dist
+directory. With --watch, the server is regenerated whenever the
+specification or the --template-dir package changes, until interrupted.
+--var sets the variables the template package declares in its manifest, and
+--lang the language of the README and code comments, where the package has
+a translation. --include-tags and --exclude-tags map only the operations
+with the given tags, or skip them.
+
+With --security-scan, or the production profile, the generated server is
+scanned with gosec and govulncheck where they are installed. The findings
+of the scans and of the formatting, vet and build checks are reported as
+warnings; with --findings-format sarif they are written as a SARIF log
+instead, to --findings-file or stdout, for code scanning services such as
+GitHub's. With the log on stdout, progress and the summary go to stderr.
+
+The command exits with 2 when a specification is invalid
+and 3 when generation fails.`,
+	Example: `  mcpweaver generate api.yaml --output ./server
+  mcpweaver generate --spec api.yaml --output ./server --template go-default
+  mcpweaver generate api.yaml --profile production --dry-run
+  mcpweaver generate api.yaml --include-tags users,orders --exclude-tags internal
+  mcpweaver generate --spec-dir ./apis --output-dir ./servers --workers 8
+  mcpweaver generate api.yaml --output ./server --watch
+  mcpweaver generate api.yaml --output ./server --build linux/amd64,darwin/arm64
+  mcpweaver generate api.yaml --template-dir ./acme-templates --var team=payments --var replicas=3
+  mcpweaver generate api.yaml --output ./server --lang de
+  mcpweaver generate api.yaml --output ./server --ci --security-scan --findings-format sarif --findings-file mcpweaver.sarif`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSpecs,
+	RunE:              runGenerate,
+}
+
+func init() {
+	flags := generateCmd.Flags()
+	flags.StringVar(&generateFlags.spec, "spec", "", "OpenAPI specification to generate from")
+	flags.StringVarP(&generateFlags.output, "output", "o", ".", "output directory for the generated server")
+	flags.StringVar(&generateFlags.template, "template", generator.DefaultTemplate, "built-in template set to render")
+	flags.StringVar(&generateFlags.templateDir, "template-dir", "", "custom template package layered over the template set")
+	flags.StringVar(&generateFlags.profile, "profile", generator.DefaultProfile, "feature profile: minimal, standard or production")
+	flags.BoolVar(&generateFlags.dryRun, "dry-run", false, "show the changes as a diff without writing files")
+	flags.StringVar(&generateFlags.specDir, "spec-dir", "", "generate every specification found below this directory")
+	flags.StringVar(&generateFlags.outputDir, "output-dir", ".", "directory receiving one server per specification with --spec-dir")
+	flags.IntVar(&generateFlags.workers, "workers", 0, "with --spec-dir, "+workersUsage)
+	flags.BoolVarP(&generateFlags.watch, "watch", "w", false, "regenerate whenever the specification or template directory changes")
+	flags.DurationVar(&generateFlags.debounce, "debounce", defaultDebounce, "with --watch, how long files must stay unchanged before regenerating")
+	flags.StringSliceVar(&generateFlags.build, "build", nil, `compile the server for these os/arch targets, or "all" for the common platforms`)
+	flags.StringArrayVar(&generateFlags.vars, "var", nil, varUsage)
+	flags.StringVar(&generateFlags.language, "lang", generator.DefaultLanguage, langUsage)
+	flags.BoolVar(&generateFlags.scan, "security-scan", false, "scan the generated server with gosec and govulncheck, where installed")
+	flags.StringVar(&generateFlags.findings, "findings-format", findingsText, "how checks of the generated server are reported: text or sarif")
+	flags.StringVar(&generateFlags.findingsOut, "findings-file", findingsStdout, `with --findings-format sarif, file receiving the log, or "-" for stdout`)
+	addFilterFlags(generateCmd)
+	registerCompletions(generateCmd, map[string]cobra.CompletionFunc{
+		"spec":            completeSpecs,
+		"output":          completeDirs,
+		"template":        completeTemplates,
+		"template-dir":    completeDirs,
+		"profile":         completeProfiles,
+		"spec-dir":        completeDirs,
+		"output-dir":      completeDirs,
+		"build":           completeBuildTargets,
+		"var":             completeVariables,
+		"lang":            completeLanguages,
+		"findings-format": completeValues(findingsText, findingsSARIF),
+	})
+	rootCmd.AddCommand(generateCmd)
+}
+
+func runGenerate(cmd *cobra.Command, args []string) error {
+	vars, err := parseVariables(generateFlags.vars)
+	if err != nil {
+		return err
+	}
+	opts := generator.Options{
+		OutputDir:    generateFlags.output,
+		Template:     generateFlags.template,
+		TemplateDir:  generateFlags.templateDir,
+		Profile:      generateFlags.profile,
+		DryRun:       generateFlags.dryRun,
+		Variables:    vars,
+		Language:     generateFlags.language,
+		SecurityScan: generateFlags.scan,
+		// Pipelines should fail on a server that does not compile
+		VerifyBuild: ciMode && !generateFlags.dryRun,
+	}
+	targets, err := buildTargets(generateFlags.build)
+	if err != nil {
+		return err
+	}
+	if len(targets) > 0 && (generateFlags.dryRun || generateFlags.watch || generateFlags.specDir != "") {
+		return fmt.Errorf("--build cannot be combined with --dry-run, --watch or --spec-dir")
+	}
+	switch generateFlags.findings {
+	case findingsText:
+	case findingsSARIF:
+		if generateFlags.watch || generateFlags.specDir != "" {
+			return fmt.Errorf("--findings-format sarif cannot be combined with --watch or --spec-dir")
+		}
+		if jsonOutput && generateFlags.findingsOut == findingsStdout {
+			return fmt.Errorf("--json and a SARIF log cannot both be written to stdout; set --findings-file")
+		}
+	default:
+		return fmt.Errorf("unknown findings format %q; use text or sarif", generateFlags.findings)
+	}
+
+	if generateFlags.specDir != "" {
+		if len(args) > 0 || generateFlags.spec != "" || cmd.Flags().Changed("output") {
+			return fmt.Errorf("--spec-dir cannot be combined with a specification or --output; use --output-dir")
+		}
+		if generateFlags.watch {
+			return fmt.Errorf("--watch cannot be combined with --spec-dir")
+		}
+		return generateBatch(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), generateFlags.specDir, generateFlags.outputDir, generateFlags.workers, opts)
+	}
+
+	specPath := generateFlags.spec
+	switch {
+	case len(args) == 1 && specPath != "":
+		return fmt.Errorf("the specification is given both as an argument and with --spec")
+	case len(args) == 1:
+		specPath = args[0]
+	case specPath == "":
+		return fmt.Errorf("no specification given; run mcpweaver generate <openapi-spec>")
+	}
+
+	if generateFlags.watch {
+		if ciMode {
+			return fmt.Errorf("--watch runs until interrupted and cannot be used with --ci")
+		}
+		return watchGenerate(cmd, specPath, opts)
+	}
+	return generateSpec(cmd, specPath, opts, targets)
+}
+
+// buildTargets parses the --build targets
+func buildTargets(values []string) ([]generator.BuildTarget, error) {
+	var targets []generator.BuildTarget
+	for _, value := range values {
+		if value == buildAll {
+			targets = append(targets, generator.DefaultBuildTargets...)
+			continue
+		}
+		target, err := generator.ParseBuildTarget(value)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// completeBuildTargets completes --build with the common platforms
+func completeBuildTargets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	values := []string{buildAll}
+	for _, target := range generator.DefaultBuildTargets {
+		values = append(values, target.String())
+	}
+	return completeValues(values...)(cmd, args, toComplete)
+}
+
+// generateSpec runs the pipeline for one specification, reporting each
+// stage, and compiles the server for targets, if any
+func generateSpec(cmd *cobra.Command, specPath string, opts generator.Options, targets []generator.BuildTarget) error {
+	out := cmd.OutOrStdout()
+	sarif := generateFlags.findings == findingsSARIF
+	if sarif && generateFlags.findingsOut == findingsStdout {
+		// Stdout carries the SARIF log only
+		out = cmd.ErrOrStderr()
+	}
+	progress := progressOutput(out)
+	fmt.Fprintln(progress, "Processing OpenAPI specification...")
+
+	var spec *parser.ParsedSpec
+	err := stage(progress, fmt.Sprintf("Parsing specification (%s)", specPath), func() (err error) {
+		spec, err = parser.NewService().ParseFile(specPath)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	err = stage(progress, "Validating OpenAPI format", func() error {
+		return parser.NewService().Validate(cmd.Context(), spec)
+	})
+	if err != nil {
+		return err
+	}
+
+	var server *transformer.MCPServer
+	err = stage(progress, fmt.Sprintf("Analyzing %d endpoints", len(spec.Operations())), func() (err error) {
+		server, err = newTransformer().Transform(spec)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var result *generator.GenerationResult
+	genErr := stage(progress, "Generating MCP server code", func() (err error) {
+		result, err = generator.NewService().Generate(server, opts)
+		return err
+	})
+	// A server that does not build is reported with the written files
+	if result == nil {
+		return genErr
+	}
+	var built *generator.BuildResult
+	if genErr == nil && len(targets) > 0 {
+		built, genErr = buildGenerated(progress, opts.OutputDir, targets)
+	}
+	if sarif {
+		if err := writeFindingsSARIF(cmd.OutOrStdout(), generateFlags.findingsOut, result.Findings); err != nil {
+			return err
+		}
+	}
+	if jsonOutput {
+		generation := newJSONGeneration(specPath, result, opts.DryRun, genErr)
+		generation.Artifacts = newJSONArtifacts(built)
+		if err := writeJSON(out, generation); err != nil {
+			return err
+		}
+		return genErr
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+	}
+	if !sarif {
+		for _, finding := range result.Findings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", finding)
+		}
+	}
+	printGenerationResult(out, result, opts.DryRun)
+	printBuildResult(out, built)
+	return genErr
+}
+
+// writeFindingsSARIF writes the findings of a generation as a SARIF log to
+// path, or to stdout for findingsStdout
+func writeFindingsSARIF(stdout io.Writer, path string, findings []generator.Finding) error {
+	if path == findingsStdout {
+		return generator.WriteSARIF(stdout, version, findings)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to create findings file", err).
+			WithFile(path)
+	}
+	if err := generator.WriteSARIF(file, version, findings); err != nil {
+		file.Close()
+		return common.NewError(common.ErrorTypeGeneration, "failed to write findings file", err).
+			WithFile(path)
+	}
+	return file.Close()
+}
+
+// buildGenerated compiles a generated server for targets, reporting each
+// like a stage
+func buildGenerated(progress io.Writer, dir string, targets []generator.BuildTarget) (*generator.BuildResult, error) {
+	var started time.Time
+	return generator.NewService().Build(generator.BuildOptions{
+		Dir:     dir,
+		Targets: targets,
+		Progress: func(event generator.BuildEvent) {
+			if !event.Done {
+				started = time.Now()
+				return
+			}
+			mark := "✓"
+			if event.Err != nil {
+				mark = "✗"
+			}
+			name := fmt.Sprintf("Building %s (%d/%d)", event.Target, event.Index+1, event.Total)
+			if verbose {
+				fmt.Fprintf(progress, "%s %s (%s)\n", mark, name, time.Since(started).Round(time.Millisecond))
+			} else {
+				fmt.Fprintf(progress, "%s %s\n", mark, name)
+			}
+		},
+	})
+}
+
+// printBuildResult lists the binaries of a build, if any
+func printBuildResult(out io.Writer, built *generator.BuildResult) {
+	if built == nil || len(built.Artifacts) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\nBuilt %d binaries in %s:\n", len(built.Artifacts), built.Duration.Round(time.Millisecond))
+	for _, artifact := range built.Artifacts {
+		fmt.Fprintf(out, "  %s - %s, %d bytes\n", artifact.Path, artifact.Target, artifact.Size)
+	}
+}
+
+// stage runs one step of the pipeline and reports it like a checklist, with
+// timings in verbose mode
+func stage(out io.Writer, name string, run func() error) error {
+	start := time.Now()
+	err := run()
+	mark := "✓"
+	if err != nil {
+		mark = "✗"
+	}
+	if verbose {
+		fmt.Fprintf(out, "%s %s (%s)\n", mark, name, time.Since(start).Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(out, "%s %s\n", mark, name)
+	}
+	return err
+}
+
+// printGenerationResult summarizes the files of a generation
+func printGenerationResult(out io.Writer, result *generator.GenerationResult, dryRun bool) {
+	if dryRun {
+		fmt.Fprintln(out, "\nDry run; no files were written.")
+		if result.Diff == "" {
+			fmt.Fprintln(out, "The output directory is up to date.")
+		}
+		fmt.Fprint(out, result.Diff)
+		return
+	}
+
+	fmt.Fprintf(out, "\nGeneration complete! Files in %s:\n", result.OutputDir)
+	for _, file := range result.Files {
+		if verbose {
+			fmt.Fprintf(out, "  %s - %s, %d bytes (%s)\n", file.Path, file.Status, file.Size, file.Template)
+		} else {
+			fmt.Fprintf(out, "  %s - %s\n", file.Path, file.Status)
+		}
+	}
+	fmt.Fprintf(out, "\n%d tools generated in %s\n", result.ToolCount, result.Duration.Round(time.Millisecond))
+}
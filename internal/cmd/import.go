@@ -0,0 +1,494 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/parser"
+	"MCPWeaver/internal/transformer"
+)
+
+// Import targets
+const (
+	importAsSpec    = "spec"
+	importAsProject = "project"
+)
+
+// projectSpecFile names the specification inside an imported project
+const projectSpecFile = "openapi"
+
+// importFlags holds the flags of the import command
+var importFlags struct {
+	as              string
+	output          string
+	headers         []string
+	token           string
+	user            string
+	cookies         []string
+	saveCredentials bool
+	sha256          string
+	maxSizeMB       int64
+	retries         int
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <url-or-file>",
+	Short: "Import a specification or Postman collection from a URL or file",
+	Long: `Import downloads or reads an OpenAPI specification or a Postman v2.0/v2.1
+collection, validates it and saves it for the other commands. Collections
+are converted to OpenAPI 3: folders become tags, request names operation IDs,
+and schemas are inferred from example bodies and saved responses.
+
+With --as spec, the specification is written to --output, by default a file
+in the working directory named after the API title. With --as project, a
+directory named after the title is created holding the specification and a
+.mcpweaver.yaml that points generate at it. Existing files are never
+overwritten.
+
+Specifications behind authentication are downloaded with --token, --user,
+--header and --cookie. With --save-credentials, these are stored for the
+URL's host in ~/.config/mcpweaver/credentials.yaml, readable by the user
+only, and sent with later downloads from it, including by init. A stored
+value written ${NAME} is read from the environment variable NAME instead.
+Credentials are not sent to other hosts the URL redirects to.
+
+Interrupted downloads are retried --retries times, resuming where they
+stopped when the server supports it. Downloads larger than --max-size-mb
+fail, and with --sha256 the specification is only accepted when its
+checksum matches.
+
+The path of the specification or project is printed on the last line, and
+alone with --ci, for use in follow-up commands.`,
+	Example: `  mcpweaver import https://petstore3.swagger.io/api/v3/openapi.json
+  mcpweaver import collection.postman_collection.json --output api.yaml
+  mcpweaver import https://example.com/openapi.yaml --as project
+  mcpweaver import https://portal.internal/api.yaml --token "$PORTAL_TOKEN" --save-credentials
+  mcpweaver import https://portal.internal/api.yaml --header "X-Tenant: acme" --cookie session=abc
+  mcpweaver import https://example.com/large.json --max-size-mb 128 --sha256 "$(cat large.json.sha256)"
+  cd "$(mcpweaver import api.yaml --as project --ci)" && mcpweaver generate`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeImportSources,
+	RunE:              runImport,
+}
+
+func init() {
+	flags := importCmd.Flags()
+	flags.StringVar(&importFlags.as, "as", importAsSpec, "what to create: spec or project")
+	flags.StringVarP(&importFlags.output, "output", "o", "", "specification file or project directory to create (default: named after the API title)")
+	flags.StringArrayVar(&importFlags.headers, "header", nil, `"Name: value" header sent with the download; repeatable`)
+	flags.StringVar(&importFlags.token, "token", "", "bearer token sent with the download")
+	flags.StringVar(&importFlags.user, "user", "", `"name:password" sent with basic authentication`)
+	flags.StringArrayVar(&importFlags.cookies, "cookie", nil, `"name=value" cookie sent with the download; repeatable`)
+	flags.BoolVar(&importFlags.saveCredentials, "save-credentials", false, "store the given credentials for the URL's host")
+	flags.StringVar(&importFlags.sha256, "sha256", "", "hex SHA-256 checksum the specification must have")
+	flags.Int64Var(&importFlags.maxSizeMB, "max-size-mb", 32, "largest download accepted, in MiB")
+	flags.IntVar(&importFlags.retries, "retries", common.DefaultAttempts-1, "times an interrupted or failed download is tried again")
+	registerCompletions(importCmd, map[string]cobra.CompletionFunc{
+		"as":     completeValues(importAsSpec, importAsProject),
+		"output": completeDirs,
+	})
+	rootCmd.AddCommand(importCmd)
+}
+
+// completeImportSources completes specification and Postman collection files
+func completeImportSources(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSpecs(cmd, args, toComplete)
+}
+
+// importResult is the JSON output of the import command
+type importResult struct {
+	Source   string `json:"source"`
+	Format   string `json:"format"`
+	Title    string `json:"title"`
+	Tools    int    `json:"tools"`
+	As       string `json:"as"`
+	Path     string `json:"path"`
+	SpecFile string `json:"specFile"`
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	if importFlags.as != importAsSpec && importFlags.as != importAsProject {
+		return fmt.Errorf("invalid --as %q: use spec or project", importFlags.as)
+	}
+	source := args[0]
+	if importFlags.sha256 != "" {
+		if err := common.CheckSHA256(importFlags.sha256); err != nil {
+			return fmt.Errorf("invalid --sha256: %w", err)
+		}
+	}
+	if importFlags.maxSizeMB <= 0 || importFlags.retries < 0 {
+		return fmt.Errorf("--max-size-mb must be positive and --retries not negative")
+	}
+	fetchOpts, given, err := importCredentials(source)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	progress := progressOutput(out)
+	fetchOpts.SHA256 = importFlags.sha256
+	fetchOpts.MaxSize = importFlags.maxSizeMB << 20
+	fetchOpts.Attempts = importFlags.retries + 1
+	fetchOpts.Retry = func(attempt int, err error) {
+		fmt.Fprintf(progress, "  retrying, attempt %d of %d: %s\n", attempt, fetchOpts.Attempts, firstLine(err.Error()))
+	}
+	service := parser.NewService()
+	display := parser.RedactURL(source)
+	fmt.Fprintf(progress, "Importing %s...\n", display)
+
+	var data []byte
+	var location string
+	fetch := "Reading " + source
+	if parser.IsURL(source) {
+		fetch = "Downloading " + display
+	}
+	err = stage(progress, fetch, func() (err error) {
+		data, location, err = service.Fetch(cmd.Context(), source, fetchOpts)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if importFlags.saveCredentials {
+		store, err := loadCredentials()
+		if err != nil {
+			return err
+		}
+		store.set(given)
+		path, err := store.save()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(progress, "  credentials for %s saved to %s\n", given.URL, path)
+	}
+
+	postman := parser.IsPostmanCollection(data)
+	parse := "Parsing specification"
+	if postman {
+		parse = "Converting Postman collection to OpenAPI 3"
+	}
+	var spec *parser.ParsedSpec
+	err = stage(progress, parse, func() (err error) {
+		spec, err = service.ParseImport(data, location)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	err = stage(progress, "Validating OpenAPI format", func() error {
+		return service.Validate(cmd.Context(), spec)
+	})
+	if err != nil {
+		return err
+	}
+	var server *transformer.MCPServer
+	err = stage(progress, fmt.Sprintf("Analyzing %d endpoints", len(spec.Operations())), func() (err error) {
+		server, err = newTransformer().Transform(spec)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Specifications are saved as published; converted collections are
+	// written as YAML unless a .json file is asked for
+	content, ext := data, specExtension(source, data)
+	if postman {
+		ext = ".yaml"
+		if filepath.Ext(importFlags.output) == ".json" && importFlags.as == importAsSpec {
+			ext = ".json"
+		}
+	}
+
+	result := importResult{
+		Source: display,
+		Format: specFormat(spec),
+		Title:  spec.Title(),
+		Tools:  len(server.Tools),
+		As:     importFlags.as,
+	}
+	err = stage(progress, "Saving "+importFlags.as, func() (err error) {
+		if postman {
+			if content, err = documentContent(spec.Document, ext); err != nil {
+				return common.NewError(common.ErrorTypeParse, "failed to write converted collection", err)
+			}
+		}
+		if importFlags.as == importAsProject {
+			result.Path, result.SpecFile, err = writeImportProject(server.Name, ext, content, display)
+		} else {
+			result.Path, err = writeImportSpec(server.Name, ext, content)
+			result.SpecFile = result.Path
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return writeJSON(out, result)
+	}
+	fmt.Fprintf(progress, "\nImported %s (%s) with %d tools. Generate the server with:\n", result.Title, result.Format, result.Tools)
+	if importFlags.as == importAsProject {
+		fmt.Fprintf(progress, "  cd %s && mcpweaver generate\n\n", result.Path)
+	} else {
+		fmt.Fprintf(progress, "  mcpweaver generate %s\n\n", result.Path)
+	}
+	fmt.Fprintln(out, result.Path)
+	return nil
+}
+
+// importCredentials combines the credentials stored for the source with the
+// ones given as flags, which take precedence. It also returns the given
+// credentials in the form they are stored in, for the source's host.
+func importCredentials(source string) (parser.FetchOptions, sourceCredentials, error) {
+	given := sourceCredentials{Token: importFlags.token}
+	if importFlags.user != "" {
+		var ok bool
+		if given.Username, given.Password, ok = strings.Cut(importFlags.user, ":"); !ok {
+			return parser.FetchOptions{}, given, fmt.Errorf(`invalid --user; use "name:password"`)
+		}
+	}
+	for _, header := range importFlags.headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return parser.FetchOptions{}, given, fmt.Errorf(`invalid --header %q; use "Name: value"`, header)
+		}
+		if given.Headers == nil {
+			given.Headers = map[string]string{}
+		}
+		given.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	for _, cookie := range importFlags.cookies {
+		name, value, ok := strings.Cut(cookie, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return parser.FetchOptions{}, given, fmt.Errorf(`invalid --cookie %q; use "name=value"`, cookie)
+		}
+		if given.Cookies == nil {
+			given.Cookies = map[string]string{}
+		}
+		given.Cookies[strings.TrimSpace(name)] = value
+	}
+
+	hasGiven := given.Token != "" || given.Username != "" || len(given.Headers) > 0 || len(given.Cookies) > 0
+	if !parser.IsURL(source) {
+		if hasGiven || importFlags.saveCredentials {
+			return parser.FetchOptions{}, given, fmt.Errorf("credentials apply to URL sources only")
+		}
+		return parser.FetchOptions{}, given, nil
+	}
+	if importFlags.saveCredentials && !hasGiven {
+		return parser.FetchOptions{}, given, fmt.Errorf("--save-credentials needs --token, --user, --header or --cookie")
+	}
+	u, _ := url.Parse(source)
+	given.URL = (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}).String()
+
+	opts, err := sourceFetchOptions(source)
+	if err != nil {
+		return opts, given, err
+	}
+	flagOpts, err := given.fetchOptions()
+	if err != nil {
+		return opts, given, err
+	}
+	if flagOpts.Token != "" || flagOpts.Username != "" {
+		opts.Token, opts.Username, opts.Password = flagOpts.Token, flagOpts.Username, flagOpts.Password
+	}
+	for name, values := range flagOpts.Header {
+		if opts.Header == nil {
+			opts.Header = http.Header{}
+		}
+		opts.Header[name] = values
+	}
+	opts.Cookies = append(opts.Cookies, flagOpts.Cookies...)
+	return opts, given, nil
+}
+
+// writeImportSpec writes the specification to --output or, without it, to
+// a file named after the API
+func writeImportSpec(name, ext string, content []byte) (string, error) {
+	path := importFlags.output
+	if path == "" {
+		path = name + ext
+	}
+	if err := createFile(path, content); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeImportProject creates the project directory holding the
+// specification and its configuration
+func writeImportProject(name, ext string, content []byte, source string) (string, string, error) {
+	dir := importFlags.output
+	if dir == "" {
+		dir = name
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", "", common.NewError(common.ErrorTypeGeneration, "failed to read project directory", err).WithFile(dir)
+	}
+	if len(entries) > 0 {
+		return "", "", common.NewError(common.ErrorTypeGeneration, "project directory is not empty", nil).
+			WithFile(dir).
+			WithSuggestion("Choose another directory with --output")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", common.NewError(common.ErrorTypeGeneration, "failed to create project directory", err).WithFile(dir)
+	}
+
+	specFile := projectSpecFile + ext
+	config := fmt.Sprintf("# Imported from %s\nspec: %s\noutput: server\n", source, specFile)
+	if err := createFile(filepath.Join(dir, specFile), content); err != nil {
+		return "", "", err
+	}
+	if err := createFile(filepath.Join(dir, projectConfigFile), []byte(config)); err != nil {
+		return "", "", err
+	}
+	return dir, filepath.Join(dir, specFile), nil
+}
+
+// createFile writes a new file, refusing to replace an existing one
+func createFile(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return common.NewError(common.ErrorTypeGeneration, "file already exists", err).
+			WithFile(path).
+			WithSuggestion("Remove it or choose another path with --output")
+	}
+	if err == nil {
+		_, err = file.Write(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to write file", err).WithFile(path)
+	}
+	return nil
+}
+
+// specExtension picks the extension of a saved specification: the source's
+// when it has a known one, otherwise .json for JSON content and .yaml
+func specExtension(source string, data []byte) string {
+	if parser.IsURL(source) {
+		source = strings.SplitN(source, "?", 2)[0]
+	}
+	switch ext := strings.ToLower(filepath.Ext(source)); ext {
+	case ".yaml", ".yml", ".json":
+		return ext
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return ".json"
+	}
+	return ".yaml"
+}
+
+// specFormat names the format and version the specification was read in
+func specFormat(spec *parser.ParsedSpec) string {
+	switch {
+	case strings.HasPrefix(spec.OriginalVersion, "postman"):
+		return spec.OriginalVersion
+	case spec.OriginalVersion == "2.0":
+		return "swagger 2.0"
+	default:
+		return "openapi " + spec.OriginalVersion
+	}
+}
+
+// documentFieldOrder orders the top-level fields of a converted document the
+// way specifications are usually written
+var documentFieldOrder = []string{"openapi", "info", "servers", "security", "tags", "paths", "components"}
+
+// documentContent encodes a converted document as JSON or YAML
+func documentContent(doc *openapi3.T, ext string) ([]byte, error) {
+	content, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// Marshaling sorts the fields, so the top-level ones are put back in order
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.SliceStable(names, func(a, b int) bool {
+		if fieldRank(names[a]) != fieldRank(names[b]) {
+			return fieldRank(names[a]) < fieldRank(names[b])
+		}
+		return names[a] < names[b]
+	})
+	var ordered bytes.Buffer
+	ordered.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			ordered.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		ordered.Write(key)
+		ordered.WriteByte(':')
+		ordered.Write(fields[name])
+	}
+	ordered.WriteByte('}')
+
+	if ext == ".json" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, ordered.Bytes(), "", "  "); err != nil {
+			return nil, err
+		}
+		indented.WriteByte('\n')
+		return indented.Bytes(), nil
+	}
+
+	// JSON is YAML, so it decodes into a node keeping the field order; the
+	// flow and quoting styles of JSON are cleared for block output
+	var node yaml.Node
+	if err := yaml.Unmarshal(ordered.Bytes(), &node); err != nil {
+		return nil, err
+	}
+	var clearStyle func(*yaml.Node)
+	clearStyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			clearStyle(child)
+		}
+	}
+	clearStyle(&node)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+// fieldRank is the position of a top-level field in documentFieldOrder
+func fieldRank(name string) int {
+	for rank, field := range documentFieldOrder {
+		if field == name {
+			return rank
+		}
+	}
+	return len(documentFieldOrder)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const importCollection = `{
+	"info": {"name": "User Service", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"variable": [{"key": "baseUrl", "value": "https://api.example.com"}],
+	"item": [
+		{"name": "Get user", "request": {"method": "GET", "url": "{{baseUrl}}/users/:id"}},
+		{"name": "Delete user", "request": {"method": "DELETE", "url": "{{baseUrl}}/users/:userId"}}
+	]
+}`
+
+// importFile runs the import command on a source with the flags set by
+// configure, returning the output
+func importFile(t *testing.T, source string, configure func()) (string, error) {
+	t.Helper()
+	saved := importFlags
+	defer func() { importFlags = saved }()
+	importFlags.as = importAsSpec
+	importFlags.maxSizeMB = 32
+	if configure != nil {
+		configure()
+	}
+	var out bytes.Buffer
+	importCmd.SetOut(&out)
+	importCmd.SetContext(context.Background())
+	err := runImport(importCmd, []string{source})
+	return out.String(), err
+}
+
+func TestImportPostmanCollection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	source := filepath.Join(dir, "users.postman_collection.json")
+	require.NoError(t, os.WriteFile(source, []byte(importCollection), 0644))
+
+	spec := filepath.Join(dir, "users.yaml")
+	out, err := importFile(t, source, func() { importFlags.output = spec })
+	require.NoError(t, err)
+	assert.Contains(t, out, "Imported User Service (postman v2.1.0) with 2 tools")
+	lines := bytes.Split(bytes.TrimSpace([]byte(out)), []byte("\n"))
+	assert.Equal(t, spec, string(lines[len(lines)-1]), "the path is printed last")
+
+	var doc struct {
+		OpenAPI string                    `yaml:"openapi"`
+		Servers []map[string]string       `yaml:"servers"`
+		Paths   map[string]map[string]any `yaml:"paths"`
+	}
+	content, err := os.ReadFile(spec)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(content, &doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "https://api.example.com", doc.Servers[0]["url"])
+	require.Contains(t, doc.Paths, "/users/{id}")
+	assert.Len(t, doc.Paths, 1, "both requests are operations of one path")
+
+	_, err = importFile(t, source, func() { importFlags.output = spec })
+	assert.ErrorContains(t, err, "file already exists", "existing files are never overwritten")
+
+	// A .json output is written as JSON
+	jsonSpec := filepath.Join(dir, "users.json")
+	_, err = importFile(t, source, func() { importFlags.output = jsonSpec })
+	require.NoError(t, err)
+	content, err = os.ReadFile(jsonSpec)
+	require.NoError(t, err)
+	assert.True(t, json.Valid(content))
+}
+
+func TestImportProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	source := filepath.Join(dir, "collection.json")
+	require.NoError(t, os.WriteFile(source, []byte(importCollection), 0644))
+
+	project := filepath.Join(dir, "users")
+	_, err := importFile(t, source, func() { importFlags.as, importFlags.output = importAsProject, project })
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(project, "openapi.yaml"))
+	config, err := os.ReadFile(filepath.Join(project, projectConfigFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "spec: openapi.yaml\noutput: server\n")
+
+	_, err = importFile(t, source, func() { importFlags.as, importFlags.output = importAsProject, project })
+	assert.ErrorContains(t, err, "project directory is not empty")
+}
+
+func TestImportURLWithCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(importCollection))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	_, err := importFile(t, server.URL+"/collection", func() { importFlags.output = filepath.Join(dir, "denied.yaml") })
+	assert.ErrorContains(t, err, "401")
+
+	_, err = importFile(t, server.URL+"/collection", func() {
+		importFlags.output = filepath.Join(dir, "first.yaml")
+		importFlags.token = "secret"
+		importFlags.headers = []string{"X-Tenant: acme"}
+		importFlags.saveCredentials = true
+	})
+	require.NoError(t, err)
+	path, err := credentialsPath()
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Stored credentials are sent with later downloads from the host, and
+	// flags override them
+	_, err = importFile(t, server.URL+"/collection", func() { importFlags.output = filepath.Join(dir, "second.yaml") })
+	require.NoError(t, err)
+	_, err = importFile(t, server.URL+"/collection", func() {
+		importFlags.output = filepath.Join(dir, "third.yaml")
+		importFlags.token = "wrong"
+	})
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestImportFlagErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	source := filepath.Join(t.TempDir(), "collection.json")
+	require.NoError(t, os.WriteFile(source, []byte(importCollection), 0644))
+
+	tests := []struct {
+		name      string
+		configure func()
+		err       string
+	}{
+		{"as", func() { importFlags.as = "server" }, `invalid --as "server"`},
+		{"sha256", func() { importFlags.sha256 = "abc" }, "invalid --sha256"},
+		{"checksum mismatch", func() { importFlags.sha256 = "0000000000000000000000000000000000000000000000000000000000000000" }, "does not match the expected checksum"},
+		{"max size", func() { importFlags.maxSizeMB = 0 }, "--max-size-mb must be positive"},
+		{"credentials for a file", func() { importFlags.token = "secret" }, "credentials apply to URL sources only"},
+		{"header", func() { importFlags.headers = []string{"no colon"} }, `invalid --header "no colon"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := importFile(t, source, tt.configure)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSpecExtension(t *testing.T) {
+	assert.Equal(t, ".yml", specExtension("api.YML", nil))
+	assert.Equal(t, ".json", specExtension("https://example.com/openapi.json?v=2", nil))
+	assert.Equal(t, ".json", specExtension("https://example.com/spec", []byte(` {"openapi": "3.0.0"}`)))
+	assert.Equal(t, ".yaml", specExtension("https://example.com/spec", []byte("openapi: 3.0.0")))
+}
@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+	"MCPWeaver/internal/parser"
+	"MCPWeaver/internal/transformer"
+)
+
+// maxListedSpecs bounds the specifications offered from the working directory
+const maxListedSpecs = 20
+
+// initFlags holds the flags of the init command
+var initFlags struct {
+	templateDir string
+	language    string
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init [openapi-spec]",
+	Short: "Generate an MCP server with a guided, interactive wizard",
+	Long: `Init walks through generating a server step by step: choosing a
+specification (a file found in the working directory, any path, a URL or a
+Postman collection), reviewing its validation summary, choosing the
+operations to expose as tools, the template set and profile, the language
+of the documentation, the values of the variables the template package
+declares, and the output directory. It
+then generates the server and, when every tool of a specification file is
+kept, prints the equivalent generate command.
+
+With --template-dir, the custom template package is layered over the chosen
+template set and its variables are asked for; secrets are shown as typed,
+so prefer setting the environment variable they name beforehand.
+
+Press Enter to accept the default shown in brackets.`,
+	Example: `  mcpweaver init
+  mcpweaver init api.yaml
+  mcpweaver init api.yaml --template-dir ./acme-templates
+  mcpweaver init api.yaml --lang ja`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSpecs,
+	RunE:              runInit,
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initFlags.templateDir, "template-dir", "", "custom template package layered over the chosen template set")
+	initCmd.Flags().StringVar(&initFlags.language, "lang", "", langUsage+" (default: asked when the template has translations)")
+	registerCompletions(initCmd, map[string]cobra.CompletionFunc{
+		"template-dir": completeDirs,
+		"lang":         completeLanguages,
+	})
+	rootCmd.AddCommand(initCmd)
+}
+
+// initChoices are the answers collected by the wizard
+type initChoices struct {
+	spec     string
+	parsed   *parser.ParsedSpec
+	server   *transformer.MCPServer
+	selected []int
+	opts     generator.Options
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	if jsonOutput || ciMode {
+		return fmt.Errorf("init is interactive; use generate with --json or --ci")
+	}
+	out := cmd.OutOrStdout()
+	p := newPrompter(cmd.InOrStdin(), out)
+	fmt.Fprintln(out, "MCPWeaver setup: answer a few questions to generate an MCP server.")
+
+	var choices initChoices
+	var spec *parser.ParsedSpec
+	source := ""
+	if len(args) == 1 {
+		source = args[0]
+	}
+	for spec == nil {
+		if source == "" {
+			var err error
+			if source, err = chooseSpec(p, out); err != nil {
+				return err
+			}
+		}
+		var err error
+		spec, choices.server, err = initLoad(cmd, out, source)
+		if err != nil {
+			fmt.Fprintf(out, "\n%s\n", FormatError(err))
+			spec, source = nil, ""
+		}
+	}
+	choices.spec, choices.parsed = source, spec
+	printSpecSummary(out, spec, choices.server)
+
+	var err error
+	if choices.selected, err = chooseTools(p, out, choices.server.Tools); err != nil {
+		return err
+	}
+	if choices.opts, err = chooseGeneration(p, out, choices.server.Name); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nReady to generate %d of %d tools from %s into %s with %s (%s profile).\n",
+		len(choices.selected), len(choices.server.Tools), parser.RedactURL(choices.spec), choices.opts.OutputDir, choices.opts.Template, choices.opts.Profile)
+	generate, err := p.confirm("Generate now?", true)
+	if err != nil {
+		return err
+	}
+	if !generate {
+		fmt.Fprintln(out, "Nothing was generated.")
+		return nil
+	}
+	return initGenerate(cmd, out, choices)
+}
+
+// chooseSpec offers the specifications found in the working directory and
+// accepts any other path or URL
+func chooseSpec(p *prompter, out io.Writer) (string, error) {
+	specs, _, err := discoverSpecs(".")
+	if err != nil || len(specs) == 0 {
+		fmt.Fprintln(out, "\nNo specifications were found in the working directory.")
+		for {
+			answer, err := p.ask("Path or URL of the OpenAPI specification or Postman collection", "")
+			if err != nil || answer != "" {
+				return answer, err
+			}
+		}
+	}
+
+	fmt.Fprintln(out, "\nStep 1: Specification")
+	if len(specs) > maxListedSpecs {
+		specs = specs[:maxListedSpecs]
+	}
+	options := append(specs, "Another path or URL")
+	i, err := p.choose("Specification", options, 0)
+	if err != nil || i < len(specs) {
+		return options[i], err
+	}
+	for {
+		answer, err := p.ask("Path or URL", "")
+		if err != nil || answer != "" {
+			return answer, err
+		}
+	}
+}
+
+// initLoad reads, validates and maps the specification, reporting each
+// stage like generate does
+func initLoad(cmd *cobra.Command, out io.Writer, source string) (*parser.ParsedSpec, *transformer.MCPServer, error) {
+	service := parser.NewService()
+	var spec *parser.ParsedSpec
+	err := stage(out, fmt.Sprintf("Parsing specification (%s)", parser.RedactURL(source)), func() error {
+		// URLs are downloaded with the credentials stored by import
+		opts, err := sourceFetchOptions(source)
+		if err != nil {
+			return err
+		}
+		data, location, err := service.Fetch(cmd.Context(), source, opts)
+		if err == nil {
+			spec, err = service.ParseImport(data, location)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	err = stage(out, "Validating OpenAPI format", func() error {
+		return service.Validate(cmd.Context(), spec)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var server *transformer.MCPServer
+	err = stage(out, fmt.Sprintf("Analyzing %d endpoints", len(spec.Operations())), func() (err error) {
+		server, err = newTransformer().Transform(spec)
+		return err
+	})
+	if err == nil && len(server.Tools) == 0 {
+		err = errors.New("the specification has no operations to expose as tools")
+	}
+	return spec, server, err
+}
+
+// printSpecSummary describes the API the server will wrap
+func printSpecSummary(out io.Writer, spec *parser.ParsedSpec, server *transformer.MCPServer) {
+	fmt.Fprintln(out, "\nStep 2: Validation summary")
+	fmt.Fprintf(out, "  API:        %s %s (%s)\n", server.Title, server.Version, specFormat(spec))
+	if server.BaseURL != "" {
+		fmt.Fprintf(out, "  Base URL:   %s\n", server.BaseURL)
+	}
+	fmt.Fprintf(out, "  Operations: %d\n", len(server.Tools))
+	if len(server.Auth.Schemes) > 0 {
+		var schemes []string
+		for _, scheme := range server.Auth.Schemes {
+			schemes = append(schemes, fmt.Sprintf("%s (%s)", scheme.Name, scheme.Type))
+		}
+		fmt.Fprintf(out, "  Auth:       %s\n", strings.Join(schemes, ", "))
+	}
+	for _, warning := range server.Warnings {
+		fmt.Fprintf(out, "  Warning: %s\n", warning)
+	}
+}
+
+// chooseTools lists the tools and asks which to generate
+func chooseTools(p *prompter, out io.Writer, tools []transformer.MCPTool) ([]int, error) {
+	fmt.Fprintln(out, "\nStep 3: Tools")
+	for i, tool := range tools {
+		fmt.Fprintf(out, "  %2d) %-30s %s %s\n", i+1, tool.Name, tool.HTTPConfig.Method, tool.HTTPConfig.Path)
+	}
+	for {
+		answer, err := p.ask("Tools to generate, e.g. 1,3-5", "all")
+		if err != nil {
+			return nil, err
+		}
+		selected, err := parseSelection(answer, len(tools))
+		if err == nil {
+			return selected, nil
+		}
+		fmt.Fprintf(out, "  %s\n", err)
+	}
+}
+
+// chooseGeneration asks for the template set, profile and output directory
+func chooseGeneration(p *prompter, out io.Writer, name string) (generator.Options, error) {
+	var opts generator.Options
+
+	fmt.Fprintln(out, "\nStep 4: Template")
+	sets := generator.TemplateSets()
+	var options []string
+	def := 0
+	for i, set := range sets {
+		options = append(options, set.Name+" - "+set.Description)
+		if set.Name == generator.DefaultTemplate {
+			def = i
+		}
+	}
+	i, err := p.choose("Template set", options, def)
+	if err != nil {
+		return opts, err
+	}
+	opts.Template = sets[i].Name
+
+	options = options[:0]
+	for i, profile := range generator.Profiles {
+		options = append(options, profile.Name+" - "+profile.Description)
+		if profile.Name == generator.DefaultProfile {
+			def = i
+		}
+	}
+	if i, err = p.choose("Profile", options, def); err != nil {
+		return opts, err
+	}
+	opts.Profile = generator.Profiles[i].Name
+
+	opts.TemplateDir = initFlags.templateDir
+	if opts.Language, err = chooseLanguage(p, opts); err != nil {
+		return opts, err
+	}
+	declared, err := generator.NewService().TemplateVariables(opts)
+	if err != nil {
+		return opts, err
+	}
+	if opts.Variables, err = chooseVariables(p, out, declared); err != nil {
+		return opts, err
+	}
+
+	fmt.Fprintln(out, "\nStep 5: Output")
+	for {
+		opts.OutputDir, err = p.ask("Output directory", "./"+name)
+		if err != nil {
+			return opts, err
+		}
+		entries, _ := os.ReadDir(opts.OutputDir)
+		if len(entries) == 0 {
+			return opts, nil
+		}
+		fmt.Fprintf(out, "  %s is not empty; generated files in it will be replaced.\n", opts.OutputDir)
+		replace, err := p.confirm("Use it anyway?", false)
+		if err != nil || replace {
+			return opts, err
+		}
+	}
+}
+
+// chooseLanguage asks for the documentation language when the template
+// package has translations and --lang did not select one
+func chooseLanguage(p *prompter, opts generator.Options) (string, error) {
+	languages, err := generator.NewService().Languages(opts)
+	if err != nil {
+		return "", err
+	}
+	if initFlags.language != "" {
+		for _, language := range languages {
+			if language == initFlags.language {
+				return language, nil
+			}
+		}
+		return "", fmt.Errorf("the template has no %q translation; available languages: %s", initFlags.language, strings.Join(languages, ", "))
+	}
+	if len(languages) < 2 {
+		return generator.DefaultLanguage, nil
+	}
+	def := 0
+	for i, language := range languages {
+		if language == generator.DefaultLanguage {
+			def = i
+		}
+	}
+	i, err := p.choose("Documentation language", languages, def)
+	if err != nil {
+		return "", err
+	}
+	return languages[i], nil
+}
+
+// chooseVariables asks for the value of each variable a template package
+// declares. Values left empty fall back to the variable's environment
+// variable or default when generating, and are not returned.
+func chooseVariables(p *prompter, out io.Writer, declared []generator.TemplateVariable) (map[string]string, error) {
+	if len(declared) == 0 {
+		return nil, nil
+	}
+	fmt.Fprintln(out, "\n  Template variables")
+	vars := map[string]string{}
+	for _, v := range declared {
+		question := fmt.Sprintf("%s (%s)", v.Name, v.Type)
+		if v.Description != "" {
+			question = fmt.Sprintf("%s - %s (%s)", v.Name, v.Description, v.Type)
+		}
+		fromEnv := false
+		if v.Env != "" {
+			_, fromEnv = os.LookupEnv(v.Env)
+		}
+		if fromEnv {
+			fmt.Fprintf(out, "  %s is set; leave %s empty to use it.\n", v.Env, v.Name)
+		}
+
+		switch v.Type {
+		case generator.VariableEnum:
+			def := 0
+			for i, option := range v.Options {
+				if option == v.Default {
+					def = i
+				}
+			}
+			i, err := p.choose(question, v.Options, def)
+			if err != nil {
+				return nil, err
+			}
+			vars[v.Name] = v.Options[i]
+			continue
+		case generator.VariableBoolean:
+			def, _ := strconv.ParseBool(v.Default)
+			answer, err := p.confirm(question, def)
+			if err != nil {
+				return nil, err
+			}
+			vars[v.Name] = strconv.FormatBool(answer)
+			continue
+		}
+
+		def := v.Default
+		if fromEnv {
+			def = ""
+		}
+		for {
+			answer, err := p.ask(question, def)
+			if err != nil {
+				return nil, err
+			}
+			if answer == "" {
+				if v.Required && !fromEnv {
+					fmt.Fprintf(out, "  %s is required\n", v.Name)
+					continue
+				}
+				break
+			}
+			if _, err := v.Parse(answer); err != nil {
+				fmt.Fprintf(out, "  %s\n", err)
+				continue
+			}
+			vars[v.Name] = answer
+			break
+		}
+	}
+	return vars, nil
+}
+
+// variableFlags writes variables as --var flags, with secrets left for the
+// user to fill in rather than printed
+func variableFlags(declared []generator.TemplateVariable, vars map[string]string) string {
+	secret := map[string]bool{}
+	for _, v := range declared {
+		secret[v.Name] = v.Type == generator.VariableSecret
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var flags strings.Builder
+	for _, name := range names {
+		value := vars[name]
+		if secret[name] {
+			value = "<secret>"
+		}
+		fmt.Fprintf(&flags, " --var %s", shellQuote(name+"="+value))
+	}
+	return flags.String()
+}
+
+// shellQuote quotes a word for a POSIX shell when it needs it
+func shellQuote(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-.,/:=@+") == "" {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// initGenerate generates the selected tools and shows how to repeat it
+func initGenerate(cmd *cobra.Command, out io.Writer, choices initChoices) error {
+	server := *choices.server
+	server.Tools = make([]transformer.MCPTool, 0, len(choices.selected))
+	for _, i := range choices.selected {
+		server.Tools = append(server.Tools, choices.server.Tools[i])
+	}
+
+	fmt.Fprintln(out)
+	var result *generator.GenerationResult
+	err := stage(out, "Generating MCP server code", func() (err error) {
+		result, err = generator.NewService().Generate(&server, choices.opts)
+		return err
+	})
+	if result == nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+	}
+	for _, finding := range result.Findings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", finding)
+	}
+	printGenerationResult(out, result, false)
+	if err != nil {
+		return err
+	}
+
+	// generate reads specification files only, and every tool
+	if len(choices.selected) == len(choices.server.Tools) && !parser.IsURL(choices.spec) && !strings.HasPrefix(choices.parsed.OriginalVersion, "postman") {
+		command := fmt.Sprintf("mcpweaver generate %s --output %s --template %s --profile %s",
+			choices.spec, filepath.Clean(choices.opts.OutputDir), choices.opts.Template, choices.opts.Profile)
+		if choices.opts.TemplateDir != "" {
+			command += " --template-dir " + shellQuote(choices.opts.TemplateDir)
+		}
+		if choices.opts.Language != generator.DefaultLanguage {
+			command += " --lang " + choices.opts.Language
+		}
+		declared, _ := generator.NewService().TemplateVariables(choices.opts)
+		command += variableFlags(declared, choices.opts.Variables)
+		fmt.Fprintf(out, "\nTo generate again without the wizard, run:\n  %s\n", command)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+// jsonOutput switches every command to machine-readable output: one JSON
+// document on stdout, and errors as JSON on stderr. Field names are part of
+// the CLI's interface; add fields rather than renaming or removing them.
+var jsonOutput bool
+
+// ciMode makes the CLI suitable for pipelines: no progress output, no
+// long-running interactive modes and a distinct exit code per failure class
+var ciMode bool
+
+// progressOutput returns where progress lines such as the generation
+// checklist go: out, or nowhere in JSON and CI modes
+func progressOutput(out io.Writer) io.Writer {
+	if jsonOutput || ciMode {
+		return io.Discard
+	}
+	return out
+}
+
+// writeJSON writes v as an indented JSON document
+func writeJSON(out io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// jsonError is the JSON form of an error
+type jsonError struct {
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+	File       string `json:"file,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Issue      string `json:"issue,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+	ExitCode   int    `json:"exitCode"`
+}
+
+// newJSONError describes err; errors outside the pipeline have type "error"
+func newJSONError(err error) *jsonError {
+	if err == nil {
+		return nil
+	}
+	var pipelineErr *common.Error
+	if !errors.As(err, &pipelineErr) {
+		return &jsonError{Type: "error", Message: err.Error(), ExitCode: ExitCode(err)}
+	}
+	jsonErr := &jsonError{
+		Type:       string(pipelineErr.Type),
+		Message:    pipelineErr.Message,
+		File:       pipelineErr.File,
+		Line:       pipelineErr.Line,
+		Suggestion: pipelineErr.Suggestion,
+		ExitCode:   ExitCode(err),
+	}
+	if pipelineErr.Err != nil {
+		jsonErr.Issue = pipelineErr.Err.Error()
+	}
+	return jsonErr
+}
+
+// jsonFile is a file of a generation
+type jsonFile struct {
+	Path     string `json:"path"`
+	Status   string `json:"status"`
+	Size     int64  `json:"size"`
+	Template string `json:"template"`
+}
+
+// jsonFinding is an issue reported by the checks of the generated code
+type jsonFinding struct {
+	Check   string `json:"check"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// jsonGeneration is the outcome of generating one specification
+type jsonGeneration struct {
+	Spec       string         `json:"spec"`
+	OutputDir  string         `json:"outputDir"`
+	DryRun     bool           `json:"dryRun"`
+	Tools      int            `json:"tools"`
+	Files      []jsonFile     `json:"files"`
+	Warnings   []string       `json:"warnings"`
+	Findings   []jsonFinding  `json:"findings"`
+	Diff       string         `json:"diff,omitempty"`
+	DurationMS int64          `json:"durationMs"`
+	Artifacts  []jsonArtifact `json:"artifacts,omitempty"`
+	Error      *jsonError     `json:"error,omitempty"`
+}
+
+// jsonArtifact is a binary compiled with generate --build
+type jsonArtifact struct {
+	Target string `json:"target"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+}
+
+// newJSONArtifacts describes the binaries of a build, if any
+func newJSONArtifacts(built *generator.BuildResult) []jsonArtifact {
+	if built == nil {
+		return nil
+	}
+	artifacts := []jsonArtifact{}
+	for _, artifact := range built.Artifacts {
+		artifacts = append(artifacts, jsonArtifact{
+			Target: artifact.Target.String(),
+			Path:   artifact.Path,
+			Size:   artifact.Size,
+		})
+	}
+	return artifacts
+}
+
+// newJSONGeneration describes a generation; result may be nil when the
+// pipeline failed before writing files
+func newJSONGeneration(spec string, result *generator.GenerationResult, dryRun bool, err error) jsonGeneration {
+	generation := jsonGeneration{
+		Spec:     spec,
+		DryRun:   dryRun,
+		Files:    []jsonFile{},
+		Warnings: []string{},
+		Findings: []jsonFinding{},
+		Error:    newJSONError(err),
+	}
+	if result == nil {
+		return generation
+	}
+	generation.OutputDir = result.OutputDir
+	generation.Tools = result.ToolCount
+	generation.Diff = result.Diff
+	generation.DurationMS = result.Duration.Milliseconds()
+	for _, file := range result.Files {
+		generation.Files = append(generation.Files, jsonFile{
+			Path:     file.Path,
+			Status:   string(file.Status),
+			Size:     file.Size,
+			Template: file.Template,
+		})
+	}
+	generation.Warnings = append(generation.Warnings, result.Warnings...)
+	generation.Findings = newJSONFindings(result.Findings)
+	return generation
+}
+
+// newJSONFindings describes findings, as an empty list when there are none
+func newJSONFindings(findings []generator.Finding) []jsonFinding {
+	described := []jsonFinding{}
+	for _, finding := range findings {
+		described = append(described, jsonFinding{
+			Check:   finding.Check,
+			File:    finding.File,
+			Line:    finding.Line,
+			Column:  finding.Column,
+			Message: finding.Message,
+		})
+	}
+	return described
+}
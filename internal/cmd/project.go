@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage project directories",
+	Long: `A project is a directory holding a specification and a .mcpweaver.yaml
+that points generate at it, as import --as project creates, along with the
+server generated from it. Project commands create, list, show, export and
+delete project directories.`,
+}
+
+// projectCreateCmd takes the flags of import, which it runs with --as
+// project; import.go is initialized first, so they are registered by now
+var projectCreateCmd = &cobra.Command{
+	Use:   "create <url-or-file> [project-dir]",
+	Short: "Create a project from a specification or Postman collection",
+	Long: `Create imports a specification or Postman collection, as import --as
+project does, into a new project directory, by default named after the API
+title. It takes the download and credential flags of import.`,
+	Example: `  mcpweaver project create https://petstore3.swagger.io/api/v3/openapi.json petstore
+  mcpweaver project create https://portal.internal/api.yaml --token "$PORTAL_TOKEN"`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeImportSources,
+	RunE:              runProjectCreate,
+}
+
+var projectListCmd = &cobra.Command{
+	Use:   "list [directory]...",
+	Short: "List the projects in directories",
+	Long: `List shows each directory given, by default the working directory, that
+is a project, and the projects directly inside it, with their
+specification, whether their server is generated, and the outcome of its
+last test.`,
+	Example: `  mcpweaver project list
+  mcpweaver project list ~/apis --json`,
+	ValidArgsFunction: completeDirs,
+	RunE:              runProjectList,
+}
+
+var projectShowCmd = &cobra.Command{
+	Use:   "show <project-dir>",
+	Short: "Show a project's specification, server and tests",
+	Long: `Show describes a project: its configuration, the API its specification
+describes with the tools it maps to, its server and the last test of the
+server. A specification that cannot be loaded is reported rather than
+failing the command.`,
+	Example:           `  mcpweaver project show ./petstore`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDirs,
+	RunE:              runProjectShow,
+}
+
+// projectExportFlags holds the flags of the project export command
+var projectExportFlags struct {
+	format string
+	force  bool
+}
+
+var projectExportCmd = &cobra.Command{
+	Use:   "export <project-dir> <archive>",
+	Short: "Export a project as a zip, tar or tar.gz archive",
+	Long: `Export writes a project directory to an archive for sharing: the
+specification, its configuration and files such as scenarios, without the
+generated server, which is generated again from them, or the test history.
+
+The format is inferred from the archive name (.zip, .tar, .tar.gz or .tgz)
+unless given with --format. An existing archive is only replaced with
+--force.`,
+	Example: `  mcpweaver project export ./petstore petstore.zip
+  mcpweaver project export ./petstore petstore --format tar.gz`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDirs,
+	RunE:              runProjectExport,
+}
+
+// projectDeleteFlags holds the flags of the project delete command
+var projectDeleteFlags struct {
+	force bool
+}
+
+var projectDeleteCmd = &cobra.Command{
+	Use:   "delete <project-dir>...",
+	Short: "Delete project directories",
+	Long: `Delete removes project directories with everything in them, including
+the generated server, after asking for confirmation. Directories that are
+not projects are refused. A server generated outside the project directory
+is left in place.
+
+--force deletes without asking, as --json and --ci require.`,
+	Example: `  mcpweaver project delete ./petstore
+  mcpweaver project delete ./old-* --force`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeDirs,
+	RunE:              runProjectDelete,
+}
+
+func init() {
+	importCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name != "as" && flag.Name != "output" {
+			projectCreateCmd.Flags().AddFlag(flag)
+		}
+	})
+	projectExportCmd.Flags().StringVar(&projectExportFlags.format, "format", "", "archive format: zip, tar or tar.gz (default: from the archive name)")
+	projectExportCmd.Flags().BoolVarP(&projectExportFlags.force, "force", "f", false, "replace an existing archive")
+	registerCompletions(projectExportCmd, map[string]cobra.CompletionFunc{
+		"format": completeValues(string(generator.ExportZip), string(generator.ExportTar), string(generator.ExportTarGz)),
+	})
+	projectDeleteCmd.Flags().BoolVarP(&projectDeleteFlags.force, "force", "f", false, "delete without asking for confirmation")
+	projectCmd.AddCommand(projectCreateCmd, projectListCmd, projectShowCmd, projectExportCmd, projectDeleteCmd)
+	rootCmd.AddCommand(projectCmd)
+}
+
+// jsonProject describes a project directory
+type jsonProject struct {
+	Name   string `json:"name"`
+	Dir    string `json:"dir"`
+	Spec   string `json:"spec"`
+	Output string `json:"output"`
+	// Generated is set when the server has been generated in Output
+	Generated bool `json:"generated"`
+	// LastTest is the newest saved result of test for the server
+	LastTest *jsonProjectTest `json:"lastTest,omitempty"`
+	// TestRuns counts the saved results of test for the server
+	TestRuns int `json:"testRuns"`
+}
+
+// jsonProjectTest is the outcome of a test run of a project's server
+type jsonProjectTest struct {
+	Time   time.Time `json:"time"`
+	Passed bool      `json:"passed"`
+}
+
+// jsonProjectDetails is the JSON output of project show
+type jsonProjectDetails struct {
+	jsonProject
+	Template    string   `json:"template,omitempty"`
+	TemplateDir string   `json:"templateDir,omitempty"`
+	Profile     string   `json:"profile,omitempty"`
+	IncludeTags []string `json:"includeTags,omitempty"`
+	ExcludeTags []string `json:"excludeTags,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Tools       []string `json:"tools"`
+	SpecError   string   `json:"specError,omitempty"`
+}
+
+// errNotProject reports a directory without a project configuration
+var errNotProject = errors.New("no " + projectConfigFile + " in the directory")
+
+// readProject describes the project in dir, returning errNotProject when
+// dir has no project configuration
+func readProject(dir string) (jsonProject, cliConfig, error) {
+	path := filepath.Join(dir, projectConfigFile)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return jsonProject{}, cliConfig{}, common.NewError(common.ErrorTypeValidation, "not a project directory", errNotProject).
+			WithFile(dir).
+			WithSuggestion("Create one with mcpweaver project create or mcpweaver import --as project")
+	}
+	config, err := readConfig(path)
+	if err != nil {
+		return jsonProject{}, cliConfig{}, common.NewError(common.ErrorTypeValidation, "invalid project configuration", err).WithFile(path)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return jsonProject{}, cliConfig{}, err
+	}
+	project := jsonProject{Name: filepath.Base(abs), Dir: dir, Spec: config.Spec, Output: config.Output}
+	if project.Output == "" {
+		project.Output = dir
+	}
+	if _, err := os.Stat(filepath.Join(project.Output, "go.mod")); err == nil {
+		project.Generated = true
+	}
+	results, _, err := readTestResults(project.Output)
+	if err == nil && len(results) > 0 {
+		project.LastTest = &jsonProjectTest{Time: results[0].Time, Passed: results[0].Passed}
+		project.TestRuns = len(results)
+	}
+	return project, config, nil
+}
+
+// findProjects returns each directory that is a project and the projects
+// directly inside it, by name
+func findProjects(dirs []string) ([]jsonProject, error) {
+	projects := []jsonProject{}
+	add := func(dir string) error {
+		project, _, err := readProject(dir)
+		if errors.Is(err, errNotProject) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		projects = append(projects, project)
+		return nil
+	}
+	for _, dir := range dirs {
+		if err := add(dir); err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeValidation, "failed to read directory", err).WithFile(dir)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+				if err := add(filepath.Join(dir, entry.Name())); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects, nil
+}
+
+func runProjectCreate(cmd *cobra.Command, args []string) error {
+	saved := importFlags
+	defer func() { importFlags = saved }()
+	importFlags.as, importFlags.output = importAsProject, ""
+	if len(args) == 2 {
+		importFlags.output = args[1]
+	}
+	return runImport(cmd, args[:1])
+}
+
+func runProjectList(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	projects, err := findProjects(args)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		return writeJSON(out, projects)
+	}
+	if len(projects) == 0 {
+		fmt.Fprintf(out, "No projects in %s\n", strings.Join(args, ", "))
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDIRECTORY\tSPECIFICATION\tSERVER\tLAST TEST")
+	for _, project := range projects {
+		server := "not generated"
+		if project.Generated {
+			server = "generated"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", project.Name, project.Dir, relativeTo(project.Dir, project.Spec), server, lastTest(project.LastTest))
+	}
+	return w.Flush()
+}
+
+// relativeTo shortens a path inside dir to its path relative to dir
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// lastTest describes the last test of a project's server
+func lastTest(test *jsonProjectTest) string {
+	if test == nil {
+		return "never"
+	}
+	status := "passed"
+	if !test.Passed {
+		status = "failed"
+	}
+	return status + " " + test.Time.Local().Format("2006-01-02 15:04")
+}
+
+func runProjectShow(cmd *cobra.Command, args []string) error {
+	project, config, err := readProject(args[0])
+	if err != nil {
+		return err
+	}
+	details := jsonProjectDetails{
+		jsonProject: project,
+		Template:    config.Template,
+		TemplateDir: config.TemplateDir,
+		Profile:     config.Profile,
+		IncludeTags: config.IncludeTags,
+		ExcludeTags: config.ExcludeTags,
+		Tools:       []string{},
+	}
+	if project.Spec == "" {
+		details.SpecError = "no spec in " + projectConfigFile
+	} else if server, err := loadServer(cmd.Context(), project.Spec); err != nil {
+		details.SpecError = firstLine(err.Error())
+	} else {
+		details.Title, details.Version = server.Title, server.Version
+		for _, tool := range server.Tools {
+			details.Tools = append(details.Tools, tool.Name)
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		return writeJSON(out, details)
+	}
+	printProject(out, details)
+	return nil
+}
+
+// printProject describes a project for project show
+func printProject(out io.Writer, p jsonProjectDetails) {
+	fmt.Fprintf(out, "Project %s (%s)\n", p.Name, p.Dir)
+	fmt.Fprintf(out, "  Specification: %s\n", p.Spec)
+	if p.SpecError != "" {
+		fmt.Fprintf(out, "  API:           cannot be loaded: %s\n", p.SpecError)
+	} else {
+		fmt.Fprintf(out, "  API:           %s %s, %d tools: %s\n", p.Title, p.Version, len(p.Tools), strings.Join(p.Tools, ", "))
+	}
+	server := "not generated"
+	if p.Generated {
+		server = "generated"
+	}
+	fmt.Fprintf(out, "  Server:        %s (%s)\n", p.Output, server)
+	template := p.Template
+	if template == "" {
+		template = generator.DefaultTemplate
+	}
+	if p.TemplateDir != "" {
+		template += " with " + p.TemplateDir
+	}
+	if p.Profile != "" {
+		template += ", " + p.Profile + " profile"
+	}
+	fmt.Fprintf(out, "  Template:      %s\n", template)
+	if len(p.IncludeTags) > 0 || len(p.ExcludeTags) > 0 {
+		fmt.Fprintf(out, "  Tags:          including %s, excluding %s\n", tagList(p.IncludeTags), tagList(p.ExcludeTags))
+	}
+	if p.LastTest == nil {
+		fmt.Fprintln(out, "  Last test:     never")
+	} else {
+		fmt.Fprintf(out, "  Last test:     %s, %d saved runs; see mcpweaver test history %s\n", lastTest(p.LastTest), p.TestRuns, p.Output)
+	}
+}
+
+// tagList joins tags for project show, "-" when there are none
+func tagList(tags []string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	return strings.Join(tags, ", ")
+}
+
+func runProjectExport(cmd *cobra.Command, args []string) error {
+	dir, dest := args[0], args[1]
+	format := generator.ExportFormat(projectExportFlags.format)
+	switch format {
+	case "":
+		var err error
+		if format, err = generator.ExportFormatFromPath(dest); err != nil {
+			return err
+		}
+	case generator.ExportZip, generator.ExportTar, generator.ExportTarGz:
+	default:
+		return fmt.Errorf("invalid --format %q; use zip, tar or tar.gz", projectExportFlags.format)
+	}
+	if _, err := os.Stat(dest); !projectExportFlags.force && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s already exists; use --force to replace it", dest)
+	}
+	project, _, err := readProject(dir)
+	if err != nil {
+		return err
+	}
+
+	// The generated server and the test history stay behind, as does the
+	// archive when it is written inside the project
+	var skipped []string
+	for _, path := range []string{project.Output, filepath.Join(dir, ".mcpweaver"), dest} {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			skipped = append(skipped, filepath.ToSlash(rel))
+		}
+	}
+	skip := func(name string) bool { return slices.Contains(skipped, name) }
+	result, err := generator.NewService().ExportDirectory(dir, skip, format, dest)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		return writeJSON(out, struct {
+			Path   string   `json:"path"`
+			Format string   `json:"format"`
+			Size   int64    `json:"size"`
+			Files  []string `json:"files"`
+		}{result.Path, string(result.Format), result.Size, result.Files})
+	}
+	fmt.Fprintf(out, "✓ Exported project %s, %d files, to %s (%s, %d bytes)\n", project.Name, len(result.Files), result.Path, result.Format, result.Size)
+	if verbose {
+		for _, file := range result.Files {
+			fmt.Fprintf(out, "  %s\n", file)
+		}
+	}
+	return nil
+}
+
+func runProjectDelete(cmd *cobra.Command, args []string) error {
+	if !projectDeleteFlags.force && (jsonOutput || ciMode) {
+		return fmt.Errorf("use --force to delete projects without confirmation")
+	}
+	var projects []jsonProject
+	for _, dir := range args {
+		project, _, err := readProject(dir)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, project)
+	}
+
+	out := cmd.OutOrStdout()
+	p := newPrompter(cmd.InOrStdin(), out)
+	deleted := []string{}
+	for _, project := range projects {
+		if !projectDeleteFlags.force {
+			ok, err := p.confirm(fmt.Sprintf("Delete project %s and everything in %s?", project.Name, project.Dir), false)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		if err := os.RemoveAll(project.Dir); err != nil {
+			return common.NewError(common.ErrorTypeGeneration, "failed to delete project", err).WithFile(project.Dir)
+		}
+		deleted = append(deleted, project.Dir)
+		if jsonOutput {
+			continue
+		}
+		fmt.Fprintf(out, "✓ Deleted project %s\n", project.Dir)
+		if rel, err := filepath.Rel(project.Dir, project.Output); err == nil && strings.HasPrefix(rel, "..") {
+			fmt.Fprintf(out, "  Its server in %s was left in place\n", project.Output)
+		}
+	}
+	if jsonOutput {
+		return writeJSON(out, struct {
+			Deleted []string `json:"deleted"`
+		}{deleted})
+	}
+	return nil
+}
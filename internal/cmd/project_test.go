@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// projectCommand returns a command for the project commands writing to out
+// and reading answers from in
+func projectCommand(out *bytes.Buffer, in string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetOut(out)
+	cmd.SetIn(strings.NewReader(in))
+	return cmd
+}
+
+// createProject creates a project in root/name from the test collection
+func createProject(t *testing.T, root, name string) string {
+	t.Helper()
+	source := filepath.Join(t.TempDir(), "collection.json")
+	require.NoError(t, os.WriteFile(source, []byte(importCollection), 0644))
+	saved := importFlags
+	defer func() { importFlags = saved }()
+	importFlags.maxSizeMB = 32
+	dir := filepath.Join(root, name)
+	require.NoError(t, runProjectCreate(projectCommand(&bytes.Buffer{}, ""), []string{source, dir}))
+	return dir
+}
+
+func TestProjectCreateAndList(t *testing.T) {
+	savedJSON := jsonOutput
+	defer func() { jsonOutput = savedJSON }()
+	jsonOutput = false
+	t.Setenv("HOME", t.TempDir())
+
+	root := t.TempDir()
+	users := createProject(t, root, "users")
+	assert.FileExists(t, filepath.Join(users, "openapi.yaml"))
+	assert.FileExists(t, filepath.Join(users, projectConfigFile))
+	assert.Equal(t, importAsSpec, importFlags.as, "the import flags are restored")
+
+	orders := createProject(t, root, "orders")
+	require.NoError(t, os.MkdirAll(filepath.Join(orders, "server"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(orders, "server", "go.mod"), []byte("module orders\n"), 0644))
+	started := time.Date(2026, 10, 16, 14, 5, 0, 0, time.Local)
+	require.NoError(t, saveTestResult(filepath.Join(orders, "server"), started, resultRun(orders, stageProbe)))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "notes"), 0755))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, runProjectList(projectCommand(out, ""), []string{root}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3, "directories that are not projects are left out:\n%s", out)
+	assert.Regexp(t, `^orders\s+\S+orders\s+openapi.yaml\s+generated\s+failed 2026-10-16 14:05$`, lines[1])
+	assert.Regexp(t, `^users\s+\S+users\s+openapi.yaml\s+not generated\s+never$`, lines[2])
+
+	jsonOutput = true
+	out.Reset()
+	require.NoError(t, runProjectList(projectCommand(out, ""), []string{users}))
+	var projects []jsonProject
+	require.NoError(t, json.Unmarshal(out.Bytes(), &projects))
+	require.Len(t, projects, 1, "a project directory given is listed itself")
+	assert.Equal(t, filepath.Join(users, "server"), projects[0].Output)
+
+	out.Reset()
+	require.NoError(t, runProjectList(projectCommand(out, ""), []string{filepath.Join(root, "notes")}))
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestProjectShow(t *testing.T) {
+	savedJSON := jsonOutput
+	defer func() { jsonOutput = savedJSON }()
+	t.Setenv("HOME", t.TempDir())
+	dir := createProject(t, t.TempDir(), "users")
+
+	jsonOutput = false
+	out := &bytes.Buffer{}
+	require.NoError(t, runProjectShow(projectCommand(out, ""), []string{dir}))
+	assert.Contains(t, out.String(), "Project users ("+dir+")\n")
+	assert.Contains(t, out.String(), "  API:           User Service 1.0.0, 2 tools: ")
+	assert.Contains(t, out.String(), "  Server:        "+filepath.Join(dir, "server")+" (not generated)\n")
+	assert.Contains(t, out.String(), "  Last test:     never\n")
+
+	// A broken specification is reported, not an error
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "openapi.yaml"), []byte("openapi: [broken"), 0644))
+	jsonOutput = true
+	out.Reset()
+	require.NoError(t, runProjectShow(projectCommand(out, ""), []string{dir}))
+	var details jsonProjectDetails
+	require.NoError(t, json.Unmarshal(out.Bytes(), &details))
+	assert.NotEmpty(t, details.SpecError)
+	assert.Empty(t, details.Tools)
+
+	err := runProjectShow(projectCommand(out, ""), []string{t.TempDir()})
+	assert.ErrorIs(t, err, errNotProject)
+}
+
+func TestProjectExport(t *testing.T) {
+	saved, savedJSON := projectExportFlags, jsonOutput
+	defer func() { projectExportFlags, jsonOutput = saved, savedJSON }()
+	jsonOutput = true
+	t.Setenv("HOME", t.TempDir())
+	dir := createProject(t, t.TempDir(), "users")
+	for _, name := range []string{"scenarios/list.yaml", "server/main.go", ".mcpweaver/notes"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+
+	dest := filepath.Join(dir, "users.zip")
+	out := &bytes.Buffer{}
+	require.NoError(t, runProjectExport(projectCommand(out, ""), []string{dir, dest}))
+	var result struct {
+		Files []string `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, []string{projectConfigFile, "openapi.yaml", "scenarios/list.yaml"}, result.Files,
+		"the server, the test history and the archive itself are left out")
+
+	err := runProjectExport(projectCommand(out, ""), []string{dir, dest})
+	assert.ErrorContains(t, err, "already exists; use --force")
+	projectExportFlags.force = true
+	assert.NoError(t, runProjectExport(projectCommand(out, ""), []string{dir, dest}))
+}
+
+func TestProjectDelete(t *testing.T) {
+	saved, savedJSON := projectDeleteFlags, jsonOutput
+	defer func() { projectDeleteFlags, jsonOutput = saved, savedJSON }()
+	jsonOutput = false
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	users := createProject(t, root, "users")
+	orders := createProject(t, root, "orders")
+
+	out := &bytes.Buffer{}
+	require.NoError(t, runProjectDelete(projectCommand(out, "n\ny\n"), []string{users, orders}))
+	assert.DirExists(t, users, "a project is kept unless confirmed")
+	assert.NoDirExists(t, orders)
+	assert.Contains(t, out.String(), "✓ Deleted project "+orders)
+
+	err := runProjectDelete(projectCommand(out, ""), []string{users, root})
+	assert.ErrorIs(t, err, errNotProject, "nothing is deleted when a directory is not a project")
+	assert.DirExists(t, users)
+
+	jsonOutput = true
+	err = runProjectDelete(projectCommand(out, ""), []string{users})
+	assert.ErrorContains(t, err, "use --force")
+	projectDeleteFlags.force = true
+	out.Reset()
+	require.NoError(t, runProjectDelete(projectCommand(out, ""), []string{users}))
+	assert.JSONEq(t, `{"deleted": [`+jsonString(users)+`]}`, out.String())
+	assert.NoDirExists(t, users)
+}
+
+// jsonString encodes s as a JSON string
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
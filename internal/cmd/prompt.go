@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errInputClosed reports that input ended while a question was asked
+var errInputClosed = errors.New("input ended before the wizard finished")
+
+// prompter asks questions on a terminal, one line per answer. Invalid
+// answers are explained and the question asked again.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// ask returns the answer to a question, or def when it is left empty
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Fprintln(p.out)
+		return "", errInputClosed
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// choose lists numbered options and returns the index of the one picked,
+// by number or by the text before its first " - "
+func (p *prompter) choose(question string, options []string, def int) (int, error) {
+	for i, option := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+	}
+	for {
+		answer, err := p.ask(question, strconv.Itoa(def+1))
+		if err != nil {
+			return 0, err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		for i, option := range options {
+			if name, _, _ := strings.Cut(option, " - "); strings.EqualFold(name, answer) {
+				return i, nil
+			}
+		}
+		fmt.Fprintf(p.out, "  Enter a number from 1 to %d\n", len(options))
+	}
+}
+
+// confirm asks a yes or no question
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := p.ask(question+" ("+hint+")", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "  Answer y or n")
+	}
+}
+
+// parseSelection reads a selection of items numbered from 1 to n, such as
+// "1,3-5", into sorted indexes. "all" selects every item.
+func parseSelection(selection string, n int) ([]int, error) {
+	picked := map[int]bool{}
+	for _, part := range strings.Split(selection, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.EqualFold(part, "all") {
+			for i := 0; i < n; i++ {
+				picked[i] = true
+			}
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		to := from
+		if err == nil && isRange {
+			to, err = strconv.Atoi(strings.TrimSpace(last))
+		}
+		if err != nil || from < 1 || to > n || from > to {
+			return nil, fmt.Errorf("%q is not a number or range between 1 and %d", part, n)
+		}
+		for i := from; i <= to; i++ {
+			picked[i-1] = true
+		}
+	}
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("select at least one item")
+	}
+	indexes := make([]int, 0, len(picked))
+	for i := range picked {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
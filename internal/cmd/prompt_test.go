@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		selection string
+		n         int
+		want      []int
+		wantErr   bool
+	}{
+		{selection: "all", n: 3, want: []int{0, 1, 2}},
+		{selection: " ALL ", n: 2, want: []int{0, 1}},
+		{selection: "1", n: 3, want: []int{0}},
+		{selection: "3,1", n: 3, want: []int{0, 2}},
+		{selection: "1,3-5", n: 5, want: []int{0, 2, 3, 4}},
+		{selection: "2-4, 3", n: 5, want: []int{1, 2, 3}},
+		{selection: " 2 - 3 ", n: 3, want: []int{1, 2}},
+		{selection: "1,,2", n: 2, want: []int{0, 1}},
+		{selection: "all,1", n: 2, want: []int{0, 1}},
+		{selection: "4-4", n: 4, want: []int{3}},
+		{selection: "", n: 3, wantErr: true},
+		{selection: ",", n: 3, wantErr: true},
+		{selection: "0", n: 3, wantErr: true},
+		{selection: "4", n: 3, wantErr: true},
+		{selection: "3-1", n: 3, wantErr: true},
+		{selection: "1-x", n: 3, wantErr: true},
+		{selection: "two", n: 3, wantErr: true},
+		{selection: "all", n: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selection, func(t *testing.T) {
+			got, err := parseSelection(tt.selection, tt.n)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrompterAsksAgain(t *testing.T) {
+	var out bytes.Buffer
+	p := newPrompter(strings.NewReader("9\nminimal\nmaybe\n\n"), &out)
+
+	i, err := p.choose("Profile", []string{"minimal - fewest files", "standard - defaults"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, i, "options are picked by name as well as number")
+	assert.Contains(t, out.String(), "Enter a number from 1 to 2")
+
+	ok, err := p.confirm("Continue?", true)
+	require.NoError(t, err)
+	assert.True(t, ok, "an empty answer takes the default")
+	assert.Contains(t, out.String(), "Answer y or n")
+
+	_, err = p.ask("More", "")
+	assert.ErrorIs(t, err, errInputClosed)
+}
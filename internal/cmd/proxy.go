@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"MCPWeaver/internal/common"
+)
+
+// proxyDirect is the --proxy value that connects directly, ignoring the
+// proxy environment variables
+const proxyDirect = "none"
+
+// proxyFlags override the proxy environment variables for outbound
+// requests
+var proxyFlags struct {
+	url     string
+	noProxy string
+}
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&proxyFlags.url, "proxy", "", `proxy for outbound requests, as http://[user:password@]host:port, or "none" (default: HTTPS_PROXY and HTTP_PROXY)`)
+	flags.StringVar(&proxyFlags.noProxy, "no-proxy", "", "comma-separated hosts, domains and CIDR ranges reached without the proxy (default: NO_PROXY)")
+}
+
+// applyProxy exports the proxy flags as the standard environment
+// variables. Every HTTP client of MCPWeaver reads them, as do the go and
+// git commands it runs, so they must be set before the first request.
+func applyProxy() error {
+	switch proxyFlags.url {
+	case "":
+	case proxyDirect:
+		for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+			os.Unsetenv(name)
+		}
+	default:
+		if err := checkProxyURL(proxyFlags.url); err != nil {
+			return err
+		}
+		for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+			os.Setenv(name, proxyFlags.url)
+		}
+	}
+	if proxyFlags.noProxy != "" {
+		os.Setenv("NO_PROXY", proxyFlags.noProxy)
+		os.Setenv("no_proxy", proxyFlags.noProxy)
+	}
+	return nil
+}
+
+// checkProxyURL rejects proxy URLs the HTTP clients cannot use
+func checkProxyURL(proxy string) error {
+	// Like the environment variables, a URL without a scheme is http
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		// The parse error repeats the URL, password included
+		err = errors.New("malformed URL")
+	} else if u.Host == "" {
+		err = errors.New("missing host")
+	}
+	if err == nil && u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		err = fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return common.NewError(common.ErrorTypeValidation, "invalid proxy URL", err).
+			WithSuggestion("Use an http, https or socks5 URL such as http://proxy.example.com:3128, with user:password@ before the host for an authenticated proxy")
+	}
+	return nil
+}
+
+// proxyEnv returns the value of a proxy environment variable, which may
+// be set in upper or lower case
+func proxyEnv(name string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return os.Getenv(strings.ToLower(name))
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Version information, injected by main at startup
+var (
+	version   = "dev"
+	buildTime = "unknown"
+	commit    = "unknown"
+)
+
+// verbose enables detailed processing output for every command
+var verbose bool
+
+var rootCmd = &cobra.Command{
+	Use:   "mcpweaver",
+	Short: "Transform OpenAPI specifications into MCP servers",
+	Long: `MCPWeaver converts OpenAPI 2.0 and 3.x specifications into ready-to-build
+Model Context Protocol (MCP) servers.
+
+Defaults for the spec, output, template, template_dir, profile, fail_on,
+proxy, no_proxy and registry flags are read from
+~/.config/mcpweaver/config.yaml and then .mcpweaver.yaml in the working
+directory. Flags given on the command line take precedence.
+
+Downloads and uploads go through the proxy set with --proxy or in the
+HTTPS_PROXY and HTTP_PROXY environment variables, except for the hosts
+listed with --no-proxy or in NO_PROXY. The settings are passed on to the go
+commands that build generated servers.`,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: applyConfig,
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "configuration file to use instead of .mcpweaver.yaml and ~/.config/mcpweaver/config.yaml")
+	rootCmd.PersistentFlags().BoolVar(&ciMode, "ci", false, "CI mode: no progress output or watching, a distinct exit code per failure class")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print machine-readable JSON, with errors as JSON on stderr")
+	registerCompletions(rootCmd, map[string]cobra.CompletionFunc{"config": completeSpecs})
+}
+
+// SetVersionInfo records build metadata for the version command
+func SetVersionInfo(v, bt, c string) {
+	version = v
+	buildTime = bt
+	commit = c
+	rootCmd.Version = v
+}
+
+// Execute runs the root command
+func Execute() error {
+	return rootCmd.Execute()
+}
@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+// serveStopTimeout is how long a replaced server may take to answer the
+// requests it already received before it is killed
+const serveStopTimeout = 5 * time.Second
+
+// serveFlags holds the flags of the serve command
+var serveFlags struct {
+	output      string
+	template    string
+	templateDir string
+	profile     string
+	env         []string
+	vars        []string
+	language    string
+	debounce    time.Duration
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve <openapi-spec>",
+	Short: "Run a generated MCP server, rebuilding it when the specification changes",
+	Long: `Serve generates and builds the server of a specification and runs it, relaying
+MCP messages between its own stdin and stdout and the server's. Register
+"mcpweaver serve <openapi-spec>" as the server command in an MCP client to
+try changes to the specification without reconfiguring the client.
+
+When the specification or the --template-dir package changes, the server is
+regenerated, rebuilt and restarted. The client's session carries over: the
+new server receives the client's initialize handshake and the client is
+notified that the tool list changed. A specification that fails to generate
+or build leaves the running server in place. The server's logs and
+MCPWeaver's own messages go to stderr, since stdout carries MCP.`,
+	Example: `  mcpweaver serve api.yaml
+  mcpweaver serve api.yaml --output ./server --profile standard --env API_TOKEN=secret`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSpecs,
+	RunE:              runServe,
+}
+
+func init() {
+	flags := serveCmd.Flags()
+	flags.StringVarP(&serveFlags.output, "output", "o", "", "directory for the generated server; a temporary directory by default")
+	flags.StringVar(&serveFlags.template, "template", generator.DefaultTemplate, "built-in template set to render")
+	flags.StringVar(&serveFlags.templateDir, "template-dir", "", "custom template package layered over the template set")
+	flags.StringVar(&serveFlags.profile, "profile", generator.DefaultProfile, "feature profile: minimal, standard or production")
+	flags.StringArrayVar(&serveFlags.env, "env", nil, "NAME=value variable for the server, e.g. credentials; repeatable")
+	flags.StringArrayVar(&serveFlags.vars, "var", nil, varUsage)
+	flags.StringVar(&serveFlags.language, "lang", generator.DefaultLanguage, langUsage)
+	flags.DurationVar(&serveFlags.debounce, "debounce", defaultDebounce, "how long files must stay unchanged before rebuilding")
+	registerCompletions(serveCmd, map[string]cobra.CompletionFunc{
+		"output":       completeDirs,
+		"template":     completeTemplates,
+		"template-dir": completeDirs,
+		"profile":      completeProfiles,
+		"var":          completeVariables,
+		"lang":         completeLanguages,
+	})
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if jsonOutput || ciMode {
+		return fmt.Errorf("serve relays MCP on stdout and cannot be used with --json or --ci")
+	}
+	specPath := args[0]
+	logs := cmd.ErrOrStderr()
+	vars, err := parseVariables(serveFlags.vars)
+	if err != nil {
+		return err
+	}
+
+	output := serveFlags.output
+	if output == "" {
+		dir, err := os.MkdirTemp("", "mcpweaver-serve-")
+		if err != nil {
+			return common.NewError(common.ErrorTypeGeneration, "failed to create server directory", err)
+		}
+		defer os.RemoveAll(dir)
+		output = dir
+	}
+	opts := generator.Options{
+		OutputDir:   output,
+		Template:    serveFlags.template,
+		TemplateDir: serveFlags.templateDir,
+		Profile:     serveFlags.profile,
+		Variables:   vars,
+		Language:    serveFlags.language,
+	}
+	paths := []string{specPath}
+	if opts.TemplateDir != "" {
+		paths = append(paths, opts.TemplateDir)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	previous := snapshotWatched(paths)
+	builds := 0
+	binary, tools, err := buildServed(ctx, specPath, opts, builds)
+	if err != nil {
+		return err
+	}
+	proxy := &serveProxy{
+		dir:  output,
+		env:  append(os.Environ(), serveFlags.env...),
+		out:  cmd.OutOrStdout(),
+		logs: logs,
+	}
+	if err := proxy.start(binary); err != nil {
+		return err
+	}
+	fmt.Fprintf(logs, "Serving %s (%d tools) from %s. Watching %s for changes.\n", specPath, tools, output, strings.Join(paths, ", "))
+
+	go watchFiles(ctx, paths, previous, serveFlags.debounce, func(changed []string) {
+		fmt.Fprintf(logs, "[%s] Changed: %s\n", time.Now().Format("15:04:05"), strings.Join(changed, ", "))
+		builds++
+		binary, tools, err := buildServed(ctx, specPath, opts, builds)
+		if err != nil {
+			fmt.Fprint(logs, FormatError(err))
+			fmt.Fprintln(logs, "The previous server keeps running.")
+			return
+		}
+		if err := proxy.restart(binary); err != nil {
+			fmt.Fprint(logs, FormatError(err))
+			return
+		}
+		fmt.Fprintf(logs, "✓ Restarted with %d tools\n", tools)
+	})
+
+	proxy.run(ctx, cmd.InOrStdin())
+	return nil
+}
+
+// buildServed generates the server and compiles it for the host. Every
+// build gets its own binary, so a running server is never overwritten.
+func buildServed(ctx context.Context, specPath string, opts generator.Options, build int) (string, int, error) {
+	server, err := loadServer(ctx, specPath)
+	if err != nil {
+		return "", 0, err
+	}
+	result, err := generator.NewService().Generate(server, opts)
+	if err != nil {
+		return "", 0, err
+	}
+	built, err := generator.NewService().Build(generator.BuildOptions{
+		Dir:     opts.OutputDir,
+		Name:    fmt.Sprintf("server-%d", build),
+		Targets: []generator.BuildTarget{{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH}},
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return built.Artifacts[0].Path, result.ToolCount, nil
+}
+
+// servedProcess is one run of the generated server
+type servedProcess struct {
+	binary string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	// done is closed once the server exited and its output was relayed
+	done chan struct{}
+}
+
+// serveProxy relays MCP messages between the client and the current server
+// process, replaying the client's handshake to every replacement
+type serveProxy struct {
+	dir  string
+	env  []string
+	out  io.Writer
+	logs io.Writer
+
+	// outMu serializes messages to the client
+	outMu sync.Mutex
+	// mu guards the fields below
+	mu     sync.Mutex
+	server *servedProcess
+	// initialize and initialized are the client's handshake messages
+	initialize   []byte
+	initID       json.RawMessage
+	initialized  []byte
+	shuttingDown bool
+}
+
+// rpcMessage holds the fields the proxy routes messages by
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+}
+
+// start runs the first server
+func (p *serveProxy) start(binary string) error {
+	server, err := p.launch(binary, false)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.server = server
+	p.mu.Unlock()
+	return nil
+}
+
+// restart replaces the server with one running binary. Requests already
+// sent to the old server are still answered; new ones go to the new server,
+// which has received the client's handshake first.
+func (p *serveProxy) restart(binary string) error {
+	server, err := p.launch(binary, true)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	old := p.server
+	p.server = server
+	initialized := p.initialize != nil
+	p.mu.Unlock()
+
+	if old != nil {
+		p.stop(old)
+		os.Remove(old.binary)
+	}
+	if initialized {
+		p.send([]byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`))
+	}
+	return nil
+}
+
+// launch starts a server process and relays its output. With replay, the
+// client's handshake is sent first and the reply to it dropped, since the
+// client already has one.
+func (p *serveProxy) launch(binary string, replay bool) (*servedProcess, error) {
+	cmd := exec.Command(binary)
+	cmd.Dir = p.dir
+	cmd.Env = p.env
+	cmd.Stderr = p.logs
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to start server", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to start server", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to start server", err).WithFile(binary)
+	}
+	server := &servedProcess{binary: binary, cmd: cmd, stdin: stdin, done: make(chan struct{})}
+
+	var swallow json.RawMessage
+	if replay {
+		p.mu.Lock()
+		if p.initialize != nil {
+			swallow = p.initID
+			stdin.Write(append(p.initialize, '\n'))
+			if p.initialized != nil {
+				stdin.Write(append(p.initialized, '\n'))
+			}
+		}
+		p.mu.Unlock()
+	}
+
+	go func() {
+		defer close(server.done)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var msg rpcMessage
+			json.Unmarshal(line, &msg)
+			if swallow != nil && msg.Method == "" && bytes.Equal(msg.ID, swallow) {
+				swallow = nil
+				continue
+			}
+			p.send(p.announceListChanged(line, msg))
+		}
+		err := cmd.Wait()
+
+		p.mu.Lock()
+		current := p.server == server
+		if current {
+			p.server = nil
+		}
+		shuttingDown := p.shuttingDown
+		p.mu.Unlock()
+		if current && !shuttingDown {
+			fmt.Fprintf(p.logs, "Server exited (%v); waiting for a change to restart it.\n", err)
+		}
+	}()
+	return server, nil
+}
+
+// announceListChanged adds the tools listChanged capability to the reply to
+// the client's initialize request, since the proxy sends the notification
+// whenever the server is replaced
+func (p *serveProxy) announceListChanged(line []byte, msg rpcMessage) []byte {
+	p.mu.Lock()
+	initID := p.initID
+	p.mu.Unlock()
+	if msg.Method != "" || initID == nil || !bytes.Equal(msg.ID, initID) {
+		return line
+	}
+
+	var reply map[string]interface{}
+	if err := json.Unmarshal(line, &reply); err != nil {
+		return line
+	}
+	result, _ := reply["result"].(map[string]interface{})
+	capabilities, _ := result["capabilities"].(map[string]interface{})
+	tools, _ := capabilities["tools"].(map[string]interface{})
+	if tools == nil {
+		return line
+	}
+	tools["listChanged"] = true
+	patched, err := json.Marshal(reply)
+	if err != nil {
+		return line
+	}
+	return patched
+}
+
+// run relays the client's messages until its input ends or ctx is done,
+// then stops the server
+func (p *serveProxy) run(ctx context.Context, in io.Reader) {
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				p.forward(append([]byte(nil), line...))
+			}
+		}
+	}()
+	select {
+	case <-ctx.Done():
+	case <-closed:
+	}
+
+	p.mu.Lock()
+	p.shuttingDown = true
+	server := p.server
+	p.mu.Unlock()
+	if server != nil {
+		p.stop(server)
+	}
+}
+
+// forward sends a client message to the server, remembering the handshake.
+// Requests arriving while no server runs are answered with an error.
+func (p *serveProxy) forward(line []byte) {
+	var msg rpcMessage
+	json.Unmarshal(line, &msg)
+
+	p.mu.Lock()
+	switch msg.Method {
+	case "initialize":
+		p.initialize, p.initID = line, msg.ID
+	case "notifications/initialized":
+		p.initialized = line
+	}
+	server := p.server
+	if server != nil {
+		server.stdin.Write(append(line, '\n'))
+	}
+	p.mu.Unlock()
+
+	if server == nil && msg.ID != nil && msg.Method != "" {
+		reply, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      msg.ID,
+			"error": map[string]interface{}{
+				"code":    -32603,
+				"message": "the server is not running; fix the specification to restart it",
+			},
+		})
+		p.send(reply)
+	}
+}
+
+// send writes one message to the client
+func (p *serveProxy) send(line []byte) {
+	p.outMu.Lock()
+	defer p.outMu.Unlock()
+	p.out.Write(append(line, '\n'))
+}
+
+// stop closes the server's input and waits for it to answer what it has
+// received, killing it after serveStopTimeout
+func (p *serveProxy) stop(server *servedProcess) {
+	server.stdin.Close()
+	select {
+	case <-server.done:
+	case <-time.After(serveStopTimeout):
+		server.cmd.Process.Kill()
+		<-server.done
+	}
+}
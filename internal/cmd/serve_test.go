@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServerEnv makes the test binary act as a served MCP server
+const fakeServerEnv = "MCPWEAVER_FAKE_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeServerEnv) != "" {
+		fakeServer()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeServer answers every request on stdin with its process ID and
+// method until stdin closes. "crash" exits at once and "slow" answers after
+// a delay, so that it is still in flight when the server is replaced.
+func fakeServer() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil || msg.ID == nil {
+			continue
+		}
+		result := map[string]interface{}{"pid": os.Getpid(), "method": msg.Method}
+		switch msg.Method {
+		case "crash":
+			fmt.Fprintln(os.Stderr, "fake server crashed")
+			os.Exit(3)
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "initialize":
+			result["capabilities"] = map[string]interface{}{"tools": map[string]interface{}{}}
+		}
+		reply, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": msg.ID, "result": result})
+		fmt.Println(string(reply))
+	}
+}
+
+// fakeServerBinary links the test binary under a new name, as every build
+// of serve has its own binary that a restart removes
+func fakeServerBinary(t *testing.T, name string) string {
+	t.Helper()
+	binary, err := os.Executable()
+	require.NoError(t, err)
+	link := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.Symlink(binary, link))
+	return link
+}
+
+// relay is a proxy relaying between a test client and fake servers
+type relay struct {
+	t      *testing.T
+	proxy  *serveProxy
+	client *io.PipeWriter
+	out    *lockedBuffer
+	logs   *lockedBuffer
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startRelay starts the proxy with a fake server and relays until the
+// client's input is closed or the relay is cancelled
+func startRelay(t *testing.T) *relay {
+	t.Helper()
+	r := &relay{t: t, out: &lockedBuffer{}, logs: &lockedBuffer{}, done: make(chan struct{})}
+	r.proxy = &serveProxy{
+		dir:  t.TempDir(),
+		env:  append(os.Environ(), fakeServerEnv+"=1"),
+		out:  r.out,
+		logs: r.logs,
+	}
+	require.NoError(t, r.proxy.start(fakeServerBinary(t, "server-0")))
+	in, client := io.Pipe()
+	r.client = client
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go func() {
+		defer close(r.done)
+		r.proxy.run(ctx, in)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		client.Close()
+		<-r.done
+	})
+	return r
+}
+
+// send writes a message from the client
+func (r *relay) send(message string) {
+	r.t.Helper()
+	_, err := io.WriteString(r.client, message+"\n")
+	require.NoError(r.t, err)
+}
+
+// call sends a request and waits for the reply to it
+func (r *relay) call(id int, method string) map[string]interface{} {
+	r.t.Helper()
+	r.send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q}`, id, method))
+	return r.reply(id)
+}
+
+// reply waits for the reply with the id
+func (r *relay) reply(id int) map[string]interface{} {
+	r.t.Helper()
+	waitForOutput(r.t, r.out, fmt.Sprintf(`"id":%d,`, id), 1)
+	for _, line := range strings.Split(r.out.String(), "\n") {
+		var msg map[string]interface{}
+		if json.Unmarshal([]byte(line), &msg) == nil && msg["id"] == float64(id) {
+			return msg
+		}
+	}
+	r.t.Fatalf("no reply %d in:\n%s", id, r.out.String())
+	return nil
+}
+
+// pid returns the process ID of the fake server that answered a reply
+func pid(reply map[string]interface{}) float64 {
+	result, _ := reply["result"].(map[string]interface{})
+	return result["pid"].(float64)
+}
+
+func TestServeProxyForwarding(t *testing.T) {
+	r := startRelay(t)
+
+	reply := r.call(1, "initialize")
+	tools := reply["result"].(map[string]interface{})["capabilities"].(map[string]interface{})["tools"]
+	assert.Equal(t, map[string]interface{}{"listChanged": true}, tools, "the client is told the tool list can change")
+	r.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	reply = r.call(2, "tools/call")
+	assert.Equal(t, "tools/call", reply["result"].(map[string]interface{})["method"])
+	assert.Equal(t, pid(r.call(3, "tools/list")), pid(reply), "requests go to the same server")
+
+	r.send("")
+	r.send(`{"jsonrpc":"2.0","id":"text-id","method":"ping"}`)
+	waitForOutput(t, r.out, `"id":"text-id"`, 1)
+	assert.Equal(t, 4, strings.Count(r.out.String(), "\n"), "every request is answered once:\n%s", r.out.String())
+}
+
+func TestServeProxyRestart(t *testing.T) {
+	r := startRelay(t)
+	first := pid(r.call(1, "initialize"))
+	r.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	// A request in flight is answered by the server it was sent to
+	r.send(`{"jsonrpc":"2.0","id":2,"method":"slow"}`)
+	binary := fakeServerBinary(t, "server-1")
+	old := r.proxy.server.binary
+	require.NoError(t, r.proxy.restart(binary))
+	assert.Equal(t, first, pid(r.reply(2)))
+	assert.NoFileExists(t, old, "the replaced binary is removed")
+
+	waitForOutput(t, r.out, `"method":"notifications/tools/list_changed"`, 1)
+	second := pid(r.call(3, "tools/list"))
+	assert.NotEqual(t, first, second, "new requests go to the new server")
+	assert.Equal(t, 1, strings.Count(r.out.String(), `"method":"initialize"`), "the reply to the replayed handshake is dropped")
+}
+
+func TestServeProxyCrash(t *testing.T) {
+	r := startRelay(t)
+	r.call(1, "initialize")
+
+	r.send(`{"jsonrpc":"2.0","id":2,"method":"crash"}`)
+	waitForOutput(t, r.logs, "Server exited (exit status 3); waiting for a change to restart it.", 1)
+	assert.Contains(t, r.logs.String(), "fake server crashed", "the server's stderr goes to the logs")
+
+	reply := r.call(3, "tools/list")
+	assert.Equal(t, "the server is not running; fix the specification to restart it",
+		reply["error"].(map[string]interface{})["message"], "requests without a server are answered with an error")
+
+	require.NoError(t, r.proxy.restart(fakeServerBinary(t, "server-1")))
+	reply = r.call(4, "tools/list")
+	assert.Equal(t, "tools/list", reply["result"].(map[string]interface{})["method"], "a rebuild restarts the crashed server")
+	waitForOutput(t, r.out, "notifications/tools/list_changed", 1)
+}
+
+func TestServeProxyShutdown(t *testing.T) {
+	// The client's input ending stops the server once it has answered
+	r := startRelay(t)
+	r.call(1, "initialize")
+	server := r.proxy.server
+	r.send(`{"jsonrpc":"2.0","id":2,"method":"slow"}`)
+	time.Sleep(50 * time.Millisecond)
+	r.client.Close()
+	select {
+	case <-r.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the relay did not stop")
+	}
+	<-server.done
+	assert.Equal(t, "slow", r.reply(2)["result"].(map[string]interface{})["method"])
+	assert.NotContains(t, r.logs.String(), "Server exited", "a stopped server is not reported as exited")
+
+	// So does an interrupt
+	r = startRelay(t)
+	r.call(1, "initialize")
+	server = r.proxy.server
+	r.cancel()
+	select {
+	case <-r.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the relay did not stop")
+	}
+	<-server.done
+	assert.True(t, server.cmd.ProcessState.Exited())
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Work with template packages",
+	Long: `Template commands help write and maintain the custom template packages
+given to generate with --template-dir, which are layered over a built-in
+template set.`,
+}
+
+// templatePackageFlags select the template package a template command
+// works on, as generate does
+type templatePackageFlags struct {
+	template    string
+	templateDir string
+	profile     string
+	vars        []string
+	language    string
+}
+
+// register adds the package flags to cmd
+func (f *templatePackageFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.template, "template", generator.DefaultTemplate, "built-in template set the package is layered over")
+	flags.StringVar(&f.templateDir, "template-dir", "", "custom template package directory")
+	flags.StringVar(&f.profile, "profile", generator.DefaultProfile, "feature profile: minimal, standard or production")
+	registerCompletions(cmd, map[string]cobra.CompletionFunc{
+		"template":     completeTemplates,
+		"template-dir": completeDirs,
+		"profile":      completeProfiles,
+	})
+}
+
+// registerRender adds --var and --lang to commands rendering the package
+func (f *templatePackageFlags) registerRender(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.vars, "var", nil, varUsage)
+	cmd.Flags().StringVar(&f.language, "lang", generator.DefaultLanguage, langUsage)
+	registerCompletions(cmd, map[string]cobra.CompletionFunc{
+		"var":  completeVariables,
+		"lang": completeLanguages,
+	})
+}
+
+// options returns the generator options selecting the package
+func (f *templatePackageFlags) options() generator.Options {
+	return generator.Options{
+		Template:    f.template,
+		TemplateDir: f.templateDir,
+		Profile:     f.profile,
+	}
+}
+
+// renderOptions returns the options selecting the package with the --var
+// values and --lang, for commands rendering it
+func (f *templatePackageFlags) renderOptions() (generator.Options, error) {
+	opts := f.options()
+	vars, err := parseVariables(f.vars)
+	opts.Variables, opts.Language = vars, f.language
+	return opts, err
+}
+
+// requireDir fails when no --template-dir was given
+func (f *templatePackageFlags) requireDir() error {
+	if f.templateDir == "" {
+		return fmt.Errorf("no template package given; set --template-dir")
+	}
+	return nil
+}
+
+// templatePreviewFlags holds the flags of the template preview command
+var templatePreviewFlags struct {
+	pkg      templatePackageFlags
+	files    []string
+	once     bool
+	interval time.Duration
+}
+
+var templatePreviewCmd = &cobra.Command{
+	Use:   "preview <openapi-spec> --template-dir <directory>",
+	Short: "Render a template package again whenever it changes",
+	Long: `Preview renders the template package in memory against the specification,
+without writing files, and renders it again whenever a file in the package
+changes, until interrupted. Each render lists the output files with their
+size and render time, and the formatting issues in the Go sources; --file
+prints the content of the given output files as well.
+
+With --json, each render is one line of JSON with the content of every
+file, as a "template:preview_updated" event.`,
+	Example: `  mcpweaver template preview api.yaml --template-dir ./my-templates
+  mcpweaver template preview api.yaml --template-dir ./my-templates --file main.go
+  mcpweaver template preview api.yaml --template-dir ./my-templates --once --json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSpecs,
+	RunE:              runTemplatePreview,
+}
+
+func init() {
+	templatePreviewFlags.pkg.register(templatePreviewCmd)
+	templatePreviewFlags.pkg.registerRender(templatePreviewCmd)
+	flags := templatePreviewCmd.Flags()
+	flags.StringSliceVar(&templatePreviewFlags.files, "file", nil, "print the content of these output files, such as main.go")
+	flags.BoolVar(&templatePreviewFlags.once, "once", false, "render once and exit instead of watching")
+	flags.DurationVar(&templatePreviewFlags.interval, "interval", generator.DefaultPreviewInterval, "how often the package is checked for changes")
+	templateCmd.AddCommand(templatePreviewCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+func runTemplatePreview(cmd *cobra.Command, args []string) error {
+	if err := templatePreviewFlags.pkg.requireDir(); err != nil {
+		return err
+	}
+	if ciMode && !templatePreviewFlags.once {
+		return fmt.Errorf("template preview runs until interrupted; use --once with --ci")
+	}
+	opts, err := templatePreviewFlags.pkg.renderOptions()
+	if err != nil {
+		return err
+	}
+	server, err := loadServer(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+
+	if templatePreviewFlags.once {
+		event, err := generator.NewService().Preview(server, opts)
+		if err != nil {
+			return err
+		}
+		return printPreview(out, *event)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	first := true
+	err = generator.NewService().WatchTemplate(ctx, server, opts, templatePreviewFlags.interval, func(event generator.PreviewEvent) {
+		if err := printPreview(out, event); err != nil && !jsonOutput {
+			fmt.Fprint(cmd.ErrOrStderr(), FormatError(err))
+		}
+		if first && !jsonOutput {
+			fmt.Fprintf(out, "\nWatching %s for changes. Press Ctrl+C to stop.\n", opts.TemplateDir)
+		}
+		first = false
+	})
+	if err != nil {
+		return err
+	}
+	if !jsonOutput {
+		fmt.Fprintln(out, "\nStopped watching.")
+	}
+	return nil
+}
+
+// jsonPreviewFile is a rendered file of a template preview
+type jsonPreviewFile struct {
+	Path         string `json:"path"`
+	Template     string `json:"template"`
+	Size         int    `json:"size"`
+	RenderTimeMS int64  `json:"renderTimeMs"`
+	Content      string `json:"content"`
+}
+
+// jsonPreview is a render of template preview
+type jsonPreview struct {
+	Event    string            `json:"event"`
+	Time     time.Time         `json:"time"`
+	Changed  []string          `json:"changed"`
+	Files    []jsonPreviewFile `json:"files"`
+	Findings []jsonFinding     `json:"findings"`
+	Error    *jsonError        `json:"error,omitempty"`
+}
+
+// printPreview reports a render; in JSON mode as one line of JSON. The
+// error of a failed render is returned for the caller to report.
+func printPreview(out io.Writer, event generator.PreviewEvent) error {
+	if jsonOutput {
+		preview := jsonPreview{
+			Event:    event.Name,
+			Time:     time.Now(),
+			Changed:  append([]string{}, event.Changed...),
+			Files:    []jsonPreviewFile{},
+			Findings: newJSONFindings(event.Findings),
+			Error:    newJSONError(event.Err),
+		}
+		for _, file := range event.Files {
+			preview.Files = append(preview.Files, jsonPreviewFile{
+				Path:         file.Path,
+				Template:     file.Template,
+				Size:         len(file.Content),
+				RenderTimeMS: file.RenderTime.Milliseconds(),
+				Content:      file.Content,
+			})
+		}
+		return json.NewEncoder(out).Encode(preview)
+	}
+
+	when := ""
+	if len(event.Changed) > 0 {
+		when = " after changes to " + strings.Join(event.Changed, ", ")
+	}
+	if event.Err != nil {
+		fmt.Fprintf(out, "✗ Render failed%s\n", when)
+		return event.Err
+	}
+	fmt.Fprintf(out, "✓ Rendered %d files%s\n", len(event.Files), when)
+	for _, file := range event.Files {
+		fmt.Fprintf(out, "  %s - %d bytes (%s, %s)\n", file.Path, len(file.Content), file.Template, file.RenderTime.Round(time.Microsecond))
+	}
+	for _, finding := range event.Findings {
+		fmt.Fprintf(out, "Warning: %s\n", finding)
+	}
+	for _, name := range templatePreviewFlags.files {
+		found := false
+		for _, file := range event.Files {
+			if file.Path == name {
+				fmt.Fprintf(out, "\n--- %s\n%s", file.Path, file.Content)
+				found = true
+			}
+		}
+		if !found {
+			fmt.Fprintf(out, "\n--- %s: not rendered\n", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+// templateCopyFlags holds the flags of the template copy command
+var templateCopyFlags struct {
+	pkg templatePackageFlags
+}
+
+var templateCopyCmd = &cobra.Command{
+	Use:   "copy <directory>",
+	Short: "Copy a template package into a new directory to customize it",
+	Long: `Copy writes the template package into a new directory, which can then be
+edited and given to generate with --template-dir without changing the
+original. A package layered over a template set is flattened, so the copy
+is self-contained. Without --template-dir, the built-in template set is
+copied, recording the built-in files it derives from so that later
+updates to them can be merged into the copy with template migrate.`,
+	Example: `  mcpweaver template copy ./my-templates
+  mcpweaver template copy ./my-templates-v2 --template-dir ./my-templates
+  mcpweaver generate api.yaml --template-dir ./my-templates`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDirs,
+	RunE:              runTemplateCopy,
+}
+
+func init() {
+	templateCopyFlags.pkg.register(templateCopyCmd)
+	templateCmd.AddCommand(templateCopyCmd)
+}
+
+func runTemplateCopy(cmd *cobra.Command, args []string) error {
+	dest := args[0]
+	files, err := generator.NewService().CopyTemplate(templateCopyFlags.pkg.options(), dest)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		return writeJSON(out, struct {
+			Dir   string   `json:"dir"`
+			Files []string `json:"files"`
+		}{dest, files})
+	}
+	fmt.Fprintf(out, "✓ Copied %d files to %s\n", len(files), dest)
+	if verbose {
+		for _, file := range files {
+			fmt.Fprintf(out, "  %s\n", file)
+		}
+	}
+	fmt.Fprintf(out, "\nUse it with: mcpweaver generate <openapi-spec> --template-dir %s\n", dest)
+	return nil
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+// templateDiffFlags holds the flags of the template diff command
+var templateDiffFlags struct {
+	template string
+}
+
+var templateDiffCmd = &cobra.Command{
+	Use:   "diff <package-dir> | <old-package-dir> <new-package-dir>",
+	Short: "Compare template packages line by line",
+	Long: `Diff compares two versions of a template package, each layered over the
+--template set, or with one directory what the package changes in the
+template set. Changed files are shown as unified diffs, followed by the
+template data fields, such as .Server.BaseURL, each file and the packages
+start or stop using.`,
+	Example: `  mcpweaver template diff ./my-templates
+  mcpweaver template diff ./my-templates-v1 ./my-templates-v2
+  mcpweaver template diff ./my-templates-v1 ./my-templates-v2 --json`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeDirs,
+	RunE:              runTemplateDiff,
+}
+
+func init() {
+	templateDiffCmd.Flags().StringVar(&templateDiffFlags.template, "template", generator.DefaultTemplate, "built-in template set the packages are layered over")
+	registerCompletions(templateDiffCmd, map[string]cobra.CompletionFunc{"template": completeTemplates})
+	templateCmd.AddCommand(templateDiffCmd)
+}
+
+func runTemplateDiff(cmd *cobra.Command, args []string) error {
+	from := generator.Options{Template: templateDiffFlags.template}
+	to := generator.Options{Template: templateDiffFlags.template, TemplateDir: args[len(args)-1]}
+	fromName := templateDiffFlags.template
+	if len(args) == 2 {
+		from.TemplateDir = args[0]
+		fromName = args[0]
+	}
+
+	diff, err := generator.NewService().DiffTemplates(from, to)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		return writeJSON(out, newJSONTemplateDiff(fromName, to.TemplateDir, diff))
+	}
+	printTemplateDiff(out, fromName, to.TemplateDir, diff)
+	return nil
+}
+
+// printTemplateDiff writes the differences as unified diffs
+func printTemplateDiff(out io.Writer, from, to string, diff *generator.TemplateDiff) {
+	if len(diff.Files) == 0 {
+		fmt.Fprintf(out, "No differences between %s and %s\n", from, to)
+		return
+	}
+	for _, file := range diff.Files {
+		oldName, newName := "a/"+file.Path, "b/"+file.Path
+		switch file.Status {
+		case generator.FileCreated:
+			oldName = "/dev/null"
+		case generator.FileRemoved:
+			newName = "/dev/null"
+		}
+		fmt.Fprintf(out, "--- %s\n+++ %s\n", oldName, newName)
+		printHunks(out, file.Hunks)
+		printVariables(out, "  ", file.VariablesAdded, file.VariablesRemoved)
+	}
+
+	fmt.Fprintf(out, "\nFiles differing between %s and %s: %d\n", from, to, len(diff.Files))
+	printVariables(out, "", diff.VariablesAdded, diff.VariablesRemoved)
+}
+
+// printHunks writes diff hunks with their unified diff headers
+func printHunks(out io.Writer, hunks []generator.DiffHunk) {
+	prefixes := map[generator.DiffKind]string{generator.DiffContext: " ", generator.DiffAdded: "+", generator.DiffRemoved: "-"}
+	for _, hunk := range hunks {
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(hunk.OldStart, hunk.OldLines), hunkRange(hunk.NewStart, hunk.NewLines))
+		for _, line := range hunk.Lines {
+			fmt.Fprintf(out, "%s%s\n", prefixes[line.Kind], line.Text)
+		}
+	}
+}
+
+// hunkRange formats a hunk header range the way diff does
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// printVariables lists template data fields that started or stopped being
+// used
+func printVariables(out io.Writer, indent string, added, removed []string) {
+	if len(added) > 0 {
+		fmt.Fprintf(out, "%sTemplate variables added: %s\n", indent, strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(out, "%sTemplate variables removed: %s\n", indent, strings.Join(removed, ", "))
+	}
+}
+
+// jsonDiffLine is a line of a template diff hunk
+type jsonDiffLine struct {
+	Kind    string `json:"kind"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+	Text    string `json:"text"`
+}
+
+// jsonDiffHunk is a group of nearby changes in a template diff
+type jsonDiffHunk struct {
+	OldStart int            `json:"oldStart"`
+	OldLines int            `json:"oldLines"`
+	NewStart int            `json:"newStart"`
+	NewLines int            `json:"newLines"`
+	Lines    []jsonDiffLine `json:"lines"`
+}
+
+// jsonTemplateFileDiff compares one file of two template packages
+type jsonTemplateFileDiff struct {
+	Path             string         `json:"path"`
+	Status           string         `json:"status"`
+	Hunks            []jsonDiffHunk `json:"hunks"`
+	VariablesAdded   []string       `json:"variablesAdded"`
+	VariablesRemoved []string       `json:"variablesRemoved"`
+}
+
+// jsonTemplateDiff is the JSON output of template diff
+type jsonTemplateDiff struct {
+	From             string                 `json:"from"`
+	To               string                 `json:"to"`
+	Files            []jsonTemplateFileDiff `json:"files"`
+	VariablesAdded   []string               `json:"variablesAdded"`
+	VariablesRemoved []string               `json:"variablesRemoved"`
+}
+
+// newJSONTemplateDiff describes a template diff with empty lists rather
+// than nulls
+func newJSONTemplateDiff(from, to string, diff *generator.TemplateDiff) jsonTemplateDiff {
+	described := jsonTemplateDiff{
+		From:             from,
+		To:               to,
+		Files:            []jsonTemplateFileDiff{},
+		VariablesAdded:   append([]string{}, diff.VariablesAdded...),
+		VariablesRemoved: append([]string{}, diff.VariablesRemoved...),
+	}
+	for _, file := range diff.Files {
+		fileDiff := jsonTemplateFileDiff{
+			Path:             file.Path,
+			Status:           string(file.Status),
+			Hunks:            newJSONDiffHunks(file.Hunks),
+			VariablesAdded:   append([]string{}, file.VariablesAdded...),
+			VariablesRemoved: append([]string{}, file.VariablesRemoved...),
+		}
+		described.Files = append(described.Files, fileDiff)
+	}
+	return described
+}
+
+// newJSONDiffHunks describes diff hunks, as an empty list when there are
+// none
+func newJSONDiffHunks(hunks []generator.DiffHunk) []jsonDiffHunk {
+	described := []jsonDiffHunk{}
+	for _, hunk := range hunks {
+		jsonHunk := jsonDiffHunk{
+			OldStart: hunk.OldStart,
+			OldLines: hunk.OldLines,
+			NewStart: hunk.NewStart,
+			NewLines: hunk.NewLines,
+			Lines:    []jsonDiffLine{},
+		}
+		for _, line := range hunk.Lines {
+			jsonHunk.Lines = append(jsonHunk.Lines, jsonDiffLine{
+				Kind:    string(line.Kind),
+				OldLine: line.OldLine,
+				NewLine: line.NewLine,
+				Text:    line.Text,
+			})
+		}
+		described = append(described, jsonHunk)
+	}
+	return described
+}
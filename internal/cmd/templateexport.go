@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+// templateExportFlags holds the flags of the template export command
+var templateExportFlags struct {
+	pkg    templatePackageFlags
+	format string
+	force  bool
+}
+
+var templateExportCmd = &cobra.Command{
+	Use:   "export <archive>",
+	Short: "Export a template package as a zip, tar or tar.gz archive",
+	Long: `Export writes the template package to an archive for sharing. A package
+layered over a template set is flattened: the archive holds the manifest,
+every template and partial the package renders, and a README describing
+it. Without --template-dir, the built-in template set is exported.
+
+The format is inferred from the archive name (.zip, .tar, .tar.gz or .tgz)
+unless given with --format. An existing archive is only replaced with
+--force.`,
+	Example: `  mcpweaver template export my-templates.zip --template-dir ./my-templates
+  mcpweaver template export go-default.tar.gz
+  mcpweaver template export package --format tar --template-dir ./my-templates`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateExport,
+}
+
+func init() {
+	templateExportFlags.pkg.register(templateExportCmd)
+	flags := templateExportCmd.Flags()
+	flags.StringVar(&templateExportFlags.format, "format", "", "archive format: zip, tar or tar.gz (default: from the archive name)")
+	flags.BoolVarP(&templateExportFlags.force, "force", "f", false, "replace an existing archive")
+	registerCompletions(templateExportCmd, map[string]cobra.CompletionFunc{
+		"format": completeValues(string(generator.ExportZip), string(generator.ExportTar), string(generator.ExportTarGz)),
+	})
+	templateCmd.AddCommand(templateExportCmd)
+}
+
+func runTemplateExport(cmd *cobra.Command, args []string) error {
+	dest := args[0]
+	format := generator.ExportFormat(templateExportFlags.format)
+	switch format {
+	case "":
+		var err error
+		if format, err = generator.ExportFormatFromPath(dest); err != nil {
+			return err
+		}
+	case generator.ExportZip, generator.ExportTar, generator.ExportTarGz:
+	default:
+		return fmt.Errorf("invalid --format %q; use zip, tar or tar.gz", templateExportFlags.format)
+	}
+	if _, err := os.Stat(dest); !templateExportFlags.force && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s already exists; use --force to replace it", dest)
+	}
+
+	result, err := generator.NewService().ExportTemplate(templateExportFlags.pkg.options(), format, dest)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		return writeJSON(out, struct {
+			Path   string   `json:"path"`
+			Format string   `json:"format"`
+			Size   int64    `json:"size"`
+			Files  []string `json:"files"`
+		}{result.Path, string(result.Format), result.Size, result.Files})
+	}
+	fmt.Fprintf(out, "✓ Exported %d files to %s (%s, %d bytes)\n", len(result.Files), result.Path, result.Format, result.Size)
+	if verbose {
+		for _, file := range result.Files {
+			fmt.Fprintf(out, "  %s\n", file)
+		}
+	}
+	return nil
+}
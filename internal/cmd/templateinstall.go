@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+// templateInstallFlags holds the flags of the template install command
+var templateInstallFlags struct {
+	pkg       templatePackageFlags
+	registry  string
+	token     string
+	sha256    string
+	maxSizeMB int64
+	retries   int
+}
+
+var templateInstallCmd = &cobra.Command{
+	Use:   "install [<name>[@<version>]...]",
+	Short: "Install template packages and their dependencies from a registry",
+	Long: `Install downloads template packages from the registry, with the packages
+they depend on, into the per-user packages directory that generation
+resolves the dependencies of a template package from. A version may be
+exact or a constraint such as ^1.2.0, ~1.2.0 or >=1.2.0; without one, the
+newest version is installed. Packages already installed in a matching
+version are not downloaded again.
+
+Without package names, the dependencies listed in the manifest of the
+--template-dir package are installed.
+
+Every archive is checked against the checksum the registry publishes and
+its SHA256SUMS file; with --sha256, the archive of the one package named
+must also have that checksum. The token defaults to the one stored for the
+registry URL in ~/.config/mcpweaver/credentials.yaml.`,
+	Example: `  mcpweaver template install acme-go --registry https://templates.example.com
+  mcpweaver template install acme-go@^1.2.0 acme-partials@1.0.0
+  mcpweaver template install --template-dir ./my-templates`,
+	RunE: runTemplateInstall,
+}
+
+func init() {
+	templateInstallFlags.pkg.register(templateInstallCmd)
+	flags := templateInstallCmd.Flags()
+	flags.StringVar(&templateInstallFlags.registry, "registry", "", "base URL of the template registry")
+	flags.StringVar(&templateInstallFlags.token, "token", "", "bearer token for the registry (default: the stored credentials)")
+	flags.StringVar(&templateInstallFlags.sha256, "sha256", "", "hex SHA-256 checksum the archive of the named package must have")
+	flags.Int64Var(&templateInstallFlags.maxSizeMB, "max-size-mb", 32, "largest package archive accepted, in MiB")
+	flags.IntVar(&templateInstallFlags.retries, "retries", common.DefaultAttempts-1, "times an interrupted or failed download is tried again")
+	templateCmd.AddCommand(templateInstallCmd)
+}
+
+func runTemplateInstall(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && templateInstallFlags.pkg.templateDir == "" {
+		return fmt.Errorf("no package given; name the packages to install or set --template-dir to install its dependencies")
+	}
+	if templateInstallFlags.sha256 != "" {
+		if len(args) != 1 {
+			return fmt.Errorf("--sha256 checks one package; name exactly one")
+		}
+		if err := common.CheckSHA256(templateInstallFlags.sha256); err != nil {
+			return fmt.Errorf("invalid --sha256: %w", err)
+		}
+	}
+	if templateInstallFlags.maxSizeMB <= 0 || templateInstallFlags.retries < 0 {
+		return fmt.Errorf("--max-size-mb must be positive and --retries not negative")
+	}
+	registry := templateInstallFlags.registry
+	if registry == "" {
+		return fmt.Errorf("no registry given; set --registry or the registry key of the configuration")
+	}
+	token := templateInstallFlags.token
+	if token == "" {
+		stored, err := sourceFetchOptions(registry)
+		if err != nil {
+			return err
+		}
+		token = stored.Token
+	}
+	install := generator.InstallOptions{
+		Registry: registry,
+		Token:    token,
+		MaxSize:  templateInstallFlags.maxSizeMB << 20,
+		Attempts: templateInstallFlags.retries + 1,
+		SHA256:   templateInstallFlags.sha256,
+	}
+
+	var installed []generator.InstalledPackage
+	var err error
+	if len(args) == 0 {
+		installed, err = generator.NewService().InstallDependencies(cmd.Context(), templateInstallFlags.pkg.options(), install)
+	}
+	for _, arg := range args {
+		name, version, _ := strings.Cut(arg, "@")
+		var packages []generator.InstalledPackage
+		packages, err = generator.NewService().InstallTemplate(cmd.Context(), name, version, install)
+		installed = append(installed, packages...)
+		if err != nil {
+			break
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		type jsonPackage struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			Dir        string `json:"dir"`
+			Downloaded bool   `json:"downloaded"`
+		}
+		packages := []jsonPackage{}
+		for _, pkg := range installed {
+			packages = append(packages, jsonPackage{pkg.Name, pkg.Version, pkg.Dir, pkg.Downloaded})
+		}
+		if writeErr := writeJSON(out, struct {
+			Packages []jsonPackage `json:"packages"`
+			Error    *jsonError    `json:"error,omitempty"`
+		}{packages, newJSONError(err)}); writeErr != nil {
+			return writeErr
+		}
+		return err
+	}
+
+	for _, pkg := range installed {
+		if pkg.Downloaded {
+			fmt.Fprintf(out, "✓ Installed %s %s in %s\n", pkg.Name, pkg.Version, pkg.Dir)
+		} else {
+			fmt.Fprintf(out, "✓ %s %s is already installed\n", pkg.Name, pkg.Version)
+		}
+	}
+	if err == nil && len(installed) == 0 {
+		fmt.Fprintln(out, "The template package has no dependencies.")
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+// templateMigrateFlags holds the flags of the template migrate command
+var templateMigrateFlags struct {
+	pkg   templatePackageFlags
+	apply bool
+}
+
+var templateMigrateCmd = &cobra.Command{
+	Use:   "migrate --template-dir <directory>",
+	Short: "Merge built-in template updates into a copied package",
+	Long: `Migrate brings a package created with template copy up to date with the
+built-in template set it was copied from. Every file whose built-in
+original changed since the copy is merged three ways with the local
+customizations, and the upstream changes are shown as diffs.
+
+Without --apply nothing is written. With --apply the merged files are
+written and the recorded originals advanced; overlapping changes are
+written with <<<<<<< local and >>>>>>> upstream conflict markers to resolve
+by hand, and the command fails while any remain.`,
+	Example: `  mcpweaver template migrate --template-dir ./my-templates
+  mcpweaver template migrate --template-dir ./my-templates --apply`,
+	Args: cobra.NoArgs,
+	RunE: runTemplateMigrate,
+}
+
+func init() {
+	templateMigrateFlags.pkg.register(templateMigrateCmd)
+	templateMigrateCmd.Flags().BoolVar(&templateMigrateFlags.apply, "apply", false, "write the merged files")
+	templateCmd.AddCommand(templateMigrateCmd)
+}
+
+// jsonMigrationFile is the migration of one file of a copied package
+type jsonMigrationFile struct {
+	Path      string         `json:"path"`
+	Status    string         `json:"status"`
+	Upstream  []jsonDiffHunk `json:"upstream"`
+	Conflicts int            `json:"conflicts"`
+}
+
+// jsonMigration is the JSON output of template migrate
+type jsonMigration struct {
+	Dir         string              `json:"dir"`
+	BaseVersion string              `json:"baseVersion"`
+	Version     string              `json:"version"`
+	Applied     bool                `json:"applied"`
+	Files       []jsonMigrationFile `json:"files"`
+}
+
+func runTemplateMigrate(cmd *cobra.Command, args []string) error {
+	if err := templateMigrateFlags.pkg.requireDir(); err != nil {
+		return err
+	}
+	migration, err := generator.NewService().MigrateTemplate(templateMigrateFlags.pkg.options(), templateMigrateFlags.apply)
+	if err != nil {
+		return err
+	}
+
+	conflicted := 0
+	for _, file := range migration.Files {
+		if file.Status == generator.MigrationConflict {
+			conflicted++
+		}
+	}
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		described := jsonMigration{
+			Dir:         migration.Dir,
+			BaseVersion: migration.BaseVersion,
+			Version:     migration.Version,
+			Applied:     migration.Applied,
+			Files:       []jsonMigrationFile{},
+		}
+		for _, file := range migration.Files {
+			described.Files = append(described.Files, jsonMigrationFile{
+				Path:      file.Path,
+				Status:    string(file.Status),
+				Upstream:  newJSONDiffHunks(file.Upstream),
+				Conflicts: file.Conflicts,
+			})
+		}
+		if err := writeJSON(out, described); err != nil {
+			return err
+		}
+	} else {
+		printMigration(out, migration)
+	}
+
+	if conflicted == 0 {
+		return nil
+	}
+	suggestion := "Apply the migration with --apply and resolve the conflict markers in the files listed"
+	if migration.Applied {
+		suggestion = "Resolve the <<<<<<< local and >>>>>>> upstream markers in the files listed"
+	}
+	return common.NewError(common.ErrorTypeValidation, fmt.Sprintf("%d files have local changes overlapping built-in updates", conflicted), nil).
+		WithFile(migration.Dir).
+		WithSuggestion(suggestion)
+}
+
+// printMigration lists the files changed upstream with the changes
+func printMigration(out io.Writer, migration *generator.TemplateMigration) {
+	versions := ""
+	if migration.BaseVersion != "" && migration.Version != "" && migration.BaseVersion != migration.Version {
+		versions = fmt.Sprintf(" (%s → %s)", migration.BaseVersion, migration.Version)
+	}
+	changed := 0
+	for _, file := range migration.Files {
+		if file.Status == generator.MigrationCurrent {
+			continue
+		}
+		changed++
+		mark := "✓"
+		if file.Status == generator.MigrationConflict {
+			mark = "✗"
+		}
+		fmt.Fprintf(out, "%s %s: %s", mark, file.Path, file.Status)
+		if file.Conflicts > 0 {
+			fmt.Fprintf(out, ", conflicting regions: %d", file.Conflicts)
+		}
+		fmt.Fprintln(out)
+		if len(file.Upstream) > 0 {
+			fmt.Fprintf(out, "--- a/%s\n+++ b/%s\n", file.Path, file.Path)
+			printHunks(out, file.Upstream)
+		}
+	}
+
+	switch {
+	case changed == 0:
+		fmt.Fprintf(out, "✓ %s is up to date with its built-in files%s\n", migration.Dir, versions)
+	case migration.Applied:
+		fmt.Fprintf(out, "\nMigrated %d files of %s%s\n", changed, migration.Dir, versions)
+	default:
+		fmt.Fprintf(out, "\n%d files of %s changed upstream%s; write the merges with --apply\n", changed, migration.Dir, versions)
+	}
+}
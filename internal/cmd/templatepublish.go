@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+// templatePublishFlags holds the flags of the template publish command
+var templatePublishFlags struct {
+	pkg      templatePackageFlags
+	registry string
+	token    string
+}
+
+var templatePublishCmd = &cobra.Command{
+	Use:   "publish --registry <url> --template-dir <directory>",
+	Short: "Publish a template package to a template registry",
+	Long: `Publish validates the template package, packages it as a zip archive with
+a SHA256SUMS file and uploads it to the registry as
+{registry}/templates/{name}/{version}, taking the name and version from
+its manifest.json, which must also have a description. A package with
+template errors is not published.
+
+The registry can be set with the registry key of the configuration. The
+token is sent as a bearer token; without --token, the one stored for the
+registry URL in ~/.config/mcpweaver/credentials.yaml is used.`,
+	Example: `  mcpweaver template publish --registry https://templates.example.com --template-dir ./my-templates --token $TOKEN
+  mcpweaver template publish --template-dir ./my-templates --json`,
+	Args: cobra.NoArgs,
+	RunE: runTemplatePublish,
+}
+
+func init() {
+	templatePublishFlags.pkg.register(templatePublishCmd)
+	flags := templatePublishCmd.Flags()
+	flags.StringVar(&templatePublishFlags.registry, "registry", "", "base URL of the template registry")
+	flags.StringVar(&templatePublishFlags.token, "token", "", "bearer token for the registry (default: the stored credentials)")
+	templateCmd.AddCommand(templatePublishCmd)
+}
+
+func runTemplatePublish(cmd *cobra.Command, args []string) error {
+	if err := templatePublishFlags.pkg.requireDir(); err != nil {
+		return err
+	}
+	registry := templatePublishFlags.registry
+	if registry == "" {
+		return fmt.Errorf("no registry given; set --registry or the registry key of the configuration")
+	}
+	token := templatePublishFlags.token
+	if token == "" {
+		stored, err := sourceFetchOptions(registry)
+		if err != nil {
+			return err
+		}
+		token = stored.Token
+	}
+
+	out := cmd.OutOrStdout()
+	progress := progressOutput(out)
+	result, err := generator.NewService().PublishTemplate(cmd.Context(), templatePublishFlags.pkg.options(), generator.PublishOptions{
+		Registry: registry,
+		Token:    token,
+		Progress: func(event generator.PublishEvent) {
+			switch {
+			case event.Stage == generator.PublishValidating:
+				fmt.Fprintln(progress, "Validating template package...")
+			case event.Stage == generator.PublishPackaging:
+				fmt.Fprintln(progress, "Packaging template package...")
+			case event.Stage == generator.PublishUploading && event.Sent == 0:
+				fmt.Fprintf(progress, "Uploading %d bytes to %s...\n", event.Total, registry)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return writeJSON(out, struct {
+			Name       string   `json:"name"`
+			Version    string   `json:"version"`
+			URL        string   `json:"url"`
+			Checksum   string   `json:"checksum"`
+			Size       int64    `json:"size"`
+			Files      []string `json:"files"`
+			DurationMS int64    `json:"durationMs"`
+		}{result.Name, result.Version, result.URL, result.Checksum, result.Size, result.Files, result.Duration.Milliseconds()})
+	}
+	fmt.Fprintf(out, "✓ Published %s %s to %s\n", result.Name, result.Version, result.URL)
+	fmt.Fprintf(out, "  %d files, %d bytes, SHA-256 %s\n", len(result.Files), result.Size, result.Checksum)
+	return nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+// templateSnapshotFlags holds the flags of the template snapshot command
+var templateSnapshotFlags struct {
+	pkg       templatePackageFlags
+	snapshots string
+	update    bool
+}
+
+var templateSnapshotCmd = &cobra.Command{
+	Use:   "snapshot <openapi-spec>...",
+	Short: "Compare template renders with approved snapshots",
+	Long: `Snapshot renders the template package for each fixture specification and
+compares every output file with its approved snapshot, kept in a directory
+per fixture below --snapshots and named after the specification file. Files
+that changed, are new or are no longer rendered are shown as diffs, and
+the command fails when any fixture drifted.
+
+After reviewing the diffs, --update approves them by rewriting the
+snapshots from the render; the first run with --update records them.`,
+	Example: `  mcpweaver template snapshot fixtures/*.yaml --template-dir ./my-templates --update
+  mcpweaver template snapshot fixtures/*.yaml --template-dir ./my-templates
+  mcpweaver template snapshot fixtures/petstore.yaml --profile production --snapshots testdata/production`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeSpecs,
+	RunE:              runTemplateSnapshot,
+}
+
+func init() {
+	templateSnapshotFlags.pkg.register(templateSnapshotCmd)
+	templateSnapshotFlags.pkg.registerRender(templateSnapshotCmd)
+	flags := templateSnapshotCmd.Flags()
+	flags.StringVar(&templateSnapshotFlags.snapshots, "snapshots", "snapshots", "directory holding a snapshot directory per fixture")
+	flags.BoolVar(&templateSnapshotFlags.update, "update", false, "approve the render by rewriting the snapshots")
+	registerCompletions(templateSnapshotCmd, map[string]cobra.CompletionFunc{"snapshots": completeDirs})
+	templateCmd.AddCommand(templateSnapshotCmd)
+}
+
+// jsonSnapshotFile is the comparison of one file with its snapshot
+type jsonSnapshotFile struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// jsonSnapshotReport is the comparison of one fixture
+type jsonSnapshotReport struct {
+	Spec    string             `json:"spec"`
+	Dir     string             `json:"dir"`
+	Drifted bool               `json:"drifted"`
+	Updated bool               `json:"updated"`
+	Files   []jsonSnapshotFile `json:"files"`
+	Error   *jsonError         `json:"error,omitempty"`
+}
+
+func runTemplateSnapshot(cmd *cobra.Command, args []string) error {
+	opts, err := templateSnapshotFlags.pkg.renderOptions()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	reports := []jsonSnapshotReport{}
+	drifted, failed := 0, 0
+	dirs := map[string]string{}
+	for _, spec := range args {
+		name := strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec))
+		if other, ok := dirs[name]; ok {
+			return fmt.Errorf("%s and %s would share the snapshot directory %s; rename one", other, spec, name)
+		}
+		dirs[name] = spec
+	}
+
+	for _, spec := range args {
+		dir := filepath.Join(templateSnapshotFlags.snapshots, strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec)))
+		report := jsonSnapshotReport{Spec: spec, Dir: dir, Files: []jsonSnapshotFile{}}
+		server, err := loadServer(cmd.Context(), spec)
+		var result *generator.SnapshotReport
+		if err == nil {
+			result, err = generator.NewService().CheckSnapshots(server, opts, dir, templateSnapshotFlags.update)
+		}
+		if err != nil {
+			failed++
+			report.Error = newJSONError(err)
+			reports = append(reports, report)
+			if !jsonOutput {
+				fmt.Fprintf(out, "✗ %s: could not render\n", spec)
+				fmt.Fprint(cmd.ErrOrStderr(), FormatError(err))
+			}
+			continue
+		}
+
+		report.Drifted, report.Updated = result.Drifted(), result.Updated
+		changed := 0
+		for _, file := range result.Files {
+			report.Files = append(report.Files, jsonSnapshotFile{Path: file.Path, Status: string(file.Status), Diff: file.Diff})
+			if file.Status != generator.SnapshotMatched {
+				changed++
+			}
+		}
+		reports = append(reports, report)
+		if report.Drifted && !report.Updated {
+			drifted++
+		}
+		if jsonOutput {
+			continue
+		}
+		switch {
+		case report.Updated:
+			fmt.Fprintf(out, "✓ %s: updated %d snapshots in %s\n", spec, changed, dir)
+		case report.Drifted:
+			fmt.Fprintf(out, "✗ %s: %d of %d files differ from %s\n", spec, changed, len(result.Files), dir)
+			for _, file := range result.Files {
+				if file.Status != generator.SnapshotMatched {
+					fmt.Fprintf(out, "  %s (%s)\n", file.Path, file.Status)
+				}
+			}
+			// New files are listed; their whole content is no diff to review
+			for _, file := range result.Files {
+				if file.Status != generator.SnapshotMissing {
+					fmt.Fprint(out, file.Diff)
+				}
+			}
+		default:
+			fmt.Fprintf(out, "✓ %s: %d files match\n", spec, len(result.Files))
+		}
+	}
+
+	var failure error
+	switch {
+	case drifted > 0:
+		failure = common.NewError(common.ErrorTypeTest, fmt.Sprintf("%d of %d fixtures drifted from their snapshots", drifted, len(args)), nil).
+			WithSuggestion("Review the diffs and approve intended changes with --update")
+	case failed > 0:
+		failure = common.NewError(common.ErrorTypeGeneration, fmt.Sprintf("%d of %d fixtures could not be rendered", failed, len(args)), nil)
+	}
+	if jsonOutput {
+		if writeErr := writeJSON(out, reports); writeErr != nil {
+			return writeErr
+		}
+	}
+	return failure
+}
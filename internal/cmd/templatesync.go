@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+// templateSyncFlags holds the flags of the template sync command
+var templateSyncFlags struct {
+	dir      string
+	watch    bool
+	interval time.Duration
+}
+
+var templateSyncCmd = &cobra.Command{
+	Use:   "sync <repository-url>",
+	Short: "Install the tagged versions of a template package kept in git",
+	Long: `Sync mirrors a git repository holding a template package and installs
+every version tagged in it, such as v1.2.0 or 1.2.0, that is not installed
+yet into the per-user packages directory, named by the package manifest.
+Installed versions can be used as dependencies of other packages or given
+to generate with --template-dir. --dir selects the package directory when
+the repository keeps it below the root.
+
+With --watch, the repository is checked for new tags again every
+--interval until interrupted; with --json each check is one line of JSON,
+a "template:repo_synced" event. Repositories are accessed with the git
+credentials already configured; git never prompts for them.`,
+	Example: `  mcpweaver template sync https://github.com/acme/mcp-templates.git
+  mcpweaver template sync git@github.com:acme/monorepo.git --dir templates/acme-go
+  mcpweaver template sync https://github.com/acme/mcp-templates.git --watch --interval 30m`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateSync,
+}
+
+func init() {
+	flags := templateSyncCmd.Flags()
+	flags.StringVar(&templateSyncFlags.dir, "dir", "", "package directory within the repository (default: its root)")
+	flags.BoolVarP(&templateSyncFlags.watch, "watch", "w", false, "check for new tags periodically until interrupted")
+	flags.DurationVar(&templateSyncFlags.interval, "interval", generator.DefaultRepoSyncInterval, "how often --watch checks for new tags")
+	templateCmd.AddCommand(templateSyncCmd)
+}
+
+func runTemplateSync(cmd *cobra.Command, args []string) error {
+	if ciMode && templateSyncFlags.watch {
+		return fmt.Errorf("template sync --watch runs until interrupted and cannot be used with --ci")
+	}
+	src := generator.GitSource{URL: args[0], Dir: templateSyncFlags.dir}
+	out := cmd.OutOrStdout()
+
+	if !templateSyncFlags.watch {
+		result, err := generator.NewService().SyncTemplateRepo(cmd.Context(), src, "")
+		if jsonOutput {
+			if writeErr := writeJSON(out, newJSONRepoSync(src, result, err)); writeErr != nil {
+				return writeErr
+			}
+			return err
+		}
+		printRepoSync(out, result)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	first := true
+	err := generator.NewService().WatchTemplateRepo(ctx, src, "", templateSyncFlags.interval, func(event generator.RepoSyncEvent) {
+		if jsonOutput {
+			now := time.Now()
+			synced := newJSONRepoSync(src, event.Result, event.Err)
+			synced.Event, synced.Time = event.Name, &now
+			json.NewEncoder(out).Encode(synced)
+			return
+		}
+		printRepoSync(out, event.Result)
+		if event.Err != nil {
+			fmt.Fprint(cmd.ErrOrStderr(), FormatError(event.Err))
+		}
+		if first {
+			fmt.Fprintf(out, "\nChecking %s for new tags every %s. Press Ctrl+C to stop.\n", src.URL, templateSyncFlags.interval)
+		}
+		first = false
+	})
+	if err != nil {
+		return err
+	}
+	if !jsonOutput {
+		fmt.Fprintln(out, "\nStopped watching.")
+	}
+	return nil
+}
+
+// jsonRepoSync is a synchronization of a template repository; Event and
+// Time are set for the events of --watch
+type jsonRepoSync struct {
+	Event     string            `json:"event,omitempty"`
+	Time      *time.Time        `json:"time,omitempty"`
+	URL       string            `json:"url"`
+	Dir       string            `json:"dir"`
+	Versions  []string          `json:"versions"`
+	Installed []jsonRepoVersion `json:"installed"`
+	Warnings  []string          `json:"warnings"`
+	Error     *jsonError        `json:"error,omitempty"`
+}
+
+// jsonRepoVersion is a package version installed from a repository
+type jsonRepoVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Dir     string `json:"dir"`
+}
+
+// newJSONRepoSync describes a synchronization, which may have failed
+// before producing a result
+func newJSONRepoSync(src generator.GitSource, result *generator.RepoSyncResult, err error) jsonRepoSync {
+	synced := jsonRepoSync{
+		URL:       src.URL,
+		Dir:       src.Dir,
+		Versions:  []string{},
+		Installed: []jsonRepoVersion{},
+		Warnings:  []string{},
+		Error:     newJSONError(err),
+	}
+	if result == nil {
+		return synced
+	}
+	synced.Versions = append(synced.Versions, result.Versions...)
+	synced.Warnings = append(synced.Warnings, result.Warnings...)
+	for _, pkg := range result.Installed {
+		synced.Installed = append(synced.Installed, jsonRepoVersion{pkg.Name, pkg.Version, pkg.Dir})
+	}
+	return synced
+}
+
+// printRepoSync lists the versions a synchronization installed
+func printRepoSync(out io.Writer, result *generator.RepoSyncResult) {
+	if result == nil {
+		return
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(out, "Warning: %s\n", warning)
+	}
+	for _, pkg := range result.Installed {
+		fmt.Fprintf(out, "✓ Installed %s %s in %s\n", pkg.Name, pkg.Version, pkg.Dir)
+	}
+	if len(result.Installed) == 0 {
+		fmt.Fprintf(out, "✓ %s: %d tagged versions, all installed\n", result.Source.URL, len(result.Versions))
+	}
+}
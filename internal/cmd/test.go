@@ -0,0 +1,1049 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+)
+
+// Test stages in the order they run
+const (
+	stageUnit      = "unit"
+	stageProbe     = "probe"
+	stageFuzz      = "fuzz"
+	stageScenarios = "scenarios"
+	stageLoad      = "load"
+)
+
+var testStageOrder = []string{stageUnit, stageProbe, stageFuzz, stageScenarios, stageLoad}
+
+// testFlags holds the flags of the test command
+var testFlags struct {
+	stages       []string
+	reports      []string
+	reportDir    string
+	env          []string
+	image        string
+	fixtures     string
+	fixturesMode string
+	upstream     string
+	fuzzCases    int
+	seed         int64
+	clients      int
+	duration     time.Duration
+	rampUp       time.Duration
+	mix          map[string]int
+	latency      time.Duration
+	maxP95       time.Duration
+	maxP99       time.Duration
+	minRPS       float64
+	maxErrorRate float64
+	maxMemoryMB  int64
+	minCoverage  float64
+	baseline     string
+	saveBaseline string
+	tolerances   generator.LoadTolerances
+	workers      int
+	every        time.Duration
+	retries      int
+	history      bool
+}
+
+var testCmd = &cobra.Command{
+	Use:   "test <server-dir>...",
+	Short: "Test generated MCP servers",
+	Long: `Test builds a generated server and runs it through the test stages against a
+local stand-in for the upstream API:
+
+  unit       the server's own Go tests pass, measuring their coverage
+  probe      the MCP handshake, tools/list and a tool call follow the protocol
+  fuzz       every tool answers boundary and random arguments without crashing
+  scenarios  the YAML scenarios in the server's scenarios directory pass
+  load       concurrent clients stay within the load thresholds
+
+Unit, probe, fuzz and scenarios run by default; unit is skipped when the
+server has no _test.go files, such as those a custom template package
+renders or added by hand, and scenarios when it has no scenarios
+directory. The unit stage runs go test -coverprofile on every package and
+reports the share of statements the tests ran; --min-coverage fails the
+stage below a percentage. With --fixtures, the upstream requests of
+the probe and scenarios are answered from the responses recorded in that
+directory, so runs are repeatable without network access; record them once
+with --fixtures-mode record, which forwards the requests to --upstream, the
+base_url of the server's config.yaml by default. Request headers are not
+saved, so credentials never end up in fixtures.
+
+The probe also checks what Claude Desktop and VS Code expect of a server,
+and lists the tools with the MCP Inspector CLI when mcp-inspector is on
+PATH; client problems are reported without failing the stage. It prints
+the claude_desktop_config.json entry registering the server, which the
+JSON output and the reports include as well.
+
+With --docker-image, every stage compiles and runs the server in disposable
+docker containers of that image instead of with the host's Go toolchain, so
+testing neither depends on nor changes the host's Go environment; only the
+--env variables are passed in. The containers share the host network to
+reach the stand-in API, which needs Docker on Linux or host networking
+enabled in Docker Desktop. Peak memory is not measured in them, and the MCP
+Inspector is not run.
+
+Results can also be written as JUnit XML
+for CI systems and as an HTML page. The command exits with 2 when any stage
+fails, or 5 with --ci.
+
+Several servers are tested --workers at a time, with a line printed as each
+finishes and all failures listed at the end; their reports go to a
+directory per server inside --report-dir, a combined report of all of them
+to --report-dir itself, and the JSON output is an array. A report that
+cannot be written is listed with the failures without stopping the others.
+Run load stages with --workers 1 so that servers do not compete for CPU.
+
+With --retries, a stage with failed checks runs again, up to that many
+times, until it passes; checks that pass on a retry are reported as flaky
+rather than failed, with the failures of the earlier attempts. Retries of
+the fuzz stage send the same payloads.
+
+The outcome of every check is kept across runs in the server's
+.mcpweaver/test-history.json, for its last 20 runs. Checks that both passed
+and failed in them, or passed only on a retry, are reported as
+intermittent with their failure count, and a stage whose failing checks
+include an intermittent one is retried at least twice even without
+--retries. The result of every run is also saved, as a timestamped file in
+the server's .mcpweaver/test-results, for test history to list after the
+reports are gone. --history=false neither reads nor updates the history
+and saves no result.
+
+--save-baseline keeps the result of the load stage in a file; a later run
+with --baseline fails the load stage when its latencies, throughput, memory
+or error rate got worse than the saved result by more than the tolerances.
+
+With --every, the servers are tested again at that interval until
+interrupted, as a regression run: each run writes its reports to a
+directory named after its start time inside --report-dir and lists the
+checks that started failing since the run before. With --json each run is
+one line of JSON, a test_run_completed event.`,
+	Example: `  mcpweaver test ./server
+  mcpweaver test ./server --report junit --report html --report-dir ./reports
+  mcpweaver test ./server --stages probe,load --max-p95 200ms --min-rps 50
+  mcpweaver test ./server --stages load --clients 20 --ramp-up 10s --mix list_users=4,create_user=1
+  mcpweaver test ./server --stages load --baseline load.json --latency-tolerance 0.2
+  mcpweaver test ./server --fixtures ./fixtures --fixtures-mode record --upstream https://api.example.com --env API_TOKEN=secret
+  mcpweaver test ./server --fixtures ./fixtures --ci
+  mcpweaver test ./server --retries 2 --report junit
+  mcpweaver test ./server --docker-image golang:1.23
+  mcpweaver test ./servers/* --workers 4 --report junit --report-dir ./reports
+  mcpweaver test ./servers/* --every 24h --report html --report-dir ./nightly`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeDirs,
+	RunE:              runTest,
+}
+
+func init() {
+	flags := testCmd.Flags()
+	flags.StringSliceVar(&testFlags.stages, "stages", []string{stageUnit, stageProbe, stageFuzz, stageScenarios}, "stages to run: unit, probe, fuzz, scenarios and load")
+	flags.StringSliceVar(&testFlags.reports, "report", nil, "reports to write: junit or html")
+	flags.StringVar(&testFlags.reportDir, "report-dir", ".", "directory receiving the reports")
+	flags.StringArrayVar(&testFlags.env, "env", nil, "NAME=value variable for the server, e.g. credentials; repeatable")
+	flags.StringVar(&testFlags.image, "docker-image", "", "compile and run the server in disposable docker containers of this image, e.g. golang:1.23")
+	flags.StringVar(&testFlags.fixtures, "fixtures", "", "answer upstream requests of the probe and scenarios from fixtures recorded in this directory")
+	flags.StringVar(&testFlags.fixturesMode, "fixtures-mode", string(generator.FixturesReplay), "with --fixtures, replay recorded responses or record them from --upstream")
+	flags.StringVar(&testFlags.upstream, "upstream", "", "API recorded against with --fixtures-mode record (default: base_url of the server's config.yaml)")
+	flags.IntVar(&testFlags.fuzzCases, "fuzz-cases", generator.DefaultFuzzCases, "payloads sent to each tool by the fuzz stage")
+	flags.Int64Var(&testFlags.seed, "seed", 0, "seed of the fuzz payloads; 0 picks one")
+	flags.IntVar(&testFlags.clients, "clients", generator.DefaultLoadClients, "concurrent clients of the load stage")
+	flags.DurationVar(&testFlags.duration, "duration", generator.DefaultLoadDuration, "duration of the load stage")
+	flags.DurationVar(&testFlags.rampUp, "ramp-up", 0, "spread the starts of the load clients evenly over this period")
+	flags.StringToIntVar(&testFlags.mix, "mix", nil, "tool=weight pairs the load clients call in proportion to; default: every tool equally")
+	flags.DurationVar(&testFlags.latency, "upstream-latency", 0, "delay every response of the stand-in API in the load stage, simulating a remote API")
+	flags.DurationVar(&testFlags.maxP95, "max-p95", 0, "fail the load stage above this 95th percentile latency")
+	flags.DurationVar(&testFlags.maxP99, "max-p99", 0, "fail the load stage above this 99th percentile latency")
+	flags.Float64Var(&testFlags.minRPS, "min-rps", 0, "fail the load stage below this many requests per second")
+	flags.Float64Var(&testFlags.maxErrorRate, "max-error-rate", 0, "fail the load stage above this share of failed requests, e.g. 0.01")
+	flags.IntVar(&testFlags.workers, "workers", 0, "with several servers, "+workersUsage)
+	flags.IntVar(&testFlags.retries, "retries", 0, "run a stage with failed checks again up to this many times; checks passing on a retry are reported as flaky")
+	flags.BoolVar(&testFlags.history, "history", true, "keep the outcome of every check in the server's "+testHistoryFile+", reporting and retrying intermittent checks, and save each run in "+testResultsDir)
+	flags.DurationVar(&testFlags.every, "every", 0, "test again at this interval, e.g. 24h, until interrupted, listing new failures")
+	flags.Float64Var(&testFlags.minCoverage, "min-coverage", 0, "fail the unit stage when its tests cover less of the statements, in percent")
+	flags.Int64Var(&testFlags.maxMemoryMB, "max-memory-mb", 0, "fail the load stage when a server uses more memory, in MiB")
+	flags.StringVar(&testFlags.baseline, "baseline", "", "fail the load stage when it is worse than the result saved in this file")
+	flags.StringVar(&testFlags.saveBaseline, "save-baseline", "", "save the result of the load stage to this file, as a baseline for later runs")
+	flags.Float64Var(&testFlags.tolerances.Latency, "latency-tolerance", generator.DefaultLoadTolerance, "with --baseline, allowed increase of the mean, p95 and p99 latency, e.g. 0.2 for 20%")
+	flags.Float64Var(&testFlags.tolerances.Throughput, "throughput-tolerance", generator.DefaultLoadTolerance, "with --baseline, allowed drop in requests per second")
+	flags.Float64Var(&testFlags.tolerances.Memory, "memory-tolerance", generator.DefaultLoadTolerance, "with --baseline, allowed growth of the peak memory")
+	flags.Float64Var(&testFlags.tolerances.ErrorRate, "error-rate-tolerance", 0, "with --baseline, allowed increase in the share of failed requests, e.g. 0.01")
+	registerCompletions(testCmd, map[string]cobra.CompletionFunc{
+		"stages":        completeValues(testStageOrder...),
+		"report":        completeValues("junit", "html"),
+		"report-dir":    completeDirs,
+		"fixtures":      completeDirs,
+		"fixtures-mode": completeValues(string(generator.FixturesReplay), string(generator.FixturesRecord)),
+	})
+	rootCmd.AddCommand(testCmd)
+}
+
+// testCase is one check of a stage; it passed when it has no failures
+type testCase struct {
+	Name     string
+	Failures []string
+	Duration time.Duration
+	// EarlierFailures are the failures of the attempts before the last,
+	// with --retries
+	EarlierFailures []string
+	// Intermittent is set when the check both passed and failed over the
+	// runs in the test history, which counts RecentRuns runs of the check
+	// and RecentFailures of them failing or flaky
+	Intermittent   bool
+	RecentRuns     int
+	RecentFailures int
+}
+
+// flaky reports whether the case passed on a retry after failing
+func (c testCase) flaky() bool {
+	return len(c.Failures) == 0 && len(c.EarlierFailures) > 0
+}
+
+// testStage is the outcome of one stage
+type testStage struct {
+	Name    string
+	Title   string
+	Summary string
+	// Skipped explains why the stage did not run
+	Skipped  string
+	Cases    []testCase
+	Duration time.Duration
+	// Attempts counts the runs of the stage, more than one when failures
+	// were retried
+	Attempts int
+	// ClaudeDesktopConfig is the claude_desktop_config.json entry of the
+	// probed server
+	ClaudeDesktopConfig string
+	// Coverage is the share of statements the unit tests ran, in percent
+	Coverage *float64
+}
+
+// failed counts the failed cases
+func (s testStage) failed() int {
+	failed := 0
+	for _, c := range s.Cases {
+		if len(c.Failures) > 0 {
+			failed++
+		}
+	}
+	return failed
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	selected := map[string]bool{}
+	for _, name := range testFlags.stages {
+		name = strings.TrimSpace(name)
+		switch name {
+		case stageUnit, stageProbe, stageFuzz, stageScenarios, stageLoad:
+			selected[name] = true
+		default:
+			return fmt.Errorf("unknown stage %q; use %s", name, strings.Join(testStageOrder, ", "))
+		}
+	}
+	for _, report := range testFlags.reports {
+		if report != "junit" && report != "html" {
+			return fmt.Errorf("unknown report %q; use junit or html", report)
+		}
+	}
+	for _, dir := range args {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+			return common.NewError(common.ErrorTypeGeneration, "directory does not contain a generated server", err).
+				WithFile(dir).
+				WithSuggestion("Generate the server first or point to its output directory")
+		}
+	}
+
+	if testFlags.baseline != "" || testFlags.saveBaseline != "" {
+		if !selected[stageLoad] {
+			return fmt.Errorf("--baseline and --save-baseline use the load stage; add load to --stages")
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("compare the load results of one server at a time")
+		}
+	}
+	if testFlags.baseline != "" {
+		// Fail before a load test that could not be compared
+		if _, err := generator.ReadLoadBaseline(testFlags.baseline); err != nil {
+			return err
+		}
+	}
+	if testFlags.retries < 0 {
+		return fmt.Errorf("--retries cannot be negative")
+	}
+	if testFlags.minCoverage < 0 || testFlags.minCoverage > 100 {
+		return fmt.Errorf("--min-coverage is a percentage from 0 to 100")
+	}
+	if t := testFlags.tolerances; t.Latency < 0 || t.Throughput < 0 || t.Memory < 0 || t.ErrorRate < 0 {
+		return fmt.Errorf("tolerances cannot be negative")
+	}
+
+	fixtures, err := testFixtures(cmd, len(args))
+	if err != nil {
+		return err
+	}
+	if testFlags.every != 0 {
+		if testFlags.every < 0 {
+			return fmt.Errorf("--every must be a positive interval such as 24h")
+		}
+		if ciMode {
+			return fmt.Errorf("--every runs until interrupted and cannot be used with --ci")
+		}
+		return scheduleTests(cmd, args, selected, fixtures)
+	}
+
+	runs, err := testOnce(cmd, args, selected, fixtures, testFlags.reportDir)
+	if runs == nil {
+		return err
+	}
+	if jsonOutput {
+		var output interface{} = runs
+		if len(args) == 1 {
+			output = runs[0]
+		}
+		if writeErr := writeJSON(cmd.OutOrStdout(), output); writeErr != nil {
+			return writeErr
+		}
+	}
+	return err
+}
+
+// testOnce tests the servers, writing their reports to reportDir, and
+// returns the run of each. The error reports failed stages or reports that
+// could not be written; a single server's report failing returns no runs.
+func testOnce(cmd *cobra.Command, dirs []string, selected map[string]bool, fixtures generator.FixtureOptions, reportDir string) ([]jsonTestRun, error) {
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		out = io.Discard
+	}
+	if len(dirs) > 1 {
+		return testServers(cmd, out, dirs, selected, fixtures, reportDir)
+	}
+
+	dir := dirs[0]
+	fmt.Fprintf(progressOutput(out), "Testing generated server in %s...\n", dir)
+	start := time.Now()
+	stages := testServer(cmd, out, dir, selected, fixtures)
+	duration := time.Since(start)
+
+	failed := printTestFailures(out, stages)
+	reports := []string{}
+	for _, report := range testFlags.reports {
+		path, err := writeTestReport(report, reportDir, []testedServer{{Dir: dir, Stages: stages, Duration: duration}}, duration)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, path)
+		fmt.Fprintf(out, "Report written to %s\n", path)
+	}
+
+	runs := []jsonTestRun{newJSONTestRun(dir, stages, duration, reports)}
+	saveTestRun(cmd, start, runs[0])
+	if failed > 0 {
+		return runs, common.NewError(common.ErrorTypeTest,
+			fmt.Sprintf("%d of %d test stages failed", failed, len(stages)), nil)
+	}
+	return runs, nil
+}
+
+// eventTestRunCompleted is logged for every run of test --every in JSON
+// mode
+const eventTestRunCompleted = "test_run_completed"
+
+// jsonTestSchedule is a run of test --every; NewFailures are the checks
+// failing in this run but not the one before, on the first run every
+// failing check
+type jsonTestSchedule struct {
+	Event       string        `json:"event"`
+	Time        time.Time     `json:"time"`
+	Runs        []jsonTestRun `json:"runs"`
+	NewFailures []string      `json:"newFailures"`
+	Error       *jsonError    `json:"error,omitempty"`
+}
+
+// scheduleTests tests the servers immediately and then every --every until
+// interrupted, like a nightly regression run. Each run writes its reports
+// to a directory named after its start time inside --report-dir and lists
+// the checks that started failing since the run before.
+func scheduleTests(cmd *cobra.Command, dirs []string, selected map[string]bool, fixtures generator.FixtureOptions) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cmd.SetContext(ctx)
+
+	out := cmd.OutOrStdout()
+	ticker := time.NewTicker(testFlags.every)
+	defer ticker.Stop()
+	failing := map[string]bool{}
+	for first := true; ctx.Err() == nil; first = false {
+		started := time.Now()
+		if !first && !jsonOutput {
+			fmt.Fprintf(out, "\n[%s] Testing again\n", started.Format("15:04:05"))
+		}
+		runs, err := testOnce(cmd, dirs, selected, fixtures, filepath.Join(testFlags.reportDir, started.Format("20060102T150405")))
+		if ctx.Err() != nil {
+			break
+		}
+
+		newFailures := []string{}
+		now := map[string]bool{}
+		for _, name := range failingChecks(runs) {
+			now[name] = true
+			if !failing[name] {
+				newFailures = append(newFailures, name)
+			}
+		}
+		failing = now
+
+		if jsonOutput {
+			if runs == nil {
+				runs = []jsonTestRun{}
+			}
+			json.NewEncoder(out).Encode(jsonTestSchedule{
+				Event:       eventTestRunCompleted,
+				Time:        started,
+				Runs:        runs,
+				NewFailures: newFailures,
+				Error:       newJSONError(err),
+			})
+		} else {
+			if runs == nil {
+				fmt.Fprint(cmd.ErrOrStderr(), FormatError(err))
+			}
+			if len(newFailures) > 0 {
+				fmt.Fprintln(out, "\nNew failures since the previous run:")
+				for _, name := range newFailures {
+					fmt.Fprintf(out, "  ✗ %s\n", name)
+				}
+			}
+			if first {
+				fmt.Fprintf(out, "\nTesting again every %s. Press Ctrl+C to stop.\n", testFlags.every)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+	if !jsonOutput {
+		fmt.Fprintln(out, "\nStopped testing.")
+	}
+	return nil
+}
+
+// failingChecks names the failed cases of the runs as "server stage/case"
+func failingChecks(runs []jsonTestRun) []string {
+	var names []string
+	for _, run := range runs {
+		for _, stage := range run.Stages {
+			for _, c := range stage.Cases {
+				if !c.Passed {
+					names = append(names, fmt.Sprintf("%s %s/%s", run.Server, stage.Name, c.Name))
+				}
+			}
+		}
+	}
+	return names
+}
+
+// testFixtures returns the fixture options of --fixtures, --fixtures-mode
+// and --upstream
+func testFixtures(cmd *cobra.Command, servers int) (generator.FixtureOptions, error) {
+	mode := generator.FixtureMode(testFlags.fixturesMode)
+	if mode != generator.FixturesReplay && mode != generator.FixturesRecord {
+		return generator.FixtureOptions{}, fmt.Errorf("unknown fixtures mode %q; use %s or %s", mode, generator.FixturesReplay, generator.FixturesRecord)
+	}
+	if testFlags.fixtures == "" {
+		if cmd.Flags().Changed("fixtures-mode") || testFlags.upstream != "" {
+			return generator.FixtureOptions{}, fmt.Errorf("--fixtures-mode and --upstream need the fixture directory; set --fixtures")
+		}
+		return generator.FixtureOptions{}, nil
+	}
+	if mode == generator.FixturesReplay && testFlags.upstream != "" {
+		return generator.FixtureOptions{}, fmt.Errorf("--upstream is only used with --fixtures-mode record")
+	}
+	if mode == generator.FixturesRecord && servers > 1 {
+		// Servers of different APIs would record into the same directory
+		return generator.FixtureOptions{}, fmt.Errorf("record the fixtures of one server at a time")
+	}
+	return generator.FixtureOptions{Mode: mode, Dir: testFlags.fixtures, Upstream: testFlags.upstream}, nil
+}
+
+// testServer runs the selected stages against one server, printing a line
+// per stage to out, and records them in the server's test history
+func testServer(cmd *cobra.Command, out io.Writer, dir string, selected map[string]bool, fixtures generator.FixtureOptions) []testStage {
+	// Retries of the fuzz stage send the same payloads
+	seed := testFlags.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	history := testHistory{Checks: map[string][]string{}}
+	if testFlags.history {
+		var err error
+		if history, err = readTestHistory(dir); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s: %v; starting a new history\n", dir, err)
+		}
+	}
+	var stages []testStage
+	for _, name := range testStageOrder {
+		if !selected[name] {
+			continue
+		}
+		run := func() testStage {
+			switch name {
+			case stageUnit:
+				return unitStage(cmd.Context(), dir, cmd.Flags().Changed("stages"))
+			case stageProbe:
+				return probeStage(cmd.Context(), dir, fixtures)
+			case stageFuzz:
+				return fuzzStage(cmd.Context(), dir, seed)
+			case stageScenarios:
+				return scenarioStage(cmd.Context(), dir, fixtures, cmd.Flags().Changed("stages"))
+			default:
+				return loadStage(cmd.Context(), dir)
+			}
+		}
+		stage := retryStage(cmd.Context(), run, testFlags.retries, history)
+		if testFlags.history {
+			history.record(&stage)
+		}
+		printTestStage(out, stage)
+		stages = append(stages, stage)
+	}
+	if testFlags.history && cmd.Context().Err() == nil {
+		if err := writeTestHistory(dir, history); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s: %v\n", dir, err)
+		}
+	}
+	return stages
+}
+
+// saveTestRun saves the run of a server, started at started, in its test
+// results unless --history=false or the run was interrupted
+func saveTestRun(cmd *cobra.Command, started time.Time, run jsonTestRun) {
+	if !testFlags.history || cmd.Context().Err() != nil {
+		return
+	}
+	if err := saveTestResult(run.Server, started, run); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s: %v\n", run.Server, err)
+	}
+}
+
+// retryStage runs a stage, and again up to retries times while checks
+// fail, or intermittentRetries times when a failing check is intermittent
+// in the history. The last attempt is kept, its cases carrying the failures
+// of the attempts before, so that checks passing on a retry show as flaky.
+func retryStage(ctx context.Context, run func() testStage, retries int, history testHistory) testStage {
+	stage := run()
+	stage.Attempts = 1
+	earlier := map[string][]string{}
+	for stage.failed() > 0 && stage.Attempts <= history.retriesFor(stage, retries) && ctx.Err() == nil {
+		for _, c := range stage.Cases {
+			for _, failure := range c.Failures {
+				earlier[c.Name] = append(earlier[c.Name], fmt.Sprintf("attempt %d: %s", stage.Attempts, failure))
+			}
+		}
+		attempts := stage.Attempts + 1
+		stage = run()
+		stage.Attempts = attempts
+	}
+	for i, c := range stage.Cases {
+		stage.Cases[i].EarlierFailures = earlier[c.Name]
+	}
+	return stage
+}
+
+// testServers tests several servers with a pool of workers, printing a
+// line as each finishes and the failures of all of them at the end. Reports
+// are written to a directory per server inside --report-dir, and combined
+// for all servers into --report-dir itself. Reports that cannot be written
+// are listed with the failures and fail the run, without stopping the
+// others.
+func testServers(cmd *cobra.Command, out io.Writer, dirs []string, selected map[string]bool, fixtures generator.FixtureOptions, reportDir string) ([]jsonTestRun, error) {
+	fmt.Fprintf(progressOutput(out), "Testing %d generated servers...\n", len(dirs))
+	started := time.Now()
+	runs := make([]jsonTestRun, len(dirs))
+	servers := make([]testedServer, len(dirs))
+	progress := &batchProgress{out: out, total: len(dirs)}
+	reportDirs := testReportDirs(reportDir, dirs)
+	var reportFailures []batchFailure
+	var reportMu sync.Mutex
+	reportFailed := func(name string, err error) {
+		reportMu.Lock()
+		defer reportMu.Unlock()
+		reportFailures = append(reportFailures, batchFailure{name: name, reason: firstLine(err.Error())})
+	}
+	runWorkers(testFlags.workers, len(dirs), func(i int) {
+		start := time.Now()
+		stages := testServer(cmd, io.Discard, dirs[i], selected, fixtures)
+		duration := time.Since(start)
+		servers[i] = testedServer{Dir: dirs[i], Stages: stages, Duration: duration}
+
+		reports := []string{}
+		for _, report := range testFlags.reports {
+			path, err := writeTestReport(report, reportDirs[i], servers[i:i+1], duration)
+			if err != nil {
+				reportFailed(fmt.Sprintf("%s %s report", dirs[i], report), err)
+				continue
+			}
+			reports = append(reports, path)
+		}
+		runs[i] = newJSONTestRun(dirs[i], stages, duration, reports)
+		saveTestRun(cmd, start, runs[i])
+
+		var err error
+		failed, skipped := 0, 0
+		for _, stage := range stages {
+			if stage.Skipped != "" {
+				skipped++
+			} else if stage.failed() > 0 {
+				failed++
+			}
+		}
+		detail := fmt.Sprintf("%d stages passed, %d skipped", len(stages)-failed-skipped, skipped)
+		if failed > 0 {
+			err = fmt.Errorf("%d of %d stages failed", failed, len(stages))
+		}
+		progress.report(dirs[i], detail, err, duration)
+	})
+
+	var combined []string
+	for _, report := range testFlags.reports {
+		path, err := writeTestReport(report, reportDir, servers, time.Since(started))
+		if err != nil {
+			reportFailed("combined "+report+" report", err)
+			continue
+		}
+		combined = append(combined, path)
+	}
+
+	var failures []batchFailure
+	for i, server := range servers {
+		for _, stage := range server.Stages {
+			for _, c := range stage.Cases {
+				for _, failure := range c.Failures {
+					failures = append(failures, batchFailure{
+						name:   fmt.Sprintf("%s %s/%s", dirs[i], stage.Name, c.Name),
+						reason: firstLine(failure),
+					})
+				}
+			}
+		}
+	}
+	failedServers := 0
+	for _, run := range runs {
+		if !run.Passed {
+			failedServers++
+		}
+	}
+	sort.Slice(reportFailures, func(i, j int) bool { return reportFailures[i].name < reportFailures[j].name })
+	printFailures(out, append(failures, reportFailures...))
+	fmt.Fprintf(out, "\n%d servers passed, %d failed\n", len(dirs)-failedServers, failedServers)
+	for _, run := range runs {
+		for _, path := range run.Reports {
+			fmt.Fprintf(out, "Report written to %s\n", path)
+		}
+	}
+	for _, path := range combined {
+		fmt.Fprintf(out, "Combined report written to %s\n", path)
+	}
+	if failedServers > 0 {
+		return runs, common.NewError(common.ErrorTypeTest,
+			fmt.Sprintf("%d of %d servers failed testing", failedServers, len(dirs)), nil)
+	}
+	if len(reportFailures) > 0 {
+		return runs, common.NewError(common.ErrorTypeGeneration,
+			fmt.Sprintf("%d test reports could not be written", len(reportFailures)), nil).
+			WithSuggestion("Check that --report-dir is writable")
+	}
+	return runs, nil
+}
+
+// testReportDirs names the report directory of each server inside
+// reportDir after the server's directory, numbering servers whose
+// directories share a name
+func testReportDirs(reportDir string, dirs []string) []string {
+	reportDirs := make([]string, len(dirs))
+	used := map[string]bool{}
+	for i, dir := range dirs {
+		base := filepath.Base(filepath.Clean(dir))
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[name] = true
+		reportDirs[i] = filepath.Join(reportDir, name)
+	}
+	return reportDirs
+}
+
+// unitStage runs the server's own Go tests, one case per test function
+// and package that failed to build, and a coverage case with --min-coverage
+func unitStage(ctx context.Context, dir string, requested bool) testStage {
+	stage := testStage{Name: stageUnit, Title: "Unit tests"}
+	if !generator.HasUnitTests(dir) {
+		if requested {
+			return stageError(stage, fmt.Errorf("the server has no _test.go files"))
+		}
+		stage.Skipped = "no unit tests"
+		return stage
+	}
+	result, err := generator.NewService().RunUnitTests(ctx, generator.UnitTestOptions{Dir: dir, Env: testFlags.env, Image: testFlags.image})
+	if err != nil {
+		return stageError(stage, err)
+	}
+	stage.Duration = result.Duration
+	stage.Summary = result.String()
+	coverage := result.Coverage()
+	stage.Coverage = &coverage
+	for _, test := range result.Tests {
+		c := testCase{Name: test.Name, Duration: test.Duration}
+		if !test.Passed {
+			c.Failures = []string{test.Output}
+		}
+		stage.Cases = append(stage.Cases, c)
+	}
+	packages := make([]string, 0, len(result.BuildFailures))
+	for pkg := range result.BuildFailures {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	for _, pkg := range packages {
+		stage.Cases = append(stage.Cases, testCase{Name: "build " + pkg, Failures: []string{result.BuildFailures[pkg]}})
+	}
+	if testFlags.minCoverage > 0 {
+		c := testCase{Name: "coverage"}
+		if coverage < testFlags.minCoverage {
+			c.Failures = []string{fmt.Sprintf("%.1f%% of statements covered, below --min-coverage %.1f%%", coverage, testFlags.minCoverage)}
+		}
+		stage.Cases = append(stage.Cases, c)
+	}
+	return stage
+}
+
+// probeStage checks the server against the protocol
+func probeStage(ctx context.Context, dir string, fixtures generator.FixtureOptions) testStage {
+	stage := testStage{Name: stageProbe, Title: "Protocol probe"}
+	result, err := generator.NewService().ProbeServer(ctx, generator.ProbeOptions{Dir: dir, Env: testFlags.env, Fixtures: fixtures, Image: testFlags.image})
+	if result == nil {
+		return stageError(stage, err)
+	}
+	stage.Duration = result.Duration
+	stage.Summary = fmt.Sprintf("%d tools listed", len(result.Tools))
+	if result.Tool != "" {
+		stage.Summary += ", called " + result.Tool
+	}
+	protocol := testCase{Name: "protocol", Failures: result.Failures, Duration: result.Duration}
+	if err != nil && len(protocol.Failures) == 0 {
+		protocol.Failures = []string{err.Error()}
+	}
+	stage.Cases = []testCase{protocol}
+	for _, client := range result.Clients {
+		// Client problems are reported but do not fail the stage
+		for _, problem := range client.Problems {
+			stage.Summary += fmt.Sprintf("; %s: %s", client.Client, problem)
+		}
+	}
+	stage.ClaudeDesktopConfig = result.ClaudeDesktopConfig
+	return stage
+}
+
+// fuzzStage fuzzes every tool, one case per tool
+func fuzzStage(ctx context.Context, dir string, seed int64) testStage {
+	stage := testStage{Name: stageFuzz, Title: "Fuzzing"}
+	result, err := generator.NewService().FuzzServer(ctx, generator.FuzzOptions{
+		Dir:   dir,
+		Cases: testFlags.fuzzCases,
+		Seed:  seed,
+		Env:   testFlags.env,
+		Image: testFlags.image,
+	})
+	if result == nil {
+		return stageError(stage, err)
+	}
+	stage.Duration = result.Duration
+	cases := 0
+	for _, tool := range result.Tools {
+		cases += tool.Cases
+		c := testCase{Name: tool.Name}
+		for _, failure := range result.Failures {
+			if failure.Tool == tool.Name {
+				c.Failures = append(c.Failures, fmt.Sprintf("%s with %s: %s", failure.Kind, failure.Arguments, failure.Message))
+			}
+		}
+		stage.Cases = append(stage.Cases, c)
+	}
+	stage.Summary = fmt.Sprintf("%d payloads, seed %d", cases, result.Seed)
+	if err != nil && stage.failed() == 0 {
+		stage.Cases = append(stage.Cases, testCase{Name: "run", Failures: []string{err.Error()}})
+	}
+	return stage
+}
+
+// scenarioStage runs the server's scenarios, one case per scenario. The
+// stage is skipped without a scenarios directory unless it was requested.
+func scenarioStage(ctx context.Context, dir string, fixtures generator.FixtureOptions, requested bool) testStage {
+	stage := testStage{Name: stageScenarios, Title: "Scenarios"}
+	if _, err := os.Stat(filepath.Join(dir, "scenarios")); err != nil && !requested {
+		stage.Skipped = "no scenarios directory"
+		return stage
+	}
+	result, err := generator.NewService().RunScenarios(ctx, generator.ScenarioOptions{Dir: dir, Fixtures: fixtures, Env: testFlags.env, Image: testFlags.image})
+	if result == nil {
+		return stageError(stage, err)
+	}
+	stage.Duration = result.Duration
+	for _, scenario := range result.Scenarios {
+		c := testCase{Name: scenario.Name, Failures: scenario.Failures, Duration: scenario.Duration}
+		for _, step := range scenario.Steps {
+			for _, failure := range step.Failures {
+				c.Failures = append(c.Failures, fmt.Sprintf("%s %s: %s", step.Phase, step.Tool, failure))
+			}
+		}
+		stage.Cases = append(stage.Cases, c)
+	}
+	stage.Summary = fmt.Sprintf("%d scenarios", len(result.Scenarios))
+	return stage
+}
+
+// loadStage load tests the server against the thresholds
+func loadStage(ctx context.Context, dir string) testStage {
+	stage := testStage{Name: stageLoad, Title: "Load test"}
+	result, err := generator.NewService().LoadTest(ctx, generator.LoadOptions{
+		Dir:             dir,
+		Clients:         testFlags.clients,
+		Duration:        testFlags.duration,
+		RampUp:          testFlags.rampUp,
+		Mix:             testFlags.mix,
+		Env:             testFlags.env,
+		Image:           testFlags.image,
+		UpstreamLatency: testFlags.latency,
+		Thresholds: generator.LoadThresholds{
+			MaxP95:               testFlags.maxP95,
+			MaxP99:               testFlags.maxP99,
+			MinRequestsPerSecond: testFlags.minRPS,
+			MaxErrorRate:         testFlags.maxErrorRate,
+			MaxPeakMemory:        testFlags.maxMemoryMB << 20,
+		},
+	})
+	if result == nil {
+		return stageError(stage, err)
+	}
+	stage.Duration = result.Duration
+	stage.Summary = fmt.Sprintf("%d requests, %.1f req/s, p95 %s, %d errors",
+		result.Requests, result.RequestsPerSecond, result.Latency.P95.Round(time.Microsecond), result.Errors)
+	if len(testFlags.mix) > 0 {
+		for _, tool := range result.Tools {
+			stage.Summary += fmt.Sprintf("; %s %d", tool.Name, tool.Requests)
+		}
+	}
+	thresholds := testCase{Name: "thresholds", Failures: result.Violations, Duration: result.Duration}
+	if err != nil && len(thresholds.Failures) == 0 {
+		thresholds.Failures = append([]string{err.Error()}, result.ClientErrors...)
+	}
+	stage.Cases = []testCase{thresholds}
+
+	if testFlags.baseline != "" {
+		compared := testCase{Name: "baseline"}
+		baseline, err := generator.ReadLoadBaseline(testFlags.baseline)
+		if err != nil {
+			compared.Failures = []string{err.Error()}
+		}
+		if baseline != nil {
+			for _, regression := range generator.CompareLoad(baseline, result, testFlags.tolerances) {
+				compared.Failures = append(compared.Failures, regression.String())
+			}
+			stage.Summary += ", compared with " + testFlags.baseline
+		}
+		stage.Cases = append(stage.Cases, compared)
+	}
+	if testFlags.saveBaseline != "" {
+		if err := generator.WriteLoadBaseline(testFlags.saveBaseline, result); err != nil {
+			stage.Cases = append(stage.Cases, testCase{Name: "save baseline", Failures: []string{err.Error()}})
+		}
+	}
+	return stage
+}
+
+// stageError records a stage that could not run
+func stageError(stage testStage, err error) testStage {
+	stage.Cases = []testCase{{Name: "run", Failures: []string{err.Error()}}}
+	return stage
+}
+
+// jsonTestCase is the JSON form of a test case
+type jsonTestCase struct {
+	Name            string   `json:"name"`
+	Passed          bool     `json:"passed"`
+	Flaky           bool     `json:"flaky"`
+	Failures        []string `json:"failures"`
+	EarlierFailures []string `json:"earlierFailures,omitempty"`
+	DurationMS      int64    `json:"durationMs"`
+	// Intermittent, RecentRuns and RecentFailures come from the test
+	// history
+	Intermittent   bool `json:"intermittent"`
+	RecentRuns     int  `json:"recentRuns,omitempty"`
+	RecentFailures int  `json:"recentFailures,omitempty"`
+}
+
+// jsonTestStage is the JSON form of a stage; status is passed, failed or
+// skipped
+type jsonTestStage struct {
+	Name                string          `json:"name"`
+	Status              string          `json:"status"`
+	Summary             string          `json:"summary,omitempty"`
+	Skipped             string          `json:"skipped,omitempty"`
+	Cases               []jsonTestCase  `json:"cases"`
+	Attempts            int             `json:"attempts"`
+	DurationMS          int64           `json:"durationMs"`
+	ClaudeDesktopConfig json.RawMessage `json:"claudeDesktopConfig,omitempty"`
+	// Coverage is the statement coverage of the unit stage, in percent
+	Coverage *float64 `json:"coverage,omitempty"`
+}
+
+// jsonTestRun is the JSON output of the test command
+type jsonTestRun struct {
+	Server     string          `json:"server"`
+	Passed     bool            `json:"passed"`
+	Stages     []jsonTestStage `json:"stages"`
+	Reports    []string        `json:"reports"`
+	DurationMS int64           `json:"durationMs"`
+}
+
+func newJSONTestRun(dir string, stages []testStage, duration time.Duration, reports []string) jsonTestRun {
+	run := jsonTestRun{Server: dir, Passed: true, Stages: []jsonTestStage{}, Reports: reports, DurationMS: duration.Milliseconds()}
+	for _, stage := range stages {
+		jsonStage := jsonTestStage{
+			Name:       stage.Name,
+			Status:     "passed",
+			Summary:    stage.Summary,
+			Skipped:    stage.Skipped,
+			Cases:      []jsonTestCase{},
+			Attempts:   stage.Attempts,
+			DurationMS: stage.Duration.Milliseconds(),
+			Coverage:   stage.Coverage,
+		}
+		if stage.ClaudeDesktopConfig != "" {
+			jsonStage.ClaudeDesktopConfig = json.RawMessage(stage.ClaudeDesktopConfig)
+		}
+		switch {
+		case stage.Skipped != "":
+			jsonStage.Status = "skipped"
+		case stage.failed() > 0:
+			jsonStage.Status = "failed"
+			run.Passed = false
+		}
+		for _, c := range stage.Cases {
+			failures := c.Failures
+			if failures == nil {
+				failures = []string{}
+			}
+			jsonStage.Cases = append(jsonStage.Cases, jsonTestCase{
+				Name:            c.Name,
+				Passed:          len(c.Failures) == 0,
+				Flaky:           c.flaky(),
+				Failures:        failures,
+				EarlierFailures: c.EarlierFailures,
+				DurationMS:      c.Duration.Milliseconds(),
+				Intermittent:    c.Intermittent,
+				RecentRuns:      c.RecentRuns,
+				RecentFailures:  c.RecentFailures,
+			})
+		}
+		run.Stages = append(run.Stages, jsonStage)
+	}
+	return run
+}
+
+// printTestStage reports a stage like a checklist line
+func printTestStage(out io.Writer, stage testStage) {
+	switch {
+	case stage.Skipped != "":
+		fmt.Fprintf(out, "- %s: skipped, %s\n", stage.Title, stage.Skipped)
+		return
+	case stage.failed() > 0:
+		fmt.Fprintf(out, "✗ %s: %d of %d checks failed", stage.Title, stage.failed(), len(stage.Cases))
+	default:
+		fmt.Fprintf(out, "✓ %s", stage.Title)
+	}
+	if stage.Summary != "" {
+		fmt.Fprintf(out, " (%s)", stage.Summary)
+	}
+	if stage.Attempts > 1 {
+		fmt.Fprintf(out, " after %d attempts", stage.Attempts)
+	}
+	if verbose {
+		fmt.Fprintf(out, " in %s", stage.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintln(out)
+	for _, c := range stage.Cases {
+		if c.flaky() {
+			fmt.Fprintf(out, "  ~ %s is flaky: passed on attempt %d after failing\n", c.Name, stage.Attempts)
+		}
+		if c.Intermittent {
+			fmt.Fprintf(out, "  ~ %s is intermittent: failed in %d of its last %d runs\n", c.Name, c.RecentFailures, c.RecentRuns)
+		}
+	}
+	if stage.ClaudeDesktopConfig != "" {
+		fmt.Fprintln(out, "  Claude Desktop config (claude_desktop_config.json):")
+		for _, line := range strings.Split(stage.ClaudeDesktopConfig, "\n") {
+			fmt.Fprintf(out, "    %s\n", line)
+		}
+	}
+}
+
+// printTestFailures lists the failures of every stage and the totals, and
+// returns the number of failed stages
+func printTestFailures(out io.Writer, stages []testStage) int {
+	failed, skipped, flaky, intermittent := 0, 0, 0, 0
+	for _, stage := range stages {
+		if stage.Skipped != "" {
+			skipped++
+		}
+		for _, c := range stage.Cases {
+			if c.flaky() {
+				flaky++
+			}
+			if c.Intermittent {
+				intermittent++
+			}
+		}
+		if stage.failed() == 0 {
+			continue
+		}
+		if failed == 0 {
+			fmt.Fprintln(out, "\nFailures:")
+		}
+		failed++
+		for _, c := range stage.Cases {
+			for _, failure := range c.Failures {
+				fmt.Fprintf(out, "  %s/%s: %s\n", stage.Name, c.Name, firstLine(failure))
+			}
+		}
+	}
+	fmt.Fprintf(out, "\n%d stages passed, %d failed, %d skipped", len(stages)-failed-skipped, failed, skipped)
+	if flaky > 0 {
+		fmt.Fprintf(out, "; %d flaky checks passed on retry", flaky)
+	}
+	if intermittent > 0 {
+		fmt.Fprintf(out, "; %d checks intermittent across runs", intermittent)
+	}
+	fmt.Fprintln(out)
+	return failed
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/generator"
+)
+
+func TestFailingChecks(t *testing.T) {
+	stages := []testStage{
+		{Name: stageProbe, Cases: []testCase{{Name: "protocol"}}},
+		{Name: stageFuzz, Cases: []testCase{
+			{Name: "list_users", Failures: []string{"crash with {}: panic"}},
+			{Name: "get_user"},
+		}},
+		{Name: stageScenarios, Skipped: "no scenarios directory"},
+	}
+	runs := []jsonTestRun{
+		newJSONTestRun("servers/users", stages, 0, nil),
+		newJSONTestRun("servers/orders", stages[:1], 0, nil),
+	}
+	assert.Equal(t, []string{"servers/users fuzz/list_users"}, failingChecks(runs))
+	assert.Empty(t, failingChecks(nil))
+}
+
+func TestRetryStage(t *testing.T) {
+	// attempts returns a stage whose "calls" case fails in the runs listed
+	attempts := func(failing ...bool) (func() testStage, *int) {
+		runs := 0
+		return func() testStage {
+			c := testCase{Name: "calls"}
+			if failing[runs] {
+				c.Failures = []string{"timeout"}
+			}
+			runs++
+			return testStage{Name: stageProbe, Cases: []testCase{{Name: "handshake"}, c}}
+		}, &runs
+	}
+
+	run, runs := attempts(false)
+	stage := retryStage(context.Background(), run, 2, testHistory{})
+	assert.Equal(t, 1, *runs, "passing stages are not retried")
+	assert.Equal(t, 1, stage.Attempts)
+	assert.False(t, stage.Cases[1].flaky())
+
+	run, runs = attempts(true, true, false)
+	stage = retryStage(context.Background(), run, 2, testHistory{})
+	assert.Equal(t, 3, *runs)
+	assert.Equal(t, 3, stage.Attempts)
+	assert.Zero(t, stage.failed())
+	assert.True(t, stage.Cases[1].flaky())
+	assert.Equal(t, []string{"attempt 1: timeout", "attempt 2: timeout"}, stage.Cases[1].EarlierFailures)
+	assert.False(t, stage.Cases[0].flaky(), "cases that never failed are not flaky")
+
+	run, runs = attempts(true, true, true)
+	stage = retryStage(context.Background(), run, 1, testHistory{})
+	assert.Equal(t, 2, *runs, "retries are bounded")
+	assert.Equal(t, 1, stage.failed())
+	assert.False(t, stage.Cases[1].flaky())
+
+	run, runs = attempts(true, false)
+	stage = retryStage(context.Background(), run, 0, testHistory{})
+	assert.Equal(t, 1, *runs, "no retries by default")
+	assert.Equal(t, 1, stage.failed())
+}
+
+func TestJUnitReportFlaky(t *testing.T) {
+	stages := []testStage{{
+		Name:     stageProbe,
+		Attempts: 2,
+		Cases:    []testCase{{Name: "protocol", EarlierFailures: []string{"attempt 1: ping: no reply"}}},
+	}}
+	report, err := junitReport([]testedServer{{Dir: "server", Stages: stages}}, time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), `<flakyFailure message="attempt 1: ping: no reply">`)
+	assert.Contains(t, string(report), `failures="0"`)
+}
+
+func TestTestServersReports(t *testing.T) {
+	saved, savedJSON := testFlags, jsonOutput
+	defer func() { testFlags, jsonOutput = saved, savedJSON }()
+	jsonOutput = false
+	testFlags.reports = []string{"junit", "html"}
+	testFlags.history = false
+
+	root := t.TempDir()
+	dirs := []string{filepath.Join(root, "users"), filepath.Join(root, "orders"), filepath.Join(root, "billing")}
+	reportDir := t.TempDir()
+	// The orders reports cannot be written: a file is in the way
+	require.NoError(t, os.WriteFile(filepath.Join(reportDir, "orders"), nil, 0644))
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	out := &bytes.Buffer{}
+	runs, err := testServers(cmd, out, dirs, map[string]bool{}, generator.FixtureOptions{}, reportDir)
+	require.Len(t, runs, 3, "the runs are returned with the report errors")
+	assert.ErrorContains(t, err, "2 test reports could not be written")
+	assert.Contains(t, out.String(), "orders html report: ")
+	assert.Contains(t, out.String(), "orders junit report: ")
+
+	assert.FileExists(t, filepath.Join(reportDir, "users", "mcpweaver-test.xml"))
+	assert.FileExists(t, filepath.Join(reportDir, "billing", "mcpweaver-test.html"))
+	assert.Equal(t, []string{filepath.Join(reportDir, "billing", "mcpweaver-test.xml"), filepath.Join(reportDir, "billing", "mcpweaver-test.html")}, runs[2].Reports)
+	assert.Empty(t, runs[1].Reports)
+	assert.Contains(t, out.String(), "Combined report written to "+filepath.Join(reportDir, "mcpweaver-test.xml"))
+	assert.FileExists(t, filepath.Join(reportDir, "mcpweaver-test.html"))
+}
+
+func TestCombinedReports(t *testing.T) {
+	servers := []testedServer{
+		{Dir: "servers/users", Stages: []testStage{{Name: stageProbe, Title: "Protocol probe", Cases: []testCase{{Name: "protocol"}}}}},
+		{Dir: "servers/orders", Stages: []testStage{
+			{Name: stageProbe, Title: "Protocol probe", Cases: []testCase{{Name: "protocol", Failures: []string{"no reply"}}}},
+			{Name: stageScenarios, Title: "Scenarios", Skipped: "no scenarios directory"},
+		}},
+	}
+	report, err := junitReport(servers, time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), `<testsuites name="mcpweaver 2 servers" tests="3" failures="1" skipped="1"`)
+	assert.Contains(t, string(report), `<testsuite name="servers/users/probe"`)
+	assert.Contains(t, string(report), `<testcase name="protocol" classname="servers/orders/probe"`)
+	assert.Contains(t, string(report), `<testsuite name="servers/orders/scenarios"`)
+
+	single, err := junitReport(servers[:1], time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, string(single), `<testsuite name="probe"`, "the stages of a single server are not prefixed")
+
+	page, err := htmlReport(servers, time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<h1>Test report: 2 servers</h1>")
+	assert.Contains(t, string(page), "1 stages passed, 1 failed, 1 skipped")
+	assert.Contains(t, string(page), "<h2>servers/orders</h2>")
+	assert.Contains(t, string(page), `<h3 class="failed">Protocol probe: failed</h3>`)
+
+	page, err = htmlReport(servers[:1], time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<h2 class="passed">Protocol probe: passed</h2>`)
+	assert.NotContains(t, string(page), "<h2>servers/users</h2>")
+}
+
+func TestUnitStage(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+	saved := testFlags
+	defer func() { testFlags = saved }()
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("go.mod", "module example.com/server\n\ngo 1.21\n")
+	write("main.go", "package main\n\nfunc main() { println(double(1)) }\n\nfunc double(n int) int { return 2 * n }\n")
+
+	stage := unitStage(context.Background(), dir, false)
+	assert.Equal(t, "no unit tests", stage.Skipped, "servers without tests skip the stage")
+	stage = unitStage(context.Background(), dir, true)
+	assert.Equal(t, 1, stage.failed(), "an explicitly requested stage fails without tests")
+
+	write("main_test.go", "package main\n\nimport \"testing\"\n\nfunc TestDouble(t *testing.T) {\n\tif double(2) != 4 {\n\t\tt.Fatal(\"wrong\")\n\t}\n}\n")
+	testFlags.minCoverage = 90
+	stage = unitStage(context.Background(), dir, false)
+	require.NotNil(t, stage.Coverage)
+	assert.Equal(t, 50.0, *stage.Coverage, "double is run, main is not")
+	assert.Equal(t, "1 tests, 50.0% of statements covered", stage.Summary)
+	require.Len(t, stage.Cases, 2)
+	assert.Equal(t, "TestDouble", stage.Cases[0].Name)
+	assert.Empty(t, stage.Cases[0].Failures)
+	assert.Equal(t, []string{"50.0% of statements covered, below --min-coverage 90.0%"}, stage.Cases[1].Failures)
+
+	run := newJSONTestRun(dir, []testStage{stage}, time.Second, nil)
+	assert.Equal(t, 50.0, *run.Stages[0].Coverage)
+	report, err := junitReport([]testedServer{{Dir: dir, Stages: []testStage{stage}}}, time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), `<property name="coverage" value="50.0"></property>`)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"MCPWeaver/internal/common"
+)
+
+// testHistoryFile keeps the outcomes of a server's checks across runs of
+// the test command, inside the server's directory
+const testHistoryFile = ".mcpweaver/test-history.json"
+
+// testHistoryRuns is how many runs of each check the history keeps
+const testHistoryRuns = 20
+
+// intermittentRetries is how many times a stage is retried, without
+// --retries, when a check failing in it is intermittent
+const intermittentRetries = 2
+
+// Outcomes of a check in one run
+const (
+	outcomePassed = "passed"
+	outcomeFailed = "failed"
+	outcomeFlaky  = "flaky"
+)
+
+// testHistory holds the outcomes of the checks of a server, oldest first,
+// keyed by "stage/case"
+type testHistory struct {
+	Checks map[string][]string `json:"checks"`
+}
+
+// readTestHistory reads the history of the server in dir; a server never
+// tested has an empty one
+func readTestHistory(dir string) (testHistory, error) {
+	history := testHistory{Checks: map[string][]string{}}
+	path := filepath.Join(dir, testHistoryFile)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return history, common.NewError(common.ErrorTypeTest, "failed to read test history", err).WithFile(path)
+	}
+	if err := json.Unmarshal(content, &history); err != nil {
+		return testHistory{Checks: map[string][]string{}}, common.NewError(common.ErrorTypeValidation, "invalid test history", err).
+			WithFile(path).
+			WithSuggestion("Delete the file to start a new history")
+	}
+	if history.Checks == nil {
+		history.Checks = map[string][]string{}
+	}
+	return history, nil
+}
+
+// writeTestHistory saves the history of the server in dir
+func writeTestHistory(dir string, history testHistory) error {
+	path := filepath.Join(dir, testHistoryFile)
+	content, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return common.NewError(common.ErrorTypeTest, "failed to create test history directory", err).WithFile(filepath.Dir(path))
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return common.NewError(common.ErrorTypeTest, "failed to write test history", err).WithFile(path)
+	}
+	return nil
+}
+
+// intermittent reports whether the check both passed and failed in the
+// recorded runs, or passed only on a retry in one of them
+func (h testHistory) intermittent(check string) bool {
+	passed, failed := false, false
+	for _, outcome := range h.Checks[check] {
+		switch outcome {
+		case outcomeFlaky:
+			return true
+		case outcomePassed:
+			passed = true
+		case outcomeFailed:
+			failed = true
+		}
+	}
+	return passed && failed
+}
+
+// record adds the outcome of each case of the stage to the history,
+// dropping the oldest beyond testHistoryRuns, and fills in the cases'
+// record over the runs kept
+func (h testHistory) record(stage *testStage) {
+	if stage.Skipped != "" {
+		return
+	}
+	for i, c := range stage.Cases {
+		check := stage.Name + "/" + c.Name
+		outcome := outcomePassed
+		switch {
+		case len(c.Failures) > 0:
+			outcome = outcomeFailed
+		case c.flaky():
+			outcome = outcomeFlaky
+		}
+		outcomes := append(h.Checks[check], outcome)
+		if len(outcomes) > testHistoryRuns {
+			outcomes = outcomes[len(outcomes)-testHistoryRuns:]
+		}
+		h.Checks[check] = outcomes
+
+		stage.Cases[i].Intermittent = h.intermittent(check)
+		stage.Cases[i].RecentRuns = len(outcomes)
+		stage.Cases[i].RecentFailures = 0
+		for _, outcome := range outcomes {
+			if outcome != outcomePassed {
+				stage.Cases[i].RecentFailures++
+			}
+		}
+	}
+}
+
+// retriesFor returns how many times the stage is retried after an attempt:
+// retries, or at least intermittentRetries when a failing check is
+// intermittent in the history
+func (h testHistory) retriesFor(stage testStage, retries int) int {
+	if retries >= intermittentRetries {
+		return retries
+	}
+	for _, c := range stage.Cases {
+		if len(c.Failures) > 0 && h.intermittent(stage.Name+"/"+c.Name) {
+			return intermittentRetries
+		}
+	}
+	return retries
+}
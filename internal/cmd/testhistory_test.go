@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyStage is a probe stage with a passing "handshake" case and a
+// "calls" case with the given failures and earlier failures
+func historyStage(failures, earlier []string) testStage {
+	return testStage{Name: stageProbe, Cases: []testCase{
+		{Name: "handshake"},
+		{Name: "calls", Failures: failures, EarlierFailures: earlier},
+	}}
+}
+
+func TestTestHistory(t *testing.T) {
+	dir := t.TempDir()
+	history, err := readTestHistory(dir)
+	require.NoError(t, err)
+	assert.Empty(t, history.Checks, "a server never tested has no history")
+
+	stage := historyStage(nil, nil)
+	history.record(&stage)
+	assert.False(t, stage.Cases[1].Intermittent)
+	assert.Equal(t, 1, stage.Cases[1].RecentRuns)
+
+	stage = historyStage([]string{"timeout"}, nil)
+	history.record(&stage)
+	assert.True(t, stage.Cases[1].Intermittent, "a check that passed and then failed is intermittent")
+	assert.Equal(t, 2, stage.Cases[1].RecentRuns)
+	assert.Equal(t, 1, stage.Cases[1].RecentFailures)
+	assert.False(t, stage.Cases[0].Intermittent)
+
+	skipped := testStage{Name: stageScenarios, Skipped: "no scenarios directory"}
+	history.record(&skipped)
+	assert.NotContains(t, history.Checks, stageScenarios+"/")
+
+	require.NoError(t, writeTestHistory(dir, history))
+	saved, err := readTestHistory(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"probe/handshake": {outcomePassed, outcomePassed},
+		"probe/calls":     {outcomePassed, outcomeFailed},
+	}, saved.Checks)
+
+	// Only the last runs are kept, and a failure that dropped out of them
+	// no longer makes the check intermittent
+	for i := 0; i < testHistoryRuns; i++ {
+		stage = historyStage(nil, nil)
+		saved.record(&stage)
+	}
+	assert.Len(t, saved.Checks["probe/calls"], testHistoryRuns)
+	assert.False(t, stage.Cases[1].Intermittent)
+	assert.Zero(t, stage.Cases[1].RecentFailures)
+
+	// Passing on a retry is enough
+	stage = historyStage(nil, []string{"attempt 1: timeout"})
+	saved.record(&stage)
+	assert.Equal(t, outcomeFlaky, saved.Checks["probe/calls"][testHistoryRuns-1])
+	assert.True(t, stage.Cases[1].Intermittent)
+	assert.Equal(t, 1, stage.Cases[1].RecentFailures)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, testHistoryFile), []byte("{"), 0644))
+	history, err = readTestHistory(dir)
+	assert.ErrorContains(t, err, "invalid test history")
+	assert.NotNil(t, history.Checks, "an invalid history is replaced by a new one")
+}
+
+func TestRetryStageIntermittent(t *testing.T) {
+	runs := 0
+	run := func() testStage {
+		runs++
+		if runs < 3 {
+			return historyStage([]string{"timeout"}, nil)
+		}
+		return historyStage(nil, nil)
+	}
+	history := testHistory{Checks: map[string][]string{"probe/calls": {outcomePassed, outcomeFailed}}}
+	stage := retryStage(context.Background(), run, 0, history)
+	assert.Equal(t, 3, runs, "intermittent checks are retried without --retries")
+	assert.True(t, stage.Cases[1].flaky())
+
+	runs = 0
+	stage = retryStage(context.Background(), run, 0, testHistory{Checks: map[string][]string{"probe/calls": {outcomePassed}}})
+	assert.Equal(t, 1, runs, "checks that never failed before are not")
+	assert.Equal(t, 1, stage.failed())
+
+	assert.Equal(t, 5, history.retriesFor(historyStage([]string{"timeout"}, nil), 5), "--retries above the default is kept")
+}
+
+func TestReportsIntermittent(t *testing.T) {
+	stage := historyStage(nil, nil)
+	stage.Title = "Protocol probe"
+	stage.Cases[1].Intermittent, stage.Cases[1].RecentRuns, stage.Cases[1].RecentFailures = true, 10, 3
+
+	out := &lockedBuffer{}
+	printTestStage(out, stage)
+	assert.Contains(t, out.String(), "~ calls is intermittent: failed in 3 of its last 10 runs")
+	printTestFailures(out, []testStage{stage})
+	assert.Contains(t, out.String(), "1 stages passed, 0 failed, 0 skipped; 1 checks intermittent across runs")
+
+	run := newJSONTestRun("server", []testStage{stage}, time.Second, nil)
+	assert.True(t, run.Stages[0].Cases[1].Intermittent)
+	assert.Equal(t, 3, run.Stages[0].Cases[1].RecentFailures)
+
+	page, err := htmlReport([]testedServer{{Dir: "server", Stages: []testStage{stage}}}, time.Second)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "intermittent: failed in 3 of the last 10 runs")
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// Report file names inside the report directory
+var testReportFiles = map[string]string{
+	"junit": "mcpweaver-test.xml",
+	"html":  "mcpweaver-test.html",
+}
+
+// testedServer is the outcome of testing the server in Dir
+type testedServer struct {
+	Dir      string
+	Stages   []testStage
+	Duration time.Duration
+}
+
+// testReportName names the servers of a report
+func testReportName(servers []testedServer) string {
+	if len(servers) == 1 {
+		return servers[0].Dir
+	}
+	return fmt.Sprintf("%d servers", len(servers))
+}
+
+// writeTestReport writes a JUnit or HTML report of the servers into dir and
+// returns its path. A report of several servers combines them, prefixing
+// their stages with the server directories.
+func writeTestReport(kind, dir string, servers []testedServer, duration time.Duration) (string, error) {
+	var content []byte
+	var err error
+	switch kind {
+	case "junit":
+		content, err = junitReport(servers, duration)
+	case "html":
+		content, err = htmlReport(servers, duration)
+	}
+	if err != nil {
+		return "", common.NewError(common.ErrorTypeGeneration, fmt.Sprintf("failed to render %s report", kind), err)
+	}
+
+	path := filepath.Join(dir, testReportFiles[kind])
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", common.NewError(common.ErrorTypeGeneration, "failed to create report directory", err).WithFile(dir)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", common.NewError(common.ErrorTypeGeneration, "failed to write report", err).WithFile(path)
+	}
+	return path, nil
+}
+
+// JUnit XML elements, as read by common CI systems
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string `xml:"name,attr"`
+	Tests    int    `xml:"tests,attr"`
+	Failures int    `xml:"failures,attr"`
+	Skipped  int    `xml:"skipped,attr"`
+	Time     string `xml:"time,attr"`
+	// Properties carry the coverage of the unit stage
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+	// SystemOut carries the Claude Desktop config of the probe
+	SystemOut *junitOutput `xml:"system-out,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	// FlakyFailures are the failures of a case that passed on a retry, as
+	// Maven Surefire reports them
+	FlakyFailures []junitMessage `xml:"flakyFailure"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitReport renders one test suite per stage, named after the stage or,
+// with several servers, "<server dir>/<stage>". A skipped stage is a suite
+// with a single skipped case.
+func junitReport(servers []testedServer, duration time.Duration) ([]byte, error) {
+	report := junitTestSuites{Name: "mcpweaver " + testReportName(servers), Time: junitTime(duration)}
+	for _, server := range servers {
+		for _, stage := range server.Stages {
+			name := stage.Name
+			if len(servers) > 1 {
+				name = server.Dir + "/" + stage.Name
+			}
+			suite := junitTestSuite{Name: name, Time: junitTime(stage.Duration)}
+			if stage.Coverage != nil {
+				suite.Properties = []junitProperty{{Name: "coverage", Value: fmt.Sprintf("%.1f", *stage.Coverage)}}
+			}
+			if stage.ClaudeDesktopConfig != "" {
+				suite.SystemOut = &junitOutput{Text: stage.ClaudeDesktopConfig}
+			}
+			if stage.Skipped != "" {
+				suite.Cases = []junitTestCase{{
+					Name:      stage.Name,
+					ClassName: name,
+					Time:      junitTime(0),
+					Skipped:   &junitMessage{Message: stage.Skipped},
+				}}
+				suite.Skipped = 1
+			}
+			for _, c := range stage.Cases {
+				junitCase := junitTestCase{Name: c.Name, ClassName: name, Time: junitTime(c.Duration)}
+				if len(c.Failures) > 0 {
+					junitCase.Failure = &junitMessage{Message: firstLine(c.Failures[0]), Text: strings.Join(c.Failures, "\n")}
+					suite.Failures++
+				} else {
+					for _, failure := range c.EarlierFailures {
+						junitCase.FlakyFailures = append(junitCase.FlakyFailures, junitMessage{Message: firstLine(failure), Text: failure})
+					}
+				}
+				suite.Cases = append(suite.Cases, junitCase)
+			}
+			suite.Tests = len(suite.Cases)
+			report.Tests += suite.Tests
+			report.Failures += suite.Failures
+			report.Skipped += suite.Skipped
+			report.Suites = append(report.Suites, suite)
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&b)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return nil, err
+	}
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// htmlReportTemplate renders a self-contained page of the stages
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>MCPWeaver test report: {{.Server}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { text-align: left; padding: 0.3rem 0.8rem; border-bottom: 1px solid #ddd; vertical-align: top; }
+.passed { color: #1a7f37; } .failed { color: #cf222e; } .skipped { color: #6e7781; } .flaky { color: #9a6700; }
+pre { margin: 0; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Test report: {{.Server}}</h1>
+<p>{{.Passed}} stages passed, {{.Failed}} failed, {{.Skipped}} skipped in {{.Duration}}. Generated {{.Generated}}.</p>
+{{range .Servers}}
+{{if $.Combined}}<h2>{{.Dir}}</h2>{{end}}
+{{range .Stages}}
+{{if $.Combined}}<h3 class="{{.Status}}">{{.Title}}: {{.Status}}</h3>{{else}}<h2 class="{{.Status}}">{{.Title}}: {{.Status}}</h2>{{end}}
+{{if .Summary}}<p>{{.Summary}}</p>{{end}}
+{{if .ClaudeDesktopConfig}}<details><summary>Claude Desktop config (claude_desktop_config.json)</summary><pre>{{.ClaudeDesktopConfig}}</pre></details>{{end}}
+{{if .Cases}}<table>
+<tr><th>Check</th><th>Result</th><th>Time</th><th>Failures</th></tr>
+{{range .Cases}}<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}{{if .History}}<br><span class="flaky">{{.History}}</span>{{end}}</td><td>{{.Duration}}</td><td><pre>{{.Failures}}</pre></td></tr>
+{{end}}</table>{{end}}
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+type htmlServer struct {
+	Dir    string
+	Stages []htmlStage
+}
+
+type htmlStage struct {
+	Title               string
+	Status              string
+	Summary             string
+	ClaudeDesktopConfig string
+	Cases               []htmlCase
+}
+
+type htmlCase struct {
+	Name     string
+	Status   string
+	Duration time.Duration
+	Failures string
+	// History notes an intermittent check
+	History string
+}
+
+// htmlReport renders the stages of the servers as a standalone HTML page,
+// with a section per server when there are several
+func htmlReport(servers []testedServer, duration time.Duration) ([]byte, error) {
+	data := struct {
+		Server    string
+		Combined  bool
+		Duration  time.Duration
+		Generated string
+		Passed    int
+		Failed    int
+		Skipped   int
+		Servers   []htmlServer
+	}{
+		Server:    testReportName(servers),
+		Combined:  len(servers) > 1,
+		Duration:  duration.Round(time.Millisecond),
+		Generated: time.Now().Format(time.RFC3339),
+	}
+
+	for _, server := range servers {
+		data.Servers = append(data.Servers, htmlServer{Dir: server.Dir, Stages: htmlStages(server.Stages, &data.Passed, &data.Failed, &data.Skipped)})
+	}
+
+	var b bytes.Buffer
+	if err := htmlReportTemplate.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// htmlStages converts the stages for the HTML report, counting them as
+// passed, failed or skipped
+func htmlStages(stages []testStage, passed, failed, skipped *int) []htmlStage {
+	var pages []htmlStage
+	for _, stage := range stages {
+		page := htmlStage{Title: stage.Title, Status: "passed", Summary: stage.Summary, ClaudeDesktopConfig: stage.ClaudeDesktopConfig}
+		switch {
+		case stage.Skipped != "":
+			page.Status = "skipped"
+			page.Summary = stage.Skipped
+			*skipped++
+		case stage.failed() > 0:
+			page.Status = "failed"
+			*failed++
+		default:
+			*passed++
+		}
+		for _, c := range stage.Cases {
+			status, failures := "passed", c.Failures
+			switch {
+			case len(c.Failures) > 0:
+				status = "failed"
+			case c.flaky():
+				status, failures = "flaky", c.EarlierFailures
+			}
+			page.Cases = append(page.Cases, htmlCase{
+				Name:     c.Name,
+				Status:   status,
+				Duration: c.Duration.Round(time.Millisecond),
+				Failures: strings.Join(failures, "\n"),
+			})
+			if c.Intermittent {
+				page.Cases[len(page.Cases)-1].History = fmt.Sprintf("intermittent: failed in %d of the last %d runs", c.RecentFailures, c.RecentRuns)
+			}
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+)
+
+// testResultsDir keeps the result of every run of the test command, a
+// timestamped JSON file per run, inside the server's directory
+const testResultsDir = ".mcpweaver/test-results"
+
+// testResultTime names the result files; in UTC they sort chronologically
+const testResultTime = "20060102T150405.000Z"
+
+// jsonTestResult is a saved run of the test command
+type jsonTestResult struct {
+	Time time.Time `json:"time"`
+	jsonTestRun
+	// File is the path of the saved result, not part of it
+	File string `json:"file,omitempty"`
+}
+
+// saveTestResult saves the run of the server in dir, started at started,
+// so that it outlives the report files
+func saveTestResult(dir string, started time.Time, run jsonTestRun) error {
+	resultsDir := filepath.Join(dir, testResultsDir)
+	if err := os.MkdirAll(resultsDir, 0755); err != nil {
+		return common.NewError(common.ErrorTypeTest, "failed to create test results directory", err).WithFile(resultsDir)
+	}
+	content, err := json.MarshalIndent(jsonTestResult{Time: started, jsonTestRun: run}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(resultsDir, started.UTC().Format(testResultTime)+".json")
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return common.NewError(common.ErrorTypeTest, "failed to save test result", err).WithFile(path)
+	}
+	return nil
+}
+
+// readTestResults reads the saved runs of the server in dir, newest first.
+// Files that are not results are skipped and returned as warnings.
+func readTestResults(dir string) ([]jsonTestResult, []string, error) {
+	resultsDir := filepath.Join(dir, testResultsDir)
+	entries, err := os.ReadDir(resultsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, common.NewError(common.ErrorTypeTest, "failed to read test results", err).WithFile(resultsDir)
+	}
+	var results []jsonTestResult
+	var warnings []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(resultsDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		var result jsonTestResult
+		if err := json.Unmarshal(content, &result); err != nil || result.Time.IsZero() {
+			warnings = append(warnings, fmt.Sprintf("%s: not a test result", path))
+			continue
+		}
+		result.File = path
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Time.After(results[j].Time) })
+	return results, warnings, nil
+}
+
+// testResultFilter selects saved runs for test history
+type testResultFilter struct {
+	Since  time.Time
+	Stage  string
+	Failed bool
+	Limit  int
+}
+
+// apply returns the results the filter selects, newest first
+func (f testResultFilter) apply(results []jsonTestResult) []jsonTestResult {
+	selected := []jsonTestResult{}
+	for _, result := range results {
+		if !f.Since.IsZero() && result.Time.Before(f.Since) {
+			continue
+		}
+		if f.Failed && result.Passed {
+			continue
+		}
+		if f.Stage != "" {
+			stage := result.stage(f.Stage)
+			if stage == nil || (f.Failed && stage.Status != "failed") {
+				continue
+			}
+		}
+		if f.Limit > 0 && len(selected) == f.Limit {
+			break
+		}
+		selected = append(selected, result)
+	}
+	return selected
+}
+
+// stage returns the named stage of the run, or nil when it did not run
+func (r jsonTestResult) stage(name string) *jsonTestStage {
+	for i := range r.Stages {
+		if r.Stages[i].Name == name {
+			return &r.Stages[i]
+		}
+	}
+	return nil
+}
+
+// testHistoryFlags holds the flags of the test history command
+var testHistoryFlags struct {
+	since  time.Duration
+	stage  string
+	failed bool
+	limit  int
+}
+
+var testHistoryCmd = &cobra.Command{
+	Use:   "history <server-dir>...",
+	Short: "List the saved results of earlier test runs",
+	Long: `History lists the runs of the test command saved in each server's
+.mcpweaver/test-results directory, newest first, with the status of every
+stage and the failed checks. Every run of test saves its result there,
+with the JSON output's fields, unless --history=false, so results remain
+after the report files are deleted.
+
+--since, --stage and --failed select runs: those of the last period, those
+that ran a stage, and those that failed, or failed in the --stage.`,
+	Example: `  mcpweaver test history ./server
+  mcpweaver test history ./server --since 168h --failed
+  mcpweaver test history ./servers/* --stage load --limit 5 --json`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeDirs,
+	RunE:              runTestHistory,
+}
+
+func init() {
+	flags := testHistoryCmd.Flags()
+	flags.DurationVar(&testHistoryFlags.since, "since", 0, "only runs started within this period, e.g. 168h")
+	flags.StringVar(&testHistoryFlags.stage, "stage", "", "only runs of this stage: unit, probe, fuzz, scenarios or load")
+	flags.BoolVar(&testHistoryFlags.failed, "failed", false, "only failed runs, or runs where --stage failed")
+	flags.IntVar(&testHistoryFlags.limit, "limit", 20, "list at most this many runs per server; 0 lists all")
+	registerCompletions(testHistoryCmd, map[string]cobra.CompletionFunc{
+		"stage": completeValues(testStageOrder...),
+	})
+	testCmd.AddCommand(testHistoryCmd)
+}
+
+func runTestHistory(cmd *cobra.Command, args []string) error {
+	filter := testResultFilter{Stage: testHistoryFlags.stage, Failed: testHistoryFlags.failed, Limit: testHistoryFlags.limit}
+	if filter.Stage != "" && !slices.Contains(testStageOrder, filter.Stage) {
+		return fmt.Errorf("unknown stage %q; use %s", filter.Stage, strings.Join(testStageOrder, ", "))
+	}
+	if testHistoryFlags.since < 0 || filter.Limit < 0 {
+		return fmt.Errorf("--since and --limit cannot be negative")
+	}
+	if testHistoryFlags.since > 0 {
+		filter.Since = time.Now().Add(-testHistoryFlags.since)
+	}
+
+	out := cmd.OutOrStdout()
+	all := []jsonTestResult{}
+	for i, dir := range args {
+		results, warnings, err := readTestResults(dir)
+		if err != nil {
+			return err
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+		}
+		selected := filter.apply(results)
+		all = append(all, selected...)
+		if jsonOutput {
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		printTestResults(out, dir, selected, len(results))
+	}
+	if jsonOutput {
+		return writeJSON(out, all)
+	}
+	return nil
+}
+
+// printTestResults lists the selected runs of the server in dir, out of
+// total saved runs
+func printTestResults(out io.Writer, dir string, results []jsonTestResult, total int) {
+	if total == 0 {
+		fmt.Fprintf(out, "%s: no saved test results\n", dir)
+		return
+	}
+	fmt.Fprintf(out, "%s: %d of %d saved runs\n", dir, len(results), total)
+	for _, result := range results {
+		status := "✓ passed"
+		if !result.Passed {
+			status = "✗ failed"
+		}
+		var stages []string
+		for _, stage := range result.Stages {
+			summary := stage.Name + " " + stage.Status
+			var failed []string
+			for _, c := range stage.Cases {
+				if !c.Passed {
+					failed = append(failed, c.Name)
+				}
+			}
+			if len(failed) > 0 {
+				summary += " (" + strings.Join(failed, ", ") + ")"
+			}
+			stages = append(stages, summary)
+		}
+		duration := (time.Duration(result.DurationMS) * time.Millisecond).Round(100 * time.Millisecond)
+		fmt.Fprintf(out, "  %s  %s  %s  %s\n", result.Time.Local().Format("2006-01-02 15:04:05"), status, duration, strings.Join(stages, ", "))
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/generator"
+)
+
+// resultRun is a run of the probe and load stages, failing the given
+// stage's "calls" check unless it is empty
+func resultRun(dir, failing string) jsonTestRun {
+	var stages []testStage
+	for _, name := range []string{stageProbe, stageLoad} {
+		stage := testStage{Name: name, Cases: []testCase{{Name: "handshake"}, {Name: "calls"}}}
+		if name == failing {
+			stage.Cases[1].Failures = []string{"timeout"}
+		}
+		stages = append(stages, stage)
+	}
+	return newJSONTestRun(dir, stages, 1500*time.Millisecond, []string{})
+}
+
+func TestTestResults(t *testing.T) {
+	dir := t.TempDir()
+	results, warnings, err := readTestResults(dir)
+	require.NoError(t, err)
+	assert.Empty(t, results, "a server never tested has no results")
+	assert.Empty(t, warnings)
+
+	now := time.Now().Truncate(time.Millisecond)
+	require.NoError(t, saveTestResult(dir, now.Add(-48*time.Hour), resultRun(dir, "")))
+	require.NoError(t, saveTestResult(dir, now.Add(-time.Hour), resultRun(dir, stageLoad)))
+	require.NoError(t, saveTestResult(dir, now, resultRun(dir, stageProbe)))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, testResultsDir, "notes.json"), []byte(`{"note": 1}`), 0644))
+
+	results, warnings, err = readTestResults(dir)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, []string{filepath.Join(dir, testResultsDir, "notes.json") + ": not a test result"}, warnings)
+	assert.True(t, results[0].Time.Equal(now), "the newest run is first")
+	assert.True(t, results[2].Passed)
+	assert.Equal(t, filepath.Join(dir, testResultsDir, now.UTC().Format(testResultTime)+".json"), results[0].File)
+	assert.Equal(t, "timeout", results[1].Stages[1].Cases[1].Failures[0], "the whole run is saved")
+
+	times := func(results []jsonTestResult) []time.Time {
+		var times []time.Time
+		for _, result := range results {
+			times = append(times, result.Time.Local())
+		}
+		return times
+	}
+	tests := []struct {
+		name   string
+		filter testResultFilter
+		want   []time.Time
+	}{
+		{"all", testResultFilter{}, []time.Time{now, now.Add(-time.Hour), now.Add(-48 * time.Hour)}},
+		{"since", testResultFilter{Since: now.Add(-24 * time.Hour)}, []time.Time{now, now.Add(-time.Hour)}},
+		{"failed", testResultFilter{Failed: true}, []time.Time{now, now.Add(-time.Hour)}},
+		{"failed stage", testResultFilter{Stage: stageLoad, Failed: true}, []time.Time{now.Add(-time.Hour)}},
+		{"stage not run", testResultFilter{Stage: stageFuzz}, nil},
+		{"limit", testResultFilter{Limit: 1}, []time.Time{now}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, times(tt.filter.apply(results)))
+		})
+	}
+}
+
+func TestTestServersSaveResults(t *testing.T) {
+	saved, savedJSON := testFlags, jsonOutput
+	defer func() { testFlags, jsonOutput = saved, savedJSON }()
+	jsonOutput = false
+	testFlags.history = true
+	testFlags.reports = nil
+
+	root := t.TempDir()
+	dirs := []string{filepath.Join(root, "users"), filepath.Join(root, "orders")}
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	_, err := testServers(cmd, &bytes.Buffer{}, dirs, map[string]bool{}, generator.FixtureOptions{}, t.TempDir())
+	require.NoError(t, err)
+	for _, dir := range dirs {
+		results, _, err := readTestResults(dir)
+		require.NoError(t, err)
+		require.Len(t, results, 1, "every run of a server is saved")
+		assert.Equal(t, dir, results[0].Server)
+	}
+
+	testFlags.history = false
+	_, err = testServers(cmd, &bytes.Buffer{}, dirs[:1], map[string]bool{}, generator.FixtureOptions{}, t.TempDir())
+	require.NoError(t, err)
+	results, _, err := readTestResults(dirs[0])
+	require.NoError(t, err)
+	assert.Len(t, results, 1, "--history=false saves nothing")
+}
+
+func TestRunTestHistory(t *testing.T) {
+	savedFlags, savedJSON := testHistoryFlags, jsonOutput
+	defer func() { testHistoryFlags, jsonOutput = savedFlags, savedJSON }()
+	testHistoryFlags.limit = 20
+
+	dir, untested := t.TempDir(), t.TempDir()
+	started := time.Date(2026, 10, 16, 14, 5, 1, 0, time.Local)
+	require.NoError(t, saveTestResult(dir, started, resultRun(dir, stageLoad)))
+	require.NoError(t, saveTestResult(dir, started.Add(-time.Hour), resultRun(dir, "")))
+
+	run := func() string {
+		cmd := &cobra.Command{}
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		require.NoError(t, runTestHistory(cmd, []string{dir, untested}))
+		return out.String()
+	}
+	jsonOutput = false
+	assert.Equal(t, dir+": 2 of 2 saved runs\n"+
+		"  2026-10-16 14:05:01  ✗ failed  1.5s  probe passed, load failed (calls)\n"+
+		"  2026-10-16 13:05:01  ✓ passed  1.5s  probe passed, load passed\n"+
+		"\n"+untested+": no saved test results\n", run())
+
+	testHistoryFlags.failed = true
+	assert.Contains(t, run(), dir+": 1 of 2 saved runs\n")
+
+	jsonOutput = true
+	var results []jsonTestResult
+	require.NoError(t, json.Unmarshal([]byte(run()), &results))
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Equal(t, dir, results[0].Server)
+
+	testHistoryFlags.stage = "smoke"
+	assert.ErrorContains(t, runTestHistory(&cobra.Command{}, []string{dir}), `unknown stage "smoke"`)
+}
@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/generator"
+	"MCPWeaver/internal/parser"
+	"MCPWeaver/internal/transformer"
+)
+
+// Rules of the issues reported by validate
+const (
+	ruleParse   = "openapi-parse"
+	ruleSchema  = "openapi-schema"
+	ruleMapping = "mcp-mapping"
+	ruleDocs    = "mcp-documentation"
+)
+
+// Validation profiles: strict also requires what MCP clients describe
+// tools with
+const (
+	validationStandard = "standard"
+	validationStrict   = "strict"
+)
+
+// ruleDescriptions describe the rules in SARIF output
+var ruleDescriptions = map[string]string{
+	ruleParse:   "The specification cannot be parsed",
+	ruleSchema:  "The specification does not conform to the OpenAPI schema",
+	ruleMapping: "An operation cannot be mapped to an MCP tool as written",
+	ruleDocs:    "An operation or parameter lacks the name or description clients show for its tool",
+}
+
+// validateFlags holds the flags of the validate command
+var validateFlags struct {
+	format   string
+	failOn   string
+	profile  string
+	workers  int
+	watch    bool
+	preview  bool
+	debounce time.Duration
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <openapi-spec>...",
+	Short: "Validate OpenAPI specifications without generating",
+	Long: `Validate parses each specification, checks it against the OpenAPI schema and
+maps it to MCP tools, reporting operations that would be skipped or
+simplified as warnings.
+
+Arguments may be glob patterns such as "apis/*.yaml". Specifications are
+validated --workers at a time, with a progress line on stderr as each
+finishes. The command exits with 2 when any specification has an issue at
+or above --fail-on. The strict --validation-profile also requires every
+operation to have an operationId and a summary or description, and every
+parameter a description, since clients know tools only by them.
+--include-tags and --exclude-tags select the operations mapped, as for
+generate.
+
+With --watch, the specifications are validated again whenever they change,
+until interrupted, and --preview lists the tools each one maps to. In JSON
+mode, every validation is logged as one line of JSON: a spec_validated
+event for each specification at the start and a spec_changed event for
+each change.`,
+	Example: `  mcpweaver validate api.yaml
+  mcpweaver validate "apis/*.yaml" --fail-on warning
+  mcpweaver validate api.yaml --validation-profile strict
+  mcpweaver validate api.yaml --format sarif > mcpweaver.sarif
+  mcpweaver validate api.yaml --watch --preview`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeSpecs,
+	RunE:              runValidate,
+}
+
+func init() {
+	flags := validateCmd.Flags()
+	flags.StringVar(&validateFlags.format, "format", "text", "output format: text, json or sarif")
+	flags.StringVar(&validateFlags.failOn, "fail-on", generator.SeverityError, "lowest severity that fails validation: error or warning")
+	flags.StringVar(&validateFlags.profile, "validation-profile", validationStandard, "rules to apply: standard, or strict to also require operation IDs and descriptions")
+	flags.IntVar(&validateFlags.workers, "workers", 0, workersUsage)
+	flags.BoolVarP(&validateFlags.watch, "watch", "w", false, "validate again whenever a specification changes")
+	flags.BoolVar(&validateFlags.preview, "preview", false, "with --watch, list the tools each specification maps to")
+	flags.DurationVar(&validateFlags.debounce, "debounce", defaultDebounce, "with --watch, how long files must stay unchanged before validating")
+	addFilterFlags(validateCmd)
+	registerCompletions(validateCmd, map[string]cobra.CompletionFunc{
+		"format":             completeValues("text", "json", "sarif"),
+		"fail-on":            completeValues(generator.SeverityError, generator.SeverityWarning),
+		"validation-profile": completeValues(validationStandard, validationStrict),
+	})
+	rootCmd.AddCommand(validateCmd)
+}
+
+// specIssue is a problem found in a specification
+type specIssue struct {
+	Severity   string `json:"severity"`
+	Rule       string `json:"rule"`
+	Message    string `json:"message"`
+	Line       int    `json:"line,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// specReport is the validation result of one specification
+type specReport struct {
+	File       string      `json:"file"`
+	Valid      bool        `json:"valid"`
+	Operations int         `json:"operations"`
+	Tools      int         `json:"tools"`
+	Issues     []specIssue `json:"issues"`
+	// failure is the first issue that made the specification invalid
+	failure string
+	// server is the mapping, for previews
+	server *transformer.MCPServer
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	format := validateFlags.format
+	switch format {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("unknown format %q; use text, json or sarif", format)
+	}
+	if jsonOutput {
+		if format != "text" && format != "json" {
+			return fmt.Errorf("--json cannot be combined with --format %s", format)
+		}
+		format = "json"
+	}
+	if validateFlags.failOn != generator.SeverityError && validateFlags.failOn != generator.SeverityWarning {
+		return fmt.Errorf("unknown severity %q for --fail-on; use error or warning", validateFlags.failOn)
+	}
+	if validateFlags.profile != validationStandard && validateFlags.profile != validationStrict {
+		return fmt.Errorf("unknown validation profile %q; use standard or strict", validateFlags.profile)
+	}
+
+	files, err := expandSpecArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if validateFlags.watch {
+		switch {
+		case ciMode:
+			return fmt.Errorf("--watch runs until interrupted and cannot be used with --ci")
+		case format == "sarif":
+			return fmt.Errorf("--watch cannot be combined with --format sarif")
+		}
+		return watchValidate(cmd, files)
+	}
+
+	// Several specifications are validated concurrently, with progress on
+	// stderr so that it stays out of reports redirected from stdout
+	progress := &batchProgress{out: io.Discard, total: len(files)}
+	if len(files) > 1 {
+		progress.out = progressOutput(cmd.ErrOrStderr())
+	}
+	reports := validateFiles(cmd.Context(), files, progress)
+	failed := 0
+	for _, report := range reports {
+		if !report.Valid {
+			failed++
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		err = writeJSON(out, reports)
+	case "sarif":
+		err = writeSpecSARIF(out, reports)
+	default:
+		printSpecReports(out, reports)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return common.NewError(common.ErrorTypeValidation,
+			fmt.Sprintf("%d of %d specifications failed validation", failed, len(reports)), nil)
+	}
+	return nil
+}
+
+// validateFiles validates the specifications --workers at a time and
+// reports each to progress as it finishes
+func validateFiles(ctx context.Context, files []string, progress *batchProgress) []specReport {
+	reports := make([]specReport, len(files))
+	runWorkers(validateFlags.workers, len(files), func(i int) {
+		start := time.Now()
+		report := validateSpec(ctx, files[i])
+		for _, issue := range report.Issues {
+			if (issue.Severity == generator.SeverityError || validateFlags.failOn == generator.SeverityWarning) && report.Valid {
+				report.Valid = false
+				report.failure = issue.Message
+			}
+		}
+		reports[i] = report
+		var err error
+		if !report.Valid {
+			err = errors.New(report.failure)
+		}
+		progress.report(report.File, "", err, time.Since(start))
+	})
+	return reports
+}
+
+// expandSpecArgs resolves glob patterns to files in argument order. An
+// argument matching nothing must name an existing file.
+func expandSpecArgs(args []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			if _, err := os.Stat(arg); err != nil {
+				return nil, common.NewError(common.ErrorTypeParse, "failed to read specification", err).WithFile(arg)
+			}
+			matches = []string{arg}
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// validateSpec parses, validates and maps one specification. Every stage
+// that fails is reported as an error; mapping warnings are warnings.
+func validateSpec(ctx context.Context, file string) specReport {
+	report := specReport{File: file, Valid: true, Issues: []specIssue{}}
+	spec, err := parser.NewService().ParseFile(file)
+	if err != nil {
+		report.Issues = append(report.Issues, issueFromError(ruleParse, err))
+		return report
+	}
+	report.Operations = len(spec.Operations())
+	if err := parser.NewService().Validate(ctx, spec); err != nil {
+		report.Issues = append(report.Issues, issueFromError(ruleSchema, err))
+	}
+	if validateFlags.profile == validationStrict {
+		report.Issues = append(report.Issues, documentationIssues(spec)...)
+	}
+
+	server, err := newTransformer().Transform(spec)
+	if err != nil {
+		report.Issues = append(report.Issues, issueFromError(ruleMapping, err))
+		return report
+	}
+	report.Tools = len(server.Tools)
+	report.server = server
+	for _, warning := range server.Warnings {
+		report.Issues = append(report.Issues, specIssue{Severity: generator.SeverityWarning, Rule: ruleMapping, Message: warning})
+	}
+	return report
+}
+
+// documentationIssues reports the operations of the strict profile that
+// map to a tool named after its path or without a description, and their
+// parameters without a description
+func documentationIssues(spec *parser.ParsedSpec) []specIssue {
+	var issues []specIssue
+	report := func(op parser.Operation, message string) {
+		issues = append(issues, specIssue{
+			Severity: generator.SeverityError,
+			Rule:     ruleDocs,
+			Message:  fmt.Sprintf("%s %s %s", op.Method, op.Path, message),
+		})
+	}
+	for _, op := range spec.Operations() {
+		if op.Operation.Deprecated || !mappingFilter.Selects(op.Operation.Tags) {
+			continue
+		}
+		if op.Operation.OperationID == "" {
+			report(op, "has no operationId")
+		}
+		if strings.TrimSpace(op.Operation.Summary) == "" && strings.TrimSpace(op.Operation.Description) == "" {
+			report(op, "has no summary or description")
+		}
+		seen := map[string]bool{}
+		for _, list := range []openapi3.Parameters{op.Operation.Parameters, op.PathItem.Parameters} {
+			for _, ref := range list {
+				if ref == nil || ref.Value == nil || seen[ref.Value.In+":"+ref.Value.Name] {
+					continue
+				}
+				seen[ref.Value.In+":"+ref.Value.Name] = true
+				if strings.TrimSpace(ref.Value.Description) == "" {
+					report(op, fmt.Sprintf("%s parameter %q has no description", ref.Value.In, ref.Value.Name))
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// issueFromError reports a pipeline error as an error issue
+func issueFromError(rule string, err error) specIssue {
+	issue := specIssue{Severity: generator.SeverityError, Rule: rule, Message: err.Error()}
+	var pipelineErr *common.Error
+	if errors.As(err, &pipelineErr) {
+		issue.Message = pipelineErr.Message
+		if pipelineErr.Err != nil {
+			issue.Message += ": " + pipelineErr.Err.Error()
+		}
+		issue.Line = pipelineErr.Line
+		issue.Suggestion = pipelineErr.Suggestion
+	}
+	return issue
+}
+
+// printSpecReports writes the reports for the terminal
+func printSpecReports(out io.Writer, reports []specReport) {
+	valid := 0
+	for _, report := range reports {
+		if report.Valid {
+			valid++
+			fmt.Fprintf(out, "✓ %s: %d operations, %d tools\n", report.File, report.Operations, report.Tools)
+		} else {
+			fmt.Fprintf(out, "✗ %s\n", report.File)
+		}
+		for _, issue := range report.Issues {
+			location := ""
+			if issue.Line > 0 {
+				location = fmt.Sprintf(" (line %d)", issue.Line)
+			}
+			fmt.Fprintf(out, "  %s: %s%s\n", issue.Severity, issue.Message, location)
+			if issue.Suggestion != "" && verbose {
+				fmt.Fprintf(out, "    Suggestion: %s\n", issue.Suggestion)
+			}
+		}
+	}
+	if len(reports) > 1 {
+		var failures []batchFailure
+		for _, report := range reports {
+			if !report.Valid {
+				failures = append(failures, batchFailure{name: report.File, reason: firstLine(report.failure)})
+			}
+		}
+		printFailures(out, failures)
+		fmt.Fprintf(out, "\n%d specifications checked: %d valid, %d invalid\n", len(reports), valid, len(reports)-valid)
+	}
+}
+
+// writeSpecSARIF writes the issues of every report as one SARIF log, with
+// paths relative to the working directory
+func writeSpecSARIF(out io.Writer, reports []specReport) error {
+	var results []generator.SARIFResult
+	for _, report := range reports {
+		file := report.File
+		if filepath.IsAbs(file) {
+			if wd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(wd, file); err == nil {
+					file = rel
+				}
+			}
+		}
+		for _, issue := range report.Issues {
+			results = append(results, generator.SARIFResult{
+				RuleID:          issue.Rule,
+				RuleName:        issue.Rule,
+				RuleDescription: ruleDescriptions[issue.Rule],
+				Level:           issue.Severity,
+				Message:         issue.Message,
+				File:            filepath.ToSlash(file),
+				Line:            issue.Line,
+			})
+		}
+	}
+	return generator.WriteSARIFResults(out, version, results)
+}
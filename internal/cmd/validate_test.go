@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/transformer"
+)
+
+const undocumentedSpec = `openapi: 3.0.3
+info: {title: Items, version: '1'}
+paths:
+  /items/{id}:
+    parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+    get:
+      tags: [items]
+      parameters: [{name: fields, in: query, description: Fields to return, schema: {type: string}}]
+      responses: {'200': {description: ok}}
+  /admin:
+    get: {operationId: getAdmin, summary: Admin settings, tags: [admin], responses: {'200': {description: ok}}}
+`
+
+func TestValidateSpecProfiles(t *testing.T) {
+	saved, savedFilter := validateFlags, mappingFilter
+	defer func() { validateFlags, mappingFilter = saved, savedFilter }()
+	spec := filepath.Join(t.TempDir(), "items.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte(undocumentedSpec), 0644))
+
+	validateFlags.profile = validationStandard
+	report := validateSpec(context.Background(), spec)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, 2, report.Tools)
+
+	validateFlags.profile = validationStrict
+	report = validateSpec(context.Background(), spec)
+	var messages []string
+	for _, issue := range report.Issues {
+		assert.Equal(t, ruleDocs, issue.Rule)
+		messages = append(messages, issue.Message)
+	}
+	assert.Equal(t, []string{
+		"GET /items/{id} has no operationId",
+		"GET /items/{id} has no summary or description",
+		`GET /items/{id} path parameter "id" has no description`,
+	}, messages)
+
+	// Operations the filter skips are neither mapped nor checked
+	mappingFilter = transformer.Filter{ExcludeTags: []string{"items"}}
+	report = validateSpec(context.Background(), spec)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, 1, report.Tools)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"MCPWeaver/internal/generator"
+)
+
+// varUsage describes the --var flag of the commands rendering templates
+const varUsage = "name=value for a variable the template package declares; repeatable"
+
+// langUsage describes the --lang flag of the commands rendering templates
+const langUsage = "language of the generated documentation, e.g. de; missing messages fall back to " + generator.DefaultLanguage
+
+// parseVariables reads --var values into the template variables of
+// generator.Options
+func parseVariables(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(values))
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q; use name=value", value)
+		}
+		if _, dup := vars[name]; dup {
+			return nil, fmt.Errorf("--var %s is given more than once", name)
+		}
+		vars[name] = v
+	}
+	return vars, nil
+}
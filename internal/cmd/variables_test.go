@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/generator"
+)
+
+func TestParseVariables(t *testing.T) {
+	vars, err := parseVariables([]string{"team=payments", "replicas=3", "greeting=a=b", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments", "replicas": "3", "greeting": "a=b", "empty": ""}, vars)
+
+	vars, err = parseVariables(nil)
+	require.NoError(t, err)
+	assert.Nil(t, vars)
+
+	for _, invalid := range [][]string{{"team"}, {"=value"}, {"team=a", "team=b"}} {
+		_, err := parseVariables(invalid)
+		assert.Error(t, err, "%q", invalid)
+	}
+}
+
+func TestChooseVariables(t *testing.T) {
+	min := 1
+	declared := []generator.TemplateVariable{
+		{Name: "team", Type: generator.VariableString, Required: true, Pattern: "^[a-z]+$"},
+		{Name: "replicas", Type: generator.VariableInteger, Default: "2", Min: &min},
+		{Name: "region", Type: generator.VariableEnum, Options: []string{"eu", "us"}, Default: "us"},
+		{Name: "debug", Type: generator.VariableBoolean},
+		{Name: "token", Type: generator.VariableSecret, Env: "MCPWEAVER_TEST_TOKEN"},
+		{Name: "notes", Type: generator.VariableString},
+	}
+	t.Setenv("MCPWEAVER_TEST_TOKEN", "from-env")
+	input := strings.Join([]string{
+		"",         // team is required
+		"Payments", // does not match the pattern
+		"payments",
+		"0", // below the minimum
+		"",  // the default
+		"eu",
+		"y",
+		"", // the environment variable
+		"", // optional
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	vars, err := chooseVariables(newPrompter(strings.NewReader(input), &out), &out, declared)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"team":     "payments",
+		"replicas": "2",
+		"region":   "eu",
+		"debug":    "true",
+	}, vars)
+	assert.Contains(t, out.String(), "team is required")
+	assert.Contains(t, out.String(), "does not match")
+	assert.Contains(t, out.String(), "0 is less than 1")
+	assert.Contains(t, out.String(), "MCPWEAVER_TEST_TOKEN is set")
+
+	none, err := chooseVariables(newPrompter(strings.NewReader(""), &out), &out, nil)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+}
+
+func TestVariableFlags(t *testing.T) {
+	declared := []generator.TemplateVariable{
+		{Name: "team", Type: generator.VariableString},
+		{Name: "greeting", Type: generator.VariableString},
+		{Name: "token", Type: generator.VariableSecret},
+	}
+	flags := variableFlags(declared, map[string]string{"team": "payments", "greeting": "it's here", "token": "s3cret"})
+	assert.Equal(t, ` --var 'greeting=it'\''s here' --var team=payments --var 'token=<secret>'`, flags)
+}
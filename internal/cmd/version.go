@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version information",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		if jsonOutput {
+			return writeJSON(out, map[string]string{
+				"version":   version,
+				"buildTime": buildTime,
+				"commit":    commit,
+				"goVersion": runtime.Version(),
+				"platform":  runtime.GOOS + "/" + runtime.GOARCH,
+			})
+		}
+		fmt.Fprintf(out, "mcpweaver %s\n", version)
+		fmt.Fprintf(out, "  Build time: %s\n", buildTime)
+		fmt.Fprintf(out, "  Commit:     %s\n", commit)
+		fmt.Fprintf(out, "  Go version: %s\n", runtime.Version())
+		fmt.Fprintf(out, "  Platform:   %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
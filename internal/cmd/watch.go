@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"MCPWeaver/internal/generator"
+)
+
+// defaultDebounce is how long the watched files must stay unchanged before
+// a regeneration, so that an editor saving several files or writing a file
+// in steps causes one regeneration
+const defaultDebounce = 300 * time.Millisecond
+
+// watchInterval is how often watch mode polls the watched files
+var watchInterval = 500 * time.Millisecond
+
+// watchStamp identifies a version of a watched file cheaply
+type watchStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchGenerate generates the server once with the full checklist, then
+// regenerates it whenever the specification or the template directory
+// changes, until interrupted. Failed regenerations are reported and the
+// watch goes on.
+func watchGenerate(cmd *cobra.Command, specPath string, opts generator.Options) error {
+	if opts.DryRun {
+		return fmt.Errorf("--watch cannot be combined with --dry-run")
+	}
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+	paths := []string{specPath}
+	if opts.TemplateDir != "" {
+		paths = append(paths, opts.TemplateDir)
+	}
+
+	previous := snapshotWatched(paths)
+	if jsonOutput {
+		regenerate(ctx, out, errOut, specPath, opts, nil)
+	} else {
+		if err := generateSpec(cmd, specPath, opts, nil); err != nil {
+			fmt.Fprint(errOut, FormatError(err))
+		}
+		fmt.Fprintf(out, "\nWatching %s for changes. Press Ctrl+C to stop.\n", strings.Join(paths, ", "))
+	}
+
+	watchFiles(ctx, paths, previous, generateFlags.debounce, func(changed []string) {
+		regenerate(ctx, out, errOut, specPath, opts, changed)
+	})
+	if !jsonOutput {
+		fmt.Fprintln(out, "\nStopped watching.")
+	}
+	return nil
+}
+
+// Events logged by validate --watch in JSON mode
+const (
+	eventSpecValidated = "spec_validated"
+	eventSpecChanged   = "spec_changed"
+)
+
+// toolPreview is the JSON form of a tool in a mapping preview
+type toolPreview struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// specEvent is a validation logged by validate --watch in JSON mode
+type specEvent struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	specReport
+	Preview []toolPreview `json:"preview,omitempty"`
+}
+
+// watchValidate validates the specifications once, then validates each
+// again whenever it changes, until interrupted
+func watchValidate(cmd *cobra.Command, files []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out := cmd.OutOrStdout()
+	previous := snapshotWatched(files)
+	tools := map[string][]string{}
+	revalidate(ctx, out, files, nil, tools)
+	if !jsonOutput {
+		fmt.Fprintf(out, "\nWatching %s for changes. Press Ctrl+C to stop.\n", strings.Join(files, ", "))
+	}
+
+	watchFiles(ctx, files, previous, validateFlags.debounce, func(changed []string) {
+		revalidate(ctx, out, changed, changed, tools)
+	})
+	if !jsonOutput {
+		fmt.Fprintln(out, "\nStopped watching.")
+	}
+	return nil
+}
+
+// revalidate validates the files and prints their reports, with the tools
+// added and removed since the previous validation, recorded in tools. In
+// JSON mode every report is logged as one line of JSON on stdout.
+func revalidate(ctx context.Context, out io.Writer, files, changed []string, tools map[string][]string) {
+	if !jsonOutput && changed != nil {
+		fmt.Fprintf(out, "\n[%s] Changed: %s\n", time.Now().Format("15:04:05"), strings.Join(changed, ", "))
+	}
+	reports := validateFiles(ctx, files, &batchProgress{out: io.Discard, total: len(files)})
+	if jsonOutput {
+		event := eventSpecValidated
+		if changed != nil {
+			event = eventSpecChanged
+		}
+		encoder := json.NewEncoder(out)
+		for _, report := range reports {
+			line := specEvent{Event: event, Time: time.Now(), specReport: report}
+			if validateFlags.preview && report.server != nil {
+				line.Preview = []toolPreview{}
+				for _, tool := range report.server.Tools {
+					line.Preview = append(line.Preview, toolPreview{Name: tool.Name, Method: tool.HTTPConfig.Method, Path: tool.HTTPConfig.Path})
+				}
+			}
+			encoder.Encode(line)
+		}
+		return
+	}
+
+	for _, report := range reports {
+		printSpecReports(out, []specReport{report})
+		if report.server == nil {
+			// The next mapping is compared with the last one that worked
+			continue
+		}
+		var names []string
+		for _, tool := range report.server.Tools {
+			names = append(names, tool.Name)
+		}
+		if before, ok := tools[report.File]; ok {
+			if added, removed := diffNames(before, names); len(added)+len(removed) > 0 {
+				fmt.Fprintf(out, "  tools added: %s; removed: %s\n", listOrNone(added), listOrNone(removed))
+			}
+		}
+		tools[report.File] = names
+		if validateFlags.preview {
+			for _, tool := range report.server.Tools {
+				fmt.Fprintf(out, "    %-30s %s %s\n", tool.Name, tool.HTTPConfig.Method, tool.HTTPConfig.Path)
+			}
+		}
+	}
+}
+
+// diffNames returns the names only in after and those only in before
+func diffNames(before, after []string) (added, removed []string) {
+	had := map[string]bool{}
+	for _, name := range before {
+		had[name] = true
+	}
+	has := map[string]bool{}
+	for _, name := range after {
+		has[name] = true
+		if !had[name] {
+			added = append(added, name)
+		}
+	}
+	for _, name := range before {
+		if !has[name] {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+// listOrNone joins names for a message
+func listOrNone(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// watchFiles polls the files below paths until ctx is done and calls
+// onChange with the files changed since the previous snapshot, once they
+// have stayed unchanged for debounce
+func watchFiles(ctx context.Context, paths []string, previous map[string]watchStamp, debounce time.Duration, onChange func(changed []string)) {
+	if debounce < 0 {
+		debounce = 0
+	}
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	pending := map[string]bool{}
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := snapshotWatched(paths)
+		if changed := changedWatched(previous, current); len(changed) > 0 {
+			previous = current
+			for _, file := range changed {
+				pending[file] = true
+			}
+			lastChange = time.Now()
+			if debounce > 0 {
+				continue
+			}
+		}
+		if len(pending) == 0 || time.Since(lastChange) < debounce {
+			continue
+		}
+
+		changed := make([]string, 0, len(pending))
+		for file := range pending {
+			changed = append(changed, file)
+		}
+		sort.Strings(changed)
+		pending = map[string]bool{}
+		onChange(changed)
+	}
+}
+
+// regenerate reruns the pipeline after a change and logs it on one line,
+// or the error that stopped it. In JSON mode every generation is logged as
+// one line of JSON on stdout, failed ones included.
+func regenerate(ctx context.Context, out, errOut io.Writer, specPath string, opts generator.Options, changed []string) {
+	if !jsonOutput {
+		fmt.Fprintf(out, "\n[%s] Changed: %s\n", time.Now().Format("15:04:05"), strings.Join(changed, ", "))
+	}
+
+	server, err := loadServer(ctx, specPath)
+	var result *generator.GenerationResult
+	if err == nil {
+		result, err = generator.NewService().Generate(server, opts)
+	}
+	if jsonOutput {
+		event := struct {
+			Time    time.Time `json:"time"`
+			Changed []string  `json:"changed"`
+			jsonGeneration
+		}{time.Now(), changed, newJSONGeneration(specPath, result, false, err)}
+		if event.Changed == nil {
+			event.Changed = []string{}
+		}
+		json.NewEncoder(out).Encode(event)
+		return
+	}
+	if result == nil {
+		fmt.Fprint(errOut, FormatError(err))
+		return
+	}
+
+	counts := map[generator.FileStatus]int{}
+	for _, file := range result.Files {
+		counts[file.Status]++
+		if verbose && file.Status != generator.FileUnchanged {
+			fmt.Fprintf(out, "  %s - %s\n", file.Path, file.Status)
+		}
+	}
+	fmt.Fprintf(out, "✓ Regenerated %d tools in %s: %d created, %d modified, %d unchanged\n",
+		result.ToolCount, result.Duration.Round(time.Millisecond),
+		counts[generator.FileCreated], counts[generator.FileModified], counts[generator.FileUnchanged])
+	if warnings := len(result.Warnings) + len(result.Findings); warnings > 0 && !verbose {
+		fmt.Fprintf(out, "  warnings: %d; run with --verbose to list them\n", warnings)
+	}
+	if verbose {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(errOut, "Warning: %s\n", warning)
+		}
+		for _, finding := range result.Findings {
+			fmt.Fprintf(errOut, "Warning: %s\n", finding)
+		}
+	}
+	if err != nil {
+		fmt.Fprint(errOut, FormatError(err))
+	}
+}
+
+// snapshotWatched records the stamps of the watched files and of every file
+// below the watched directories. Paths that cannot be read are left out,
+// so a file replaced by an editor shows up as changed once it is back.
+func snapshotWatched(paths []string) map[string]watchStamp {
+	snapshot := map[string]watchStamp{}
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				if path != root && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info, err := entry.Info(); err == nil {
+				snapshot[path] = watchStamp{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+	return snapshot
+}
+
+// changedWatched lists files added, removed or modified between snapshots
+func changedWatched(before, after map[string]watchStamp) []string {
+	var changed []string
+	for path, stamp := range after {
+		if old, ok := before[path]; !ok || old.size != stamp.size || !old.modTime.Equal(stamp.modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
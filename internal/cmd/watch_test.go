@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/generator"
+)
+
+// lockedBuffer is a bytes.Buffer written by a watch while the test reads it
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitForOutput waits until out contains text count times
+func waitForOutput(t *testing.T, out *lockedBuffer, text string, count int) {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for strings.Count(out.String(), text) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("waited for %d × %q, got:\n%s", count, text, out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// fastWatch polls the watched files every few milliseconds for the test
+func fastWatch(t *testing.T) {
+	saved := watchInterval
+	watchInterval = 5 * time.Millisecond
+	t.Cleanup(func() { watchInterval = saved })
+}
+
+func TestChangedWatched(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("main.go.tmpl", "a")
+	write("partials/header.tmpl", "b")
+	write(".git/HEAD", "ref")
+	spec := filepath.Join(t.TempDir(), "api.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte("openapi: 3.0.3"), 0644))
+
+	before := snapshotWatched([]string{dir, spec, filepath.Join(dir, "missing")})
+	assert.Len(t, before, 3, "hidden directories and missing paths are left out")
+
+	write("main.go.tmpl", "changed")
+	write("partials/footer.tmpl", "c")
+	require.NoError(t, os.Remove(filepath.Join(dir, "partials", "header.tmpl")))
+	write(".git/HEAD", "other ref")
+	after := snapshotWatched([]string{dir, spec})
+	assert.Equal(t, []string{
+		filepath.Join(dir, "main.go.tmpl"),
+		filepath.Join(dir, "partials", "footer.tmpl"),
+		filepath.Join(dir, "partials", "header.tmpl"),
+	}, changedWatched(before, after))
+	assert.Empty(t, changedWatched(after, snapshotWatched([]string{dir, spec})))
+}
+
+func TestWatchFilesDebounce(t *testing.T) {
+	fastWatch(t)
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "api.yaml")
+	templates := filepath.Join(dir, "templates")
+	require.NoError(t, os.Mkdir(templates, 0755))
+	require.NoError(t, os.WriteFile(spec, []byte("v0"), 0644))
+
+	watch := func(debounce time.Duration, edit func()) [][]string {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls [][]string
+		done := make(chan struct{})
+		previous := snapshotWatched([]string{spec, templates})
+		go func() {
+			defer close(done)
+			watchFiles(ctx, []string{spec, templates}, previous, debounce, func(changed []string) {
+				calls = append(calls, changed)
+			})
+		}()
+		edit()
+		time.Sleep(debounce + 100*time.Millisecond)
+		cancel()
+		<-done
+		return calls
+	}
+
+	// Saves in quick succession are one change
+	calls := watch(200*time.Millisecond, func() {
+		for i := 1; i <= 4; i++ {
+			require.NoError(t, os.WriteFile(spec, []byte(strings.Repeat("v", i+1)), 0644))
+			time.Sleep(20 * time.Millisecond)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(templates, "main.go.tmpl"), []byte("x"), 0644))
+	})
+	assert.Equal(t, [][]string{{spec, filepath.Join(templates, "main.go.tmpl")}}, calls)
+
+	// Without a debounce every poll that sees a change reports it. The
+	// saves replace the file, so that no poll sees one half written.
+	save := func(content string) {
+		tmp := filepath.Join(dir, "api.yaml.tmp")
+		require.NoError(t, os.WriteFile(tmp, []byte(content), 0644))
+		require.NoError(t, os.Rename(tmp, spec))
+	}
+	calls = watch(0, func() {
+		save("first")
+		time.Sleep(50 * time.Millisecond)
+		save("second!")
+	})
+	assert.Equal(t, [][]string{{spec}, {spec}}, calls)
+
+	assert.Empty(t, watch(0, func() {}), "nothing is reported without changes")
+}
+
+func TestWatchGenerate(t *testing.T) {
+	fastWatch(t)
+	saved, savedJSON, savedVerbose := generateFlags, jsonOutput, verbose
+	defer func() { generateFlags, jsonOutput, verbose = saved, savedJSON, savedVerbose }()
+	jsonOutput, verbose = false, false
+	generateFlags.debounce = 20 * time.Millisecond
+
+	valid, err := os.ReadFile(filepath.Join("..", "generator", "testdata", "users.yaml"))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "users.yaml")
+	require.NoError(t, os.WriteFile(spec, valid, 0644))
+	output := filepath.Join(dir, "server")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, errOut := &lockedBuffer{}, &lockedBuffer{}
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	done := make(chan error)
+	go func() {
+		done <- watchGenerate(cmd, spec, generator.Options{OutputDir: output, SkipVet: true})
+	}()
+	waitForOutput(t, out, "Watching "+spec, 1)
+	assert.FileExists(t, filepath.Join(output, "main.go"))
+
+	// A change regenerates the server
+	require.NoError(t, os.WriteFile(spec, bytes.Replace(valid, []byte("operationId: listUsers"), []byte("operationId: findUsers"), 1), 0644))
+	waitForOutput(t, out, "✓ Regenerated 3 tools", 1)
+	main, err := os.ReadFile(filepath.Join(output, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(main), `"find_users"`)
+
+	// A broken specification is reported and the watch goes on
+	require.NoError(t, os.WriteFile(spec, []byte("openapi: [broken"), 0644))
+	waitForOutput(t, out, "Changed: "+spec, 2)
+	waitForOutput(t, errOut, "Error", 1)
+	assert.Equal(t, 1, strings.Count(out.String(), "✓ Regenerated"))
+
+	require.NoError(t, os.WriteFile(spec, valid, 0644))
+	waitForOutput(t, out, "✓ Regenerated 3 tools", 2)
+	main, err = os.ReadFile(filepath.Join(output, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(main), `"list_users"`, "the fixed specification is generated")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the watch did not stop")
+	}
+	assert.Contains(t, out.String(), "Stopped watching.")
+}
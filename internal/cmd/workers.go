@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// workersUsage describes the --workers flag of the batch commands
+const workersUsage = "items processed concurrently; 0 uses one per CPU"
+
+// runWorkers calls run for every index below count with a pool of workers,
+// one per CPU when workers is not positive, and returns when all are done
+func runWorkers(workers, count int, run func(i int)) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > count {
+		workers = count
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				run(i)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// batchProgress prints a checklist line as each item of a batch finishes,
+// numbered in the order they finish. It is safe for concurrent use.
+type batchProgress struct {
+	mu    sync.Mutex
+	out   io.Writer
+	total int
+	done  int
+}
+
+// report prints the line for a finished item; detail, if set, follows it
+func (p *batchProgress) report(name, detail string, err error, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	mark := "✓"
+	if err != nil {
+		mark, detail = "✗", firstLine(err.Error())
+	}
+	line := fmt.Sprintf("%s [%d/%d] %s", mark, p.done, p.total, name)
+	if detail != "" {
+		line += ": " + detail
+	}
+	if verbose {
+		line += fmt.Sprintf(" (%s)", duration.Round(time.Millisecond))
+	}
+	fmt.Fprintln(p.out, line)
+}
+
+// batchFailure names a failed item of a batch and why it failed
+type batchFailure struct {
+	name   string
+	reason string
+}
+
+// printFailures lists the failed items after the results of a batch, so
+// they need not be searched for among the successes
+func printFailures(out io.Writer, failures []batchFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\nFailed (%d):\n", len(failures))
+	for _, failure := range failures {
+		fmt.Fprintf(out, "  %s: %s\n", failure.name, failure.reason)
+	}
+}
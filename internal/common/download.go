@@ -0,0 +1,198 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAttempts is how often a download is tried unless configured
+const DefaultAttempts = 4
+
+// DownloadOptions control how Download retries and checks a download
+type DownloadOptions struct {
+	// Description names what is downloaded in error messages
+	Description string
+	// MaxSize fails downloads larger than this many bytes
+	MaxSize int64
+	// Attempts is how often the download is tried before giving up;
+	// 0 uses DefaultAttempts
+	Attempts int
+	// SHA256 is the hex checksum the content must have, if set
+	SHA256 string
+	// Retry is called before each further attempt, if set
+	Retry func(attempt int, err error)
+	// Backoff returns the delay before an attempt after the first; nil
+	// uses DefaultBackoff
+	Backoff func(attempt int) time.Duration
+}
+
+// DefaultBackoff waits a second before the second attempt, doubling the
+// delay for each further one
+func DefaultBackoff(attempt int) time.Duration {
+	return time.Duration(1<<(attempt-2)) * time.Second
+}
+
+// retryableStatus reports responses worth trying again
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusBadGateway ||
+		code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// Download sends a GET request and reads the response. Connections lost
+// while reading are resumed with a Range request where the server allows
+// it, and started over where it does not; failed connections and
+// temporarily unavailable servers are retried with an increasing delay.
+//
+// An unsuccessful response is returned with its body closed and no content
+// for the caller to report; its status is the caller's to check.
+func Download(ctx context.Context, client *http.Client, req *http.Request, opts DownloadOptions) (*http.Response, []byte, *Error) {
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+	backoff := opts.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+	var content bytes.Buffer
+	// validator is the ETag or Last-Modified of the partial content, which
+	// a resumed response must still have
+	validator := ""
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if opts.Retry != nil {
+				opts.Retry(attempt, lastErr)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, nil, NewError(ErrorTypeNetwork, "failed to download "+opts.Description, ctx.Err())
+			case <-time.After(backoff(attempt)):
+			}
+		}
+
+		attemptReq := req.Clone(ctx)
+		resume := content.Len() > 0 && validator != ""
+		if resume {
+			attemptReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", content.Len()))
+			attemptReq.Header.Set("If-Range", validator)
+		}
+		resp, err := client.Do(attemptReq)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, NewError(ErrorTypeNetwork, "failed to download "+opts.Description, ctx.Err())
+			}
+			lastErr = err
+			continue
+		}
+		switch {
+		case resp.StatusCode == http.StatusPartialContent:
+			if !resume || rangeStart(resp) != int64(content.Len()) {
+				resp.Body.Close()
+				content.Reset()
+				validator, lastErr = "", errors.New("server resumed the download at another offset")
+				continue
+			}
+		case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+			// The server sent everything again
+			content.Reset()
+		case resume:
+			// The partial content is no longer usable; start over
+			resp.Body.Close()
+			content.Reset()
+			validator, lastErr = "", fmt.Errorf("server returned %s when resuming", resp.Status)
+			continue
+		case retryableStatus(resp.StatusCode) && attempt < attempts:
+			resp.Body.Close()
+			lastErr = fmt.Errorf("server returned %s", resp.Status)
+			continue
+		default:
+			resp.Body.Close()
+			return resp, nil, nil
+		}
+		validator = resp.Header.Get("ETag")
+		if validator == "" || strings.HasPrefix(validator, "W/") {
+			validator = resp.Header.Get("Last-Modified")
+		}
+
+		if opts.MaxSize > 0 && int64(content.Len())+resp.ContentLength > opts.MaxSize {
+			resp.Body.Close()
+			return nil, nil, sizeError(opts)
+		}
+		reader := io.Reader(resp.Body)
+		if opts.MaxSize > 0 {
+			reader = io.LimitReader(resp.Body, opts.MaxSize-int64(content.Len())+1)
+		}
+		_, err = content.ReadFrom(reader)
+		resp.Body.Close()
+		if opts.MaxSize > 0 && int64(content.Len()) > opts.MaxSize {
+			return nil, nil, sizeError(opts)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err := VerifySHA256(content.Bytes(), opts.SHA256, opts.Description); err != nil {
+			return nil, nil, err
+		}
+		return resp, content.Bytes(), nil
+	}
+
+	message := "failed to download " + opts.Description
+	if attempts > 1 {
+		message += fmt.Sprintf(" after %d attempts", attempts)
+	}
+	return nil, nil, NewError(ErrorTypeNetwork, message, lastErr).
+		WithSuggestion(ConnectionSuggestion(req))
+}
+
+// rangeStart returns the offset a partial response starts at, or -1
+func rangeStart(resp *http.Response) int64 {
+	unit, spec, ok := strings.Cut(resp.Header.Get("Content-Range"), " ")
+	if !ok || unit != "bytes" {
+		return -1
+	}
+	first, _, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return start
+}
+
+// sizeError reports a download larger than allowed
+func sizeError(opts DownloadOptions) *Error {
+	return NewError(ErrorTypeNetwork, fmt.Sprintf("%s exceeds %d bytes", opts.Description, opts.MaxSize), nil)
+}
+
+// VerifySHA256 compares content with the hex checksum a user expects, if
+// any, before it is accepted
+func VerifySHA256(content []byte, checksum, description string) *Error {
+	if checksum == "" {
+		return nil
+	}
+	sum := sha256.Sum256(content)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, checksum) {
+		return NewError(ErrorTypeNetwork, fmt.Sprintf("%s does not match the expected checksum", description), nil).
+			WithSuggestion(fmt.Sprintf("Its SHA-256 is %s, not %s; check the checksum and that the source is the one intended", got, strings.ToLower(checksum)))
+	}
+	return nil
+}
+
+// CheckSHA256 validates a checksum given by a user
+func CheckSHA256(checksum string) error {
+	if _, err := hex.DecodeString(checksum); err != nil || len(checksum) != sha256.Size*2 {
+		return errors.New("a SHA-256 checksum is 64 hexadecimal digits")
+	}
+	return nil
+}
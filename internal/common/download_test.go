@@ -0,0 +1,193 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const downloadContent = "openapi: 3.0.0\ninfo:\n  title: Users\n  version: 1.0.0\npaths: {}\n"
+
+// flakyServer serves downloadContent, dropping the connection halfway
+// through the first response. It records the Range and If-Range headers of
+// every request; resume decides whether a Range request is honored.
+type flakyServer struct {
+	etag   string
+	resume bool
+
+	mu       sync.Mutex
+	requests []http.Header
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Header.Clone())
+	first := len(s.requests) == 1
+	s.mu.Unlock()
+
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	content := downloadContent
+	if rng := r.Header.Get("Range"); rng != "" && s.resume && r.Header.Get("If-Range") == s.etag {
+		start, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)-start))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(content[start:]))
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	if first {
+		w.Write([]byte(content[:len(content)/2]))
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	w.Write([]byte(content))
+}
+
+func download(t *testing.T, url string, opts DownloadOptions) (*http.Response, []byte, *Error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	opts.Description = "the specification"
+	// Retries are tested without waiting
+	if opts.Backoff == nil {
+		opts.Backoff = func(int) time.Duration { return 0 }
+	}
+	return Download(context.Background(), http.DefaultClient, req, opts)
+}
+
+func TestDownloadResumes(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *flakyServer
+		// ranged is whether the second request continues the first
+		ranged bool
+	}{
+		{"range", &flakyServer{etag: `"v1"`, resume: true}, true},
+		{"range ignored", &flakyServer{etag: `"v1"`}, true},
+		{"weak etag", &flakyServer{etag: `W/"v1"`, resume: true}, false},
+		{"no validator", &flakyServer{resume: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(tt.server)
+			defer server.Close()
+
+			retried := 0
+			resp, content, err := download(t, server.URL, DownloadOptions{Retry: func(int, error) { retried++ }})
+			require.Nil(t, err)
+			status := http.StatusOK
+			if tt.ranged && tt.server.resume {
+				status = http.StatusPartialContent
+			}
+			assert.Equal(t, status, resp.StatusCode)
+			assert.Equal(t, downloadContent, string(content), "the content is complete and in order")
+			assert.Equal(t, 1, retried)
+
+			require.Len(t, tt.server.requests, 2)
+			assert.Empty(t, tt.server.requests[0].Get("Range"))
+			if tt.ranged {
+				assert.Equal(t, fmt.Sprintf("bytes=%d-", len(downloadContent)/2), tt.server.requests[1].Get("Range"))
+				assert.Equal(t, tt.server.etag, tt.server.requests[1].Get("If-Range"))
+			} else {
+				assert.Empty(t, tt.server.requests[1].Get("Range"), "a weak or missing validator cannot resume")
+			}
+		})
+	}
+}
+
+func TestDownloadStatus(t *testing.T) {
+	t.Parallel()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/busy":
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	resp, content, err := download(t, server.URL+"/missing", DownloadOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "the status is the caller's to check")
+	assert.Nil(t, content)
+	assert.Equal(t, 1, requests, "missing content is not retried")
+
+	requests = 0
+	resp, _, err = download(t, server.URL+"/busy", DownloadOptions{Attempts: 2})
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 2, requests, "an unavailable server is retried")
+}
+
+func TestDownloadBackoff(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		[]time.Duration{DefaultBackoff(2), DefaultBackoff(3), DefaultBackoff(4)})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var delays []int
+	_, _, err := download(t, server.URL, DownloadOptions{Attempts: 3, Backoff: func(attempt int) time.Duration {
+		delays = append(delays, attempt)
+		return time.Millisecond
+	}})
+	require.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, delays, "every attempt after the first waits")
+
+	// A cancelled download stops waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, reqErr)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	_, _, err = Download(ctx, http.DefaultClient, req, DownloadOptions{Backoff: func(int) time.Duration { return time.Hour }})
+	require.NotNil(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestDownloadChecks(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(downloadContent))
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte(downloadContent))
+	_, content, err := download(t, server.URL, DownloadOptions{SHA256: strings.ToUpper(hex.EncodeToString(sum[:]))})
+	require.Nil(t, err)
+	assert.Equal(t, downloadContent, string(content))
+
+	_, _, err = download(t, server.URL, DownloadOptions{SHA256: strings.Repeat("0", 64)})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "does not match the expected checksum")
+
+	_, _, err = download(t, server.URL, DownloadOptions{MaxSize: 10})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "exceeds 10 bytes")
+}
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorType categorizes failures so the CLI can report them consistently
+type ErrorType string
+
+const (
+	ErrorTypeParse          ErrorType = "parse"
+	ErrorTypeValidation     ErrorType = "validation"
+	ErrorTypeTransformation ErrorType = "transformation"
+	ErrorTypeGeneration     ErrorType = "generation"
+	ErrorTypeNetwork        ErrorType = "network"
+	// ErrorTypeTest reports a generated server that failed its tests
+	ErrorTypeTest ErrorType = "test"
+)
+
+// Error is the structured error returned by every pipeline stage
+type Error struct {
+	Type       ErrorType
+	Message    string
+	File       string
+	Line       int
+	Suggestion string
+	Err        error
+}
+
+// NewError creates a pipeline error of the given type
+func NewError(errType ErrorType, message string, err error) *Error {
+	return &Error{
+		Type:    errType,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// WithFile attaches the file the error refers to
+func (e *Error) WithFile(file string) *Error {
+	e.File = file
+	return e
+}
+
+// WithLine attaches the line number the error refers to
+func (e *Error) WithLine(line int) *Error {
+	e.Line = line
+	return e
+}
+
+// WithSuggestion attaches a hint on how to resolve the error
+func (e *Error) WithSuggestion(suggestion string) *Error {
+	e.Suggestion = suggestion
+	return e
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Message)
+	if e.File != "" {
+		if e.Line > 0 {
+			fmt.Fprintf(&b, " (%s:%d)", e.File, e.Line)
+		} else {
+			fmt.Fprintf(&b, " (%s)", e.File)
+		}
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
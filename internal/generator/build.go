@@ -0,0 +1,191 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// BuildTarget is a GOOS/GOARCH pair to compile a generated server for
+type BuildTarget struct {
+	GOOS   string
+	GOARCH string
+}
+
+// String returns the target in "os/arch" form
+func (t BuildTarget) String() string {
+	return t.GOOS + "/" + t.GOARCH
+}
+
+// DefaultBuildTargets covers the platforms MCP clients commonly run on
+var DefaultBuildTargets = []BuildTarget{
+	{GOOS: "linux", GOARCH: "amd64"},
+	{GOOS: "linux", GOARCH: "arm64"},
+	{GOOS: "darwin", GOARCH: "amd64"},
+	{GOOS: "darwin", GOARCH: "arm64"},
+	{GOOS: "windows", GOARCH: "amd64"},
+	{GOOS: "windows", GOARCH: "arm64"},
+}
+
+// ParseBuildTarget parses an "os/arch" target such as "linux/amd64"
+func ParseBuildTarget(s string) (BuildTarget, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" {
+		return BuildTarget{}, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid build target %q", s), nil).
+			WithSuggestion("Use the os/arch form, e.g. linux/amd64")
+	}
+	return BuildTarget{GOOS: goos, GOARCH: goarch}, nil
+}
+
+// BuildOptions controls how a generated server is compiled
+type BuildOptions struct {
+	// Dir is the directory containing the generated server
+	Dir string
+	// Name is the binary base name; defaults to the directory name
+	Name string
+	// OutputDir receives the binaries; defaults to "dist" inside Dir
+	OutputDir string
+	// Targets defaults to DefaultBuildTargets
+	Targets []BuildTarget
+	// Progress, if set, is called before and after each target is built
+	Progress func(BuildEvent)
+}
+
+// BuildEvent reports progress while compiling targets
+type BuildEvent struct {
+	Target BuildTarget
+	// Index is the zero-based position of the target; Total is the count
+	Index int
+	Total int
+	// Done is false when the build starts and true when it finishes
+	Done bool
+	Err  error
+}
+
+// BuildArtifact is a binary produced for one target
+type BuildArtifact struct {
+	Target BuildTarget
+	Path   string
+	Size   int64
+}
+
+// BuildResult lists the binaries produced by a build
+type BuildResult struct {
+	Artifacts []BuildArtifact
+	Duration  time.Duration
+}
+
+// Build compiles a generated server for each target. Every target is
+// attempted; the error reports the targets that failed and wraps each
+// target's error, a *BuildError where the compiler reported diagnostics.
+func (s *Service) Build(opts BuildOptions) (*BuildResult, error) {
+	start := time.Now()
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "go toolchain not found", err).
+			WithSuggestion("Install Go 1.21 or later and make sure it is on PATH")
+	}
+
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to resolve server directory", err).
+			WithFile(opts.Dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "directory does not contain a generated server", err).
+			WithFile(dir).
+			WithSuggestion("Generate the server first or point to its output directory")
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(dir, "dist")
+	}
+	outputDir, err = filepath.Abs(outputDir)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to resolve build output directory", err).
+			WithFile(opts.OutputDir)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to create build output directory", err).
+			WithFile(outputDir)
+	}
+
+	targets := opts.Targets
+	if len(targets) == 0 {
+		targets = DefaultBuildTargets
+	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(BuildEvent) {}
+	}
+
+	result := &BuildResult{}
+	var failed []string
+	var errs []error
+	var diagnostics *BuildError
+	for i, target := range targets {
+		progress(BuildEvent{Target: target, Index: i, Total: len(targets)})
+
+		binary := fmt.Sprintf("%s-%s-%s", name, target.GOOS, target.GOARCH)
+		if target.GOOS == "windows" {
+			binary += ".exe"
+		}
+		artifact, err := buildTarget(goTool, dir, filepath.Join(outputDir, binary), target)
+		if err != nil {
+			failed = append(failed, target.String())
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+			if buildErr, ok := err.(*BuildError); ok && diagnostics == nil {
+				diagnostics = buildErr
+			}
+		} else {
+			result.Artifacts = append(result.Artifacts, artifact)
+		}
+
+		progress(BuildEvent{Target: target, Index: i, Total: len(targets), Done: true, Err: err})
+	}
+
+	result.Duration = time.Since(start)
+	if len(failed) > 0 {
+		buildErr := common.NewError(common.ErrorTypeGeneration,
+			fmt.Sprintf("build failed for %s", strings.Join(failed, ", ")), errors.Join(errs...))
+		if diagnostics != nil {
+			if first := diagnostics.Diagnostics[0]; first.File != "" {
+				buildErr = buildErr.WithFile(filepath.Join(dir, filepath.FromSlash(first.File))).WithLine(first.Line)
+			}
+			buildErr = buildErr.WithSuggestion("The server does not compile; check the diagnostics against the template it was rendered from")
+		}
+		return result, buildErr
+	}
+	return result, nil
+}
+
+func buildTarget(goTool, dir, output string, target BuildTarget) (BuildArtifact, error) {
+	cmd := exec.Command(goTool, "build", "-trimpath", "-ldflags", "-s -w", "-o", output, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "CGO_ENABLED=0", "GOOS="+target.GOOS, "GOARCH="+target.GOARCH)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		findings := sourceFindings(CheckGoBuild, "", string(out))
+		if len(findings) == 0 {
+			return BuildArtifact{}, err
+		}
+		return BuildArtifact{}, &BuildError{Diagnostics: findings}
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		return BuildArtifact{}, err
+	}
+	return BuildArtifact{Target: target, Path: output, Size: info.Size()}, nil
+}
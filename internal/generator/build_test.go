@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/common"
+)
+
+func TestParseBuildTarget(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    BuildTarget
+		wantErr bool
+	}{
+		{input: "linux/amd64", want: BuildTarget{GOOS: "linux", GOARCH: "amd64"}},
+		{input: "windows/arm64", want: BuildTarget{GOOS: "windows", GOARCH: "arm64"}},
+		{input: "linux", wantErr: true},
+		{input: "/amd64", wantErr: true},
+		{input: "linux/", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseBuildTarget(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.input, got.String())
+		})
+	}
+}
+
+func TestBuildKeepsDiagnostics(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not on PATH")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module broken\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {\n\tundefined()\n}\n"), 0644))
+
+	_, err := NewService().Build(BuildOptions{
+		Dir:     dir,
+		Targets: []BuildTarget{{GOOS: "linux", GOARCH: "amd64"}},
+	})
+	require.Error(t, err)
+
+	var buildErr *BuildError
+	require.True(t, errors.As(err, &buildErr), "the compiler diagnostics are wrapped: %v", err)
+	require.NotEmpty(t, buildErr.Diagnostics)
+	assert.Equal(t, 4, buildErr.Diagnostics[0].Line)
+	assert.Contains(t, buildErr.Diagnostics[0].Message, "undefined")
+
+	var pipelineErr *common.Error
+	require.True(t, errors.As(err, &pipelineErr))
+	assert.Equal(t, "build failed for linux/amd64", pipelineErr.Message)
+	assert.Equal(t, filepath.Join(dir, "main.go"), pipelineErr.File)
+	assert.Equal(t, 4, pipelineErr.Line)
+	assert.Contains(t, pipelineErr.Err.Error(), "linux/amd64: ")
+}
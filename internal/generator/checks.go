@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Check tools reported in findings
+const (
+	CheckGofmt     = "gofmt"
+	CheckGoimports = "goimports"
+	CheckGoVet     = "go vet"
+	CheckGoBuild   = "go build"
+)
+
+// Finding is an issue reported by a post-generation check
+type Finding struct {
+	Check string
+	// File is relative to the output directory
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the finding like compiler output
+func (f Finding) String() string {
+	location := f.File
+	if f.Line > 0 {
+		location += ":" + strconv.Itoa(f.Line)
+		if f.Column > 0 {
+			location += ":" + strconv.Itoa(f.Column)
+		}
+	}
+	if location == "" {
+		return fmt.Sprintf("%s (%s)", f.Message, f.Check)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, f.Message, f.Check)
+}
+
+// diagnosticPattern matches "file.go:line:col: message" lines printed by the
+// Go toolchain
+var diagnosticPattern = regexp.MustCompile(`^(?:vet: )?(?:\./)?([^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// formatSources runs the Go sources through goimports, or go/format when
+// goimports is not installed. Sources that cannot be formatted are left as
+// they are and reported.
+func formatSources(files []templateFile, rendered [][]byte) ([][]byte, []Finding, []string) {
+	var findings []Finding
+	var warnings []string
+
+	goimports, err := exec.LookPath("goimports")
+	if err != nil {
+		goimports = ""
+		warnings = append(warnings, "goimports not found on PATH; formatted with gofmt only")
+	}
+
+	out := make([][]byte, len(rendered))
+	for i, file := range files {
+		out[i] = rendered[i]
+		if path.Ext(file.Output) != ".go" {
+			continue
+		}
+
+		check := CheckGofmt
+		var formatted []byte
+		if goimports != "" {
+			check = CheckGoimports
+			formatted, err = runGoimports(goimports, rendered[i])
+		} else {
+			formatted, err = format.Source(rendered[i])
+		}
+		if err != nil {
+			findings = append(findings, sourceFindings(check, file.Output, err.Error())...)
+			continue
+		}
+
+		if !bytes.Equal(formatted, rendered[i]) {
+			findings = append(findings, Finding{
+				Check:   check,
+				File:    file.Output,
+				Message: "template output was not formatted; rewritten",
+			})
+		}
+		out[i] = formatted
+	}
+	return out, findings, warnings
+}
+
+func runGoimports(goimports string, src []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(goimports)
+	cmd.Stdin = bytes.NewReader(src)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return nil, errors.New(strings.ReplaceAll(strings.TrimSpace(stderr.String()), "<standard input>:", ""))
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// vetOutput runs go vet in the output directory. It is skipped with a warning
+// when the Go toolchain is not installed.
+func vetOutput(dir string) ([]Finding, []string) {
+	return runGoCheck(CheckGoVet, dir, "vet", "./...")
+}
+
+// buildOutput copies the generated files into a temporary module and compiles
+// it, so no binary is left in the output directory. It is skipped with a
+// warning when the Go toolchain is not installed.
+func buildOutput(files []templateFile, rendered [][]byte) ([]Finding, []string, error) {
+	dir, err := os.MkdirTemp("", "mcpweaver-build-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	for i, file := range files {
+		target := filepath.Join(dir, filepath.FromSlash(file.Output))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, nil, err
+		}
+		if err := os.WriteFile(target, rendered[i], 0644); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	findings, warnings := runGoCheck(CheckGoBuild, dir, "build", "-o", filepath.Join(dir, "server"), ".")
+	return findings, warnings, nil
+}
+
+// runGoCheck runs a go subcommand in dir and converts its diagnostics to
+// findings. A missing toolchain is reported as a warning.
+func runGoCheck(check, dir string, args ...string) ([]Finding, []string) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		return nil, []string{fmt.Sprintf("go toolchain not found on PATH; skipped %s", check)}
+	}
+
+	cmd := exec.Command(goTool, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+
+	findings := sourceFindings(check, "", string(output))
+	if len(findings) == 0 {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, []string{fmt.Sprintf("%s could not be run: %v", check, err)}
+		}
+		findings = append(findings, Finding{Check: check, Message: strings.TrimSpace(string(output))})
+	}
+	return findings, nil
+}
+
+// sourceFindings converts toolchain diagnostics to findings. Lines without a
+// position are attributed to defaultFile.
+func sourceFindings(check, defaultFile, output string) []Finding {
+	var findings []Finding
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		m := diagnosticPattern.FindStringSubmatch(line)
+		if m == nil && defaultFile != "" {
+			// Formatters report "line:col: message" without a file name
+			m = diagnosticPattern.FindStringSubmatch(defaultFile + ":" + line)
+		}
+		if m == nil {
+			findings = append(findings, Finding{Check: check, File: defaultFile, Message: line})
+			continue
+		}
+
+		lineNo, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		findings = append(findings, Finding{
+			Check:   check,
+			File:    filepath.ToSlash(m[1]),
+			Line:    lineNo,
+			Column:  column,
+			Message: m[4],
+		})
+	}
+	return findings
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Clients whose expectations the probe checks. The MCP Inspector is only
+// checked when its CLI is installed.
+const (
+	ClientClaudeDesktop = "Claude Desktop"
+	ClientVSCode        = "VS Code"
+	ClientMCPInspector  = "MCP Inspector"
+)
+
+// inspectorTimeout bounds a run of the MCP Inspector CLI
+const inspectorTimeout = 30 * time.Second
+
+// ClientCompatibility lists what keeps an MCP client from using a probed
+// server; no problems means the server is compatible
+type ClientCompatibility struct {
+	Client   string
+	Problems []string
+}
+
+// Compatible reports whether the client can use the server
+func (c ClientCompatibility) Compatible() bool {
+	return len(c.Problems) == 0
+}
+
+// knownProtocolVersions are the MCP revisions the checked clients negotiate
+var knownProtocolVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// modelToolName is the tool name syntax of the model APIs behind the clients;
+// tools named otherwise are rejected or dropped
+var modelToolName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// maxVSCodeTools is the number of tools VS Code sends to the model per
+// request
+const maxVSCodeTools = 128
+
+// clientCompatibility checks the protocol version and tools of a server
+// against the clients
+func clientCompatibility(protocolVersion string, tools []string) []ClientCompatibility {
+	var shared []string
+	if !knownProtocolVersions[protocolVersion] {
+		shared = append(shared, fmt.Sprintf("protocol version %q is not one the client negotiates", protocolVersion))
+	}
+	for _, tool := range tools {
+		if !modelToolName.MatchString(tool) {
+			shared = append(shared, fmt.Sprintf("tool name %q must be 1-64 letters, digits, _ or -", tool))
+		}
+	}
+
+	claude := ClientCompatibility{Client: ClientClaudeDesktop, Problems: append([]string(nil), shared...)}
+	vscode := ClientCompatibility{Client: ClientVSCode, Problems: append([]string(nil), shared...)}
+	if len(tools) > maxVSCodeTools {
+		vscode.Problems = append(vscode.Problems,
+			fmt.Sprintf("%d tools exceed the %d VS Code sends to the model", len(tools), maxVSCodeTools))
+	}
+	return []ClientCompatibility{claude, vscode}
+}
+
+// claudeDesktopConfig returns a claude_desktop_config.json entry for a server
+// built in dir
+func claudeDesktopConfig(dir, name string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	type entry struct {
+		Command string   `json:"command"`
+		Args    []string `json:"args"`
+	}
+	config := map[string]map[string]entry{
+		"mcpServers": {
+			name: {Command: filepath.Join(dir, name), Args: []string{"-config", filepath.Join(dir, "config.yaml")}},
+		},
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// inspectorCompatibility lists the tools of the server with the MCP
+// Inspector CLI, installed as mcp-inspector, and compares them with the
+// tools the probe listed. The server runs in dir, which holds its
+// config.yaml, with env.
+func inspectorCompatibility(ctx context.Context, inspector, binary, dir string, env, tools []string) ClientCompatibility {
+	compat := ClientCompatibility{Client: ClientMCPInspector}
+	ctx, cancel := context.WithTimeout(ctx, inspectorTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, inspector, "--cli", binary, "--method", "tools/list")
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		compat.Problems = append(compat.Problems, "inspector failed: "+tail(message, 300))
+		return compat
+	}
+
+	var listed struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &listed); err != nil {
+		compat.Problems = append(compat.Problems, fmt.Sprintf("inspector output is not a tools/list result: %v", err))
+		return compat
+	}
+	seen := map[string]bool{}
+	for _, tool := range listed.Tools {
+		seen[tool.Name] = true
+	}
+	for _, tool := range tools {
+		if !seen[tool] {
+			compat.Problems = append(compat.Problems, fmt.Sprintf("tool %q is not listed by the inspector", tool))
+		}
+	}
+	if len(listed.Tools) > len(tools) {
+		compat.Problems = append(compat.Problems, fmt.Sprintf("inspector listed %d tools, the probe %d", len(listed.Tools), len(tools)))
+	}
+	return compat
+}
@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCompatibility(t *testing.T) {
+	clients := clientCompatibility("2025-03-26", []string{"list_users", "get_user"})
+	require.Len(t, clients, 2)
+	for _, client := range clients {
+		assert.True(t, client.Compatible(), client.Client)
+	}
+
+	clients = clientCompatibility("1999-01-01", []string{"list users"})
+	assert.Len(t, clients[0].Problems, 2, "unknown protocol version and invalid tool name")
+}
+
+func TestClaudeDesktopConfig(t *testing.T) {
+	config, err := claudeDesktopConfig("/srv/users", "users-api")
+	require.NoError(t, err)
+	var parsed struct {
+		MCPServers map[string]struct {
+			Command string   `json:"command"`
+			Args    []string `json:"args"`
+		} `json:"mcpServers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(config), &parsed))
+	server := parsed.MCPServers["users-api"]
+	assert.Equal(t, filepath.Join("/srv/users", "users-api"), server.Command)
+	assert.Equal(t, []string{"-config", filepath.Join("/srv/users", "config.yaml")}, server.Args)
+}
+
+func TestInspectorCompatibility(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not on PATH")
+	}
+	fake := func(script string) string {
+		path := filepath.Join(t.TempDir(), "mcp-inspector")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+		return path
+	}
+	tools := []string{"list_users", "get_user"}
+
+	listed := fake(`[ "$1 $3 $4" = "--cli --method tools/list" ] || exit 2
+echo '{"tools": [{"name": "list_users"}, {"name": "get_user"}]}'`)
+	compat := inspectorCompatibility(context.Background(), listed, "/work/server", t.TempDir(), nil, tools)
+	assert.Equal(t, ClientMCPInspector, compat.Client)
+	assert.True(t, compat.Compatible(), compat.Problems)
+
+	partial := fake(`echo '{"tools": [{"name": "list_users"}]}'`)
+	compat = inspectorCompatibility(context.Background(), partial, "/work/server", t.TempDir(), nil, tools)
+	assert.Equal(t, []string{`tool "get_user" is not listed by the inspector`}, compat.Problems)
+
+	failing := fake(`echo "Failed to connect to MCP server" >&2; exit 1`)
+	compat = inspectorCompatibility(context.Background(), failing, "/work/server", t.TempDir(), nil, tools)
+	assert.Equal(t, []string{"inspector failed: Failed to connect to MCP server"}, compat.Problems)
+
+	garbled := fake(`echo 'not json'`)
+	compat = inspectorCompatibility(context.Background(), garbled, "/work/server", t.TempDir(), nil, tools)
+	require.Len(t, compat.Problems, 1)
+	assert.Contains(t, compat.Problems[0], "not a tools/list result")
+}
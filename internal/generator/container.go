@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"MCPWeaver/internal/common"
+)
+
+// Paths inside the containers of the test harness
+const (
+	containerSource = "/src"
+	containerWork   = "/work"
+)
+
+// dockerTool finds the docker CLI for testing in containers of image
+func dockerTool(image string) (string, error) {
+	docker, err := exec.LookPath("docker")
+	if err != nil {
+		return "", common.NewError(common.ErrorTypeGeneration, "docker not found", err).
+			WithSuggestion(fmt.Sprintf("Install docker to test in %s containers, or test with the host toolchain", image))
+	}
+	return docker, nil
+}
+
+// containerUser runs containers as the current user, so the files they
+// write can be removed on the host; empty where there are no user IDs
+func containerUser() []string {
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid < 0 {
+		return nil
+	}
+	// Arbitrary users have no home directory to keep the Go caches in
+	return []string{
+		"--user", fmt.Sprintf("%d:%d", uid, gid),
+		"--env", "HOME=/tmp",
+		"--env", "GOCACHE=/tmp/go-build",
+		"--env", "GOPATH=/tmp/go",
+	}
+}
+
+// dockerBuildArgs compiles the server in dir to the binary name in work
+// inside a disposable container. The source is mounted read-only, and
+// modules are downloaded into the container, never into the host's cache.
+func dockerBuildArgs(image, dir, work, name string) []string {
+	args := []string{"run", "--rm",
+		"--volume", dir + ":" + containerSource + ":ro",
+		"--volume", work + ":" + containerWork,
+		"--workdir", containerSource,
+		"--env", "GOWORK=off",
+		"--env", "CGO_ENABLED=0",
+		"--env", "GOFLAGS=-buildvcs=false",
+	}
+	args = append(args, containerUser()...)
+	return append(args, image, "go", "build", "-trimpath", "-ldflags", "-s -w", "-o", containerWork+"/"+name, ".")
+}
+
+// dockerRunArgs runs the binary name in work, with the config file config,
+// in a disposable container called container. It shares the host network
+// to reach the stand-in API on the loopback interface. Values of env are
+// passed by name, so they do not appear in the process list.
+func dockerRunArgs(image, container, work, name, config string, env []string) []string {
+	args := []string{"run", "--rm", "--interactive",
+		"--name", container,
+		"--network", "host",
+		"--volume", work + ":" + containerWork + ":ro",
+	}
+	args = append(args, containerUser()...)
+	for _, kv := range env {
+		variable, _, _ := strings.Cut(kv, "=")
+		args = append(args, "--env", variable)
+	}
+	return append(args, image, containerWork+"/"+name, "-config", containerWork+"/"+config)
+}
+
+// buildInContainer compiles the server in dir to output with docker
+func buildInContainer(docker, image, dir, output string) error {
+	cmd := exec.Command(docker, dockerBuildArgs(image, dir, filepath.Dir(output), filepath.Base(output))...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		findings := sourceFindings(CheckGoBuild, "", string(out))
+		if len(findings) == 0 {
+			if message := strings.TrimSpace(string(out)); message != "" {
+				return fmt.Errorf("%v: %s", err, tail(message, 500))
+			}
+			return err
+		}
+		return &BuildError{Diagnostics: findings}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerArgs(t *testing.T) {
+	build := strings.Join(dockerBuildArgs("golang:1.23", "/home/me/server", "/tmp/work", "server"), " ")
+	assert.Contains(t, build, "--volume /home/me/server:/src:ro", "the source is mounted read-only")
+	assert.Contains(t, build, "--volume /tmp/work:/work ")
+	assert.Contains(t, build, "--env CGO_ENABLED=0")
+	assert.True(t, strings.HasSuffix(build, "golang:1.23 go build -trimpath -ldflags -s -w -o /work/server ."), build)
+
+	run := dockerRunArgs("golang:1.23", "mcpweaver-probe-1-1", "/tmp/work", "server", "config.yaml", []string{"API_TOKEN=secret"})
+	joined := strings.Join(run, " ")
+	assert.Contains(t, joined, "--interactive --name mcpweaver-probe-1-1 --network host")
+	assert.Contains(t, joined, "--volume /tmp/work:/work:ro")
+	assert.Contains(t, joined, "--env API_TOKEN ")
+	assert.NotContains(t, joined, "secret", "values stay out of the process list")
+	assert.True(t, strings.HasSuffix(joined, "golang:1.23 /work/server -config /work/config.yaml"), joined)
+}
+
+// fakeDocker stands in for the docker CLI: it runs the command of docker
+// run on the host, with the /src and /work volumes mapped to their host
+// directories, and logs its arguments
+const fakeDocker = `#!/usr/bin/env bash
+echo "$*" >> "$DOCKER_LOG"
+[ "$1" = run ] || exit 0
+shift
+declare -A volumes
+while [ $# -gt 0 ]; do
+	case $1 in
+	--rm|--interactive) shift ;;
+	--volume) v=${2%:ro}; volumes[${v##*:}]=${v%:*}; shift 2 ;;
+	--workdir) workdir=$2; shift 2 ;;
+	--name|--network|--user|--env) shift 2 ;;
+	*) break ;;
+	esac
+done
+shift
+args=()
+for arg in "$@"; do
+	for path in "${!volumes[@]}"; do
+		arg=${arg/#$path/${volumes[$path]}}
+	done
+	args+=("$arg")
+done
+[ -n "$workdir" ] && cd "${volumes[$workdir]}"
+exec "${args[@]}"
+`
+
+func TestProbeServerInContainer(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not on PATH")
+	}
+	dir := generateTestServer(t, "users.yaml")
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker"), []byte(fakeDocker), 0755))
+	log := filepath.Join(t.TempDir(), "docker.log")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DOCKER_LOG", log)
+
+	result, err := NewService().ProbeServer(context.Background(), ProbeOptions{Dir: dir, Image: "golang:1.23"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Tools)
+
+	calls, err := os.ReadFile(log)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(calls)), "\n")
+	require.Len(t, lines, 2, "one container builds the server and one runs it")
+	assert.Contains(t, lines[0], "golang:1.23 go build")
+	assert.Contains(t, lines[1], "golang:1.23 /work/server -config /work/config.yaml")
+}
+
+func TestProbeServerWithoutDocker(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := NewService().ProbeServer(context.Background(), ProbeOptions{Dir: t.TempDir(), Image: "golang:1.23"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "docker not found")
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"MCPWeaver/internal/common"
+)
+
+// CopyTemplate writes the template package selected by the options into
+// dest, which must not exist yet, and returns the copied paths. Layered
+// packages are flattened like exports, so the copy is self-contained and
+// can be edited and used as a custom template directory without changing
+// the original. The built-in files the copy derives from are recorded so
+// MigrateTemplate can merge later changes to them. A partially written copy
+// is removed on failure.
+func (s *Service) CopyTemplate(opts Options, dest string) ([]string, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	files, err := packageFiles(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(dest); err == nil {
+		return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("%s already exists", dest), nil).
+			WithSuggestion("Choose a new directory for the copy")
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to create template directory", err).WithFile(dest)
+	}
+
+	var copied []string
+	for _, file := range files {
+		if file.Synthesized {
+			continue
+		}
+		target := filepath.Join(dest, filepath.FromSlash(file.Name))
+		err := os.MkdirAll(filepath.Dir(target), 0755)
+		if err == nil {
+			err = os.WriteFile(target, file.Content, 0644)
+		}
+		if err != nil {
+			os.RemoveAll(dest)
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to copy template", err).WithFile(target)
+		}
+		copied = append(copied, file.Name)
+	}
+	if err := recordOriginals(opts, dest, files); err != nil {
+		os.RemoveAll(dest)
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to record template originals", err).WithFile(dest)
+	}
+	return copied, nil
+}
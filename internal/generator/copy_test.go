@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyTemplate(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "copy")
+	files, err := NewService().CopyTemplate(Options{}, dest)
+	require.NoError(t, err)
+	assert.Contains(t, files, "manifest.json")
+	assert.Contains(t, files, "main.go.tmpl")
+	for _, file := range files {
+		assert.FileExists(t, filepath.Join(dest, filepath.FromSlash(file)))
+	}
+
+	// The copy renders the same files as the template set it came from
+	diff, err := NewService().DiffTemplates(Options{}, Options{TemplateDir: dest})
+	require.NoError(t, err)
+	assert.Empty(t, diff.Files)
+
+	// Editing the copy leaves the original alone
+	require.NoError(t, os.WriteFile(filepath.Join(dest, "README.md.tmpl"), []byte("# {{ .Server.Name }}\n"), 0644))
+	_, err = NewService().CopyTemplate(Options{TemplateDir: dest}, filepath.Join(t.TempDir(), "second"))
+	require.NoError(t, err)
+	original, err := templatePackageFiles(Options{})
+	require.NoError(t, err)
+	assert.NotEqual(t, "# {{ .Server.Name }}\n", string(original["README.md.tmpl"]))
+
+	_, err = NewService().CopyTemplate(Options{}, dest)
+	assert.Error(t, err, "an existing directory is not overwritten")
+}
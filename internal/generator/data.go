@@ -0,0 +1,328 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"MCPWeaver/internal/transformer"
+)
+
+// TemplateData is the root object passed to every template
+type TemplateData struct {
+	Server     *transformer.MCPServer
+	Options    Options
+	ModuleName string
+	Tools      []ToolData
+	EnvVars    []EnvVar
+	// Vars holds the typed values of the template package's variables
+	Vars map[string]interface{}
+}
+
+// ToolData wraps a tool with values precomputed for rendering
+type ToolData struct {
+	transformer.MCPTool
+	// InputSchemaJSON is the compact JSON encoding of the input schema
+	InputSchemaJSON string
+	// ResponseSchemaJSON is the compact JSON encoding of the response schema,
+	// empty when the operation documents none
+	ResponseSchemaJSON string
+	// ExampleArguments is an indented JSON object of sample arguments
+	ExampleArguments string
+	// ExampleCall is an indented JSON-RPC tools/call request
+	ExampleCall string
+}
+
+// exampleCall mirrors a JSON-RPC tools/call request so fields keep their
+// conventional order when encoded
+type exampleCall struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	} `json:"params"`
+}
+
+// EnvVar is an environment variable read by the generated server
+type EnvVar struct {
+	Name        string
+	Description string
+	Required    bool
+	// ConfigKey is the dotted config.yaml key the variable overrides, if any
+	ConfigKey string
+	// Secret marks credentials that should not be committed to config files
+	Secret bool
+}
+
+// SecretEnvVars returns the credential variables clients need to provide
+func (d *TemplateData) SecretEnvVars() []EnvVar {
+	var secrets []EnvVar
+	for _, env := range d.EnvVars {
+		if env.Secret {
+			secrets = append(secrets, env)
+		}
+	}
+	return secrets
+}
+
+// ConfigName returns the last segment of the config key
+func (e EnvVar) ConfigName() string {
+	if i := strings.LastIndex(e.ConfigKey, "."); i >= 0 {
+		return e.ConfigKey[i+1:]
+	}
+	return e.ConfigKey
+}
+
+func newTemplateData(server *transformer.MCPServer, opts Options) (*TemplateData, error) {
+	data := &TemplateData{
+		Server:     server,
+		Options:    opts,
+		ModuleName: opts.ModuleName,
+		EnvVars:    append(runtimeEnvVars(server, opts), authEnvVars(server)...),
+	}
+	if data.ModuleName == "" {
+		data.ModuleName = server.Name
+	}
+
+	for _, tool := range server.Tools {
+		schemaJSON, err := json.Marshal(tool.InputSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode input schema for tool %s: %w", tool.Name, err)
+		}
+
+		var responseSchemaJSON []byte
+		if tool.ResponseSchema != nil {
+			responseSchemaJSON, err = json.Marshal(tool.ResponseSchema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode response schema for tool %s: %w", tool.Name, err)
+			}
+		}
+
+		args := exampleArguments(tool)
+		argsJSON, err := json.MarshalIndent(args, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode example arguments for tool %s: %w", tool.Name, err)
+		}
+
+		call := exampleCall{JSONRPC: "2.0", ID: 1, Method: "tools/call"}
+		call.Params.Name = tool.Name
+		call.Params.Arguments = args
+		callJSON, err := json.MarshalIndent(call, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode example call for tool %s: %w", tool.Name, err)
+		}
+
+		data.Tools = append(data.Tools, ToolData{
+			MCPTool:            tool,
+			InputSchemaJSON:    string(schemaJSON),
+			ResponseSchemaJSON: string(responseSchemaJSON),
+			ExampleArguments:   string(argsJSON),
+			ExampleCall:        string(callJSON),
+		})
+	}
+	return data, nil
+}
+
+// runtimeEnvVars lists the variables overriding the general config.yaml settings
+func runtimeEnvVars(server *transformer.MCPServer, opts Options) []EnvVar {
+	prefix := server.EnvPrefix + "_"
+	vars := []EnvVar{
+		{
+			Name:        prefix + "CONFIG",
+			Description: "Path to the configuration file (default `config.yaml`)",
+		},
+		{
+			Name:        prefix + "BASE_URL",
+			Description: fmt.Sprintf("Base URL of the upstream API (default `%s`)", server.BaseURL),
+			ConfigKey:   "base_url",
+		},
+		{
+			Name:        prefix + "TIMEOUT",
+			Description: "Upstream request timeout as a Go duration (default `30s`)",
+			ConfigKey:   "timeout",
+		},
+		{
+			Name:        prefix + "LOG_LEVEL",
+			Description: "One of `debug`, `info`, `warn` or `error` (default `info`)",
+			ConfigKey:   "log_level",
+		},
+	}
+	if opts.RateLimiting {
+		vars = append(vars,
+			EnvVar{
+				Name:        prefix + "RATE_LIMIT",
+				Description: "Global upstream requests per second, 0 to disable (default `10`)",
+				ConfigKey:   "rate_limit.requests_per_second",
+			},
+			EnvVar{
+				Name:        prefix + "RATE_LIMIT_BURST",
+				Description: "Requests allowed in a burst above the rate (default `20`)",
+				ConfigKey:   "rate_limit.burst",
+			},
+		)
+	}
+	if opts.Retries {
+		vars = append(vars, EnvVar{
+			Name:        prefix + "RETRY_MAX_ATTEMPTS",
+			Description: "Attempts per upstream request including the first (default `3`)",
+			ConfigKey:   "retry.max_attempts",
+		})
+	}
+	return vars
+}
+
+func authEnvVars(server *transformer.MCPServer) []EnvVar {
+	var vars []EnvVar
+	for _, scheme := range server.Auth.Schemes {
+		switch scheme.Type {
+		case transformer.AuthTypeAPIKey:
+			vars = append(vars, EnvVar{
+				Name:        scheme.EnvVar,
+				Description: fmt.Sprintf("API key for the %s scheme, sent as %s %s", scheme.Name, scheme.In, scheme.ParamName),
+			})
+		case transformer.AuthTypeBearer:
+			vars = append(vars, EnvVar{
+				Name:        scheme.EnvVar,
+				Description: fmt.Sprintf("Bearer token for the %s scheme", scheme.Name),
+			})
+		case transformer.AuthTypeBasic:
+			vars = append(vars,
+				EnvVar{
+					Name:        scheme.UsernameEnvVar,
+					Description: fmt.Sprintf("Username for the %s scheme", scheme.Name),
+				},
+				EnvVar{
+					Name:        scheme.PasswordEnvVar,
+					Description: fmt.Sprintf("Password for the %s scheme", scheme.Name),
+				},
+			)
+		}
+	}
+
+	for i := range vars {
+		vars[i].Secret = true
+		key := strings.ToLower(strings.TrimPrefix(vars[i].Name, server.EnvPrefix+"_"))
+		vars[i].ConfigKey = "auth." + key
+		// A single scheme is usually mandatory for the API to work at all
+		vars[i].Required = len(server.Auth.Schemes) == 1
+	}
+	return vars
+}
+
+// exampleArguments builds sample arguments covering every required parameter
+// and, when there are none, every parameter
+func exampleArguments(tool transformer.MCPTool) map[string]interface{} {
+	params := tool.RequiredParameters()
+	if len(params) == 0 {
+		params = tool.Parameters
+	}
+
+	args := map[string]interface{}{}
+	for _, p := range params {
+		switch {
+		case p.Example != nil:
+			args[p.Name] = p.Example
+		case p.Default != nil:
+			args[p.Name] = p.Default
+		case len(p.Enum) > 0:
+			args[p.Name] = p.Enum[0]
+		default:
+			args[p.Name] = exampleValue(p.Schema, 0)
+		}
+	}
+	return args
+}
+
+func exampleValue(schema map[string]interface{}, depth int) interface{} {
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	if def, ok := schema["default"]; ok {
+		return def
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	schemaType, _ := schema["type"].(string)
+	if types, ok := schema["type"].([]string); ok && len(types) > 0 {
+		schemaType = types[0]
+	}
+	if schemaType == "" {
+		if _, ok := schema["properties"]; ok {
+			schemaType = "object"
+		}
+	}
+
+	switch schemaType {
+	case "integer":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "array":
+		if depth > 3 {
+			return []interface{}{}
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		return []interface{}{exampleValue(items, depth+1)}
+	case "object":
+		out := map[string]interface{}{}
+		if depth > 3 {
+			return out
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := requiredNames(schema)
+		if len(names) == 0 {
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		for _, name := range names {
+			prop, _ := properties[name].(map[string]interface{})
+			out[name] = exampleValue(prop, depth+1)
+		}
+		return out
+	default:
+		format, _ := schema["format"].(string)
+		return exampleString(format)
+	}
+}
+
+func requiredNames(schema map[string]interface{}) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return append([]string(nil), required...)
+	case []interface{}:
+		var names []string
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func exampleString(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "example"
+	}
+}
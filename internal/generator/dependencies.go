@@ -0,0 +1,538 @@
+package generator
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"MCPWeaver/internal/common"
+)
+
+// maxPackageSize bounds a downloaded template package archive
+const maxPackageSize = 32 << 20
+
+// DefaultPackagesDir returns the per-user directory installed template
+// packages are kept in, laid out as {name}/{version}
+func DefaultPackagesDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "mcpweaver", "templates")
+}
+
+func packagesDir(opts Options) string {
+	if opts.PackagesDir != "" {
+		return opts.PackagesDir
+	}
+	return DefaultPackagesDir()
+}
+
+// InstallOptions configures where template packages are installed from
+type InstallOptions struct {
+	// Registry is the base URL of the template registry
+	Registry string
+	// Token is sent as a bearer token when set
+	Token string
+	// PackagesDir is the install location; defaults to DefaultPackagesDir
+	PackagesDir string
+	// MaxSize bounds each download, in bytes; 0 allows 32 MiB
+	MaxSize int64
+	// Attempts is how often each download is tried; 0 uses
+	// common.DefaultAttempts
+	Attempts int
+	// SHA256 is the hex checksum the archive of the package InstallTemplate
+	// downloads must have, if set. Dependencies are checked against the
+	// checksums the registry publishes.
+	SHA256 string
+}
+
+// InstalledPackage is a template package present in the packages directory
+type InstalledPackage struct {
+	Name    string
+	Version string
+	Dir     string
+	// Downloaded is false when a matching version was already installed
+	Downloaded bool
+}
+
+// checkPackageRef rejects a package name, and a version unless empty, that
+// could not be published and so is not safe to join to the packages
+// directory, such as "../x"
+func checkPackageRef(name, version string) *common.Error {
+	if !packageNamePattern.MatchString(name) {
+		return common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid package name %q", name), nil).
+			WithSuggestion("Package names use lowercase letters, digits and dashes")
+	}
+	if version != "" && !packageVersionPattern.MatchString(version) {
+		return common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid version %q of %s", version, name), nil).
+			WithSuggestion("Package versions are semantic versions such as 1.2.0")
+	}
+	return nil
+}
+
+// resolveDependencies returns a layer for every package the manifest depends
+// on, directly or through other dependencies, using the newest installed
+// version satisfying each constraint
+func resolveDependencies(m *manifest, dir string) ([]templateLayer, error) {
+	var layers []templateLayer
+	resolved := map[string]string{}
+	var resolve func(owner string, deps []manifestDependency) error
+	resolve = func(owner string, deps []manifestDependency) error {
+		for _, dep := range deps {
+			if dep.Name == m.Name {
+				continue
+			}
+			if err := checkPackageRef(dep.Name, ""); err != nil {
+				return err.WithSuggestion(fmt.Sprintf("Fix the dependencies of %s", owner))
+			}
+			if version, ok := resolved[dep.Name]; ok {
+				if satisfied, _ := versionSatisfies(version, dep.Version); !satisfied {
+					return common.NewError(common.ErrorTypeGeneration,
+						fmt.Sprintf("%s requires %s %s, which conflicts with %s %s", owner, dep.Name, dep.Version, dep.Name, version), nil)
+				}
+				continue
+			}
+			version, err := installedVersion(dir, dep)
+			if err != nil {
+				return err
+			}
+			if version == "" {
+				return common.NewError(common.ErrorTypeGeneration,
+					fmt.Sprintf("template dependency %s %s of %s is not installed", dep.Name, dep.Version, owner), nil).
+					WithFile(filepath.Join(dir, dep.Name)).
+					WithSuggestion("Install the package's dependencies from the template registry")
+			}
+			resolved[dep.Name] = version
+
+			location := filepath.Join(dir, dep.Name, version)
+			layer := templateLayer{fsys: os.DirFS(location), location: location, onDisk: true, dependency: true}
+			layers = append(layers, layer)
+			depManifest, err := readManifest(layer.fsys)
+			if err != nil {
+				return common.NewError(common.ErrorTypeGeneration, "failed to load template manifest", err).
+					WithFile(layer.path(manifestFile))
+			}
+			if err := resolve(dep.Name, depManifest.Dependencies); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := resolve(m.Name, m.Dependencies); err != nil {
+		return nil, err
+	}
+	return layers, nil
+}
+
+// installedVersion returns the newest installed version of the dependency
+// that satisfies its constraint, or "" when there is none
+func installedVersion(dir string, dep manifestDependency) (string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, dep.Name))
+	if err != nil && !os.IsNotExist(err) {
+		return "", common.NewError(common.ErrorTypeGeneration, "failed to read installed packages", err).
+			WithFile(filepath.Join(dir, dep.Name))
+	}
+	var versions []string
+	for _, entry := range entries {
+		// Skips the temporary directories of installs in progress
+		if entry.IsDir() && packageVersionPattern.MatchString(entry.Name()) {
+			versions = append(versions, entry.Name())
+		}
+	}
+	return newestSatisfying(versions, dep)
+}
+
+// newestSatisfying picks the highest version matching the constraint,
+// ignoring anything that is not a version
+func newestSatisfying(versions []string, dep manifestDependency) (string, error) {
+	best := ""
+	for _, version := range versions {
+		if !packageVersionPattern.MatchString(version) {
+			continue
+		}
+		ok, err := versionSatisfies(version, dep.Version)
+		if err != nil {
+			return "", common.NewError(common.ErrorTypeValidation,
+				fmt.Sprintf("invalid version constraint %q for %s", dep.Version, dep.Name), err)
+		}
+		if ok && (best == "" || compareVersions(version, best) > 0) {
+			best = version
+		}
+	}
+	return best, nil
+}
+
+// InstallTemplate downloads version of the named package from the registry,
+// verifies its checksums and installs it along with its dependencies. The
+// version may be a constraint, in which case the newest matching version
+// the registry lists is installed. Satisfied packages are not downloaded
+// again.
+func (s *Service) InstallTemplate(ctx context.Context, name, version string, install InstallOptions) ([]InstalledPackage, error) {
+	if install.PackagesDir == "" {
+		install.PackagesDir = DefaultPackagesDir()
+	}
+	return installPackages(ctx, install, []manifestDependency{{Name: name, Version: version}}, map[string]bool{})
+}
+
+// InstallDependencies installs whatever the template package selected by the
+// options depends on, e.g. after importing an exported package
+func (s *Service) InstallDependencies(ctx context.Context, opts Options, install InstallOptions) ([]InstalledPackage, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	if install.PackagesDir == "" {
+		install.PackagesDir = packagesDir(opts)
+	}
+	// Dependencies may not be installed yet, so they are not resolved
+	source, err := openTemplateSource(opts.Template, opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	m, manifestName, err := source.manifest()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to load template manifest", err).
+			WithFile(manifestName)
+	}
+	install.SHA256 = ""
+	return installPackages(ctx, install, m.Dependencies, map[string]bool{m.Name: true})
+}
+
+// installPackages installs each dependency and, recursively, what it needs.
+// Packages in seen have already been handled.
+func installPackages(ctx context.Context, install InstallOptions, deps []manifestDependency, seen map[string]bool) ([]InstalledPackage, error) {
+	var installed []InstalledPackage
+	for _, dep := range deps {
+		if seen[dep.Name] {
+			continue
+		}
+		seen[dep.Name] = true
+		if err := checkPackageRef(dep.Name, ""); err != nil {
+			return installed, err
+		}
+		version, err := installedVersion(install.PackagesDir, dep)
+		if err != nil {
+			return installed, err
+		}
+		downloaded := false
+		if version == "" {
+			if version, err = registryVersion(ctx, install, dep); err != nil {
+				return installed, err
+			}
+			if err := downloadPackage(ctx, install, dep.Name, version); err != nil {
+				return installed, err
+			}
+			downloaded = true
+		}
+
+		dir := filepath.Join(install.PackagesDir, dep.Name, version)
+		installed = append(installed, InstalledPackage{Name: dep.Name, Version: version, Dir: dir, Downloaded: downloaded})
+		m, err := readManifest(os.DirFS(dir))
+		if err != nil {
+			return installed, common.NewError(common.ErrorTypeGeneration, "failed to load template manifest", err).
+				WithFile(filepath.Join(dir, manifestFile))
+		}
+		// The checksum given is the requested package's
+		install.SHA256 = ""
+		nested, err := installPackages(ctx, install, m.Dependencies, seen)
+		installed = append(installed, nested...)
+		if err != nil {
+			return installed, err
+		}
+	}
+	return installed, nil
+}
+
+// registryVersion returns the exact version to download for the dependency,
+// asking the registry for its versions of the package when given a range
+func registryVersion(ctx context.Context, install InstallOptions, dep manifestDependency) (string, error) {
+	if packageVersionPattern.MatchString(dep.Version) {
+		return dep.Version, nil
+	}
+	body, err := registryGet(ctx, install, "", "templates", dep.Name)
+	if err != nil {
+		return "", err
+	}
+	var listing struct {
+		Versions []string `json:"versions"`
+	}
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return "", common.NewError(common.ErrorTypeNetwork, "invalid registry response", err)
+	}
+	version, err := newestSatisfying(listing.Versions, dep)
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		return "", common.NewError(common.ErrorTypeNetwork,
+			fmt.Sprintf("the registry has no version of %s matching %q", dep.Name, dep.Version), nil)
+	}
+	return version, nil
+}
+
+// downloadPackage fetches a published archive, checks it against its
+// checksums and manifest and extracts it into the packages directory
+func downloadPackage(ctx context.Context, install InstallOptions, name, version string) error {
+	archive, err := registryGet(ctx, install, install.SHA256, "templates", name, version)
+	if err != nil {
+		return err
+	}
+	files, err := readPackageArchive(archive, maxPackageSize)
+	if err != nil {
+		return common.NewError(common.ErrorTypeNetwork, fmt.Sprintf("invalid package archive for %s %s", name, version), err)
+	}
+
+	m, err := parseManifest(files[manifestFile])
+	if err != nil {
+		return common.NewError(common.ErrorTypeNetwork, fmt.Sprintf("invalid package archive for %s %s", name, version), err)
+	}
+	if m.Name != name || m.Version != version {
+		return common.NewError(common.ErrorTypeNetwork,
+			fmt.Sprintf("registry returned %s %s when asked for %s %s", m.Name, m.Version, name, version), nil)
+	}
+
+	return installFiles(install.PackagesDir, name, version, files)
+}
+
+// installFiles places a package's files at {dir}/{name}/{version}. They are
+// written beside the destination and renamed, so a failed install never
+// leaves a partial package behind.
+func installFiles(dir, name, version string, files map[string][]byte) error {
+	if err := checkPackageRef(name, version); err != nil {
+		return err
+	}
+	parent := filepath.Join(dir, name)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to create packages directory", err).WithFile(parent)
+	}
+	tmp, err := os.MkdirTemp(parent, "."+version+"-")
+	if err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to install template package", err).WithFile(parent)
+	}
+	defer os.RemoveAll(tmp)
+	for name, content := range files {
+		target := filepath.Join(tmp, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(target), 0755)
+		if err == nil {
+			err = os.WriteFile(target, content, 0644)
+		}
+		if err != nil {
+			return common.NewError(common.ErrorTypeGeneration, "failed to install template package", err).WithFile(target)
+		}
+	}
+	dest := filepath.Join(parent, version)
+	if err := os.Rename(tmp, dest); err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to install template package", err).WithFile(dest)
+	}
+	return nil
+}
+
+// registryGet fetches a registry resource below the base URL, checking it
+// against the given checksum, if any
+func registryGet(ctx context.Context, install InstallOptions, checksum string, elem ...string) ([]byte, error) {
+	endpoint, err := url.Parse(install.Registry)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid registry URL %q", install.Registry), err).
+			WithSuggestion("Use an http or https URL")
+	}
+	target := endpoint.JoinPath(elem...).String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeNetwork, "failed to create registry request", err)
+	}
+	if install.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+install.Token)
+	}
+	maxSize := install.MaxSize
+	if maxSize <= 0 {
+		maxSize = maxPackageSize
+	}
+	resp, body, dlErr := common.Download(ctx, http.DefaultClient, req, common.DownloadOptions{
+		Description: "template package",
+		MaxSize:     maxSize,
+		Attempts:    install.Attempts,
+		SHA256:      checksum,
+	})
+	if dlErr != nil {
+		return nil, dlErr.WithFile(target)
+	}
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		return nil, common.NewError(common.ErrorTypeNetwork, fmt.Sprintf("proxy returned %s", resp.Status), nil).
+			WithFile(target).
+			WithSuggestion(common.ProxyAuthSuggestion)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, common.NewError(common.ErrorTypeNetwork, "template package not found in the registry", nil).
+			WithFile(target)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, common.NewError(common.ErrorTypeNetwork, fmt.Sprintf("template registry returned %s", resp.Status), nil).
+			WithFile(target)
+	}
+	if want := resp.Header.Get("X-Checksum-Sha256"); want != "" {
+		sum := sha256.Sum256(body)
+		if !strings.EqualFold(want, hex.EncodeToString(sum[:])) {
+			return nil, common.NewError(common.ErrorTypeNetwork, "downloaded archive does not match its checksum", nil).
+				WithFile(target)
+		}
+	}
+	return body, nil
+}
+
+// readPackageArchive unpacks a published zip archive in memory. Every file
+// must be listed in its SHA256SUMS with a matching checksum, and the files
+// together may not unpack to more than limit bytes.
+func readPackageArchive(archive []byte, limit int64) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	var total int64
+	for _, entry := range zr.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if !fs.ValidPath(entry.Name) || !entry.Mode().IsRegular() {
+			return nil, fmt.Errorf("unsafe archive entry %q", entry.Name)
+		}
+		// The sizes are checked before decompressing anything; the reader
+		// fails entries larger than their header declares
+		if entry.UncompressedSize64 > uint64(limit) {
+			return nil, fmt.Errorf("archive entry %q is too large: %d bytes, at most %d allowed", entry.Name, entry.UncompressedSize64, limit)
+		}
+		if total += int64(entry.UncompressedSize64); total > limit {
+			return nil, fmt.Errorf("archive unpacks to more than %d bytes", limit)
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive entry %q: %w", entry.Name, err)
+		}
+		files[entry.Name] = content
+	}
+
+	sums, ok := files[checksumsFile]
+	if !ok {
+		return nil, fmt.Errorf("archive has no %s", checksumsFile)
+	}
+	verified := map[string]bool{checksumsFile: true}
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		want, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			continue
+		}
+		content, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is listed in %s but missing", name, checksumsFile)
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != want {
+			return nil, fmt.Errorf("checksum mismatch for %s", name)
+		}
+		verified[name] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, entry := range zr.File {
+		if !entry.FileInfo().IsDir() && !verified[entry.Name] {
+			return nil, fmt.Errorf("%s is not listed in %s", entry.Name, checksumsFile)
+		}
+	}
+	return files, nil
+}
+
+// readManifest reads the manifest at the root of a package
+func readManifest(fsys fs.FS) (*manifest, error) {
+	content, err := fs.ReadFile(fsys, manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifest(content)
+}
+
+// versionSatisfies reports whether version meets the constraint
+func versionSatisfies(version, constraint string) (bool, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" || constraint == "*" {
+		return true, nil
+	}
+	if !packageVersionPattern.MatchString(version) {
+		return false, nil
+	}
+
+	op := ""
+	for _, prefix := range []string{">=", "^", "~", "="} {
+		if strings.HasPrefix(constraint, prefix) {
+			op, constraint = prefix, strings.TrimSpace(strings.TrimPrefix(constraint, prefix))
+			break
+		}
+	}
+	if !packageVersionPattern.MatchString(constraint) {
+		return false, fmt.Errorf("expected a version such as 1.2.0")
+	}
+
+	have, want := versionParts(version), versionParts(constraint)
+	switch op {
+	case "^":
+		return have[0] == want[0] && compareVersions(version, constraint) >= 0, nil
+	case "~":
+		return have[0] == want[0] && have[1] == want[1] && compareVersions(version, constraint) >= 0, nil
+	case ">=":
+		return compareVersions(version, constraint) >= 0, nil
+	}
+	return compareVersions(version, constraint) == 0, nil
+}
+
+// compareVersions orders semantic versions; a pre-release sorts before its
+// release
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	_, preA, _ := strings.Cut(a, "-")
+	_, preB, _ := strings.Cut(b, "-")
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
+
+func versionParts(version string) [3]int {
+	var parts [3]int
+	core, _, _ := strings.Cut(version, "-")
+	for i, field := range strings.SplitN(core, ".", 3) {
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts
+}
@@ -0,0 +1,254 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.2.3", b: "1.2.3", want: 0},
+		{a: "1.2.3", b: "1.2.4", want: -1},
+		{a: "1.10.0", b: "1.9.0", want: 1},
+		{a: "2.0.0", b: "1.99.99", want: 1},
+		{a: "1.0.0-rc.1", b: "1.0.0", want: -1},
+		{a: "1.0.0", b: "1.0.0-rc.1", want: 1},
+		{a: "1.0.0-alpha", b: "1.0.0-beta", want: -1},
+		{a: "1.0.0-rc.1", b: "1.0.0-rc.1", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+		wantErr    bool
+	}{
+		{version: "1.2.0", constraint: "", want: true},
+		{version: "1.2.0", constraint: "*", want: true},
+		{version: "1.2.0", constraint: "1.2.0", want: true},
+		{version: "1.2.0", constraint: "=1.2.0", want: true},
+		{version: "1.2.1", constraint: "1.2.0", want: false},
+		{version: "1.9.0", constraint: "^1.2.0", want: true},
+		{version: "1.1.0", constraint: "^1.2.0", want: false},
+		{version: "2.0.0", constraint: "^1.2.0", want: false},
+		{version: "1.2.9", constraint: "~1.2.0", want: true},
+		{version: "1.3.0", constraint: "~1.2.0", want: false},
+		{version: "3.0.0", constraint: ">=1.2.0", want: true},
+		{version: "1.2.0-rc.1", constraint: ">=1.2.0", want: false},
+		{version: "1.2.0", constraint: " >= 1.2.0 ", want: true},
+		{version: "latest", constraint: "^1.0.0", want: false},
+		{version: "1.2.0", constraint: "^1.2", wantErr: true},
+		{version: "1.2.0", constraint: "<2.0.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			got, err := versionSatisfies(tt.version, tt.constraint)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewestSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.4.0", "../../x", "2.0.0", "1.4.1-rc.1"}
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{constraint: "", want: "2.0.0"},
+		{constraint: "^1.0.0", want: "1.4.1-rc.1"},
+		{constraint: "~1.0.0", want: "1.0.0"},
+		{constraint: "^3.0.0", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			got, err := newestSatisfying(versions, manifestDependency{Name: "acme", Version: tt.constraint})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckPackageRef(t *testing.T) {
+	tests := []struct {
+		name, version string
+		wantErr       bool
+	}{
+		{name: "acme-go", version: "1.2.0"},
+		{name: "acme-go"},
+		{name: "../../x", wantErr: true},
+		{name: "acme/go", wantErr: true},
+		{name: "", wantErr: true},
+		{name: "acme", version: "../1.0.0", wantErr: true},
+		{name: "acme", version: "1.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"@"+tt.version, func(t *testing.T) {
+			err := checkPackageRef(tt.name, tt.version)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestInstallFilesStaysInPackagesDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "packages")
+	files := map[string][]byte{manifestFile: []byte("{}")}
+	for _, ref := range [][2]string{{"../../x", "1.0.0"}, {"acme", "../../1.0.0"}} {
+		assert.Error(t, installFiles(dir, ref[0], ref[1], files))
+	}
+	entries, _ := os.ReadDir(root)
+	assert.Len(t, entries, 0, "nothing is written outside the packages directory")
+
+	require.NoError(t, installFiles(dir, "acme", "1.0.0", files))
+	assert.FileExists(t, filepath.Join(dir, "acme", "1.0.0", manifestFile))
+}
+
+func TestResolveDependenciesRejectsUnsafeNames(t *testing.T) {
+	m := &manifest{Name: "app", Dependencies: []manifestDependency{{Name: "../../x"}}}
+	_, err := resolveDependencies(m, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid package name")
+}
+
+// packageArchive builds a registry archive of a package with the given
+// dependencies
+func packageArchive(t *testing.T, name, version string, deps ...manifestDependency) []byte {
+	t.Helper()
+	var depList []string
+	for _, dep := range deps {
+		depList = append(depList, fmt.Sprintf(`{"name": %q, "version": %q}`, dep.Name, dep.Version))
+	}
+	manifestJSON := fmt.Sprintf(`{"name": %q, "version": %q, "description": "test", "files": [], "dependencies": [%s]}`,
+		name, version, strings.Join(depList, ", "))
+	files := []exportFile{{Name: manifestFile, Content: []byte(manifestJSON)}}
+	sum := sha256.Sum256(files[0].Content)
+	files = append(files, exportFile{Name: checksumsFile, Content: []byte(hex.EncodeToString(sum[:]) + "  " + manifestFile + "\n")})
+	var b bytes.Buffer
+	require.NoError(t, writeZip(&b, files))
+	return b.Bytes()
+}
+
+func TestInstallTemplate(t *testing.T) {
+	archives := map[string][]byte{
+		"/templates/app/1.0.0":  packageArchive(t, "app", "1.0.0", manifestDependency{Name: "base", Version: "^1.1.0"}),
+		"/templates/base/1.1.0": packageArchive(t, "base", "1.1.0"),
+		"/templates/base/1.2.0": packageArchive(t, "base", "1.2.0"),
+		"/templates/base/2.0.0": packageArchive(t, "base", "2.0.0"),
+	}
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/templates/base" {
+			fmt.Fprint(w, `{"versions": ["1.1.0", "1.2.0", "2.0.0", "../../evil"]}`)
+			return
+		}
+		archive, ok := archives[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		sum := sha256.Sum256(archive)
+		w.Header().Set("X-Checksum-Sha256", hex.EncodeToString(sum[:]))
+		w.Write(archive)
+	}))
+	defer registry.Close()
+
+	dir := t.TempDir()
+	install := InstallOptions{Registry: registry.URL, PackagesDir: dir, Attempts: 1}
+	installed, err := NewService().InstallTemplate(context.Background(), "app", "1.0.0", install)
+	require.NoError(t, err)
+	require.Len(t, installed, 2)
+	assert.Equal(t, InstalledPackage{Name: "app", Version: "1.0.0", Dir: filepath.Join(dir, "app", "1.0.0"), Downloaded: true}, installed[0])
+	assert.Equal(t, InstalledPackage{Name: "base", Version: "1.2.0", Dir: filepath.Join(dir, "base", "1.2.0"), Downloaded: true}, installed[1])
+
+	// Installed packages are not downloaded again
+	installed, err = NewService().InstallTemplate(context.Background(), "app", "1.0.0", install)
+	require.NoError(t, err)
+	for _, pkg := range installed {
+		assert.False(t, pkg.Downloaded, pkg.Name)
+	}
+
+	_, err = NewService().InstallTemplate(context.Background(), "../app", "1.0.0", install)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid package name")
+}
+
+func TestReadPackageArchive(t *testing.T) {
+	// sum is the SHA256SUMS line of content under name
+	sum := func(name string, content []byte) string {
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:]) + "  " + name + "\n"
+	}
+	archive := func(sums string, files ...exportFile) []byte {
+		files = append(files, exportFile{Name: checksumsFile, Content: []byte(sums)})
+		var b bytes.Buffer
+		require.NoError(t, writeZip(&b, files))
+		return b.Bytes()
+	}
+	manifestJSON := exportFile{Name: manifestFile, Content: []byte(`{"name": "app"}`)}
+	readme := exportFile{Name: "README.md", Content: []byte("# app\n")}
+	large := exportFile{Name: "large.tmpl", Content: bytes.Repeat([]byte("x"), 600)}
+	half := exportFile{Name: "half.tmpl", Content: bytes.Repeat([]byte("x"), 400)}
+	other := exportFile{Name: "other.tmpl", Content: half.Content}
+	manifestSum := sum(manifestFile, manifestJSON.Content)
+
+	tests := []struct {
+		name    string
+		archive []byte
+		err     string
+	}{
+		{"verified", archive(manifestSum+sum("README.md", readme.Content), manifestJSON, readme), ""},
+		{"unlisted file", archive(manifestSum, manifestJSON, readme), "README.md is not listed in SHA256SUMS"},
+		{"missing file", archive(manifestSum+sum("README.md", readme.Content), manifestJSON), "README.md is listed in SHA256SUMS but missing"},
+		{"checksum mismatch", archive(manifestSum+sum("README.md", []byte("# other\n")), manifestJSON, readme), "checksum mismatch for README.md"},
+		{"no checksums", func() []byte {
+			var b bytes.Buffer
+			require.NoError(t, writeZip(&b, []exportFile{manifestJSON}))
+			return b.Bytes()
+		}(), "archive has no SHA256SUMS"},
+		{"entry too large", archive(manifestSum+sum("large.tmpl", large.Content), manifestJSON, large),
+			`archive entry "large.tmpl" is too large: 600 bytes, at most 512 allowed`},
+		{"total too large", archive(manifestSum+sum("half.tmpl", half.Content)+sum("other.tmpl", other.Content), manifestJSON, half, other),
+			"archive unpacks to more than 512 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := readPackageArchive(tt.archive, 512)
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, readme.Content, files["README.md"])
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
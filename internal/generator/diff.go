@@ -0,0 +1,266 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' keeps, '-' deletes and '+'
+// inserts the line
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// DiffHunk is a group of nearby changes with surrounding context. Starts are
+// 1-based; an empty range starts at the line before the change.
+type DiffHunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []DiffLine
+}
+
+// DiffLine is a line of a hunk. Line numbers are 0 on the side the line is
+// absent from.
+type DiffLine struct {
+	Kind    DiffKind
+	OldLine int
+	NewLine int
+	Text    string
+}
+
+// DiffKind classifies a diff line
+type DiffKind string
+
+// Diff line kinds
+const (
+	DiffContext DiffKind = "context"
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+)
+
+// unifiedDiff returns a unified diff turning before into after, or "" when
+// they are equal. A file that did not exist is diffed against /dev/null.
+func unifiedDiff(name string, before, after []byte, existed bool) string {
+	if existed && bytes.Equal(before, after) {
+		return ""
+	}
+
+	var b strings.Builder
+	if existed {
+		fmt.Fprintf(&b, "--- a/%s\n", name)
+	} else {
+		b.WriteString("--- /dev/null\n")
+	}
+	fmt.Fprintf(&b, "+++ b/%s\n", name)
+	b.WriteString(formatHunks(before, after))
+	return b.String()
+}
+
+// formatHunks returns the hunks of a unified diff without file headers
+func formatHunks(before, after []byte) string {
+	var b strings.Builder
+	prefixes := map[DiffKind]byte{DiffContext: ' ', DiffAdded: '+', DiffRemoved: '-'}
+	for _, hunk := range diffHunks(splitLines(before), splitLines(after)) {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(hunk.OldStart, hunk.OldLines), hunkRange(hunk.NewStart, hunk.NewLines))
+		for _, line := range hunk.Lines {
+			b.WriteByte(prefixes[line.Kind])
+			b.WriteString(line.Text)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// diffHunks groups the edit script between two line slices into hunks with
+// diffContext lines of context
+func diffHunks(a, b []string) []DiffHunk {
+	ops := diffLines(a, b)
+
+	// oldLine[i] and newLine[i] count the lines consumed before ops[i]
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.kind != '+' {
+			oldLine[i+1]++
+		}
+		if op.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	var hunks []DiffHunk
+	for next := 0; next < len(ops); {
+		first := next
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Extend the hunk until the changes are separated by more unchanged
+		// lines than the context on both sides would show
+		last := first + 1
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				last = i + 1
+			} else if i-last >= 2*diffContext {
+				break
+			}
+		}
+
+		start := first - diffContext
+		if start < next {
+			start = next
+		}
+		end := last + diffContext
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		hunk := DiffHunk{
+			OldStart: oldLine[start] + 1,
+			OldLines: oldLine[end] - oldLine[start],
+			NewStart: newLine[start] + 1,
+			NewLines: newLine[end] - newLine[start],
+		}
+		if hunk.OldLines == 0 {
+			hunk.OldStart--
+		}
+		if hunk.NewLines == 0 {
+			hunk.NewStart--
+		}
+		for i := start; i < end; i++ {
+			line := DiffLine{Text: ops[i].line}
+			switch ops[i].kind {
+			case '+':
+				line.Kind, line.NewLine = DiffAdded, newLine[i]+1
+			case '-':
+				line.Kind, line.OldLine = DiffRemoved, oldLine[i]+1
+			default:
+				line.Kind, line.OldLine, line.NewLine = DiffContext, oldLine[i]+1, newLine[i]+1
+			}
+			hunk.Lines = append(hunk.Lines, line)
+		}
+		hunks = append(hunks, hunk)
+		next = end
+	}
+	return hunks
+}
+
+// hunkRange formats a hunk header range
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// diffLines computes a shortest edit script between two line slices using
+// Myers' algorithm. The common prefix and suffix are trimmed first, which
+// keeps regenerations with few changes cheap.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards, collecting the edit script in reverse
+	var reversed []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, diffOp{'+', b[y-1]})
+				y--
+			} else {
+				reversed = append(reversed, diffOp{'-', a[x-1]})
+				x--
+			}
+		}
+	}
+
+	ops := make([]diffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
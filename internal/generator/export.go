@@ -0,0 +1,294 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// ExportFormat is an archive format for exported template packages
+type ExportFormat string
+
+// Supported export formats
+const (
+	ExportZip   ExportFormat = "zip"
+	ExportTar   ExportFormat = "tar"
+	ExportTarGz ExportFormat = "tar.gz"
+)
+
+// ExportFormatFromPath infers the archive format from a file name
+func ExportFormatFromPath(name string) (ExportFormat, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ExportTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return ExportTar, nil
+	case strings.HasSuffix(lower, ".zip"):
+		return ExportZip, nil
+	}
+	return "", common.NewError(common.ErrorTypeValidation, fmt.Sprintf("cannot infer archive format of %s", name), nil).
+		WithSuggestion("Use a .zip, .tar, .tar.gz or .tgz file name")
+}
+
+// ExportResult describes a written archive
+type ExportResult struct {
+	Path   string
+	Format ExportFormat
+	// Files lists the archived paths, relative to the package or directory
+	Files []string
+	Size  int64
+}
+
+// exportFile is a file placed in an exported archive
+type exportFile struct {
+	Name    string
+	Content []byte
+	// Synthesized is set for generated files the package does not contain
+	Synthesized bool
+}
+
+// ExportTemplate writes the template package selected by the options to an
+// archive at dest. Layered packages are flattened, so the archive holds the
+// manifest, every template and partial actually used, and a README
+// describing the package.
+func (s *Service) ExportTemplate(opts Options, format ExportFormat, dest string) (*ExportResult, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := packageFiles(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeArchive(files, format, dest)
+}
+
+// ExportDirectory writes the files inside dir to an archive at dest, named
+// by their paths relative to dir. Files and directories for which skip
+// returns true, given their relative slash-separated path, are left out.
+func (s *Service) ExportDirectory(dir string, skip func(name string) bool, format ExportFormat, dest string) (*ExportResult, error) {
+	var files []exportFile
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if skip != nil && skip(name) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files = append(files, exportFile{Name: name, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to read directory", err).WithFile(dir)
+	}
+	return writeArchive(files, format, dest)
+}
+
+// writeArchive writes the files to a new archive at dest
+func writeArchive(files []exportFile, format ExportFormat, dest string) (*ExportResult, error) {
+	out, err := os.Create(dest)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to create archive", err).WithFile(dest)
+	}
+
+	switch format {
+	case ExportZip:
+		err = writeZip(out, files)
+	case ExportTar:
+		err = writeTar(out, files)
+	case ExportTarGz:
+		gz := gzip.NewWriter(out)
+		err = writeTar(gz, files)
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to write archive", err).WithFile(dest)
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to read archive", err).WithFile(dest)
+	}
+	result := &ExportResult{Path: dest, Format: format, Size: info.Size()}
+	for _, file := range files {
+		result.Files = append(result.Files, file.Name)
+	}
+	return result, nil
+}
+
+// packageFiles collects the effective contents of a layered package
+func packageFiles(source *templateSource) ([]exportFile, error) {
+	m, manifestName, err := source.manifest()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to load template manifest", err).
+			WithFile(manifestName)
+	}
+	manifestContent, _, err := source.readFile(manifestFile)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to read template manifest", err).
+			WithFile(manifestName)
+	}
+
+	files := []exportFile{{Name: manifestFile, Content: manifestContent}}
+	seen := map[string]bool{manifestFile: true}
+	add := func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		content, location, err := source.readFile(name)
+		if err != nil {
+			return common.NewError(common.ErrorTypeGeneration, "failed to read template", err).WithFile(location)
+		}
+		seen[name] = true
+		files = append(files, exportFile{Name: name, Content: content})
+		return nil
+	}
+
+	for _, entry := range m.Files {
+		if err := add(entry.Template); err != nil {
+			return nil, err
+		}
+	}
+	for _, layer := range source.layers {
+		// Dependencies are installed separately rather than bundled
+		if layer.dependency {
+			continue
+		}
+		names, err := fs.Glob(layer.fsys, path.Join(partialsDir, "*.tmpl"))
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to list template partials", err)
+		}
+		catalogs, err := fs.Glob(layer.fsys, path.Join(localesDir, "*.json"))
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to list message catalogs", err)
+		}
+		names = append(names, catalogs...)
+		sort.Strings(names)
+		for _, name := range names {
+			if err := add(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	readme := "README.md"
+	if _, _, err := source.readFile(readme); err == nil {
+		if err := add(readme); err != nil {
+			return nil, err
+		}
+	} else {
+		files = append(files, exportFile{Name: readme, Content: []byte(packageReadme(m)), Synthesized: true})
+	}
+	return files, nil
+}
+
+// packageReadme documents a package that ships no README of its own
+func packageReadme(m *manifest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", m.Name)
+	if m.Version != "" {
+		fmt.Fprintf(&b, "Version %s\n\n", m.Version)
+	}
+	if m.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", m.Description)
+	}
+	b.WriteString("MCPWeaver template package. Use it by extracting the archive and passing\nthe directory as the custom template directory.\n\n")
+	b.WriteString("| Template | Output | Condition |\n|----------|--------|-----------|\n")
+	for _, entry := range m.Files {
+		condition := entry.Condition
+		if condition == "" {
+			condition = "always"
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` | `%s` |\n", entry.Template, entry.Output, condition)
+	}
+	return b.String()
+}
+
+func writeZip(w io.Writer, files []exportFile) error {
+	zw := zip.NewWriter(w)
+	modTime := time.Now()
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: modTime}
+		header.SetMode(0644)
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(file.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeTar(w io.Writer, files []exportFile) error {
+	tw := tar.NewWriter(w)
+	modTime := time.Now()
+	dirs := map[string]bool{}
+	for _, file := range files {
+		if dir := path.Dir(file.Name); dir != "." && !dirs[dir] {
+			dirs[dir] = true
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     dir + "/",
+				Mode:     0755,
+				ModTime:  modTime,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     filepath.ToSlash(file.Name),
+			Mode:     0644,
+			Size:     int64(len(file.Content)),
+			ModTime:  modTime,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.Content); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
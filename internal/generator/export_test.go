@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportFormatFromPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    ExportFormat
+		wantErr bool
+	}{
+		{name: "pkg.zip", want: ExportZip},
+		{name: "pkg.ZIP", want: ExportZip},
+		{name: "pkg.tar", want: ExportTar},
+		{name: "pkg.tar.gz", want: ExportTarGz},
+		{name: "pkg.tgz", want: ExportTarGz},
+		{name: "pkg.gz", wantErr: true},
+		{name: "pkg", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExportFormatFromPath(tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExportTemplateArchives(t *testing.T) {
+	for _, format := range []ExportFormat{ExportZip, ExportTar, ExportTarGz} {
+		t.Run(string(format), func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "package."+string(format))
+			result, err := NewService().ExportTemplate(Options{}, format, dest)
+			require.NoError(t, err)
+			assert.Contains(t, result.Files, "manifest.json")
+			assert.Contains(t, result.Files, "README.md")
+			assert.Equal(t, result.Files, archiveNames(t, dest, format))
+		})
+	}
+}
+
+// archiveNames lists the regular files of an archive in order
+func archiveNames(t *testing.T, path string, format ExportFormat) []string {
+	t.Helper()
+	var names []string
+	if format == ExportZip {
+		r, err := zip.OpenReader(path)
+		require.NoError(t, err)
+		defer r.Close()
+		for _, file := range r.File {
+			names = append(names, file.Name)
+		}
+		return names
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var r io.Reader = f
+	if format == ExportTarGz {
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return names
+		}
+		require.NoError(t, err)
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, header.Name)
+		}
+	}
+}
+
+func TestExportDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"openapi.yaml", ".mcpweaver.yaml", "scenarios/list.yaml", "server/main.go", "server/go.mod"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+	skip := func(name string) bool { return name == "server" }
+	for _, format := range []ExportFormat{ExportZip, ExportTarGz} {
+		dest := filepath.Join(t.TempDir(), "project."+string(format))
+		result, err := NewService().ExportDirectory(dir, skip, format, dest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{".mcpweaver.yaml", "openapi.yaml", "scenarios/list.yaml"}, result.Files, "skipped directories are left out")
+		assert.Equal(t, result.Files, archiveNames(t, dest, format))
+	}
+
+	_, err := NewService().ExportDirectory(filepath.Join(dir, "missing"), nil, ExportZip, filepath.Join(t.TempDir(), "x.zip"))
+	assert.ErrorContains(t, err, "failed to read directory")
+}
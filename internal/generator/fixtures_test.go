@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixturesRecordAndReplay(t *testing.T) {
+	var received []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.Method+" "+r.URL.RequestURI()+" "+string(body)+" "+r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":7}`)
+	}))
+	defer api.Close()
+	dir := filepath.Join(t.TempDir(), "fixtures")
+
+	// Recording forwards the request with its headers and saves the answer
+	record := &upstreamRecorder{fixtures: FixtureOptions{Mode: FixturesRecord, Dir: dir, Upstream: api.URL + "/"}}
+	req := httptest.NewRequest(http.MethodPost, "/users?dry=1", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	record.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":7}`, w.Body.String())
+	assert.Equal(t, []string{`POST /users?dry=1 {"name":"a"} Bearer secret`}, received)
+	assert.Empty(t, record.failures())
+
+	name := fixtureFile(dir, http.MethodPost, "/users?dry=1", []byte(`{"name":"a"}`))
+	content, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "secret", "request headers are not recorded")
+	assert.Contains(t, string(content), `"status": 201`)
+
+	// Replaying answers from the fixture without the API
+	api.Close()
+	replay := &upstreamRecorder{fixtures: FixtureOptions{Mode: FixturesReplay, Dir: dir}}
+	w = httptest.NewRecorder()
+	replay.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users?dry=1", strings.NewReader(`{"name":"a"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"id":7}`, w.Body.String())
+	assert.Empty(t, replay.failures())
+
+	// Another body is another request, which was never recorded
+	w = httptest.NewRecorder()
+	replay.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users?dry=1", strings.NewReader(`{"name":"b"}`)))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	require.Len(t, replay.failures(), 1)
+	assert.Contains(t, replay.failures()[0], "no recorded response")
+}
+
+func TestFixtureFile(t *testing.T) {
+	a := fixtureFile("fx", "GET", "/users?page=1", nil)
+	assert.Equal(t, a, fixtureFile("fx", "GET", "/users?page=1", nil))
+	assert.True(t, strings.HasPrefix(filepath.Base(a), "get-"))
+	assert.NotEqual(t, a, fixtureFile("fx", "GET", "/users?page=2", nil))
+	assert.NotEqual(t, a, fixtureFile("fx", "DELETE", "/users?page=1", nil))
+	assert.NotEqual(t, fixtureFile("fx", "POST", "/users", []byte("a")), fixtureFile("fx", "POST", "/users", []byte("b")))
+}
+
+func TestConfigBaseURL(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("log_level: info\nbase_url: \"https://api.example.com/v1\"\n"), 0644))
+	got, err := configBaseURL(config)
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/v1", got)
+
+	require.NoError(t, os.WriteFile(config, []byte("log_level: info\n"), 0644))
+	_, err = configBaseURL(config)
+	assert.Error(t, err)
+}
@@ -0,0 +1,297 @@
+package generator
+
+import (
+	"encoding/json"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// funcMap is available to every template:
+//
+//	title, upper, lower    change case of the whole string
+//	camelCase              "list-user_ids" → "listUserIds"
+//	pascalCase             "list-user_ids" → "ListUserIds"
+//	snakeCase              "listUserIDs" → "list_user_ids"
+//	kebabCase              "listUserIDs" → "list-user-ids"
+//	pluralize              "category" → "categories"
+//	sanitizeIdentifier     makes a valid, non-keyword Go identifier
+//	goType                 maps a JSON schema to a Go type expression
+//	toJSON, toPrettyJSON   encode a value as compact or indented JSON
+//	quote                  formats a string as a Go string literal
+//	join                   joins a string slice with a separator
+//	anchor                 the GitHub anchor of a Markdown heading
+//	apiName                "Users API" → "Users", for text adding " API"
+//	t, tn                  translated messages; see localeFuncs
+var funcMap = template.FuncMap{
+	"title":              strings.Title, //nolint:staticcheck // ASCII-only titles are sufficient here
+	"upper":              strings.ToUpper,
+	"lower":              strings.ToLower,
+	"camelCase":          camelCase,
+	"pascalCase":         pascalCase,
+	"snakeCase":          snakeCase,
+	"kebabCase":          kebabCase,
+	"pluralize":          pluralize,
+	"sanitizeIdentifier": sanitizeIdentifier,
+	"goType":             goType,
+	"toJSON":             toJSON,
+	"toPrettyJSON":       toPrettyJSON,
+	"quote":              strconv.Quote,
+	"join":               join,
+	"anchor":             anchor,
+	"apiName":            apiName,
+	// Replaced by localeFuncs when rendering
+	"t":  func(string, ...interface{}) (string, error) { return "", errNoLocale },
+	"tn": func(string, int, ...interface{}) (string, error) { return "", errNoLocale },
+}
+
+// splitWords breaks an identifier into lowercase words at separators and
+// case changes, keeping acronyms such as "ID", "IDs" or "HTTP" together
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// A trailing "s" pluralizes an acronym ("IDs") rather than
+			// starting a word
+			if nextLower && runes[i+1] == 's' && (i+2 == len(runes) || !unicode.IsLower(runes[i+2])) {
+				nextLower = false
+			}
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
+
+func camelCase(s string) string {
+	words := splitWords(s)
+	for i := 1; i < len(words); i++ {
+		words[i] = capitalize(words[i])
+	}
+	return strings.Join(words, "")
+}
+
+func pascalCase(s string) string {
+	words := splitWords(s)
+	for i := range words {
+		words[i] = capitalize(words[i])
+	}
+	return strings.Join(words, "")
+}
+
+func snakeCase(s string) string {
+	return strings.Join(splitWords(s), "_")
+}
+
+func kebabCase(s string) string {
+	return strings.Join(splitWords(s), "-")
+}
+
+func capitalize(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// irregularPlurals are the plurals the suffix rules get wrong, including
+// nouns that are the same in both forms
+var irregularPlurals = map[string]string{
+	"child":     "children",
+	"person":    "people",
+	"man":       "men",
+	"woman":     "women",
+	"mouse":     "mice",
+	"foot":      "feet",
+	"tooth":     "teeth",
+	"leaf":      "leaves",
+	"life":      "lives",
+	"status":    "statuses",
+	"alias":     "aliases",
+	"bus":       "buses",
+	"quiz":      "quizzes",
+	"datum":     "data",
+	"criterion": "criteria",
+	"data":      "data",
+	"metadata":  "metadata",
+	"news":      "news",
+	"series":    "series",
+	"species":   "species",
+	"sheep":     "sheep",
+	"fish":      "fish",
+	"info":      "info",
+}
+
+// pluralize applies the common English plural rules to the last word of s;
+// words that already look plural are returned unchanged
+func pluralize(s string) string {
+	if plural, ok := irregularPlural(s); ok {
+		return plural
+	}
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "sh"), strings.HasSuffix(lower, "ch"),
+		strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"):
+		return s + "es"
+	case strings.HasSuffix(lower, "s"):
+		return s
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
+
+// irregularPlural looks up the last word of s in irregularPlurals, keeping
+// its case: "Person" → "People", "USER_STATUS" → "USER_STATUSES"
+func irregularPlural(s string) (string, bool) {
+	lower := strings.ToLower(s)
+	for singular, plural := range irregularPlurals {
+		if !strings.HasSuffix(lower, singular) {
+			continue
+		}
+		start := len(s) - len(singular)
+		word := s[start:]
+		// The match must be a whole word: "Salesperson" is not "person"
+		if start > 0 {
+			prev := rune(s[start-1])
+			if unicode.IsLetter(prev) && !(unicode.IsLower(prev) && unicode.IsUpper(rune(word[0]))) {
+				continue
+			}
+		}
+		switch {
+		case word == strings.ToUpper(word) && len(word) > 1:
+			plural = strings.ToUpper(plural)
+		case unicode.IsUpper(rune(word[0])):
+			plural = capitalize(plural)
+		}
+		return s[:start] + plural, true
+	}
+	return "", false
+}
+
+// sanitizeIdentifier turns s into a valid Go identifier: invalid characters
+// become underscores, a leading digit is prefixed and keywords get a trailing
+// underscore
+func sanitizeIdentifier(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+
+	id := b.String()
+	switch {
+	case id == "":
+		return "_"
+	case unicode.IsDigit([]rune(id)[0]):
+		id = "_" + id
+	case token.IsKeyword(id):
+		id += "_"
+	}
+	return id
+}
+
+// goType returns the Go type used to decode values of a JSON schema. Objects
+// without a fixed value type decode to map[string]interface{}.
+func goType(schema map[string]interface{}) string {
+	schemaType, _ := schema["type"].(string)
+	// OpenAPI 3.1 lists nullable types as ["string", "null"]
+	switch types := schema["type"].(type) {
+	case []string:
+		for _, t := range types {
+			if t != "null" {
+				schemaType = t
+				break
+			}
+		}
+	case []interface{}:
+		for _, t := range types {
+			if t, ok := t.(string); ok && t != "null" {
+				schemaType = t
+				break
+			}
+		}
+	}
+	format, _ := schema["format"].(string)
+
+	switch schemaType {
+	case "string":
+		return "string"
+	case "integer":
+		if format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		return "[]" + goType(items)
+	case "object":
+		if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			return "map[string]" + goType(values)
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func toPrettyJSON(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	return string(data), err
+}
+
+// apiName drops a trailing " API" from an API title, so that templates
+// writing "the {{apiName .Server.Title}} API" do not repeat it
+func apiName(title string) string {
+	title = strings.TrimSpace(title)
+	if len(title) > len(" API") && strings.EqualFold(title[len(title)-len(" API"):], " API") {
+		return strings.TrimSpace(title[:len(title)-len(" API")])
+	}
+	return title
+}
+
+// join takes the separator first so it can end a pipeline:
+// {{.Names | join ", "}}
+func join(sep string, elems []string) string {
+	return strings.Join(elems, sep)
+}
@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseFuncs(t *testing.T) {
+	tests := []struct {
+		in                          string
+		camel, pascal, snake, kebab string
+	}{
+		{"list-user_ids", "listUserIds", "ListUserIds", "list_user_ids", "list-user-ids"},
+		{"listUserIDs", "listUserIds", "ListUserIds", "list_user_ids", "list-user-ids"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server", "http-server"},
+		{"get_v2_items", "getV2Items", "GetV2Items", "get_v2_items", "get-v2-items"},
+		{"crème brûlée", "crèmeBrûlée", "CrèmeBrûlée", "crème_brûlée", "crème-brûlée"},
+		{"", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.camel, camelCase(tt.in))
+			assert.Equal(t, tt.pascal, pascalCase(tt.in))
+			assert.Equal(t, tt.snake, snakeCase(tt.in))
+			assert.Equal(t, tt.kebab, kebabCase(tt.in))
+		})
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"user":     "users",
+		"category": "categories",
+		"key":      "keys",
+		"address":  "addresses",
+		"box":      "boxes",
+		"match":    "matches",
+		"wish":     "wishes",
+		"quiz":     "quizzes",
+		"users":    "users",
+		"ID":       "IDs",
+		"Category": "Categories",
+
+		"person":         "people",
+		"Person":         "People",
+		"child":          "children",
+		"woman":          "women",
+		"leaf":           "leaves",
+		"status":         "statuses",
+		"Status":         "Statuses",
+		"datum":          "data",
+		"criterion":      "criteria",
+		"data":           "data",
+		"metadata":       "metadata",
+		"series":         "series",
+		"sheep":          "sheep",
+		"UserStatus":     "UserStatuses",
+		"contact_person": "contact_people",
+		"USER_STATUS":    "USER_STATUSES",
+		"Salesperson":    "Salespersons",
+		"Postman":        "Postmans",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, pluralize(in), "pluralize(%q)", in)
+	}
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"user", "user"},
+		{"user_id", "user_id"},
+		{"user-id", "user_id"},
+		{"x-rate-limit", "x_rate_limit"},
+		{"user.name", "user_name"},
+		{"page size", "page_size"},
+		{"$ref", "_ref"},
+		{"2fa", "_2fa"},
+		{"404", "_404"},
+		{"ünïcode", "ünïcode"},
+		{"名前", "名前"},
+		{"emoji🙂", "emoji_"},
+		{"type", "type_"},
+		{"func", "func_"},
+		{"range", "range_"},
+		{"Type", "Type"},
+		{"", "_"},
+		{"-", "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := sanitizeIdentifier(tt.in)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, token.IsIdentifier(got), "%q is a Go identifier", got)
+		})
+	}
+
+	// Every keyword is escaped
+	for tok := token.BREAK; tok <= token.VAR; tok++ {
+		if tok.IsKeyword() {
+			assert.True(t, token.IsIdentifier(sanitizeIdentifier(tok.String())), tok.String())
+		}
+	}
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]interface{}
+		want   string
+	}{
+		{"string", map[string]interface{}{"type": "string"}, "string"},
+		{"date-time", map[string]interface{}{"type": "string", "format": "date-time"}, "string"},
+		{"date", map[string]interface{}{"type": "string", "format": "date"}, "string"},
+		{"uuid", map[string]interface{}{"type": "string", "format": "uuid"}, "string"},
+		{"email", map[string]interface{}{"type": "string", "format": "email"}, "string"},
+		{"uri", map[string]interface{}{"type": "string", "format": "uri"}, "string"},
+		{"byte", map[string]interface{}{"type": "string", "format": "byte"}, "string"},
+		{"binary", map[string]interface{}{"type": "string", "format": "binary"}, "string"},
+		{"password", map[string]interface{}{"type": "string", "format": "password"}, "string"},
+		{"integer", map[string]interface{}{"type": "integer"}, "int64"},
+		{"int32", map[string]interface{}{"type": "integer", "format": "int32"}, "int32"},
+		{"int64", map[string]interface{}{"type": "integer", "format": "int64"}, "int64"},
+		{"number", map[string]interface{}{"type": "number"}, "float64"},
+		{"float", map[string]interface{}{"type": "number", "format": "float"}, "float32"},
+		{"double", map[string]interface{}{"type": "number", "format": "double"}, "float64"},
+		{"boolean", map[string]interface{}{"type": "boolean"}, "bool"},
+		{"array", map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer", "format": "int32"}}, "[]int32"},
+		{"array without items", map[string]interface{}{"type": "array"}, "[]interface{}"},
+		{"nested array", map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}}, "[][]string"},
+		{"object", map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}, "map[string]interface{}"},
+		{"map", map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "boolean"}}, "map[string]bool"},
+		{"open object", map[string]interface{}{"type": "object", "additionalProperties": true}, "map[string]interface{}"},
+		{"nullable", map[string]interface{}{"type": []string{"null", "integer"}}, "int64"},
+		{"nullable from JSON", map[string]interface{}{"type": []interface{}{"number", "null"}}, "float64"},
+		{"only null", map[string]interface{}{"type": []string{"null"}}, "interface{}"},
+		{"no type", map[string]interface{}{}, "interface{}"},
+		{"nil", nil, "interface{}"},
+		{"unknown", map[string]interface{}{"type": "file"}, "interface{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, goType(tt.schema))
+		})
+	}
+}
+
+func TestAPIName(t *testing.T) {
+	tests := map[string]string{
+		"User Management API": "User Management",
+		"Petstore":            "Petstore",
+		"Payments api ":       "Payments",
+		"RAPI":                "RAPI",
+		"API":                 "API",
+		" API":                "API",
+		"OpenAPI":             "OpenAPI",
+		"":                    "",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, apiName(in), "apiName(%q)", in)
+	}
+}
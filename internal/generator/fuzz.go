@@ -0,0 +1,466 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// Fuzzing defaults
+const (
+	// DefaultFuzzCases is the number of random payloads sent to each tool in
+	// addition to the boundary cases
+	DefaultFuzzCases = 25
+	// DefaultFuzzCallTimeout is how long a call may take before the server
+	// counts as hung
+	DefaultFuzzCallTimeout = 10 * time.Second
+	// DefaultFuzzTimeout bounds a whole fuzzing run
+	DefaultFuzzTimeout = 10 * time.Minute
+)
+
+// FuzzOptions controls a fuzzing run against a generated server
+type FuzzOptions struct {
+	// Dir is the directory containing the generated server
+	Dir string
+	// Tools limits the run to the named tools; empty fuzzes every tool
+	Tools []string
+	// Cases defaults to DefaultFuzzCases
+	Cases int
+	// Seed makes the random payloads reproducible; zero picks one, which is
+	// reported in the result
+	Seed int64
+	// CallTimeout defaults to DefaultFuzzCallTimeout and Timeout to
+	// DefaultFuzzTimeout
+	CallTimeout time.Duration
+	Timeout     time.Duration
+	// Env holds extra NAME=value variables for the server, e.g. credentials
+	Env []string
+	// Image, when set, compiles and runs the server in disposable docker
+	// containers of this image instead of with the host toolchain
+	Image string
+}
+
+// FuzzFailureKind classifies how a server mishandled a payload
+type FuzzFailureKind string
+
+// Fuzz failure kinds
+const (
+	// FuzzCrash marks payloads after which the server exited
+	FuzzCrash FuzzFailureKind = "crash"
+	// FuzzHang marks payloads the server did not answer in time
+	FuzzHang FuzzFailureKind = "hang"
+	// FuzzInvalidReply marks answers that are neither a JSON-RPC error nor a
+	// well-formed tool result
+	FuzzInvalidReply FuzzFailureKind = "invalid_reply"
+)
+
+// FuzzFailure is a payload the server did not answer with a structured
+// result or error
+type FuzzFailure struct {
+	Tool      string
+	Kind      FuzzFailureKind
+	Arguments json.RawMessage
+	Message   string
+	// Stderr is the end of the server's log when it crashed
+	Stderr string
+}
+
+// FuzzToolResult counts the outcomes of the payloads sent to one tool
+type FuzzToolResult struct {
+	Name  string
+	Cases int
+	// Accepted payloads got a successful result; Rejected ones got a
+	// JSON-RPC error or an error result
+	Accepted int
+	Rejected int
+	Failed   int
+}
+
+// FuzzResult describes a fuzzing run
+type FuzzResult struct {
+	Seed     int64
+	Tools    []FuzzToolResult
+	Failures []FuzzFailure
+	// Restarts counts servers relaunched after a crash or hang
+	Restarts int
+	Duration time.Duration
+}
+
+// FuzzServer sends each tool of a generated server boundary and random
+// arguments derived from its input schema: missing, null and mistyped
+// values, extreme numbers, long and unusual strings and deep nesting. Every
+// call must be answered with a JSON-RPC error or a tool result; a crash,
+// hang or malformed answer is a failure, after which the server is
+// restarted. Upstream requests go to a local stand-in API.
+func (s *Service) FuzzServer(ctx context.Context, opts FuzzOptions) (*FuzzResult, error) {
+	start := time.Now()
+	cases := opts.Cases
+	if cases <= 0 {
+		cases = DefaultFuzzCases
+	}
+	callTimeout := opts.CallTimeout
+	if callTimeout <= 0 {
+		callTimeout = DefaultFuzzCallTimeout
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultFuzzTimeout
+	}
+	result := &FuzzResult{Seed: opts.Seed}
+	if result.Seed == 0 {
+		result.Seed = time.Now().UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(result.Seed))
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	harness, err := newProbeHarness(opts.Dir, opts.Env, FixtureOptions{}, opts.Image)
+	if err != nil {
+		return nil, err
+	}
+	defer harness.close()
+
+	server, schemas, err := startClient(ctx, harness, callTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		server.kill()
+		server.stop()
+	}()
+	// restart replaces a server whose session can no longer be trusted and
+	// returns the end of its log. A crashed server is left to exit, so that
+	// its log is complete.
+	restart := func(crashed bool) (string, error) {
+		if !crashed {
+			server.kill()
+		}
+		server.stop()
+		stderr := tail(server.stderr.String(), 2048)
+		next, _, err := startClient(ctx, harness, callTimeout)
+		if err != nil {
+			return stderr, err
+		}
+		server = next
+		result.Restarts++
+		return stderr, nil
+	}
+
+	names := opts.Tools
+	if len(names) == 0 {
+		for name := range schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		if _, ok := schemas[name]; !ok {
+			return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("the server has no tool %q", name), nil)
+		}
+	}
+
+	for _, name := range names {
+		tool := FuzzToolResult{Name: name}
+		for _, args := range fuzzPayloads(schemas[name], cases, rnd) {
+			if ctx.Err() != nil {
+				break
+			}
+			tool.Cases++
+			failure, rejected := callToolChecked(server.probeSession, name, args)
+			switch {
+			case failure == nil && rejected:
+				tool.Rejected++
+				continue
+			case failure == nil:
+				tool.Accepted++
+				continue
+			}
+
+			tool.Failed++
+			stderr, err := restart(failure.Kind == FuzzCrash)
+			if failure.Kind == FuzzCrash {
+				failure.Stderr = stderr
+			}
+			result.Failures = append(result.Failures, *failure)
+			if err != nil {
+				return result, err
+			}
+		}
+
+		if ctx.Err() == nil {
+			if err := server.call("ping", nil, nil); err != nil {
+				tool.Failed++
+				result.Failures = append(result.Failures, FuzzFailure{
+					Tool:    name,
+					Kind:    FuzzHang,
+					Message: fmt.Sprintf("unresponsive after fuzzing: %v", err),
+				})
+				if _, err := restart(false); err != nil {
+					return result, err
+				}
+			}
+		}
+		result.Tools = append(result.Tools, tool)
+	}
+	result.Duration = time.Since(start)
+
+	if ctx.Err() != nil {
+		return result, common.NewError(common.ErrorTypeGeneration, "fuzzing did not finish in time", ctx.Err()).
+			WithSuggestion(fmt.Sprintf("The run gives up after %s; fuzz fewer tools or cases", timeout))
+	}
+	if len(result.Failures) > 0 {
+		total := 0
+		for _, tool := range result.Tools {
+			total += tool.Cases
+		}
+		return result, common.NewError(common.ErrorTypeValidation,
+			fmt.Sprintf("server mishandled %d of %d fuzz cases", len(result.Failures), total), nil).
+			WithSuggestion(fmt.Sprintf("Reproduce the run with seed %d", result.Seed))
+	}
+	return result, nil
+}
+
+// callToolChecked sends one payload. It returns the failure, if the server
+// mishandled it, and whether the server rejected it.
+func callToolChecked(session *probeSession, tool string, args json.RawMessage) (*FuzzFailure, bool) {
+	var called struct {
+		Content []struct {
+			Type string `json:"type"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	err := session.call("tools/call", map[string]interface{}{"name": tool, "arguments": args}, &called)
+
+	failure := &FuzzFailure{Tool: tool, Arguments: args, Kind: FuzzInvalidReply}
+	var rpcErr *probeRPCError
+	switch {
+	case err == nil && len(called.Content) == 0:
+		failure.Message = "result has no content"
+	case err == nil:
+		return nil, called.IsError
+	case errors.As(err, &rpcErr):
+		return nil, true
+	case errors.Is(err, errServerClosed):
+		failure.Kind, failure.Message = FuzzCrash, err.Error()
+	case errors.Is(err, errNoReply):
+		failure.Kind, failure.Message = FuzzHang, err.Error()
+	default:
+		failure.Message = err.Error()
+	}
+	return failure, false
+}
+
+// fuzzPayloads returns the arguments sent to a tool: malformed argument
+// objects, the example arguments with each property replaced by its
+// boundary values or removed, and then random values for the schema
+func fuzzPayloads(schema map[string]interface{}, cases int, rnd *rand.Rand) []json.RawMessage {
+	var payloads []json.RawMessage
+	add := func(v interface{}) {
+		if raw, err := json.Marshal(v); err == nil {
+			payloads = append(payloads, raw)
+		}
+	}
+
+	example, _ := exampleValue(schema, 0).(map[string]interface{})
+	if example == nil {
+		example = map[string]interface{}{}
+	}
+	add(nil)
+	add([]interface{}{})
+	add("arguments")
+	add(map[string]interface{}{})
+	add(example)
+	add(with(example, "mcpweaver_unknown", true))
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, _ := properties[name].(map[string]interface{})
+		without := with(example, name, nil)
+		delete(without, name)
+		add(without)
+		for _, value := range boundaryValues(prop) {
+			add(with(example, name, value))
+		}
+	}
+
+	for i := 0; i < cases; i++ {
+		add(randomValue(schema, rnd, 0))
+	}
+	return payloads
+}
+
+// with returns a copy of args with name set to value
+func with(args map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(args)+1)
+	for k, v := range args {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+// boundaryValues lists values at and beyond the edges of a schema, including
+// one value of every other JSON type
+func boundaryValues(schema map[string]interface{}) []interface{} {
+	values := []interface{}{nil}
+	schemaType, _ := schema["type"].(string)
+	if _, ok := schema["enum"]; ok {
+		values = append(values, "mcpweaver-not-allowed")
+	}
+	switch schemaType {
+	case "string":
+		values = append(values, "", strings.Repeat("A", 64<<10), "\x00\u202e\ufffd\U0001F600",
+			"../../../etc/passwd", "a/b?c=d&e=f#g %2F", "' OR '1'='1", 12345, true)
+	case "integer":
+		values = append(values, 0, -1, int64(math.MaxInt64), int64(math.MinInt64), 1e308, 1.5, "1", true)
+	case "number":
+		values = append(values, 0, -1e308, 1e308, 5e-324, "1.5", true)
+	case "boolean":
+		values = append(values, "true", 0, 1)
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		many := make([]interface{}, 1000)
+		for i := range many {
+			many[i] = exampleValue(items, 1)
+		}
+		values = append(values, []interface{}{}, []interface{}{nil}, many, "item", map[string]interface{}{})
+	default:
+		values = append(values, map[string]interface{}{}, deepObject(100), []interface{}{}, "object", 1)
+	}
+	return values
+}
+
+// deepObject nests an object depth levels deep
+func deepObject(depth int) map[string]interface{} {
+	object := map[string]interface{}{}
+	for i := 0; i < depth; i++ {
+		object = map[string]interface{}{"a": object}
+	}
+	return object
+}
+
+// fuzzRunes mixes ordinary characters with ones that need escaping in URLs,
+// JSON and shells
+var fuzzRunes = []rune("abcXYZ019 -_./?#&=%+:;'\"\\<>{}[]|$`~\t\n\u00e9\u4e2d\u202e\ufffd\U0001F600")
+
+// randomValue returns a random value that mostly, but not always, follows
+// the schema
+func randomValue(schema map[string]interface{}, rnd *rand.Rand, depth int) interface{} {
+	if rnd.Intn(10) == 0 || depth > 4 {
+		return randomAny(rnd, depth)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 && rnd.Intn(4) > 0 {
+		return enum[rnd.Intn(len(enum))]
+	}
+
+	schemaType, _ := schema["type"].(string)
+	switch schemaType {
+	case "string":
+		return randomString(rnd)
+	case "integer":
+		switch rnd.Intn(4) {
+		case 0:
+			return rnd.Int63() - rnd.Int63()
+		case 1:
+			return -rnd.Intn(1000)
+		}
+		return rnd.Intn(1000)
+	case "number":
+		return rnd.NormFloat64() * math.Pow(10, float64(rnd.Intn(20)))
+	case "boolean":
+		return rnd.Intn(2) == 0
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		values := make([]interface{}, rnd.Intn(6))
+		for i := range values {
+			values[i] = randomValue(items, rnd, depth+1)
+		}
+		return values
+	}
+
+	object := map[string]interface{}{}
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	for _, name := range requiredNames(schema) {
+		required[name] = true
+	}
+	// Properties are visited in order, so a seed always gives the same values
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		keep := 7
+		if required[name] {
+			keep = 9
+		}
+		if rnd.Intn(10) < keep {
+			propSchema, _ := properties[name].(map[string]interface{})
+			object[name] = randomValue(propSchema, rnd, depth+1)
+		}
+	}
+	return object
+}
+
+// randomAny returns a random value of any JSON type
+func randomAny(rnd *rand.Rand, depth int) interface{} {
+	kind := rnd.Intn(6)
+	if depth > 4 {
+		kind = rnd.Intn(4)
+	}
+	switch kind {
+	case 0:
+		return nil
+	case 1:
+		return rnd.Intn(2) == 0
+	case 2:
+		return rnd.NormFloat64() * 1e6
+	case 3:
+		return randomString(rnd)
+	case 4:
+		values := make([]interface{}, rnd.Intn(4))
+		for i := range values {
+			values[i] = randomAny(rnd, depth+1)
+		}
+		return values
+	}
+	object := map[string]interface{}{}
+	for i := rnd.Intn(4); i > 0; i-- {
+		object[randomString(rnd)] = randomAny(rnd, depth+1)
+	}
+	return object
+}
+
+func randomString(rnd *rand.Rand) string {
+	n := rnd.Intn(32)
+	if rnd.Intn(20) == 0 {
+		n = 4096 + rnd.Intn(4096)
+	}
+	runes := make([]rune, n)
+	for i := range runes {
+		runes[i] = fuzzRunes[rnd.Intn(len(fuzzRunes))]
+	}
+	return string(runes)
+}
+
+// tail returns at most the last n bytes of s
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
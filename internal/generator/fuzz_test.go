@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fuzzSchema is the input schema of a tool with a property of every type
+var fuzzSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []interface{}{"id"},
+	"properties": map[string]interface{}{
+		"id":     map[string]interface{}{"type": "string", "example": "7"},
+		"limit":  map[string]interface{}{"type": "integer"},
+		"ratio":  map[string]interface{}{"type": "number"},
+		"active": map[string]interface{}{"type": "boolean"},
+		"status": map[string]interface{}{"type": "string", "enum": []interface{}{"open", "closed"}},
+		"tags":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		"filter": map[string]interface{}{"type": "object", "properties": map[string]interface{}{"q": map[string]interface{}{"type": "string"}}},
+	},
+}
+
+func TestFuzzPayloads(t *testing.T) {
+	payloads := fuzzPayloads(fuzzSchema, 10, rand.New(rand.NewSource(42)))
+
+	// Malformed argument objects come first, then the example arguments
+	var example map[string]interface{}
+	require.NoError(t, json.Unmarshal(payloads[4], &example))
+	assert.Equal(t, "7", example["id"])
+	assert.Equal(t, []string{`null`, `[]`, `"arguments"`, `{}`}, []string{
+		string(payloads[0]), string(payloads[1]), string(payloads[2]), string(payloads[3])})
+	var unknown map[string]interface{}
+	require.NoError(t, json.Unmarshal(payloads[5], &unknown))
+	assert.Equal(t, true, unknown["mcpweaver_unknown"])
+
+	// Each property is removed once and set to each of its boundary values
+	expected := 6 + 10
+	for _, prop := range fuzzSchema["properties"].(map[string]interface{}) {
+		expected += 1 + len(boundaryValues(prop.(map[string]interface{})))
+	}
+	assert.Len(t, payloads, expected)
+	withoutID := 0
+	for _, payload := range payloads {
+		var args map[string]interface{}
+		if json.Unmarshal(payload, &args) == nil && args != nil {
+			if _, ok := args["id"]; !ok {
+				withoutID++
+			}
+		}
+	}
+	assert.Positive(t, withoutID, "the required property is left out")
+
+	// A seed reproduces the payloads exactly
+	again := fuzzPayloads(fuzzSchema, 10, rand.New(rand.NewSource(42)))
+	assert.Equal(t, payloads, again)
+	other := fuzzPayloads(fuzzSchema, 10, rand.New(rand.NewSource(43)))
+	assert.Equal(t, payloads[:expected-10], other[:expected-10], "only the random cases depend on the seed")
+	assert.NotEqual(t, payloads[expected-10:], other[expected-10:])
+}
+
+func TestBoundaryValues(t *testing.T) {
+	tests := []struct {
+		schema   map[string]interface{}
+		includes []interface{}
+	}{
+		{map[string]interface{}{"type": "string"}, []interface{}{nil, "", "../../../etc/passwd", 12345, true}},
+		{map[string]interface{}{"type": "string", "enum": []interface{}{"a"}}, []interface{}{"mcpweaver-not-allowed"}},
+		{map[string]interface{}{"type": "integer"}, []interface{}{0, -1, int64(math.MaxInt64), int64(math.MinInt64), 1.5, "1"}},
+		{map[string]interface{}{"type": "number"}, []interface{}{-1e308, 1e308, 5e-324, "1.5"}},
+		{map[string]interface{}{"type": "boolean"}, []interface{}{"true", 0, 1}},
+		{map[string]interface{}{"type": "array"}, []interface{}{[]interface{}{}, []interface{}{nil}, "item"}},
+		{map[string]interface{}{"type": "object"}, []interface{}{map[string]interface{}{}, []interface{}{}, "object", 1}},
+	}
+	for _, tt := range tests {
+		values := boundaryValues(tt.schema)
+		assert.Nil(t, values[0], "null is always tried")
+		for _, value := range tt.includes {
+			assert.Contains(t, values, value, "%v", tt.schema)
+		}
+	}
+
+	var long string
+	for _, value := range boundaryValues(map[string]interface{}{"type": "string"}) {
+		if s, ok := value.(string); ok && len(s) > len(long) {
+			long = s
+		}
+	}
+	assert.Len(t, long, 64<<10)
+
+	depth := 0
+	for object := deepObject(100); object["a"] != nil; object = object["a"].(map[string]interface{}) {
+		depth++
+	}
+	assert.Equal(t, 100, depth)
+}
+
+func TestRandomValue(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	typed, enumerated := 0, 0
+	for i := 0; i < 500; i++ {
+		value := randomValue(fuzzSchema, rnd, 0)
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		typed++
+		if status, ok := object["status"].(string); ok && (status == "open" || status == "closed") {
+			enumerated++
+		}
+		assert.LessOrEqual(t, nesting(value), 7, "values are not nested without bound")
+	}
+	assert.Greater(t, typed, 400, "values mostly follow the schema")
+	assert.Greater(t, enumerated, 150, "enum values are mostly picked from the enum")
+}
+
+// nesting returns how deeply arrays and objects are nested in v
+func nesting(v interface{}) int {
+	deepest := 0
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			deepest = max(deepest, nesting(child))
+		}
+	case []interface{}:
+		for _, child := range v {
+			deepest = max(deepest, nesting(child))
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
+
+func TestCallToolChecked(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		close    bool
+		kind     FuzzFailureKind
+		message  string
+		rejected bool
+	}{
+		{name: "accepted", reply: `{"jsonrpc": "2.0", "id": 1, "result": {"content": [{"type": "text", "text": "ok"}]}}`},
+		{name: "error result", reply: `{"jsonrpc": "2.0", "id": 1, "result": {"content": [{"type": "text", "text": "bad"}], "isError": true}}`, rejected: true},
+		{name: "JSON-RPC error", reply: `{"jsonrpc": "2.0", "id": 1, "error": {"code": -32602, "message": "invalid params"}}`, rejected: true},
+		{name: "no content", reply: `{"jsonrpc": "2.0", "id": 1, "result": {}}`, kind: FuzzInvalidReply, message: "result has no content"},
+		{name: "not JSON", reply: `panic: runtime error`, kind: FuzzInvalidReply, message: "reply is not JSON"},
+		{name: "wrong ID", reply: `{"jsonrpc": "2.0", "id": 2, "result": {"content": [{"type": "text"}]}}`, kind: FuzzInvalidReply, message: "reply has ID 2, expected 1"},
+		{name: "both result and error", reply: `{"jsonrpc": "2.0", "id": 1, "result": {}, "error": {"code": 1, "message": "x"}}`, kind: FuzzInvalidReply, message: "both a result and an error"},
+		{name: "crash", close: true, kind: FuzzCrash, message: "server closed its output"},
+		{name: "hang", kind: FuzzHang, message: "no reply within"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make(chan []byte, 1)
+			switch {
+			case tt.close:
+				close(lines)
+			case tt.reply != "":
+				lines <- []byte(tt.reply)
+			}
+			session := &probeSession{ctx: context.Background(), stdin: &discardWriter{}, lines: lines, callTimeout: 50 * time.Millisecond}
+			args := json.RawMessage(`{"id": "7"}`)
+
+			failure, rejected := callToolChecked(session, "get_user", args)
+			assert.Equal(t, tt.rejected, rejected)
+			if tt.kind == "" {
+				assert.Nil(t, failure)
+				return
+			}
+			require.NotNil(t, failure)
+			assert.Equal(t, tt.kind, failure.Kind)
+			assert.Contains(t, failure.Message, tt.message)
+			assert.Equal(t, "get_user", failure.Tool)
+			assert.Equal(t, args, failure.Arguments, "failures keep the payload to reproduce them")
+		})
+	}
+}
+
+// discardWriter accepts the requests of a session without a server
+type discardWriter struct{}
+
+func (*discardWriter) Write(p []byte) (int, error) { return len(p), nil }
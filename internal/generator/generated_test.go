@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testGenerated generates the server of users.yaml with opts, adds the
+// tests named from testdata/servertests and runs them with go test in the
+// generated module
+func testGenerated(t *testing.T, opts Options, tests ...string) {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not on PATH")
+	}
+	dir := t.TempDir()
+	opts.OutputDir = dir
+	opts.SkipVet = true
+	_, err := NewService().Generate(testServer(t, "users.yaml"), opts)
+	require.NoError(t, err)
+	for _, name := range tests {
+		content, err := os.ReadFile(filepath.Join("testdata", "servertests", name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0644))
+	}
+
+	cmd := exec.Command("go", "test", "-count=1", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOTOOLCHAIN=local")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "tests of the generated server failed:\n%s", output)
+}
+
+func TestGeneratedRateLimiting(t *testing.T) {
+	testGenerated(t, Options{RateLimiting: true, Retries: true}, "ratelimit_test.go")
+}
+
+func TestGeneratedConfig(t *testing.T) {
+	testGenerated(t, Options{RateLimiting: true, Retries: true}, "config_test.go")
+}
+
+func TestGeneratedValidation(t *testing.T) {
+	testGenerated(t, Options{Validation: true}, "validation_test.go")
+}
@@ -0,0 +1,252 @@
+package generator
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// EventTemplateRepoSynced is the name of events emitted by WatchTemplateRepo
+const EventTemplateRepoSynced = "template:repo_synced"
+
+// DefaultRepoSyncInterval is how often WatchTemplateRepo checks for new tags
+const DefaultRepoSyncInterval = time.Hour
+
+// mirrorsDir holds the git mirrors of template repositories inside the
+// packages directory; it is not a valid package name
+const mirrorsDir = ".git"
+
+// GitSource is a git repository of template packages. Every tag naming a
+// semantic version, optionally prefixed with v, is a version of the package.
+type GitSource struct {
+	URL string
+	// Dir is the package directory within the repository; empty for the root
+	Dir string
+}
+
+// RepoSyncResult describes a synchronization of a template repository
+type RepoSyncResult struct {
+	Source GitSource
+	// Versions lists every version tagged in the repository
+	Versions []string
+	// Installed lists the versions installed by this synchronization
+	Installed []InstalledPackage
+	// Warnings explain tags that were skipped
+	Warnings []string
+}
+
+// RepoSyncEvent carries the result of a periodic synchronization
+type RepoSyncEvent struct {
+	Name   string
+	Result *RepoSyncResult
+	Err    error
+}
+
+// SyncTemplateRepo clones or updates a mirror of the repository and installs
+// every tagged version not yet in the packages directory as
+// {name}/{version}, where name comes from the package manifest. Installed
+// versions can be used as dependencies or as template directories.
+func (s *Service) SyncTemplateRepo(ctx context.Context, src GitSource, packagesDir string) (*RepoSyncResult, error) {
+	if packagesDir == "" {
+		packagesDir = DefaultPackagesDir()
+	}
+	// git would read a URL starting with a dash as an option, such as
+	// --upload-pack running a command of the URL's choosing
+	if src.URL == "" || strings.HasPrefix(src.URL, "-") {
+		return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid template repository URL %q", src.URL), nil).
+			WithSuggestion("Give the URL of a git repository, such as https://github.com/acme/mcp-templates.git")
+	}
+	if src.Dir != "" {
+		src.Dir = path.Clean(filepath.ToSlash(src.Dir))
+		if !fs.ValidPath(src.Dir) {
+			return nil, common.NewError(common.ErrorTypeValidation,
+				fmt.Sprintf("package directory %q must be relative to the repository", src.Dir), nil)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(src.URL))
+	mirror := filepath.Join(packagesDir, mirrorsDir, hex.EncodeToString(sum[:8])+".git")
+	if _, err := os.Stat(mirror); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(mirror), 0755); err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to create packages directory", err).
+				WithFile(filepath.Dir(mirror))
+		}
+		if _, err := runGit(ctx, "", "clone", "--mirror", "--quiet", "--", src.URL, mirror); err != nil {
+			os.RemoveAll(mirror)
+			return nil, common.NewError(common.ErrorTypeNetwork, "failed to clone template repository", err).
+				WithFile(src.URL)
+		}
+	} else if _, err := runGit(ctx, mirror, "fetch", "--prune", "--tags", "--quiet", "origin"); err != nil {
+		return nil, common.NewError(common.ErrorTypeNetwork, "failed to update template repository", err).
+			WithFile(src.URL)
+	}
+
+	tags, err := runGit(ctx, mirror, "tag", "--list")
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to list repository tags", err).WithFile(src.URL)
+	}
+	result := &RepoSyncResult{Source: src}
+	var versionTags []string
+	for _, tag := range strings.Fields(string(tags)) {
+		if packageVersionPattern.MatchString(strings.TrimPrefix(tag, "v")) {
+			versionTags = append(versionTags, tag)
+		}
+	}
+	sort.Slice(versionTags, func(i, j int) bool {
+		return compareVersions(strings.TrimPrefix(versionTags[i], "v"), strings.TrimPrefix(versionTags[j], "v")) < 0
+	})
+
+	for _, tag := range versionTags {
+		version := strings.TrimPrefix(tag, "v")
+		result.Versions = append(result.Versions, version)
+
+		manifestPath := manifestFile
+		if src.Dir != "" {
+			manifestPath = path.Join(src.Dir, manifestFile)
+		}
+		content, err := runGit(ctx, mirror, "show", tag+":"+manifestPath)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("tag %s has no %s", tag, manifestPath))
+			continue
+		}
+		m, err := parseManifest(content)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("tag %s: invalid manifest: %v", tag, err))
+			continue
+		}
+		if !packageNamePattern.MatchString(m.Name) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("tag %s: invalid package name %q", tag, m.Name))
+			continue
+		}
+		if m.Version != "" && m.Version != version {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("tag %s: manifest declares version %s", tag, m.Version))
+			continue
+		}
+
+		dest := filepath.Join(packagesDir, m.Name, version)
+		if _, err := os.Stat(dest); err == nil {
+			continue
+		}
+		archive, err := runGit(ctx, mirror, "archive", "--format=tar", tag, "--", treeOf(src.Dir))
+		if err != nil {
+			return result, common.NewError(common.ErrorTypeGeneration, fmt.Sprintf("failed to read tag %s", tag), err).
+				WithFile(src.URL)
+		}
+		files, err := untarPackage(archive, src.Dir)
+		if err != nil {
+			return result, common.NewError(common.ErrorTypeGeneration, fmt.Sprintf("failed to read tag %s", tag), err).
+				WithFile(src.URL)
+		}
+		if err := installFiles(packagesDir, m.Name, version, files); err != nil {
+			return result, err
+		}
+		result.Installed = append(result.Installed, InstalledPackage{Name: m.Name, Version: version, Dir: dest, Downloaded: true})
+	}
+	return result, nil
+}
+
+// WatchTemplateRepo synchronizes the repository immediately and then every
+// interval, or DefaultRepoSyncInterval when zero, passing each result to
+// emit. It returns when ctx is done.
+func (s *Service) WatchTemplateRepo(ctx context.Context, src GitSource, packagesDir string, interval time.Duration, emit func(RepoSyncEvent)) error {
+	if interval <= 0 {
+		interval = DefaultRepoSyncInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := s.SyncTemplateRepo(ctx, src, packagesDir)
+		if ctx.Err() != nil {
+			return nil
+		}
+		emit(RepoSyncEvent{Name: EventTemplateRepoSynced, Result: result, Err: err})
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// treeOf returns the git pathspec of a package directory
+func treeOf(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// untarPackage reads the regular files of a git archive below dir, keyed by
+// their path relative to it
+func untarPackage(archive []byte, dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := header.Name
+		if dir != "" {
+			var ok bool
+			if name, ok = strings.CutPrefix(name, dir+"/"); !ok {
+				continue
+			}
+		}
+		if !fs.ValidPath(name) {
+			return nil, fmt.Errorf("unsafe archive entry %q", header.Name)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxPackageSize))
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+}
+
+// runGit runs git, in the repository gitDir when set, without prompting for
+// credentials
+func runGit(ctx context.Context, gitDir string, args ...string) ([]byte, error) {
+	gitTool, err := exec.LookPath("git")
+	if err != nil {
+		return nil, errors.New("git not found on PATH")
+	}
+	if gitDir != "" {
+		args = append([]string{"--git-dir", gitDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, gitTool, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.New(message)
+		}
+		return nil, err
+	}
+	return output, nil
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncTemplateRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not on PATH")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, output)
+	}
+	writePackage := func(version, readme string) {
+		t.Helper()
+		dir := filepath.Join(repo, "templates")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		manifest := `{"name": "acme-go", "version": "` + version + `", "files": [{"template": "README.md.tmpl", "output": "README.md"}]}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(manifest), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md.tmpl"), []byte(readme), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, "NOTES"), []byte(version), 0644))
+		git("add", "-A")
+		git("commit", "--quiet", "-m", version)
+	}
+
+	git("init", "--quiet")
+	writePackage("1.0.0", "# one\n")
+	git("tag", "v1.0.0")
+	writePackage("1.1.0", "# two\n")
+	git("tag", "v1.1.0")
+	git("tag", "not-a-version")
+	writePackage("9.9.9", "# wrong\n")
+	git("tag", "2.0.0")
+
+	packages := t.TempDir()
+	src := GitSource{URL: repo, Dir: "templates"}
+	result, err := NewService().SyncTemplateRepo(context.Background(), src, packages)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0", "2.0.0"}, result.Versions)
+	require.Len(t, result.Installed, 2)
+	assert.Equal(t, "1.0.0", result.Installed[0].Version)
+	assert.Equal(t, "1.1.0", result.Installed[1].Version)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "manifest declares version 9.9.9")
+
+	readme, err := os.ReadFile(filepath.Join(packages, "acme-go", "1.1.0", "README.md.tmpl"))
+	require.NoError(t, err)
+	assert.Equal(t, "# two\n", string(readme))
+	assert.NoFileExists(t, filepath.Join(packages, "acme-go", "1.1.0", "NOTES"), "only the package directory is installed")
+
+	// A new tag is picked up from the mirror; installed versions are kept
+	writePackage("1.2.0", "# three\n")
+	git("tag", "v1.2.0")
+	result, err = NewService().SyncTemplateRepo(context.Background(), src, packages)
+	require.NoError(t, err)
+	require.Len(t, result.Installed, 1)
+	assert.Equal(t, "1.2.0", result.Installed[0].Version)
+
+	_, err = NewService().SyncTemplateRepo(context.Background(), GitSource{URL: repo, Dir: "../outside"}, packages)
+	assert.Error(t, err, "the package directory stays inside the repository")
+}
+
+func TestSyncTemplateRepoOptionURL(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not on PATH")
+	}
+	marker := filepath.Join(t.TempDir(), "pwned")
+	for _, url := range []string{"--upload-pack=touch " + marker, "-u touch " + marker, ""} {
+		_, err := NewService().SyncTemplateRepo(context.Background(), GitSource{URL: url}, t.TempDir())
+		assert.ErrorContains(t, err, "invalid template repository URL", url)
+	}
+	assert.NoFileExists(t, marker, "git never runs a command given as the URL")
+}
@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/parser"
+	"MCPWeaver/internal/transformer"
+)
+
+// testServer maps the specification in testdata to an MCP server
+func testServer(t *testing.T, spec string) *transformer.MCPServer {
+	t.Helper()
+	parsed, err := parser.NewService().ParseFile("testdata/" + spec)
+	require.NoError(t, err)
+	server, err := transformer.NewService().Transform(parsed)
+	require.NoError(t, err)
+	return server
+}
+
+// generateTestServer writes the server of the specification in testdata to
+// a temporary directory; tests that compile it are skipped without Go
+func generateTestServer(t *testing.T, spec string) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not on PATH")
+	}
+	dir := t.TempDir()
+	_, err := NewService().Generate(testServer(t, spec), Options{OutputDir: dir, SkipVet: true})
+	require.NoError(t, err)
+	return dir
+}
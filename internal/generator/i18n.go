@@ -0,0 +1,258 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+
+	"MCPWeaver/internal/common"
+)
+
+// DefaultLanguage is the language of generated documentation unless
+// Options.Language selects another. Its messages are the fallback for keys
+// other locales do not translate.
+const DefaultLanguage = "en"
+
+// localesDir holds a template package's message catalogs, one
+// {language}.json object of key to message per language. Catalogs of all
+// layers are merged, so a custom package only needs the keys it changes.
+const localesDir = "locales"
+
+// errNoLocale is returned by t and tn outside of a localized render
+var errNoLocale = errors.New("translations are only available when rendering")
+
+// localeFuncs are the translation functions. Messages are fmt format strings
+// applied to any arguments:
+//
+//	t "key" args...        the message for key
+//	tn "key" count args... the message for key.one when count is 1 and
+//	                       key.other otherwise, with count as first argument
+func localeFuncs(messages, fallback map[string]string) template.FuncMap {
+	lookup := func(key string) (string, error) {
+		if message, ok := messages[key]; ok {
+			return message, nil
+		}
+		if message, ok := fallback[key]; ok {
+			return message, nil
+		}
+		return "", fmt.Errorf("no message for %q", key)
+	}
+	t := func(key string, args ...interface{}) (string, error) {
+		message, err := lookup(key)
+		if err != nil || len(args) == 0 {
+			return message, err
+		}
+		return fmt.Sprintf(message, args...), nil
+	}
+	return template.FuncMap{
+		"t": t,
+		"tn": func(key string, count int, args ...interface{}) (string, error) {
+			if count == 1 {
+				key += ".one"
+			} else {
+				key += ".other"
+			}
+			return t(key, append([]interface{}{count}, args...)...)
+		},
+	}
+}
+
+// messages merges the catalogs of a language across the layers, base first
+// so that more specific layers win. A language no layer provides yields an
+// empty catalog.
+func (s *templateSource) messages(language string) (map[string]string, error) {
+	name := path.Join(localesDir, language+".json")
+	messages := map[string]string{}
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		content, err := fs.ReadFile(s.layers[i].fsys, name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err == nil {
+			err = json.Unmarshal(content, &messages)
+		}
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read message catalog", err).
+				WithFile(s.layers[i].path(name))
+		}
+	}
+	return messages, nil
+}
+
+// languages lists the languages any layer has a catalog for
+func (s *templateSource) languages() ([]string, error) {
+	seen := map[string]bool{}
+	for _, layer := range s.layers {
+		names, err := fs.Glob(layer.fsys, path.Join(localesDir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			seen[strings.TrimSuffix(path.Base(name), ".json")] = true
+		}
+	}
+	languages := make([]string, 0, len(seen))
+	for language := range seen {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages, nil
+}
+
+// translations returns the translation functions for the language of the
+// options
+func (s *templateSource) translations(opts Options) (template.FuncMap, error) {
+	language := opts.Language
+	if language == "" {
+		language = DefaultLanguage
+	}
+	fallback, err := s.messages(DefaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+	if language == DefaultLanguage {
+		return localeFuncs(fallback, nil), nil
+	}
+
+	languages, err := s.languages()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to list message catalogs", err)
+	}
+	found := false
+	for _, l := range languages {
+		found = found || l == language
+	}
+	if !found {
+		err := common.NewError(common.ErrorTypeValidation, fmt.Sprintf("the template has no %q translation", language), nil)
+		if len(languages) > 0 {
+			err.WithSuggestion("Available languages: " + strings.Join(languages, ", "))
+		}
+		return nil, err
+	}
+	messages, err := s.messages(language)
+	if err != nil {
+		return nil, err
+	}
+	return localeFuncs(messages, fallback), nil
+}
+
+// Languages lists the languages the template package selected by the
+// options can generate documentation in
+func (s *Service) Languages(opts Options) ([]string, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	languages, err := source.languages()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to list message catalogs", err)
+	}
+	return languages, nil
+}
+
+// anchor returns the GitHub heading anchor of a Markdown heading
+func anchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// checkMessages reports literal message keys of t and tn that the default
+// catalog lacks, which fail the render, and keys other catalogs lack, which
+// fall back to the default language
+func checkMessages(source *templateSource, parsed []parsedTemplate) []TemplateIssue {
+	catalogFile := path.Join(localesDir, DefaultLanguage+".json")
+	fallback, err := source.messages(DefaultLanguage)
+	if err != nil {
+		return []TemplateIssue{{File: catalogFile, Severity: SeverityError, Message: err.Error()}}
+	}
+	languages, err := source.languages()
+	if err != nil {
+		return []TemplateIssue{{File: localesDir, Severity: SeverityError, Message: err.Error()}}
+	}
+
+	var issues []TemplateIssue
+	catalogs := map[string]map[string]string{}
+	for _, language := range languages {
+		if language == DefaultLanguage {
+			continue
+		}
+		messages, err := source.messages(language)
+		if err != nil {
+			issues = append(issues, TemplateIssue{
+				File:     path.Join(localesDir, language+".json"),
+				Severity: SeverityError,
+				Message:  err.Error(),
+			})
+			continue
+		}
+		catalogs[language] = messages
+	}
+
+	for _, p := range parsed {
+		// Each key is reported once per file, at its first use
+		reported := map[string]bool{}
+		for _, tree := range p.trees {
+			walkTree(tree.Root, func(node parse.Node) {
+				cmd, ok := node.(*parse.CommandNode)
+				if !ok || len(cmd.Args) < 2 {
+					return
+				}
+				fn, ok := cmd.Args[0].(*parse.IdentifierNode)
+				if !ok || (fn.Ident != "t" && fn.Ident != "tn") {
+					return
+				}
+				key, ok := cmd.Args[1].(*parse.StringNode)
+				if !ok {
+					return
+				}
+				keys := []string{key.Text}
+				if fn.Ident == "tn" {
+					keys = []string{key.Text + ".one", key.Text + ".other"}
+				}
+				for _, k := range keys {
+					if reported[k] {
+						continue
+					}
+					reported[k] = true
+					if _, ok := fallback[k]; !ok {
+						issues = append(issues, nodeIssue(p.file, tree, key, SeverityError,
+							fmt.Sprintf("message %q is missing from %s", k, catalogFile)))
+						continue
+					}
+					var missing []string
+					for _, language := range languages {
+						if messages, ok := catalogs[language]; ok {
+							if _, ok := messages[k]; !ok {
+								missing = append(missing, language)
+							}
+						}
+					}
+					if len(missing) > 0 {
+						issues = append(issues, nodeIssue(p.file, tree, key, SeverityWarning,
+							fmt.Sprintf("message %q is not translated to %s", k, strings.Join(missing, ", "))))
+					}
+				}
+			})
+		}
+	}
+	return issues
+}
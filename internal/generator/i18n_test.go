@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/common"
+)
+
+func TestLocalizedReadme(t *testing.T) {
+	server := testServer(t, "users.yaml")
+	readme := func(opts Options) string {
+		t.Helper()
+		event, err := NewService().Preview(server, opts)
+		require.NoError(t, err)
+		for _, file := range event.Files {
+			if file.Path == "README.md" {
+				return file.Content
+			}
+		}
+		require.Fail(t, "README.md not rendered")
+		return ""
+	}
+
+	english := readme(Options{})
+	assert.True(t, strings.HasPrefix(english, "# User Management API MCP Server\n"), english)
+	assert.Contains(t, english, "## Contents")
+	assert.Equal(t, english, readme(Options{Language: DefaultLanguage}))
+
+	german := readme(Options{Language: "de"})
+	assert.True(t, strings.HasPrefix(german, "# User Management API MCP-Server\n"), german)
+	assert.Contains(t, german, "## Inhalt")
+
+	// A package translating only some keys falls back to English for the rest
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, localesDir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, localesDir, "fr.json"), []byte(`{"readme.title": "Serveur MCP %s"}`), 0644))
+	languages, err := NewService().Languages(Options{TemplateDir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"de", "en", "fr", "ja"}, languages)
+	french := readme(Options{TemplateDir: dir, Language: "fr"})
+	assert.True(t, strings.HasPrefix(french, "# Serveur MCP User Management API\n"), french)
+	assert.Contains(t, french, "## Contents")
+
+	_, err = NewService().Preview(server, Options{Language: "xx"})
+	require.Error(t, err)
+	var unknown *common.Error
+	require.True(t, errors.As(err, &unknown))
+	assert.Equal(t, common.ErrorTypeValidation, unknown.Type)
+	assert.Contains(t, unknown.Suggestion, "de, en, ja")
+}
@@ -0,0 +1,219 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"MCPWeaver/internal/common"
+)
+
+// Lint rules applied by ValidateTemplates
+const (
+	// LintHardcodedURL flags absolute URLs outside Markdown outputs; base
+	// URLs belong in the template data or the generated configuration
+	LintHardcodedURL = "hardcoded-url"
+	// LintHeaderComment requires Go outputs to start with the generated
+	// code header
+	LintHeaderComment = "header-comment"
+	// LintUndocumentedVariable requires a comment before each {{$x := ...}}
+	LintUndocumentedVariable = "undocumented-variable"
+	// LintNestingDepth limits how deeply if, range and with blocks nest
+	LintNestingDepth = "nesting-depth"
+)
+
+// SeverityOff disables a lint rule
+const SeverityOff = "off"
+
+// DefaultMaxNesting is the nesting limit used when LintConfig leaves it unset
+const DefaultMaxNesting = 6
+
+// lintDefaults are the severities of the rules unless configured
+var lintDefaults = map[string]string{
+	LintHardcodedURL:         SeverityWarning,
+	LintHeaderComment:        SeverityWarning,
+	LintUndocumentedVariable: SeverityWarning,
+	LintNestingDepth:         SeverityWarning,
+}
+
+// LintConfig adjusts the lint rules of template validation
+type LintConfig struct {
+	// Severities overrides the severity of rules by name: SeverityError,
+	// SeverityWarning or SeverityOff
+	Severities map[string]string
+	// MaxNesting is the deepest allowed nesting of blocks; defaults to
+	// DefaultMaxNesting
+	MaxNesting int
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s"'` + "`" + `)<>]+`)
+
+// linter applies the configured rules to parsed templates
+type linter struct {
+	severities map[string]string
+	maxNesting int
+}
+
+func newLinter(config LintConfig) (*linter, error) {
+	l := &linter{severities: map[string]string{}, maxNesting: config.MaxNesting}
+	for rule, severity := range lintDefaults {
+		l.severities[rule] = severity
+	}
+	for rule, severity := range config.Severities {
+		if _, ok := lintDefaults[rule]; !ok {
+			rules := make([]string, 0, len(lintDefaults))
+			for name := range lintDefaults {
+				rules = append(rules, name)
+			}
+			sort.Strings(rules)
+			return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("unknown lint rule %q", rule), nil).
+				WithSuggestion("Use one of " + strings.Join(rules, ", "))
+		}
+		if severity != SeverityError && severity != SeverityWarning && severity != SeverityOff {
+			return nil, common.NewError(common.ErrorTypeValidation,
+				fmt.Sprintf("invalid severity %q for lint rule %s", severity, rule), nil).
+				WithSuggestion(fmt.Sprintf("Use %s, %s or %s", SeverityError, SeverityWarning, SeverityOff))
+		}
+		l.severities[rule] = severity
+	}
+	if l.maxNesting <= 0 {
+		l.maxNesting = DefaultMaxNesting
+	}
+	return l, nil
+}
+
+// lint checks a parsed file. output is the path the file generates, or ""
+// for partials.
+func (l *linter) lint(p parsedTemplate, output string) []TemplateIssue {
+	var issues []TemplateIssue
+	report := func(tree *parse.Tree, node parse.Node, rule, message string) {
+		severity := l.severities[rule]
+		if severity == SeverityOff {
+			return
+		}
+		issue := nodeIssue(p.file, tree, node, severity, message)
+		issue.Rule = rule
+		issues = append(issues, issue)
+	}
+
+	for _, tree := range p.trees {
+		if path.Ext(output) != ".md" {
+			walkTree(tree.Root, func(node parse.Node) {
+				if text, ok := node.(*parse.TextNode); ok {
+					for _, url := range urlPattern.FindAllString(string(text.Text), -1) {
+						report(tree, node, LintHardcodedURL, fmt.Sprintf("hardcoded URL %s", url))
+					}
+				}
+			})
+		}
+		l.lintVariables(tree, tree.Root, report)
+		l.lintNesting(tree, tree.Root, 0, report)
+	}
+
+	if path.Ext(output) == ".go" {
+		if tree := p.trees[p.file]; tree != nil && !hasHeader(tree.Root) {
+			report(tree, tree.Root, LintHeaderComment,
+				`Go output should start with {{template "header" .}} or a "// Code generated" comment`)
+		}
+	}
+	return issues
+}
+
+// lintVariables reports variable declarations not preceded by a comment,
+// ignoring the whitespace between them
+func (l *linter) lintVariables(tree *parse.Tree, node parse.Node, report func(*parse.Tree, parse.Node, string, string)) {
+	list, ok := node.(*parse.ListNode)
+	if !ok || list == nil {
+		return
+	}
+	documented := false
+	for _, child := range list.Nodes {
+		switch n := child.(type) {
+		case *parse.CommentNode:
+			documented = true
+			continue
+		case *parse.TextNode:
+			if strings.TrimSpace(string(n.Text)) == "" {
+				continue
+			}
+		case *parse.ActionNode:
+			if len(n.Pipe.Decl) > 0 && !n.Pipe.IsAssign && !documented {
+				report(tree, n, LintUndocumentedVariable,
+					fmt.Sprintf("variable %s is not documented by a preceding comment", n.Pipe.Decl[0].Ident[0]))
+			}
+		case *parse.IfNode:
+			l.lintBranchVariables(tree, &n.BranchNode, report)
+		case *parse.RangeNode:
+			l.lintBranchVariables(tree, &n.BranchNode, report)
+		case *parse.WithNode:
+			l.lintBranchVariables(tree, &n.BranchNode, report)
+		}
+		documented = false
+	}
+}
+
+func (l *linter) lintBranchVariables(tree *parse.Tree, branch *parse.BranchNode, report func(*parse.Tree, parse.Node, string, string)) {
+	l.lintVariables(tree, branch.List, report)
+	if branch.ElseList != nil {
+		l.lintVariables(tree, branch.ElseList, report)
+	}
+}
+
+// lintNesting reports blocks nested deeper than the limit, once per
+// outermost offending block
+func (l *linter) lintNesting(tree *parse.Tree, node parse.Node, depth int, report func(*parse.Tree, parse.Node, string, string)) {
+	var branch *parse.BranchNode
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			l.lintNesting(tree, child, depth, report)
+		}
+		return
+	case *parse.IfNode:
+		branch = &n.BranchNode
+	case *parse.RangeNode:
+		branch = &n.BranchNode
+	case *parse.WithNode:
+		branch = &n.BranchNode
+	default:
+		return
+	}
+
+	depth++
+	if depth > l.maxNesting {
+		report(tree, node, LintNestingDepth,
+			fmt.Sprintf("blocks are nested %d deep, more than the limit of %d", depth, l.maxNesting))
+		return
+	}
+	l.lintNesting(tree, branch.List, depth, report)
+	if branch.ElseList != nil {
+		l.lintNesting(tree, branch.ElseList, depth, report)
+	}
+}
+
+// hasHeader reports whether a template starts with the header partial or a
+// generated code comment
+func hasHeader(root *parse.ListNode) bool {
+	for _, node := range root.Nodes {
+		switch n := node.(type) {
+		case *parse.CommentNode:
+			continue
+		case *parse.TextNode:
+			text := strings.TrimSpace(string(n.Text))
+			if text == "" {
+				continue
+			}
+			return strings.HasPrefix(text, "// Code generated")
+		case *parse.TemplateNode:
+			return n.Name == "header"
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,377 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// Load test defaults
+const (
+	DefaultLoadClients  = 4
+	DefaultLoadDuration = 30 * time.Second
+	// DefaultLoadCallTimeout is how long a call may take before it counts as
+	// failed and its client stops
+	DefaultLoadCallTimeout = 30 * time.Second
+)
+
+// LoadOptions configures a load test of a generated server
+type LoadOptions struct {
+	// Dir is the directory containing the generated server
+	Dir string
+	// Clients is the number of concurrent MCP clients. Stdio servers serve a
+	// single client, so each one gets its own server process.
+	Clients int
+	// Duration is how long every client keeps calling tools once started
+	Duration time.Duration
+	// RampUp spreads the client starts evenly over this period
+	RampUp time.Duration
+	// Mix weights how often each tool is called; empty calls every tool
+	// equally
+	Mix map[string]int
+	// Arguments replaces the example arguments derived from the input schema
+	// of the named tools
+	Arguments map[string]map[string]interface{}
+	// UpstreamLatency delays every response of the stand-in API
+	UpstreamLatency time.Duration
+	// CallTimeout defaults to DefaultLoadCallTimeout
+	CallTimeout time.Duration
+	// Env holds extra NAME=value variables for the servers, e.g. credentials
+	Env []string
+	// Image, when set, compiles and runs the server in disposable docker
+	// containers of this image instead of with the host toolchain
+	Image string
+	// Thresholds fail the load test when exceeded
+	Thresholds LoadThresholds
+}
+
+// LoadThresholds are hard limits for a load test, e.g. to gate CI. Zero
+// values are not checked.
+type LoadThresholds struct {
+	MaxP95               time.Duration
+	MaxP99               time.Duration
+	MinRequestsPerSecond float64
+	// MaxErrorRate is the largest allowed share of failed requests
+	MaxErrorRate float64
+	// MaxPeakMemory is the largest allowed PeakMemory in bytes
+	MaxPeakMemory int64
+}
+
+// LatencyStats summarizes call latencies
+type LatencyStats struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// ToolLoadResult describes the calls made to one tool
+type ToolLoadResult struct {
+	Name     string
+	Requests int
+	// Errors counts JSON-RPC errors, error results and unanswered calls
+	Errors  int
+	Latency LatencyStats
+}
+
+// LoadResult describes a load test
+type LoadResult struct {
+	Clients  int
+	Requests int
+	Errors   int
+	// Duration is the wall time from the first client start to the last
+	// client finishing
+	Duration          time.Duration
+	RequestsPerSecond float64
+	Latency           LatencyStats
+	Tools             []ToolLoadResult
+	// ClientErrors explain clients that stopped early
+	ClientErrors []string
+	// PeakMemory is the largest resident memory of a server process in
+	// bytes; zero where the platform does not report it and for servers run
+	// in containers
+	PeakMemory int64
+	// Violations list the thresholds the load test exceeded
+	Violations []string
+}
+
+// loadSample is the outcome of one call
+type loadSample struct {
+	tool    string
+	latency time.Duration
+	failed  bool
+}
+
+// LoadTest runs concurrent MCP clients against a generated server, each
+// calling tools in the configured mix for the configured duration, and
+// reports throughput and latency. Upstream requests go to a local stand-in
+// API, so the results measure the server itself.
+func (s *Service) LoadTest(ctx context.Context, opts LoadOptions) (*LoadResult, error) {
+	clients := opts.Clients
+	if clients <= 0 {
+		clients = DefaultLoadClients
+	}
+	duration := opts.Duration
+	if duration <= 0 {
+		duration = DefaultLoadDuration
+	}
+	callTimeout := opts.CallTimeout
+	if callTimeout <= 0 {
+		callTimeout = DefaultLoadCallTimeout
+	}
+	if opts.RampUp < 0 {
+		return nil, common.NewError(common.ErrorTypeValidation, "ramp-up cannot be negative", nil)
+	}
+
+	harness, err := newProbeHarness(opts.Dir, opts.Env, FixtureOptions{}, opts.Image)
+	if err != nil {
+		return nil, err
+	}
+	defer harness.close()
+	harness.upstream.latency = opts.UpstreamLatency
+
+	// A first session lists the tools so the mix can be checked up front
+	server, schemas, err := startClient(ctx, harness, callTimeout)
+	if err != nil {
+		return nil, err
+	}
+	server.stop()
+	tools, weights, err := loadMix(schemas, opts.Mix)
+	if err != nil {
+		return nil, err
+	}
+	args := map[string]json.RawMessage{}
+	for _, tool := range tools {
+		value := interface{}(opts.Arguments[tool])
+		if opts.Arguments[tool] == nil {
+			value = exampleValue(schemas[tool], 0)
+		}
+		if args[tool], err = json.Marshal(value); err != nil {
+			return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid arguments for %s", tool), err)
+		}
+	}
+
+	var (
+		mu           sync.Mutex
+		samples      []loadSample
+		clientErrors []string
+		peakMemory   int64
+		wg           sync.WaitGroup
+	)
+	start := time.Now()
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			delay := time.Duration(0)
+			if clients > 1 {
+				delay = opts.RampUp * time.Duration(i) / time.Duration(clients-1)
+			}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+
+			rnd := rand.New(rand.NewSource(int64(i) + 1))
+			clientSamples, memory, err := runLoadClient(ctx, harness, callTimeout, time.Now().Add(duration), func() string {
+				return pickWeighted(tools, weights, rnd)
+			}, args)
+			mu.Lock()
+			defer mu.Unlock()
+			samples = append(samples, clientSamples...)
+			if memory > peakMemory {
+				peakMemory = memory
+			}
+			if err != nil {
+				clientErrors = append(clientErrors, fmt.Sprintf("client %d: %v", i+1, err))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	result := &LoadResult{Clients: clients, Duration: time.Since(start), ClientErrors: clientErrors, PeakMemory: peakMemory}
+	byTool := map[string][]time.Duration{}
+	var all []time.Duration
+	toolErrors := map[string]int{}
+	for _, sample := range samples {
+		result.Requests++
+		if sample.failed {
+			result.Errors++
+			toolErrors[sample.tool]++
+		}
+		all = append(all, sample.latency)
+		byTool[sample.tool] = append(byTool[sample.tool], sample.latency)
+	}
+	result.Latency = latencyStats(all)
+	if seconds := result.Duration.Seconds(); seconds > 0 {
+		result.RequestsPerSecond = float64(result.Requests) / seconds
+	}
+	for _, tool := range tools {
+		result.Tools = append(result.Tools, ToolLoadResult{
+			Name:     tool,
+			Requests: len(byTool[tool]),
+			Errors:   toolErrors[tool],
+			Latency:  latencyStats(byTool[tool]),
+		})
+	}
+
+	if ctx.Err() != nil {
+		return result, common.NewError(common.ErrorTypeGeneration, "load test cancelled", ctx.Err())
+	}
+	if len(clientErrors) == clients {
+		return result, common.NewError(common.ErrorTypeValidation, "every client failed", nil).
+			WithSuggestion(clientErrors[0])
+	}
+	result.Violations = checkLoadThresholds(result, opts.Thresholds)
+	switch len(result.Violations) {
+	case 0:
+		return result, nil
+	case 1:
+		return result, common.NewError(common.ErrorTypeValidation, "load test exceeded a threshold: "+result.Violations[0], nil)
+	}
+	return result, common.NewError(common.ErrorTypeValidation,
+		fmt.Sprintf("load test exceeded %d thresholds", len(result.Violations)), nil)
+}
+
+// checkLoadThresholds lists the thresholds a result exceeds
+func checkLoadThresholds(result *LoadResult, thresholds LoadThresholds) []string {
+	var violations []string
+	if thresholds.MaxP95 > 0 && result.Latency.P95 > thresholds.MaxP95 {
+		violations = append(violations, fmt.Sprintf("p95 latency %s is above %s", result.Latency.P95, thresholds.MaxP95))
+	}
+	if thresholds.MaxP99 > 0 && result.Latency.P99 > thresholds.MaxP99 {
+		violations = append(violations, fmt.Sprintf("p99 latency %s is above %s", result.Latency.P99, thresholds.MaxP99))
+	}
+	if thresholds.MinRequestsPerSecond > 0 && result.RequestsPerSecond < thresholds.MinRequestsPerSecond {
+		violations = append(violations, fmt.Sprintf("%.1f requests/s is below %.1f", result.RequestsPerSecond, thresholds.MinRequestsPerSecond))
+	}
+	if thresholds.MaxErrorRate > 0 && errorRate(result) > thresholds.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.2f%% is above %.2f%%", errorRate(result)*100, thresholds.MaxErrorRate*100))
+	}
+	if thresholds.MaxPeakMemory > 0 {
+		switch {
+		case result.PeakMemory == 0:
+			violations = append(violations, "peak memory is not reported on this platform or for servers in containers")
+		case result.PeakMemory > thresholds.MaxPeakMemory:
+			violations = append(violations, fmt.Sprintf("peak memory of %d bytes is above %d", result.PeakMemory, thresholds.MaxPeakMemory))
+		}
+	}
+	return violations
+}
+
+// runLoadClient starts a server and calls tools on it until the deadline,
+// returning the samples and the server's peak memory. A call without a
+// proper answer ends the client, as its session can no longer be trusted.
+func runLoadClient(ctx context.Context, harness *probeHarness, callTimeout time.Duration, deadline time.Time, next func() string, args map[string]json.RawMessage) ([]loadSample, int64, error) {
+	server, _, err := startClient(ctx, harness, callTimeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The exchange log would grow with every call
+	server.exchanges = nil
+
+	var samples []loadSample
+	var failed error
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		tool := next()
+		start := time.Now()
+		failure, rejected := callToolChecked(server.probeSession, tool, args[tool])
+		samples = append(samples, loadSample{tool: tool, latency: time.Since(start), failed: failure != nil || rejected})
+		server.exchanges = nil
+		if failure != nil {
+			server.kill()
+			failed = fmt.Errorf("%s: %s", tool, failure.Message)
+			break
+		}
+	}
+	server.stop()
+	if harness.docker != "" {
+		// The process is the docker CLI, not the server
+		return samples, 0, failed
+	}
+	return samples, peakMemory(server.cmd.ProcessState), failed
+}
+
+// loadMix returns the tools to call and their weights, sorted by name
+func loadMix(schemas map[string]map[string]interface{}, mix map[string]int) ([]string, []int, error) {
+	var tools []string
+	var weights []int
+	if len(mix) == 0 {
+		for name := range schemas {
+			tools = append(tools, name)
+		}
+		sort.Strings(tools)
+		for range tools {
+			weights = append(weights, 1)
+		}
+	} else {
+		for name := range mix {
+			tools = append(tools, name)
+		}
+		sort.Strings(tools)
+		for _, name := range tools {
+			if _, ok := schemas[name]; !ok {
+				return nil, nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("the server has no tool %q", name), nil)
+			}
+			if mix[name] <= 0 {
+				return nil, nil, common.NewError(common.ErrorTypeValidation,
+					fmt.Sprintf("weight of %s must be positive", name), nil)
+			}
+			weights = append(weights, mix[name])
+		}
+	}
+	if len(tools) == 0 {
+		return nil, nil, common.NewError(common.ErrorTypeValidation, "the server has no tools to call", nil)
+	}
+	return tools, weights, nil
+}
+
+// pickWeighted picks a tool with probability proportional to its weight
+func pickWeighted(tools []string, weights []int, rnd *rand.Rand) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	n := rnd.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return tools[i]
+		}
+		n -= w
+	}
+	return tools[len(tools)-1]
+}
+
+// latencyStats computes nearest-rank percentiles
+func latencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	percentile := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+	}
+	return LatencyStats{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(0.50),
+		P95:  percentile(0.95),
+		P99:  percentile(0.99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMix(t *testing.T) {
+	schemas := map[string]map[string]interface{}{"list_users": nil, "get_user": nil, "create_user": nil}
+	tests := []struct {
+		name        string
+		mix         map[string]int
+		wantTools   []string
+		wantWeights []int
+		wantErr     string
+	}{
+		{
+			name:        "every tool equally",
+			wantTools:   []string{"create_user", "get_user", "list_users"},
+			wantWeights: []int{1, 1, 1},
+		},
+		{
+			name:        "weighted subset",
+			mix:         map[string]int{"list_users": 4, "create_user": 1},
+			wantTools:   []string{"create_user", "list_users"},
+			wantWeights: []int{1, 4},
+		},
+		{
+			name:    "unknown tool",
+			mix:     map[string]int{"delete_user": 1},
+			wantErr: `the server has no tool "delete_user"`,
+		},
+		{
+			name:    "zero weight",
+			mix:     map[string]int{"get_user": 0},
+			wantErr: "weight of get_user must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools, weights, err := loadMix(schemas, tt.mix)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTools, tools)
+			assert.Equal(t, tt.wantWeights, weights)
+		})
+	}
+
+	_, _, err := loadMix(map[string]map[string]interface{}{}, nil)
+	assert.Error(t, err, "a server without tools cannot be load tested")
+}
+
+func TestPickWeighted(t *testing.T) {
+	tools, weights := []string{"a", "b"}, []int{3, 1}
+	rnd := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		counts[pickWeighted(tools, weights, rnd)]++
+	}
+	assert.InDelta(t, 3000, counts["a"], 200)
+	assert.InDelta(t, 1000, counts["b"], 200)
+}
+
+func TestLatencyStats(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	stats := latencyStats(latencies)
+	assert.Equal(t, LatencyStats{
+		Min:  time.Millisecond,
+		Mean: 50500 * time.Microsecond,
+		P50:  50 * time.Millisecond,
+		P95:  95 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+		Max:  100 * time.Millisecond,
+	}, stats)
+	assert.Equal(t, LatencyStats{}, latencyStats(nil))
+}
+
+func TestCheckLoadThresholds(t *testing.T) {
+	result := &LoadResult{
+		Requests:          100,
+		Errors:            5,
+		RequestsPerSecond: 40,
+		Latency:           LatencyStats{P95: 120 * time.Millisecond, P99: 300 * time.Millisecond},
+		PeakMemory:        64 << 20,
+	}
+	assert.Empty(t, checkLoadThresholds(result, LoadThresholds{}), "zero thresholds are not checked")
+	assert.Empty(t, checkLoadThresholds(result, LoadThresholds{
+		MaxP95:               200 * time.Millisecond,
+		MaxP99:               time.Second,
+		MinRequestsPerSecond: 10,
+		MaxErrorRate:         0.1,
+		MaxPeakMemory:        128 << 20,
+	}))
+	violations := checkLoadThresholds(result, LoadThresholds{
+		MaxP95:               100 * time.Millisecond,
+		MaxP99:               200 * time.Millisecond,
+		MinRequestsPerSecond: 50,
+		MaxErrorRate:         0.01,
+		MaxPeakMemory:        32 << 20,
+	})
+	assert.Len(t, violations, 5)
+	assert.Contains(t, violations[0], "p95 latency")
+
+	result.PeakMemory = 0
+	assert.Equal(t, []string{"peak memory is not reported on this platform or for servers in containers"},
+		checkLoadThresholds(result, LoadThresholds{MaxPeakMemory: 1}))
+}
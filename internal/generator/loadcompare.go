@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// DefaultLoadTolerance is the relative change allowed before a metric counts
+// as regressed
+const DefaultLoadTolerance = 0.1
+
+// LoadTolerances sets how much worse a load test may be than its baseline.
+// Each value is a fraction, e.g. 0.2 allows 20% slower latencies.
+type LoadTolerances struct {
+	// Latency applies to the mean, p95 and p99 latency, overall and per tool
+	Latency float64
+	// Throughput applies to the drop in requests per second
+	Throughput float64
+	// Memory applies to the growth of the peak memory
+	Memory float64
+	// ErrorRate is the absolute increase allowed in the share of failed
+	// requests, e.g. 0.01 for one percentage point
+	ErrorRate float64
+}
+
+// LoadRegression is a metric that got worse than the tolerance allows
+type LoadRegression struct {
+	// Metric names the metric, e.g. "p95" or "list_pets p95"
+	Metric string
+	// Baseline and Current are milliseconds for latencies, requests per
+	// second for throughput, MiB for memory and a fraction for the error
+	// rate
+	Baseline float64
+	Current  float64
+	// Change is the relative change from the baseline, positive when worse;
+	// for the error rate it is the absolute increase
+	Change float64
+}
+
+// String formats the regression for display
+func (r LoadRegression) String() string {
+	return fmt.Sprintf("%s: %.4g -> %.4g (%+.1f%%)", r.Metric, r.Baseline, r.Current, r.Change*100)
+}
+
+// WriteLoadBaseline saves a load test result as JSON, to be compared with
+// later runs
+func WriteLoadBaseline(path string, result *LoadResult) error {
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to encode load baseline", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to create baseline directory", err).WithFile(filepath.Dir(path))
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to write load baseline", err).WithFile(path)
+	}
+	return nil
+}
+
+// ReadLoadBaseline reads a result saved by WriteLoadBaseline
+func ReadLoadBaseline(path string) (*LoadResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeValidation, "failed to read load baseline", err).WithFile(path)
+	}
+	var result LoadResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, common.NewError(common.ErrorTypeValidation, "invalid load baseline", err).WithFile(path)
+	}
+	return &result, nil
+}
+
+// CompareLoad lists the metrics of current that are worse than the baseline
+// by more than the tolerances. Zero tolerances default to
+// DefaultLoadTolerance, except ErrorRate which defaults to no increase. Tools
+// missing from either run are not compared.
+func CompareLoad(baseline, current *LoadResult, tolerances LoadTolerances) []LoadRegression {
+	if tolerances.Latency <= 0 {
+		tolerances.Latency = DefaultLoadTolerance
+	}
+	if tolerances.Throughput <= 0 {
+		tolerances.Throughput = DefaultLoadTolerance
+	}
+	if tolerances.Memory <= 0 {
+		tolerances.Memory = DefaultLoadTolerance
+	}
+
+	var regressions []LoadRegression
+	latency := func(prefix string, base, cur LatencyStats) {
+		for _, m := range []struct {
+			name      string
+			base, cur time.Duration
+		}{
+			{"mean", base.Mean, cur.Mean},
+			{"p95", base.P95, cur.P95},
+			{"p99", base.P99, cur.P99},
+		} {
+			if m.base <= 0 {
+				continue
+			}
+			change := float64(m.cur-m.base) / float64(m.base)
+			if change > tolerances.Latency {
+				regressions = append(regressions, LoadRegression{
+					Metric:   prefix + m.name,
+					Baseline: m.base.Seconds() * 1000,
+					Current:  m.cur.Seconds() * 1000,
+					Change:   change,
+				})
+			}
+		}
+	}
+
+	latency("", baseline.Latency, current.Latency)
+	if baseline.RequestsPerSecond > 0 {
+		change := (baseline.RequestsPerSecond - current.RequestsPerSecond) / baseline.RequestsPerSecond
+		if change > tolerances.Throughput {
+			regressions = append(regressions, LoadRegression{
+				Metric:   "requests/s",
+				Baseline: baseline.RequestsPerSecond,
+				Current:  current.RequestsPerSecond,
+				Change:   change,
+			})
+		}
+	}
+	if baseline.PeakMemory > 0 && current.PeakMemory > 0 {
+		change := float64(current.PeakMemory-baseline.PeakMemory) / float64(baseline.PeakMemory)
+		if change > tolerances.Memory {
+			regressions = append(regressions, LoadRegression{
+				Metric:   "peak memory",
+				Baseline: float64(baseline.PeakMemory) / (1 << 20),
+				Current:  float64(current.PeakMemory) / (1 << 20),
+				Change:   change,
+			})
+		}
+	}
+	baseRate, curRate := errorRate(baseline), errorRate(current)
+	if curRate-baseRate > tolerances.ErrorRate {
+		regressions = append(regressions, LoadRegression{
+			Metric:   "error rate",
+			Baseline: baseRate,
+			Current:  curRate,
+			Change:   curRate - baseRate,
+		})
+	}
+
+	baseTools := map[string]ToolLoadResult{}
+	for _, tool := range baseline.Tools {
+		baseTools[tool.Name] = tool
+	}
+	for _, tool := range current.Tools {
+		if base, ok := baseTools[tool.Name]; ok {
+			latency(tool.Name+" ", base.Latency, tool.Latency)
+		}
+	}
+	return regressions
+}
+
+// errorRate is the share of failed requests
+func errorRate(result *LoadResult) float64 {
+	if result.Requests == 0 {
+		return 0
+	}
+	return float64(result.Errors) / float64(result.Requests)
+}
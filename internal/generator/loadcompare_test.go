@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBaselineRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baselines", "load.json")
+	saved := &LoadResult{
+		Requests:          1000,
+		Errors:            2,
+		RequestsPerSecond: 500,
+		Latency:           LatencyStats{Mean: 2 * time.Millisecond, P95: 5 * time.Millisecond},
+		Tools:             []ToolLoadResult{{Name: "list_users", Requests: 1000}},
+		PeakMemory:        12 << 20,
+	}
+	require.NoError(t, WriteLoadBaseline(path, saved))
+	read, err := ReadLoadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved, read)
+
+	_, err = ReadLoadBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestCompareLoad(t *testing.T) {
+	baseline := &LoadResult{
+		Requests:          1000,
+		RequestsPerSecond: 500,
+		Latency:           LatencyStats{Mean: 10 * time.Millisecond, P95: 20 * time.Millisecond, P99: 40 * time.Millisecond},
+		Tools: []ToolLoadResult{
+			{Name: "list_users", Latency: LatencyStats{P95: 20 * time.Millisecond}},
+			{Name: "removed_tool", Latency: LatencyStats{P95: 20 * time.Millisecond}},
+		},
+		PeakMemory: 10 << 20,
+	}
+
+	same := *baseline
+	assert.Empty(t, CompareLoad(baseline, &same, LoadTolerances{}))
+
+	worse := &LoadResult{
+		Requests:          1000,
+		Errors:            20,
+		RequestsPerSecond: 400,
+		Latency:           LatencyStats{Mean: 10500 * time.Microsecond, P95: 30 * time.Millisecond, P99: 40 * time.Millisecond},
+		Tools: []ToolLoadResult{
+			{Name: "list_users", Latency: LatencyStats{P95: 30 * time.Millisecond}},
+			{Name: "new_tool", Latency: LatencyStats{P95: time.Second}},
+		},
+		PeakMemory: 12 << 20,
+	}
+	var metrics []string
+	for _, regression := range CompareLoad(baseline, worse, LoadTolerances{}) {
+		metrics = append(metrics, regression.Metric)
+	}
+	assert.Equal(t, []string{"p95", "requests/s", "peak memory", "error rate", "list_users p95"}, metrics,
+		"a 5% slower mean is within the default tolerance; tools missing from either run are skipped")
+
+	metrics = nil
+	for _, regression := range CompareLoad(baseline, worse, LoadTolerances{Latency: 0.6, Throughput: 0.25, Memory: 0.25, ErrorRate: 0.05}) {
+		metrics = append(metrics, regression.Metric)
+	}
+	assert.Empty(t, metrics, "wider tolerances accept the run")
+
+	regression := LoadRegression{Metric: "p95", Baseline: 20, Current: 30, Change: 0.5}
+	assert.Equal(t, "p95: 20 -> 30 (+50.0%)", regression.String())
+}
@@ -0,0 +1,288 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"MCPWeaver/internal/common"
+)
+
+// upstreamDir keeps, inside a copied package, the built-in files it was
+// derived from, so later built-in changes can be merged into the copy
+const upstreamDir = ".upstream"
+
+// MigrationStatus describes how a file of a derived package follows its
+// built-in original
+type MigrationStatus string
+
+// Migration statuses
+const (
+	// MigrationCurrent marks files whose original did not change
+	MigrationCurrent MigrationStatus = "current"
+	// MigrationUpdated marks unmodified copies that take the new original
+	MigrationUpdated MigrationStatus = "updated"
+	// MigrationMerged marks customized copies merged cleanly with the new
+	// original
+	MigrationMerged MigrationStatus = "merged"
+	// MigrationConflict marks customizations overlapping changes of the
+	// original; the merge carries conflict markers
+	MigrationConflict MigrationStatus = "conflict"
+	// MigrationRemoved marks files the built-in package no longer has
+	MigrationRemoved MigrationStatus = "removed"
+)
+
+// TemplateMigration is the plan for bringing a derived package up to date
+// with the built-in package it was copied from
+type TemplateMigration struct {
+	Dir string
+	// BaseVersion is the built-in version the package was derived from and
+	// Version the current one
+	BaseVersion string
+	Version     string
+	Files       []MigrationFile
+	// Applied is set when the merged files were written
+	Applied bool
+}
+
+// MigrationFile is the migration of one derived file
+type MigrationFile struct {
+	Path   string
+	Status MigrationStatus
+	// Upstream holds what changed in the built-in file since the copy
+	Upstream []DiffHunk
+	// Merged is the proposed content of the file
+	Merged    string
+	Conflicts int
+}
+
+// Conflicts reports whether any file needs its merge resolved by hand
+func (m *TemplateMigration) Conflicts() bool {
+	for _, file := range m.Files {
+		if file.Status == MigrationConflict {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrateTemplate compares the custom package in opts.TemplateDir, created
+// with CopyTemplate, with the built-in package it was derived from. Files
+// changed upstream are merged three ways with the local customizations.
+// With apply, merged files are written, conflicts included with markers,
+// and the recorded originals are advanced to the current built-in files.
+func (s *Service) MigrateTemplate(opts Options, apply bool) (*TemplateMigration, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	if opts.TemplateDir == "" {
+		return nil, common.NewError(common.ErrorTypeValidation, "no template directory to migrate", nil).
+			WithSuggestion("Set the directory of the custom template package")
+	}
+	builtin, err := openTemplateSource(opts.Template, "")
+	if err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Join(opts.TemplateDir, upstreamDir)
+	bases, err := snapshotDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, common.NewError(common.ErrorTypeValidation, "template package records no built-in originals", err).
+				WithFile(opts.TemplateDir).
+				WithSuggestion("Only packages created by copying a built-in template can be migrated")
+		}
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to read template originals", err).WithFile(baseDir)
+	}
+
+	migration := &TemplateMigration{Dir: opts.TemplateDir}
+	if m, err := readManifest(os.DirFS(baseDir)); err == nil {
+		migration.BaseVersion = m.Version
+	}
+	if m, _, err := builtin.manifest(); err == nil {
+		migration.Version = m.Version
+	}
+
+	names := make([]string, 0, len(bases))
+	for name := range bases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	upstream := map[string][]byte{}
+	for _, name := range names {
+		base, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read template original", err).WithFile(name)
+		}
+		target := filepath.Join(opts.TemplateDir, filepath.FromSlash(name))
+		local, err := os.ReadFile(target)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read template", err).WithFile(target)
+		}
+		if os.IsNotExist(err) {
+			// The copy dropped the file and inherits it again
+			continue
+		}
+
+		file := MigrationFile{Path: name, Status: MigrationCurrent, Merged: string(local)}
+		theirs, _, err := builtin.readFile(name)
+		switch {
+		case os.IsNotExist(err):
+			file.Status = MigrationRemoved
+		case err != nil:
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read built-in template", err).WithFile(name)
+		case bytes.Equal(base, theirs):
+		default:
+			upstream[name] = theirs
+			file.Upstream = diffHunks(splitLines(base), splitLines(theirs))
+			if bytes.Equal(base, local) {
+				file.Status, file.Merged = MigrationUpdated, string(theirs)
+				break
+			}
+			merged, conflicts := merge3(splitLines(base), splitLines(local), splitLines(theirs))
+			file.Merged, file.Conflicts = merged, conflicts
+			file.Status = MigrationMerged
+			if conflicts > 0 {
+				file.Status = MigrationConflict
+			}
+		}
+		migration.Files = append(migration.Files, file)
+	}
+
+	if !apply {
+		return migration, nil
+	}
+	for _, file := range migration.Files {
+		theirs, ok := upstream[file.Path]
+		if !ok {
+			continue
+		}
+		target := filepath.Join(opts.TemplateDir, filepath.FromSlash(file.Path))
+		if err := os.WriteFile(target, []byte(file.Merged), 0644); err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to write template", err).WithFile(target)
+		}
+		base := filepath.Join(baseDir, filepath.FromSlash(file.Path))
+		if err := os.WriteFile(base, theirs, 0644); err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to record template original", err).WithFile(base)
+		}
+	}
+	migration.Applied = true
+	return migration, nil
+}
+
+// merge3 merges the changes from base to ours and from base to theirs,
+// returning the result and the number of conflicting regions, which are
+// wrapped in conflict markers
+func merge3(base, ours, theirs []string) (string, int) {
+	inOurs, inTheirs := matchLines(base, ours), matchLines(base, theirs)
+
+	var merged []string
+	conflicts := 0
+	i, a, b := 0, 0, 0
+	for {
+		// Copy lines unchanged on both sides
+		for i < len(base) && inOurs[i] == a && inTheirs[i] == b {
+			merged = append(merged, base[i])
+			i, a, b = i+1, a+1, b+1
+		}
+		if i == len(base) && a == len(ours) && b == len(theirs) {
+			break
+		}
+
+		// The changed region ends at the next base line both sides kept
+		j := i
+		for j < len(base) && (inOurs[j] < 0 || inTheirs[j] < 0) {
+			j++
+		}
+		endOurs, endTheirs := len(ours), len(theirs)
+		if j < len(base) {
+			endOurs, endTheirs = inOurs[j], inTheirs[j]
+		}
+		baseRegion, oursRegion, theirsRegion := base[i:j], ours[a:endOurs], theirs[b:endTheirs]
+
+		switch {
+		case equalLines(oursRegion, baseRegion):
+			merged = append(merged, theirsRegion...)
+		case equalLines(theirsRegion, baseRegion), equalLines(oursRegion, theirsRegion):
+			merged = append(merged, oursRegion...)
+		default:
+			conflicts++
+			merged = append(merged, "<<<<<<< local")
+			merged = append(merged, oursRegion...)
+			merged = append(merged, "=======")
+			merged = append(merged, theirsRegion...)
+			merged = append(merged, ">>>>>>> upstream")
+		}
+		i, a, b = j, endOurs, endTheirs
+	}
+
+	if len(merged) == 0 {
+		return "", conflicts
+	}
+	return strings.Join(merged, "\n") + "\n", conflicts
+}
+
+// matchLines maps every line of base to the index of the same line in other,
+// or -1 when the edit script removes it
+func matchLines(base, other []string) []int {
+	matches := make([]int, len(base))
+	x, y := 0, 0
+	for _, op := range diffLines(base, other) {
+		switch op.kind {
+		case ' ':
+			matches[x] = y
+			x, y = x+1, y+1
+		case '-':
+			matches[x] = -1
+			x++
+		case '+':
+			y++
+		}
+	}
+	return matches
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// recordOriginals stores in dir/.upstream the copied files that are
+// unchanged built-in files, or whose original the source package recorded
+func recordOriginals(opts Options, dest string, files []exportFile) error {
+	builtin, err := openTemplateSource(opts.Template, "")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		original, _, err := builtin.readFile(file.Name)
+		if err != nil || !bytes.Equal(original, file.Content) {
+			original = nil
+			if opts.TemplateDir != "" {
+				original, _ = os.ReadFile(filepath.Join(opts.TemplateDir, upstreamDir, filepath.FromSlash(file.Name)))
+			}
+		}
+		if original == nil {
+			continue
+		}
+		target := filepath.Join(dest, upstreamDir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, original, 0644); err != nil {
+			return fmt.Errorf("recording original of %s: %w", file.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "only theirs changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "only ours changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nC\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nC\n",
+		},
+		{
+			name:   "separate changes",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "insertions on both sides",
+			base:   "a\nb\nc\n",
+			ours:   "first\na\nb\nc\n",
+			theirs: "a\nb\nc\nlast\n",
+			want:   "first\na\nb\nc\nlast\n",
+		},
+		{
+			name:   "identical changes",
+			base:   "a\nb\nc\n",
+			ours:   "a\nX\nc\n",
+			theirs: "a\nX\nc\n",
+			want:   "a\nX\nc\n",
+		},
+		{
+			name:   "removal and unrelated change",
+			base:   "a\nb\nc\nd\n",
+			ours:   "a\nc\nd\n",
+			theirs: "a\nb\nc\nD\n",
+			want:   "a\nc\nD\n",
+		},
+		{
+			name:          "overlapping changes",
+			base:          "a\nb\nc\n",
+			ours:          "a\nours\nc\n",
+			theirs:        "a\ntheirs\nc\n",
+			want:          "a\n<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> upstream\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			name:          "two conflicting regions",
+			base:          "a\nb\nc\nd\ne\n",
+			ours:          "A1\nb\nc\nd\nE1\n",
+			theirs:        "A2\nb\nc\nd\nE2\n",
+			want:          "<<<<<<< local\nA1\n=======\nA2\n>>>>>>> upstream\nb\nc\nd\n<<<<<<< local\nE1\n=======\nE2\n>>>>>>> upstream\n",
+			wantConflicts: 2,
+		},
+		{
+			name: "all empty",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := merge3(splitLines([]byte(tt.base)), splitLines([]byte(tt.ours)), splitLines([]byte(tt.theirs)))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantConflicts, conflicts)
+		})
+	}
+}
+
+func TestMigrateTemplate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "copy")
+	_, err := NewService().CopyTemplate(Options{}, dir)
+	require.NoError(t, err)
+
+	migration, err := NewService().MigrateTemplate(Options{TemplateDir: dir}, false)
+	require.NoError(t, err)
+	for _, file := range migration.Files {
+		assert.Equal(t, MigrationCurrent, file.Status, file.Path)
+	}
+
+	// Pretend the copy was made before the built-in title line changed,
+	// and customize the last line locally
+	current, err := os.ReadFile(filepath.Join(dir, "README.md.tmpl"))
+	require.NoError(t, err)
+	lines := splitLines(current)
+	require.Greater(t, len(lines), 4)
+	base := append([]string{"# Old title"}, lines[1:]...)
+	local := append(append([]string{}, base[:len(base)-1]...), "Customized")
+	writeLines(t, filepath.Join(dir, upstreamDir, "README.md.tmpl"), base)
+	writeLines(t, filepath.Join(dir, "README.md.tmpl"), local)
+
+	migration, err = NewService().MigrateTemplate(Options{TemplateDir: dir}, true)
+	require.NoError(t, err)
+	readme := migrationFile(t, migration, "README.md.tmpl")
+	assert.Equal(t, MigrationMerged, readme.Status)
+	assert.NotEmpty(t, readme.Upstream)
+	assert.False(t, migration.Conflicts())
+	assert.True(t, migration.Applied)
+
+	merged, err := os.ReadFile(filepath.Join(dir, "README.md.tmpl"))
+	require.NoError(t, err)
+	want := append(append([]string{}, lines[:len(lines)-1]...), "Customized")
+	assert.Equal(t, strings.Join(want, "\n")+"\n", string(merged))
+	original, err := os.ReadFile(filepath.Join(dir, upstreamDir, "README.md.tmpl"))
+	require.NoError(t, err)
+	assert.Equal(t, current, original, "the recorded original advances")
+
+	// A local change to the line changed upstream conflicts
+	writeLines(t, filepath.Join(dir, upstreamDir, "README.md.tmpl"), base)
+	writeLines(t, filepath.Join(dir, "README.md.tmpl"), append([]string{"# Local title"}, lines[1:]...))
+	migration, err = NewService().MigrateTemplate(Options{TemplateDir: dir}, false)
+	require.NoError(t, err)
+	readme = migrationFile(t, migration, "README.md.tmpl")
+	assert.Equal(t, MigrationConflict, readme.Status)
+	assert.Equal(t, 1, readme.Conflicts)
+	assert.True(t, migration.Conflicts())
+	assert.False(t, migration.Applied)
+	unchanged, err := os.ReadFile(filepath.Join(dir, "README.md.tmpl"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(unchanged), "# Local title\n"), "nothing is written without apply")
+
+	_, err = NewService().MigrateTemplate(Options{TemplateDir: t.TempDir()}, false)
+	assert.Error(t, err, "a package without originals cannot be migrated")
+}
+
+func writeLines(t *testing.T, path string, lines []string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+}
+
+func migrationFile(t *testing.T, migration *TemplateMigration, path string) MigrationFile {
+	t.Helper()
+	for _, file := range migration.Files {
+		if file.Path == path {
+			return file
+		}
+	}
+	require.Failf(t, "file not migrated", "%s is not part of the migration", path)
+	return MigrationFile{}
+}
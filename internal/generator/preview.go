@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/transformer"
+)
+
+// EventPreviewUpdated is the name of events emitted by WatchTemplate
+const EventPreviewUpdated = "template:preview_updated"
+
+// DefaultPreviewInterval is how often WatchTemplate polls for changes
+const DefaultPreviewInterval = 500 * time.Millisecond
+
+// PreviewEvent carries a fresh render of a watched template package
+type PreviewEvent struct {
+	Name string
+	// Changed lists the template files, relative to the package, that
+	// triggered the render; empty for the initial render
+	Changed []string
+	Files   []PreviewFile
+	// Findings are formatting issues in the rendered Go sources
+	Findings []Finding
+	// Err is set instead of Files when the package failed to render
+	Err error
+}
+
+// PreviewFile is one rendered output of a preview
+type PreviewFile struct {
+	Path     string
+	Template string
+	Content  string
+	// RenderTime is how long the template took to execute
+	RenderTime time.Duration
+}
+
+// Preview renders the template package in memory against the server without
+// writing or vetting anything
+func (s *Service) Preview(server *transformer.MCPServer, opts Options) (*PreviewEvent, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	opts, err := applyProfile(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.renderAll(server, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &PreviewEvent{Name: EventPreviewUpdated, Findings: out.findings}
+	for i, file := range out.files {
+		event.Files = append(event.Files, PreviewFile{
+			Path:       file.Output,
+			Template:   file.Template,
+			Content:    string(out.rendered[i]),
+			RenderTime: out.durations[i],
+		})
+	}
+	return event, nil
+}
+
+// WatchTemplate renders a preview of the custom template package in
+// opts.TemplateDir and re-renders it whenever a file in the package changes,
+// passing each result to emit. It polls every interval, or
+// DefaultPreviewInterval when zero, and returns when ctx is done.
+func (s *Service) WatchTemplate(ctx context.Context, server *transformer.MCPServer, opts Options, interval time.Duration, emit func(PreviewEvent)) error {
+	if opts.TemplateDir == "" {
+		return common.NewError(common.ErrorTypeValidation, "no template directory to watch", nil).
+			WithSuggestion("Set the directory of the custom template package")
+	}
+	if interval <= 0 {
+		interval = DefaultPreviewInterval
+	}
+
+	previous, err := snapshotDir(opts.TemplateDir)
+	if err != nil {
+		return common.NewError(common.ErrorTypeGeneration, "failed to read template directory", err).
+			WithFile(opts.TemplateDir)
+	}
+	emit(s.previewEvent(server, opts, nil))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := snapshotDir(opts.TemplateDir)
+		if err != nil {
+			emit(PreviewEvent{Name: EventPreviewUpdated, Err: err})
+			continue
+		}
+		if changed := changedFiles(previous, current); len(changed) > 0 {
+			previous = current
+			emit(s.previewEvent(server, opts, changed))
+		}
+	}
+}
+
+func (s *Service) previewEvent(server *transformer.MCPServer, opts Options, changed []string) PreviewEvent {
+	event, err := s.Preview(server, opts)
+	if err != nil {
+		return PreviewEvent{Name: EventPreviewUpdated, Changed: changed, Err: err}
+	}
+	event.Changed = changed
+	return *event
+}
+
+// fileStamp identifies a version of a file cheaply
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshotDir records the stamps of every file below dir, keyed by slash
+// separated relative path
+func snapshotDir(dir string) (map[string]fileStamp, error) {
+	snapshot := map[string]fileStamp{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		snapshot[filepath.ToSlash(rel)] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return snapshot, err
+}
+
+// changedFiles lists files added, removed or modified between snapshots
+func changedFiles(before, after map[string]fileStamp) []string {
+	var changed []string
+	for name, stamp := range after {
+		if old, ok := before[name]; !ok || old.size != stamp.size || !old.modTime.Equal(stamp.modTime) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedFiles(t *testing.T) {
+	now := time.Now()
+	stamp := fileStamp{modTime: now, size: 10}
+	tests := []struct {
+		name   string
+		before map[string]fileStamp
+		after  map[string]fileStamp
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			before: map[string]fileStamp{"main.go.tmpl": stamp},
+			after:  map[string]fileStamp{"main.go.tmpl": stamp},
+		},
+		{
+			name:   "modified time",
+			before: map[string]fileStamp{"main.go.tmpl": stamp},
+			after:  map[string]fileStamp{"main.go.tmpl": {modTime: now.Add(time.Second), size: 10}},
+			want:   []string{"main.go.tmpl"},
+		},
+		{
+			name:   "modified size",
+			before: map[string]fileStamp{"main.go.tmpl": stamp},
+			after:  map[string]fileStamp{"main.go.tmpl": {modTime: now, size: 11}},
+			want:   []string{"main.go.tmpl"},
+		},
+		{
+			name:   "added and removed",
+			before: map[string]fileStamp{"b.tmpl": stamp, "c.tmpl": stamp},
+			after:  map[string]fileStamp{"a.tmpl": stamp, "c.tmpl": stamp},
+			want:   []string{"a.tmpl", "b.tmpl"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, changedFiles(tt.before, tt.after))
+		})
+	}
+}
@@ -0,0 +1,689 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// DefaultProbeTimeout bounds a protocol probe, including compiling the server
+const DefaultProbeTimeout = 2 * time.Minute
+
+// ProbeOptions controls a protocol probe of a generated server
+type ProbeOptions struct {
+	// Dir is the directory containing the generated server
+	Dir string
+	// Tool is the tool called by the probe. When empty the first listed tool
+	// is called with example arguments derived from its input schema.
+	Tool      string
+	Arguments map[string]interface{}
+	// Timeout defaults to DefaultProbeTimeout
+	Timeout time.Duration
+	// Env holds extra NAME=value variables for the server, e.g. credentials
+	Env []string
+	// Fixtures records the real API's responses or replays recorded ones
+	// instead of answering upstream requests with an empty object
+	Fixtures FixtureOptions
+	// Image, when set, compiles and runs the server in disposable docker
+	// containers of this image, e.g. golang:1.23, instead of with the host
+	// toolchain
+	Image string
+}
+
+// ProbeExchange is one JSON-RPC message sent to the server and its reply
+type ProbeExchange struct {
+	Method   string
+	Request  json.RawMessage
+	Response json.RawMessage
+	Duration time.Duration
+}
+
+// ProbeResult describes a session with a generated server
+type ProbeResult struct {
+	ProtocolVersion string
+	ServerName      string
+	ServerVersion   string
+	Tools           []string
+	// Tool is the tool that was called
+	Tool string
+	// Upstream lists the HTTP requests the tool call sent to the stand-in
+	// API, as "METHOD /path?query"
+	Upstream []string
+	// ToolError is set when the tool call returned an error result
+	ToolError bool
+	// Failures are the protocol expectations the server did not meet
+	Failures  []string
+	Exchanges []ProbeExchange
+	// Stderr is the server's log output
+	Stderr   string
+	Duration time.Duration
+	// Clients reports what keeps common MCP clients from using the server.
+	// Client problems are not protocol failures.
+	Clients []ClientCompatibility
+	// ClaudeDesktopConfig registers the server with Claude Desktop, assuming
+	// it is built in Dir
+	ClaudeDesktopConfig string
+}
+
+// ProbeServer compiles a generated server for the host, starts it against a
+// local stand-in for the upstream API and talks MCP to it over stdio: the
+// initialize handshake, ping, tools/list, a tools/call and an unknown method.
+// Every response is checked against the protocol; the error reports when any
+// check failed, with the details in the result.
+func (s *Service) ProbeServer(ctx context.Context, opts ProbeOptions) (*ProbeResult, error) {
+	start := time.Now()
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	harness, err := newProbeHarness(opts.Dir, opts.Env, opts.Fixtures, opts.Image)
+	if err != nil {
+		return nil, err
+	}
+	defer harness.close()
+	server, err := harness.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProbeResult{}
+	server.run(result, opts, harness.upstream)
+	unsolicited, waitErr := server.stop()
+	for _, line := range unsolicited {
+		result.Failures = append(result.Failures, fmt.Sprintf("unsolicited output: %s", line))
+	}
+	for _, problem := range harness.upstream.failures() {
+		result.Failures = append(result.Failures, "upstream "+problem)
+	}
+	if result.ServerName != "" {
+		result.Clients = clientCompatibility(result.ProtocolVersion, result.Tools)
+		// The inspector starts the server itself, which only runs in
+		// containers with an image
+		if inspector, err := exec.LookPath("mcp-inspector"); err == nil && harness.docker == "" {
+			result.Clients = append(result.Clients, inspectorCompatibility(ctx, inspector, harness.binary, harness.work, harness.env, result.Tools))
+		}
+		if config, err := claudeDesktopConfig(opts.Dir, result.ServerName); err == nil {
+			result.ClaudeDesktopConfig = config
+		}
+	}
+	result.Stderr = server.stderr.String()
+	result.Exchanges = server.exchanges
+	result.Duration = time.Since(start)
+
+	if ctx.Err() != nil {
+		return result, common.NewError(common.ErrorTypeGeneration, "server did not finish the probe in time", ctx.Err()).
+			WithSuggestion(fmt.Sprintf("The probe gives up after %s", timeout))
+	}
+	if waitErr != nil {
+		result.Failures = append(result.Failures, fmt.Sprintf("server exited with %v after its input was closed", waitErr))
+	}
+	switch len(result.Failures) {
+	case 0:
+		return result, nil
+	case 1:
+		return result, common.NewError(common.ErrorTypeValidation, "server failed a protocol check", nil)
+	}
+	return result, common.NewError(common.ErrorTypeValidation,
+		fmt.Sprintf("server failed %d protocol checks", len(result.Failures)), nil)
+}
+
+// probeHarness is a generated server compiled for the host, or for a
+// container image, configured to send its upstream requests to a local
+// stand-in API
+type probeHarness struct {
+	work     string
+	binary   string
+	config   string
+	env      []string
+	upstream *upstreamRecorder
+	api      *httptest.Server
+
+	// docker runs the servers in containers of image; extraEnv are the
+	// variables passed into them
+	docker   string
+	image    string
+	extraEnv []string
+	started  atomic.Int64
+}
+
+func newProbeHarness(serverDir string, env []string, fixtures FixtureOptions, image string) (*probeHarness, error) {
+	var goTool, docker string
+	var err error
+	if image != "" {
+		docker, err = dockerTool(image)
+		if err != nil {
+			return nil, err
+		}
+	} else if goTool, err = exec.LookPath("go"); err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "go toolchain not found", err).
+			WithSuggestion("Install Go 1.21 or later and make sure it is on PATH")
+	}
+	dir, err := filepath.Abs(serverDir)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to resolve server directory", err).
+			WithFile(serverDir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "directory does not contain a generated server", err).
+			WithFile(dir).
+			WithSuggestion("Generate the server first or point to its output directory")
+	}
+
+	switch fixtures.Mode {
+	case FixturesOff:
+	case FixturesRecord, FixturesReplay:
+		if fixtures.Dir == "" {
+			return nil, common.NewError(common.ErrorTypeValidation, "no fixture directory", nil).
+				WithSuggestion("Set the directory fixtures are recorded to and replayed from")
+		}
+		if fixtures.Mode == FixturesReplay {
+			if _, err := os.Stat(fixtures.Dir); err != nil {
+				return nil, common.NewError(common.ErrorTypeValidation, "no recorded fixtures", err).
+					WithFile(fixtures.Dir).
+					WithSuggestion("Record fixtures against the real API first")
+			}
+		}
+		if fixtures.Mode == FixturesRecord && fixtures.Upstream == "" {
+			if fixtures.Upstream, err = configBaseURL(filepath.Join(dir, "config.yaml")); err != nil {
+				return nil, common.NewError(common.ErrorTypeValidation, "no upstream API to record against", err).
+					WithSuggestion("Set the base URL of the API to record")
+			}
+		}
+	default:
+		return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("unknown fixture mode %q", fixtures.Mode), nil).
+			WithSuggestion(fmt.Sprintf("Use %s or %s", FixturesRecord, FixturesReplay))
+	}
+
+	work, err := os.MkdirTemp("", "mcpweaver-probe-")
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to create probe directory", err)
+	}
+	h := &probeHarness{
+		work:   work,
+		binary: filepath.Join(work, "server"),
+		config: filepath.Join(work, "config.yaml"),
+		env:    append(probeEnv(os.Environ()), env...),
+		docker: docker,
+		image:  image,
+	}
+	if docker != "" {
+		// The container sees none of the host's variables, only the extra
+		// ones, which the docker CLI takes from its own environment
+		h.env, h.extraEnv = append(os.Environ(), env...), env
+		err = buildInContainer(docker, image, dir, h.binary)
+	} else {
+		if runtime.GOOS == "windows" {
+			h.binary += ".exe"
+		}
+		_, err = buildTarget(goTool, dir, h.binary, BuildTarget{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH})
+	}
+	if err != nil {
+		os.RemoveAll(work)
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to build server", err).WithFile(dir)
+	}
+
+	h.upstream = &upstreamRecorder{fixtures: fixtures}
+	h.api = httptest.NewServer(h.upstream)
+	if err := os.WriteFile(h.config, []byte(fmt.Sprintf("base_url: %s\nlog_level: debug\n", h.api.URL)), 0644); err != nil {
+		h.close()
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to write probe config", err).WithFile(h.config)
+	}
+	return h, nil
+}
+
+func (h *probeHarness) close() {
+	if h.api != nil {
+		h.api.Close()
+	}
+	os.RemoveAll(h.work)
+}
+
+// probedServer is a running server of a harness
+type probedServer struct {
+	*probeSession
+	cmd    *exec.Cmd
+	stdin  io.Closer
+	stderr *bytes.Buffer
+	// kill stops the server without waiting for it to finish
+	kill context.CancelFunc
+}
+
+// start launches the server; it is killed when ctx is done
+func (h *probeHarness) start(ctx context.Context) (*probedServer, error) {
+	ctx, kill := context.WithCancel(ctx)
+	cmd := exec.CommandContext(ctx, h.binary, "-config", h.config)
+	if h.docker != "" {
+		container := fmt.Sprintf("%s-%d", filepath.Base(h.work), h.started.Add(1))
+		cmd = exec.CommandContext(ctx, h.docker, dockerRunArgs(h.image, container, h.work, filepath.Base(h.binary), filepath.Base(h.config), h.extraEnv)...)
+		// Killing the docker CLI would leave the container running
+		cmd.Cancel = func() error {
+			exec.Command(h.docker, "rm", "--force", container).Run()
+			return cmd.Process.Kill()
+		}
+	}
+	cmd.Dir = h.work
+	cmd.Env = h.env
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		kill()
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to start server", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		kill()
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to start server", err)
+	}
+	if err := cmd.Start(); err != nil {
+		kill()
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to start server", err).WithFile(h.binary)
+	}
+	return &probedServer{
+		probeSession: &probeSession{ctx: ctx, stdin: stdin, lines: readLines(stdout)},
+		cmd:          cmd,
+		stdin:        stdin,
+		stderr:       stderr,
+		kill:         kill,
+	}, nil
+}
+
+// stop closes the server's input, which ends the session, and waits for it
+// to exit. It returns anything the server still wrote, which are replies
+// nobody asked for.
+func (s *probedServer) stop() ([][]byte, error) {
+	defer s.kill()
+	s.stdin.Close()
+	var unsolicited [][]byte
+	for line := range s.lines {
+		unsolicited = append(unsolicited, line)
+	}
+	return unsolicited, s.cmd.Wait()
+}
+
+// startClient launches a server, completes the handshake without checking
+// it and returns the input schemas of the tools
+func startClient(ctx context.Context, harness *probeHarness, callTimeout time.Duration) (*probedServer, map[string]map[string]interface{}, error) {
+	server, err := harness.start(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	server.callTimeout = callTimeout
+
+	var listed struct {
+		Tools []struct {
+			Name        string                 `json:"name"`
+			InputSchema map[string]interface{} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	err = server.call("initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "mcpweaver-probe", "version": "1.0.0"},
+	}, nil)
+	if err == nil {
+		err = server.notify("notifications/initialized")
+	}
+	if err == nil {
+		err = server.call("tools/list", nil, &listed)
+	}
+	if err != nil {
+		server.kill()
+		server.stop()
+		return nil, nil, common.NewError(common.ErrorTypeValidation, "server failed the MCP handshake", err).
+			WithSuggestion("Probe the server to see which step fails")
+	}
+
+	schemas := map[string]map[string]interface{}{}
+	for _, tool := range listed.Tools {
+		schemas[tool.Name] = tool.InputSchema
+	}
+	return server, schemas, nil
+}
+
+// probeSession sends requests to a running server and collects its replies
+type probeSession struct {
+	ctx   context.Context
+	stdin io.Writer
+	lines <-chan []byte
+	// callTimeout, when set, bounds the wait for each reply
+	callTimeout time.Duration
+	nextID      int
+	exchanges   []ProbeExchange
+}
+
+// Errors of a session whose server stopped answering
+var (
+	errNoReply      = errors.New("no reply")
+	errServerClosed = errors.New("server closed its output")
+)
+
+// rpcReply is a JSON-RPC response as read from the server
+type rpcReply struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *probeSession) run(result *ProbeResult, opts ProbeOptions, upstream *upstreamRecorder) {
+	fail := func(format string, args ...interface{}) {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+	}
+
+	var initialized struct {
+		ProtocolVersion string                     `json:"protocolVersion"`
+		Capabilities    map[string]json.RawMessage `json:"capabilities"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	if err := p.call("initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "mcpweaver-probe", "version": "1.0.0"},
+	}, &initialized); err != nil {
+		// Nothing else is meaningful without a handshake
+		fail("initialize: %v", err)
+		return
+	}
+	result.ProtocolVersion = initialized.ProtocolVersion
+	result.ServerName, result.ServerVersion = initialized.ServerInfo.Name, initialized.ServerInfo.Version
+	if initialized.ProtocolVersion == "" {
+		fail("initialize: no protocolVersion")
+	}
+	if initialized.ServerInfo.Name == "" {
+		fail("initialize: no serverInfo.name")
+	}
+	if _, ok := initialized.Capabilities["tools"]; !ok {
+		fail("initialize: tools capability not announced")
+	}
+
+	// The server must not answer notifications; a reply would surface as an
+	// unexpected ID on the next request
+	if err := p.notify("notifications/initialized"); err != nil {
+		fail("notifications/initialized: %v", err)
+		return
+	}
+	if err := p.call("ping", nil, nil); err != nil {
+		fail("ping: %v", err)
+	}
+
+	var listed struct {
+		Tools []struct {
+			Name        string          `json:"name"`
+			InputSchema json.RawMessage `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := p.call("tools/list", nil, &listed); err != nil {
+		fail("tools/list: %v", err)
+		return
+	}
+	schemas := map[string]json.RawMessage{}
+	for _, tool := range listed.Tools {
+		if tool.Name == "" {
+			fail("tools/list: tool without a name")
+			continue
+		}
+		if _, ok := schemas[tool.Name]; ok {
+			fail("tools/list: tool %s is listed twice", tool.Name)
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal(tool.InputSchema, &schema); err != nil || schema["type"] != "object" {
+			fail("tools/list: tool %s has no object inputSchema", tool.Name)
+		}
+		schemas[tool.Name] = tool.InputSchema
+		result.Tools = append(result.Tools, tool.Name)
+	}
+
+	result.Tool = opts.Tool
+	if result.Tool == "" && len(result.Tools) > 0 {
+		result.Tool = result.Tools[0]
+	}
+	if result.Tool != "" {
+		p.callTool(result, opts, schemas, upstream, fail)
+	}
+
+	var rpcErr *probeRPCError
+	switch err := p.call("mcpweaver/probe", nil, nil); {
+	case err == nil:
+		fail("unknown method: expected error -32601, got a result")
+	case !errors.As(err, &rpcErr) || rpcErr.code != -32601:
+		fail("unknown method: expected error -32601, got %v", err)
+	}
+}
+
+func (p *probeSession) callTool(result *ProbeResult, opts ProbeOptions, schemas map[string]json.RawMessage, upstream *upstreamRecorder, fail func(string, ...interface{})) {
+	args := opts.Arguments
+	if args == nil {
+		var schema map[string]interface{}
+		if raw, ok := schemas[result.Tool]; ok {
+			json.Unmarshal(raw, &schema)
+		}
+		args, _ = exampleValue(schema, 0).(map[string]interface{})
+	}
+
+	var called struct {
+		Content []struct {
+			Type string `json:"type"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	before := len(upstream.requests())
+	if err := p.call("tools/call", map[string]interface{}{"name": result.Tool, "arguments": args}, &called); err != nil {
+		fail("tools/call %s: %v", result.Tool, err)
+		return
+	}
+	result.Upstream = upstream.requests()[before:]
+	result.ToolError = called.IsError
+	if len(called.Content) == 0 {
+		fail("tools/call %s: result has no content", result.Tool)
+	}
+	for _, content := range called.Content {
+		if content.Type == "" {
+			fail("tools/call %s: content without a type", result.Tool)
+		}
+	}
+	if !called.IsError && len(result.Upstream) == 0 {
+		fail("tools/call %s: succeeded without calling the API", result.Tool)
+	}
+}
+
+// probeRPCError is a JSON-RPC error returned by the server
+type probeRPCError struct {
+	code    int
+	message string
+}
+
+func (e *probeRPCError) Error() string {
+	return fmt.Sprintf("error %d: %s", e.code, e.message)
+}
+
+// call sends a request and decodes the result of the reply into out
+func (p *probeSession) call(method string, params interface{}, out interface{}) error {
+	p.nextID++
+	id := p.nextID
+	request := map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	line, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing request: %w", err)
+	}
+	var expired <-chan time.Time
+	if p.callTimeout > 0 {
+		timer := time.NewTimer(p.callTimeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	var response []byte
+	select {
+	case response = <-p.lines:
+	case <-expired:
+		return fmt.Errorf("%w within %s", errNoReply, p.callTimeout)
+	case <-p.ctx.Done():
+		return fmt.Errorf("%w: %v", errNoReply, p.ctx.Err())
+	}
+	exchange := ProbeExchange{Method: method, Request: line, Duration: time.Since(start)}
+	if response == nil {
+		p.exchanges = append(p.exchanges, exchange)
+		return errServerClosed
+	}
+	exchange.Response = response
+	p.exchanges = append(p.exchanges, exchange)
+
+	var reply rpcReply
+	if err := json.Unmarshal(response, &reply); err != nil {
+		return fmt.Errorf("reply is not JSON: %v", err)
+	}
+	if reply.JSONRPC != "2.0" {
+		return fmt.Errorf("reply has jsonrpc %q, expected \"2.0\"", reply.JSONRPC)
+	}
+	if string(reply.ID) != fmt.Sprint(id) {
+		return fmt.Errorf("reply has ID %s, expected %d", reply.ID, id)
+	}
+	if reply.Error != nil {
+		if reply.Result != nil {
+			return fmt.Errorf("reply has both a result and an error")
+		}
+		return &probeRPCError{code: reply.Error.Code, message: reply.Error.Message}
+	}
+	if reply.Result == nil {
+		return fmt.Errorf("reply has neither a result nor an error")
+	}
+	if out != nil {
+		if err := json.Unmarshal(reply.Result, out); err != nil {
+			return fmt.Errorf("unexpected result: %v", err)
+		}
+	}
+	return nil
+}
+
+// notify sends a notification, which gets no reply
+func (p *probeSession) notify(method string) error {
+	line, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": method})
+	if err != nil {
+		return err
+	}
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing notification: %w", err)
+	}
+	p.exchanges = append(p.exchanges, ProbeExchange{Method: method, Request: line})
+	return nil
+}
+
+// readLines delivers the non-empty lines of r, then nil once it is closed
+func readLines(r io.Reader) <-chan []byte {
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				lines <- append([]byte(nil), line...)
+			}
+		}
+	}()
+	return lines
+}
+
+// probeEnv drops variables that could point the server at another config
+// file or the real API instead of the stand-in
+func probeEnv(environ []string) []string {
+	var env []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasSuffix(name, "_BASE_URL") || strings.HasSuffix(name, "_CONFIG") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// upstreamRecorder stands in for the upstream API and remembers every
+// request. It answers with an empty JSON object or, with fixtures, with
+// recorded responses.
+type upstreamRecorder struct {
+	fixtures FixtureOptions
+	// latency delays every response, simulating a remote API
+	latency  time.Duration
+	mu       sync.Mutex
+	seen     []string
+	problems []string
+}
+
+func (u *upstreamRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := r.Method + " " + r.URL.RequestURI()
+	u.mu.Lock()
+	u.seen = append(u.seen, request)
+	u.mu.Unlock()
+	if u.latency > 0 {
+		time.Sleep(u.latency)
+	}
+
+	if u.fixtures.Mode == FixturesOff {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxFixtureBody))
+	if err == nil {
+		name := fixtureFile(u.fixtures.Dir, r.Method, r.URL.RequestURI(), body)
+		if u.fixtures.Mode == FixturesRecord {
+			err = recordFixture(w, r, body, u.fixtures.Upstream, name)
+		} else {
+			err = replayFixture(w, name)
+		}
+	}
+	if err != nil {
+		u.mu.Lock()
+		u.problems = append(u.problems, fmt.Sprintf("%s: %v", request, err))
+		u.mu.Unlock()
+		// 501 is not retried by generated servers, unlike 502-504
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	}
+}
+
+func (u *upstreamRecorder) requests() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.seen...)
+}
+
+// failures lists requests that could not be recorded or replayed
+func (u *upstreamRecorder) failures() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.problems...)
+}
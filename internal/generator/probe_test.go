@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeServer(t *testing.T) {
+	dir := generateTestServer(t, "users.yaml")
+
+	result, err := NewService().ProbeServer(context.Background(), ProbeOptions{Dir: dir})
+	require.NoError(t, err)
+	assert.Empty(t, result.Failures)
+	assert.NotEmpty(t, result.ProtocolVersion)
+	assert.NotEmpty(t, result.ServerName)
+	assert.ElementsMatch(t, []string{"list_users", "create_user", "get_user"}, result.Tools)
+	assert.Equal(t, result.Tools[0], result.Tool, "the first tool is called by default")
+	assert.NotEmpty(t, result.Upstream, "the tool call reaches the stand-in API")
+	assert.NotEmpty(t, result.ClaudeDesktopConfig)
+
+	result, err = NewService().ProbeServer(context.Background(), ProbeOptions{
+		Dir:       dir,
+		Tool:      "get_user",
+		Arguments: map[string]interface{}{"id": "42"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "get_user", result.Tool)
+	require.Len(t, result.Upstream, 1)
+	assert.Equal(t, "GET /users/42", result.Upstream[0])
+
+	_, err = NewService().ProbeServer(context.Background(), ProbeOptions{Dir: dir, Tool: "delete_user"})
+	assert.Error(t, err, "unknown tools are reported")
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"MCPWeaver/internal/common"
+)
+
+// Profile is a named bundle of generation options
+type Profile struct {
+	Name        string
+	Description string
+	// apply enables the profile's features; options already enabled by the
+	// caller are kept
+	apply func(opts *Options)
+}
+
+// DefaultProfile is used when no profile is selected; it enables nothing so
+// the options alone decide what is generated
+const DefaultProfile = "minimal"
+
+// Profiles lists the built-in generation profiles from leanest to fullest
+var Profiles = []Profile{
+	{
+		Name:        "minimal",
+		Description: "Bare stdio server with logging and config loading only",
+		apply:       func(opts *Options) {},
+	},
+	{
+		Name:        "standard",
+		Description: "Adds runtime validation of tool arguments and responses",
+		apply: func(opts *Options) {
+			opts.Validation = true
+		},
+	},
+	{
+		Name:        "production",
+		Description: "Adds rate limiting, retries, a Dockerfile, build verification and security scans",
+		apply: func(opts *Options) {
+			opts.Validation = true
+			opts.RateLimiting = true
+			opts.Retries = true
+			opts.Docker = true
+			opts.VerifyBuild = true
+			opts.SecurityScan = true
+		},
+	},
+}
+
+// FindProfile looks up a built-in profile by name
+func FindProfile(name string) (Profile, error) {
+	names := make([]string, len(Profiles))
+	for i, profile := range Profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+		names[i] = profile.Name
+	}
+	return Profile{}, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("unknown profile %q", name), nil).
+		WithSuggestion("Use one of: " + strings.Join(names, ", "))
+}
+
+// applyProfile enables the features of the selected profile on top of the
+// options set explicitly
+func applyProfile(opts Options) (Options, error) {
+	if opts.Profile == "" {
+		opts.Profile = DefaultProfile
+	}
+	profile, err := FindProfile(opts.Profile)
+	if err != nil {
+		return opts, err
+	}
+	profile.apply(&opts)
+	return opts, nil
+}
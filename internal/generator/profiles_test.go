@@ -0,0 +1,28 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProfile(t *testing.T) {
+	opts, err := applyProfile(Options{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultProfile, opts.Profile)
+	assert.False(t, opts.SecurityScan)
+
+	opts, err = applyProfile(Options{Profile: "minimal", SecurityScan: true})
+	require.NoError(t, err)
+	assert.True(t, opts.SecurityScan, "options set explicitly are kept")
+
+	opts, err = applyProfile(Options{Profile: "production"})
+	require.NoError(t, err)
+	assert.True(t, opts.Validation)
+	assert.True(t, opts.VerifyBuild)
+	assert.True(t, opts.SecurityScan)
+
+	_, err = applyProfile(Options{Profile: "enterprise"})
+	assert.Error(t, err)
+}
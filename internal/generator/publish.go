@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// EventPublishProgress is the name of events emitted while publishing
+const EventPublishProgress = "template:publish_progress"
+
+// checksumsFile lists the SHA-256 of every file in a published archive
+const checksumsFile = "SHA256SUMS"
+
+// Publish stages, in order
+const (
+	PublishValidating = "validating"
+	PublishPackaging  = "packaging"
+	PublishUploading  = "uploading"
+	PublishDone       = "done"
+)
+
+var (
+	packageNamePattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	packageVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?$`)
+)
+
+// PublishOptions configures where a template package is published
+type PublishOptions struct {
+	// Registry is the base URL of the template registry
+	Registry string
+	// Token is sent as a bearer token when set
+	Token string
+	// Progress receives an event when each stage starts and as the archive
+	// uploads
+	Progress func(PublishEvent)
+}
+
+// PublishEvent reports the progress of a publish
+type PublishEvent struct {
+	Name  string
+	Stage string
+	// Sent and Total count uploaded bytes during the uploading stage
+	Sent  int64
+	Total int64
+}
+
+// PublishResult describes a published template package
+type PublishResult struct {
+	Name    string
+	Version string
+	// URL is where the registry stored the package
+	URL string
+	// Checksum is the hex SHA-256 of the uploaded archive
+	Checksum string
+	Files    []string
+	Size     int64
+	Duration time.Duration
+}
+
+// PublishTemplate validates the template package selected by the options,
+// packages it as a zip archive with a SHA256SUMS file, and uploads it with
+// PUT to {registry}/templates/{name}/{version}. The manifest must carry a
+// name, version and description, and the package must validate without
+// errors.
+func (s *Service) PublishTemplate(ctx context.Context, opts Options, publish PublishOptions) (*PublishResult, error) {
+	start := time.Now()
+	progress := func(event PublishEvent) {
+		if publish.Progress != nil {
+			event.Name = EventPublishProgress
+			publish.Progress(event)
+		}
+	}
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	endpoint, err := url.Parse(publish.Registry)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid registry URL %q", publish.Registry), err).
+			WithSuggestion("Use an http or https URL")
+	}
+
+	progress(PublishEvent{Stage: PublishValidating})
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	m, manifestName, err := source.manifest()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to load template manifest", err).
+			WithFile(manifestName)
+	}
+	if err := checkPublishMetadata(m); err != nil {
+		return nil, err.WithFile(manifestName)
+	}
+	issues, err := s.ValidateTemplates(opts)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			problems = append(problems, issue.String())
+		}
+	}
+	if len(problems) > 0 {
+		return nil, common.NewError(common.ErrorTypeValidation,
+			"template package has errors:\n  "+strings.Join(problems, "\n  "), nil).
+			WithSuggestion("Fix the reported issues before publishing")
+	}
+
+	progress(PublishEvent{Stage: PublishPackaging})
+	files, err := packageFiles(source)
+	if err != nil {
+		return nil, err
+	}
+	var sums strings.Builder
+	for _, file := range files {
+		sum := sha256.Sum256(file.Content)
+		fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(sum[:]), file.Name)
+	}
+	files = append(files, exportFile{Name: checksumsFile, Content: []byte(sums.String()), Synthesized: true})
+
+	var archive bytes.Buffer
+	if err := writeZip(&archive, files); err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to package template", err)
+	}
+	sum := sha256.Sum256(archive.Bytes())
+	result := &PublishResult{
+		Name:     m.Name,
+		Version:  m.Version,
+		Checksum: hex.EncodeToString(sum[:]),
+		Size:     int64(archive.Len()),
+	}
+	for _, file := range files {
+		result.Files = append(result.Files, file.Name)
+	}
+
+	target := endpoint.JoinPath("templates", m.Name, m.Version)
+	body := &progressReader{r: &archive, report: func(sent int64) {
+		progress(PublishEvent{Stage: PublishUploading, Sent: sent, Total: result.Size})
+	}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), body)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeNetwork, "failed to create upload request", err)
+	}
+	req.ContentLength = result.Size
+	req.Header.Set("Content-Type", "application/zip")
+	req.Header.Set("X-Checksum-Sha256", result.Checksum)
+	if publish.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+publish.Token)
+	}
+
+	progress(PublishEvent{Stage: PublishUploading, Total: result.Size})
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeNetwork, "failed to upload template package", err).
+			WithFile(target.String()).
+			WithSuggestion(common.ConnectionSuggestion(req))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, publishError(resp).WithFile(target.String())
+	}
+
+	result.URL = target.String()
+	if location, err := resp.Location(); err == nil {
+		result.URL = location.String()
+	}
+	result.Duration = time.Since(start)
+	progress(PublishEvent{Stage: PublishDone, Sent: result.Size, Total: result.Size})
+	return result, nil
+}
+
+// checkPublishMetadata checks the manifest fields a registry relies on
+func checkPublishMetadata(m *manifest) *common.Error {
+	switch {
+	case !packageNamePattern.MatchString(m.Name):
+		return common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid package name %q", m.Name), nil).
+			WithSuggestion("Use lowercase letters, digits and dashes")
+	case !packageVersionPattern.MatchString(m.Version):
+		return common.NewError(common.ErrorTypeValidation, fmt.Sprintf("invalid package version %q", m.Version), nil).
+			WithSuggestion(`Set "version" in the manifest to a semantic version such as 1.0.0`)
+	case strings.TrimSpace(m.Description) == "":
+		return common.NewError(common.ErrorTypeValidation, "package description is empty", nil).
+			WithSuggestion(`Set "description" in the manifest`)
+	}
+	return nil
+}
+
+// publishError describes a rejected upload using the registry's response
+func publishError(resp *http.Response) *common.Error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	message := fmt.Sprintf("registry rejected the package: %s", resp.Status)
+	if text := strings.TrimSpace(string(detail)); text != "" {
+		message += ": " + text
+	}
+	err := common.NewError(common.ErrorTypeNetwork, message, nil)
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		err.WithSuggestion("Check the registry token")
+	case http.StatusConflict:
+		err.WithSuggestion("This version is already published; bump the manifest version")
+	case http.StatusProxyAuthRequired:
+		err.WithSuggestion(common.ProxyAuthSuggestion)
+	}
+	return err
+}
+
+// progressReader reports the bytes read through it
+type progressReader struct {
+	r      io.Reader
+	sent   int64
+	report func(sent int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.report(p.sent)
+	}
+	return n, err
+}
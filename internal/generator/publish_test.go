@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPublishMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest manifest
+		wantErr  string
+	}{
+		{name: "valid", manifest: manifest{Name: "acme-go", Version: "1.2.0", Description: "Acme servers"}},
+		{name: "prerelease", manifest: manifest{Name: "acme", Version: "1.2.0-rc.1", Description: "Acme"}},
+		{name: "uppercase name", manifest: manifest{Name: "Acme", Version: "1.0.0", Description: "Acme"}, wantErr: "invalid package name"},
+		{name: "path in name", manifest: manifest{Name: "../acme", Version: "1.0.0", Description: "Acme"}, wantErr: "invalid package name"},
+		{name: "partial version", manifest: manifest{Name: "acme", Version: "1.0", Description: "Acme"}, wantErr: "invalid package version"},
+		{name: "no description", manifest: manifest{Name: "acme", Version: "1.0.0", Description: " "}, wantErr: "description is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPublishMetadata(&tt.manifest)
+			if tt.wantErr == "" {
+				assert.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPublishTemplate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "acme")
+	_, err := NewService().CopyTemplate(Options{}, dir)
+	require.NoError(t, err)
+	manifestPath := filepath.Join(dir, manifestFile)
+	content, err := os.ReadFile(manifestPath)
+	require.NoError(t, err)
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &m))
+	m["name"], m["version"] = "acme-go", "1.2.0"
+	content, err = json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(manifestPath, content, 0644))
+
+	var uploaded []byte
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/templates/acme-go/1.2.0", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		uploaded, _ = io.ReadAll(r.Body)
+		sum := sha256.Sum256(uploaded)
+		assert.Equal(t, hex.EncodeToString(sum[:]), r.Header.Get("X-Checksum-Sha256"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer registry.Close()
+
+	var stages []string
+	result, err := NewService().PublishTemplate(context.Background(), Options{TemplateDir: dir}, PublishOptions{
+		Registry: registry.URL,
+		Token:    "secret",
+		Progress: func(event PublishEvent) {
+			if len(stages) == 0 || stages[len(stages)-1] != event.Stage {
+				stages = append(stages, event.Stage)
+			}
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{PublishValidating, PublishPackaging, PublishUploading, PublishDone}, stages)
+	assert.Equal(t, registry.URL+"/templates/acme-go/1.2.0", result.URL)
+	assert.Equal(t, int64(len(uploaded)), result.Size)
+
+	archive, err := zip.NewReader(bytes.NewReader(uploaded), int64(len(uploaded)))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, result.Files, names)
+	assert.Contains(t, names, checksumsFile)
+
+	registry.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})
+	_, err = NewService().PublishTemplate(context.Background(), Options{TemplateDir: dir}, PublishOptions{Registry: registry.URL})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "409")
+}
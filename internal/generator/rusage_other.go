@@ -0,0 +1,10 @@
+//go:build !unix
+
+package generator
+
+import "os"
+
+// peakMemory is not reported on this platform
+func peakMemory(state *os.ProcessState) int64 {
+	return 0
+}
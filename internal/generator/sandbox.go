@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// Render limits applied when the options leave them unset
+const (
+	DefaultRenderTimeout = 10 * time.Second
+	DefaultMaxRenderSize = 8 << 20
+)
+
+// errRenderAborted stops a template whose render was abandoned
+var errRenderAborted = errors.New("render aborted")
+
+// sandboxFuncs is funcMap with the builtins that reach outside the template
+// data replaced. The remaining functions are pure.
+var sandboxFuncs = func() template.FuncMap {
+	funcs := template.FuncMap{
+		"call": func(...interface{}) (interface{}, error) {
+			return nil, errors.New("call is not available in templates")
+		},
+	}
+	for name, fn := range funcMap {
+		funcs[name] = fn
+	}
+	return funcs
+}()
+
+// renderLimits returns the timeout and per-file output cap for the options
+func renderLimits(opts Options) (time.Duration, int) {
+	timeout, maxSize := opts.RenderTimeout, opts.MaxRenderSize
+	if timeout <= 0 {
+		timeout = DefaultRenderTimeout
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxRenderSize
+	}
+	return timeout, maxSize
+}
+
+// limitedBuffer fails writes past max bytes or after the render was
+// abandoned, which stops the executing template at its next output
+type limitedBuffer struct {
+	buf     bytes.Buffer
+	max     int
+	aborted chan struct{}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	select {
+	case <-b.aborted:
+		return 0, errRenderAborted
+	default:
+	}
+	if b.buf.Len()+len(p) > b.max {
+		return 0, fmt.Errorf("output exceeds %d bytes", b.max)
+	}
+	return b.buf.Write(p)
+}
+
+// execute runs tmpl against data, giving up once timeout elapses. A template
+// that loops without writing cannot be interrupted, so it is abandoned and
+// left to finish in the background.
+func execute(tmpl *template.Template, data interface{}, timeout time.Duration, maxSize int) ([]byte, error) {
+	out := &limitedBuffer{max: maxSize, aborted: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		done <- tmpl.Execute(out, data)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, err
+		}
+		return out.buf.Bytes(), nil
+	case <-timer.C:
+		close(out.aborted)
+		return nil, fmt.Errorf("template did not finish within %s; it stops at its next output, and one looping without output keeps running until mcpweaver exits", timeout)
+	}
+}
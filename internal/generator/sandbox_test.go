@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"sync/atomic"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/transformer"
+)
+
+func TestEvaluate(t *testing.T) {
+	data := &TemplateData{
+		Server:  &transformer.MCPServer{Name: "petstore"},
+		Options: Options{Validation: true, MaxRenderSize: 32},
+	}
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "path", text: "cmd/{{ .Server.Name }}/main.go", want: "cmd/petstore/main.go"},
+		{name: "condition", text: "{{if .Options.Validation}}true{{end}}", want: "true"},
+		{name: "trimmed", text: "  docs/README.md\n", want: "docs/README.md"},
+		{name: "missing key", text: "{{ .Vars.missing }}", wantErr: "missing"},
+		{name: "call is sandboxed", text: `{{ call .Server.Name }}`, wantErr: "call is not available"},
+		{name: "output limit", text: `{{ printf "%040d" 0 }}`, wantErr: "exceeds 32 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluate(tt.text, data)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	var steps atomic.Int32
+	tmpl := template.Must(template.New("slow").Funcs(template.FuncMap{
+		"step": func() int {
+			time.Sleep(10 * time.Millisecond)
+			return int(steps.Add(1))
+		},
+	}).Parse(`{{range .}}{{step}}{{end}}`))
+
+	_, err := execute(tmpl, make([]struct{}, 1000), 50*time.Millisecond, DefaultMaxRenderSize)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template did not finish within 50ms")
+	assert.Contains(t, err.Error(), "keeps running until mcpweaver exits", "the error tells what cannot be stopped")
+
+	// The step in progress at the timeout finishes, and its output stops
+	// the template
+	abandoned := steps.Load()
+	time.Sleep(100 * time.Millisecond)
+	assert.LessOrEqual(t, steps.Load(), abandoned+1)
+
+	steps.Store(0)
+	out, err := execute(tmpl, make([]struct{}, 3), time.Second, DefaultMaxRenderSize)
+	require.NoError(t, err)
+	assert.Equal(t, "123", string(out))
+}
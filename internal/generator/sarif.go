@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SARIF version written by WriteSARIF, the one GitHub code scanning accepts
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifLog is the subset of the SARIF format the findings need
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// checkDescriptions describe the checks as SARIF rules
+var checkDescriptions = map[string]string{
+	CheckGofmt:       "Generated Go source is not gofmt formatted",
+	CheckGoimports:   "Generated Go source is not goimports formatted",
+	CheckGoVet:       "go vet reported a problem in the generated server",
+	CheckGoBuild:     "The generated server does not compile",
+	CheckGosec:       "gosec reported a security problem in the generated server",
+	CheckGovulncheck: "The generated server calls code with a known vulnerability",
+}
+
+// SARIFResult is one result of a SARIF log. Results of the same rule share
+// its name and description.
+type SARIFResult struct {
+	RuleID          string
+	RuleName        string
+	RuleDescription string
+	// Level is "error", "warning" or "note"
+	Level   string
+	Message string
+	// File is relative to the source root; Line and Column are optional
+	File   string
+	Line   int
+	Column int
+}
+
+// WriteSARIF writes findings as a SARIF log, so they can be uploaded to code
+// scanning services such as GitHub's. File paths are relative to the output
+// directory; findings without a file are attributed to go.mod, as SARIF
+// results need a location. version is the mcpweaver version.
+func WriteSARIF(w io.Writer, version string, findings []Finding) error {
+	results := make([]SARIFResult, len(findings))
+	for i, f := range findings {
+		description := checkDescriptions[f.Check]
+		if description == "" {
+			description = f.Check
+		}
+		results[i] = SARIFResult{
+			RuleID:          sarifRuleID(f.Check),
+			RuleName:        f.Check,
+			RuleDescription: description,
+			Level:           sarifLevel(f.Check),
+			Message:         f.Message,
+			File:            f.File,
+			Line:            f.Line,
+			Column:          f.Column,
+		}
+		if f.File == "" {
+			results[i].File = "go.mod"
+		}
+	}
+	return WriteSARIFResults(w, version, results)
+}
+
+// WriteSARIFResults writes results as a SARIF log of one mcpweaver run
+func WriteSARIFResults(w io.Writer, version string, results []SARIFResult) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "mcpweaver", Version: version, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+
+	seen := map[string]bool{}
+	for _, r := range results {
+		if !seen[r.RuleID] {
+			seen[r.RuleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               r.RuleID,
+				Name:             r.RuleName,
+				ShortDescription: sarifMessage{Text: r.RuleDescription},
+			})
+		}
+
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: r.File, URIBaseID: "%SRCROOT%"},
+		}
+		if r.Line > 0 {
+			location.Region = &sarifRegion{StartLine: r.Line, StartColumn: r.Column}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    r.RuleID,
+			Level:     r.Level,
+			Message:   sarifMessage{Text: r.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// sarifRuleID turns a check name into a rule id, e.g. "go vet" into "go-vet"
+func sarifRuleID(check string) string {
+	return strings.ReplaceAll(check, " ", "-")
+}
+
+// sarifLevel maps a check to a SARIF level. Compile errors break the server
+// and reachable vulnerabilities are exploitable; everything else is a
+// warning.
+func sarifLevel(check string) string {
+	if check == CheckGoBuild || check == CheckGovulncheck {
+		return "error"
+	}
+	return "warning"
+}
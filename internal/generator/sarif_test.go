@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	findings := []Finding{
+		{Check: CheckGoVet, File: "main.go", Line: 12, Column: 3, Message: "unreachable code"},
+		{Check: CheckGoBuild, File: "tools.go", Line: 40, Message: "undefined: client"},
+		{Check: CheckGoVet, File: "tools.go", Line: 7, Message: "printf verb mismatch"},
+		{Check: CheckGovulncheck, Message: "GO-2024-0001 is called"},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, "1.2.3", findings))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, sarifVersion, log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "mcpweaver", run.Tool.Driver.Name)
+	assert.Equal(t, "1.2.3", run.Tool.Driver.Version)
+
+	var rules []string
+	for _, rule := range run.Tool.Driver.Rules {
+		rules = append(rules, rule.ID)
+	}
+	assert.Equal(t, []string{"go-build", "go-vet", "govulncheck"}, rules, "one rule per check, sorted")
+
+	require.Len(t, run.Results, 4)
+	vet := run.Results[0]
+	assert.Equal(t, "go-vet", vet.RuleID)
+	assert.Equal(t, "warning", vet.Level)
+	assert.Equal(t, "unreachable code", vet.Message.Text)
+	assert.Equal(t, "main.go", vet.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 12, StartColumn: 3}, vet.Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "error", run.Results[1].Level, "compile errors are errors")
+
+	vuln := run.Results[3]
+	assert.Equal(t, "error", vuln.Level)
+	assert.Equal(t, "go.mod", vuln.Locations[0].PhysicalLocation.ArtifactLocation.URI, "findings without a file are attributed to go.mod")
+	assert.Nil(t, vuln.Locations[0].PhysicalLocation.Region)
+}
+
+func TestWriteSARIFEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, "dev", nil))
+	assert.Contains(t, buf.String(), `"results": []`)
+	assert.Contains(t, buf.String(), `"rules": []`)
+}
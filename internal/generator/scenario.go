@@ -0,0 +1,351 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"MCPWeaver/internal/common"
+)
+
+// DefaultScenarioCallTimeout is how long a scenario step may wait for its
+// reply
+const DefaultScenarioCallTimeout = 30 * time.Second
+
+// Scenario is a sequence of tool calls and the responses expected from them,
+// read from a YAML file:
+//
+//	name: create and fetch a pet
+//	setup:
+//	  - tool: delete_pet
+//	    arguments: {petId: "7"}
+//	steps:
+//	  - tool: create_pet
+//	    arguments: {name: Rex}
+//	    expect:
+//	      contains: ["Rex"]
+//	      upstream: ["POST /pets"]
+//	teardown:
+//	  - tool: delete_pet
+//	    arguments: {petId: "7"}
+//
+// A failed step skips the rest of its phase and a failed setup skips the
+// steps; teardown always runs.
+type Scenario struct {
+	Name     string         `yaml:"name"`
+	Setup    []ScenarioStep `yaml:"setup"`
+	Steps    []ScenarioStep `yaml:"steps"`
+	Teardown []ScenarioStep `yaml:"teardown"`
+}
+
+// ScenarioStep is one tool call of a scenario
+type ScenarioStep struct {
+	Tool      string                 `yaml:"tool"`
+	Arguments map[string]interface{} `yaml:"arguments"`
+	Expect    ScenarioExpect         `yaml:"expect"`
+}
+
+// ScenarioExpect holds the assertions on a tool call. The call must always
+// return a result rather than a JSON-RPC error.
+type ScenarioExpect struct {
+	// Error expects an error result; otherwise the result must succeed
+	Error bool `yaml:"error"`
+	// Contains lists text every one of which the result text must contain
+	Contains []string `yaml:"contains"`
+	// Matches is a regular expression the result text must match
+	Matches string `yaml:"matches"`
+	// Upstream lists the API requests the call must send, in order, as
+	// "METHOD /path?query"
+	Upstream []string `yaml:"upstream"`
+}
+
+// ScenarioOptions controls a scenario run
+type ScenarioOptions struct {
+	// Dir is the directory containing the generated server
+	Dir string
+	// ScenarioDir holds the *.yaml scenario files; defaults to "scenarios"
+	// inside Dir
+	ScenarioDir string
+	// Fixtures answers upstream requests from recorded responses, which
+	// gives the response assertions something to check
+	Fixtures FixtureOptions
+	// CallTimeout defaults to DefaultScenarioCallTimeout
+	CallTimeout time.Duration
+	// Env holds extra NAME=value variables for the server, e.g. credentials
+	Env []string
+	// Image, when set, compiles and runs the server in disposable docker
+	// containers of this image instead of with the host toolchain
+	Image string
+}
+
+// ScenarioStepResult is the outcome of one step
+type ScenarioStepResult struct {
+	// Phase is "setup", "step" or "teardown"
+	Phase string
+	Tool  string
+	// Text joins the text content of the result
+	Text     string
+	IsError  bool
+	Upstream []string
+	// Failures are the expectations the call did not meet
+	Failures []string
+	Duration time.Duration
+}
+
+// ScenarioResult is the outcome of one scenario
+type ScenarioResult struct {
+	Name string
+	File string
+	// Failures explain why the scenario could not run; step failures are
+	// reported with the steps
+	Failures []string
+	Steps    []ScenarioStepResult
+	Duration time.Duration
+}
+
+// Passed reports whether the scenario ran and met every expectation
+func (r ScenarioResult) Passed() bool {
+	if len(r.Failures) > 0 {
+		return false
+	}
+	for _, step := range r.Steps {
+		if len(step.Failures) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ScenarioRunResult lists the scenarios in file order
+type ScenarioRunResult struct {
+	Scenarios []ScenarioResult
+	Duration  time.Duration
+}
+
+// loadedScenario is a parsed scenario file
+type loadedScenario struct {
+	file     string
+	scenario Scenario
+	matches  []*regexp.Regexp
+}
+
+// RunScenarios runs the YAML scenarios of a generated server. Each scenario
+// gets a fresh server process; the error reports when any scenario failed.
+func (s *Service) RunScenarios(ctx context.Context, opts ScenarioOptions) (*ScenarioRunResult, error) {
+	start := time.Now()
+	callTimeout := opts.CallTimeout
+	if callTimeout <= 0 {
+		callTimeout = DefaultScenarioCallTimeout
+	}
+	scenarioDir := opts.ScenarioDir
+	if scenarioDir == "" {
+		scenarioDir = filepath.Join(opts.Dir, "scenarios")
+	}
+	scenarios, err := loadScenarios(scenarioDir)
+	if err != nil {
+		return nil, err
+	}
+
+	harness, err := newProbeHarness(opts.Dir, opts.Env, opts.Fixtures, opts.Image)
+	if err != nil {
+		return nil, err
+	}
+	defer harness.close()
+
+	result := &ScenarioRunResult{}
+	failed := 0
+	for _, loaded := range scenarios {
+		scenario := runScenario(ctx, harness, callTimeout, loaded)
+		if !scenario.Passed() {
+			failed++
+		}
+		result.Scenarios = append(result.Scenarios, scenario)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	result.Duration = time.Since(start)
+
+	if ctx.Err() != nil {
+		return result, common.NewError(common.ErrorTypeGeneration, "scenario run cancelled", ctx.Err())
+	}
+	if failed > 0 {
+		return result, common.NewError(common.ErrorTypeValidation,
+			fmt.Sprintf("%d of %d scenarios failed", failed, len(scenarios)), nil)
+	}
+	return result, nil
+}
+
+// loadScenarios parses the scenario files of a directory, sorted by name
+func loadScenarios(dir string) ([]loadedScenario, error) {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeValidation, "failed to list scenarios", err).WithFile(dir)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, common.NewError(common.ErrorTypeValidation, "no scenarios found", nil).
+			WithFile(dir).
+			WithSuggestion("Add a *.yaml file with a name and steps for each scenario")
+	}
+
+	var scenarios []loadedScenario
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeValidation, "failed to read scenario", err).WithFile(file)
+		}
+		loaded := loadedScenario{file: file}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&loaded.scenario); err != nil {
+			return nil, common.NewError(common.ErrorTypeValidation, "invalid scenario", err).WithFile(file)
+		}
+		if loaded.scenario.Name == "" {
+			loaded.scenario.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		if len(loaded.scenario.Steps) == 0 {
+			return nil, common.NewError(common.ErrorTypeValidation, "scenario has no steps", nil).WithFile(file)
+		}
+
+		phases := [][]ScenarioStep{loaded.scenario.Setup, loaded.scenario.Steps, loaded.scenario.Teardown}
+		for _, steps := range phases {
+			for _, step := range steps {
+				if step.Tool == "" {
+					return nil, common.NewError(common.ErrorTypeValidation, "scenario step names no tool", nil).WithFile(file)
+				}
+				var matches *regexp.Regexp
+				if step.Expect.Matches != "" {
+					if matches, err = regexp.Compile(step.Expect.Matches); err != nil {
+						return nil, common.NewError(common.ErrorTypeValidation,
+							fmt.Sprintf("invalid matches pattern for %s", step.Tool), err).WithFile(file)
+					}
+				}
+				loaded.matches = append(loaded.matches, matches)
+			}
+		}
+		scenarios = append(scenarios, loaded)
+	}
+	return scenarios, nil
+}
+
+// runScenario runs one scenario on a fresh server
+func runScenario(ctx context.Context, harness *probeHarness, callTimeout time.Duration, loaded loadedScenario) ScenarioResult {
+	start := time.Now()
+	result := ScenarioResult{Name: loaded.scenario.Name, File: loaded.file}
+	server, schemas, err := startClient(ctx, harness, callTimeout)
+	if err != nil {
+		result.Failures = append(result.Failures, err.Error())
+		result.Duration = time.Since(start)
+		return result
+	}
+	defer server.stop()
+
+	index := 0
+	run := func(phase string, steps []ScenarioStep) bool {
+		ok := true
+		for _, step := range steps {
+			matches := loaded.matches[index]
+			index++
+			if !ok && phase != "teardown" {
+				continue
+			}
+			stepResult := runScenarioStep(server.probeSession, harness.upstream, schemas, step, matches)
+			stepResult.Phase = phase
+			result.Steps = append(result.Steps, stepResult)
+			if len(stepResult.Failures) > 0 {
+				ok = false
+			}
+		}
+		return ok
+	}
+	if run("setup", loaded.scenario.Setup) {
+		run("step", loaded.scenario.Steps)
+	} else {
+		index += len(loaded.scenario.Steps)
+		result.Failures = append(result.Failures, "setup failed; steps were skipped")
+	}
+	run("teardown", loaded.scenario.Teardown)
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// runScenarioStep calls a tool and checks the expectations of the step
+func runScenarioStep(session *probeSession, upstream *upstreamRecorder, schemas map[string]map[string]interface{}, step ScenarioStep, matches *regexp.Regexp) ScenarioStepResult {
+	result := ScenarioStepResult{Tool: step.Tool}
+	fail := func(format string, args ...interface{}) {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+	}
+	if _, ok := schemas[step.Tool]; !ok {
+		fail("the server has no tool %q", step.Tool)
+		return result
+	}
+	args := step.Arguments
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	var called struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	start := time.Now()
+	before, problemsBefore := len(upstream.requests()), len(upstream.failures())
+	err := session.call("tools/call", map[string]interface{}{"name": step.Tool, "arguments": args}, &called)
+	result.Duration = time.Since(start)
+	result.Upstream = upstream.requests()[before:]
+	for _, problem := range upstream.failures()[problemsBefore:] {
+		fail("upstream %s", problem)
+	}
+	// The session log would grow with every step
+	session.exchanges = nil
+	if err != nil {
+		fail("tools/call: %v", err)
+		return result
+	}
+
+	var texts []string
+	for _, content := range called.Content {
+		if content.Type == "text" {
+			texts = append(texts, content.Text)
+		}
+	}
+	result.Text = strings.Join(texts, "\n")
+	result.IsError = called.IsError
+
+	expect := step.Expect
+	switch {
+	case expect.Error && !called.IsError:
+		fail("expected an error result, got success")
+	case !expect.Error && called.IsError:
+		fail("expected success, got an error result: %s", tail(result.Text, 200))
+	}
+	for _, text := range expect.Contains {
+		if !strings.Contains(result.Text, text) {
+			fail("result does not contain %q", text)
+		}
+	}
+	if matches != nil && !matches.MatchString(result.Text) {
+		fail("result does not match %q", expect.Matches)
+	}
+	if expect.Upstream != nil && strings.Join(expect.Upstream, "\n") != strings.Join(result.Upstream, "\n") {
+		fail("expected upstream requests %q, got %q", expect.Upstream, result.Upstream)
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeScenarios writes scenario files, by name, to a new directory
+func writeScenarios(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoadScenarios(t *testing.T) {
+	dir := writeScenarios(t, map[string]string{
+		"b-fetch.yml": "steps:\n  - tool: get_user\n    arguments: {id: \"7\"}\n    expect: {matches: '^\\{'}\n",
+		"a-list.yaml": "name: list users\nsetup:\n  - tool: create_user\nsteps:\n  - tool: list_users\nteardown:\n  - tool: delete_user\n",
+		"notes.txt":   "not a scenario",
+	})
+	scenarios, err := loadScenarios(dir)
+	require.NoError(t, err)
+	require.Len(t, scenarios, 2)
+	assert.Equal(t, "list users", scenarios[0].scenario.Name)
+	assert.Equal(t, "b-fetch", scenarios[1].scenario.Name, "scenarios are named after their file by default")
+	assert.Equal(t, map[string]interface{}{"id": "7"}, scenarios[1].scenario.Steps[0].Arguments)
+	require.Len(t, scenarios[0].matches, 3, "one pattern slot per step of every phase")
+	assert.Nil(t, scenarios[0].matches[0])
+	assert.True(t, scenarios[1].matches[0].MatchString("{}"))
+
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"no steps", "name: empty\nsetup:\n  - tool: list_users\n", "scenario has no steps"},
+		{"no tool", "steps:\n  - arguments: {id: 1}\n", "scenario step names no tool"},
+		{"no tool in teardown", "steps:\n  - tool: list_users\nteardown:\n  - expect: {error: true}\n", "scenario step names no tool"},
+		{"unknown field", "steps:\n  - tool: list_users\n    expects: {error: true}\n", "invalid scenario"},
+		{"invalid pattern", "steps:\n  - tool: list_users\n    expect: {matches: '('}\n", "invalid matches pattern for list_users"},
+		{"invalid YAML", "steps: [\n", "invalid scenario"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeScenarios(t, map[string]string{"scenario.yaml": tt.content})
+			_, err := loadScenarios(dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Contains(t, err.Error(), filepath.Join(dir, "scenario.yaml"), "errors name the file")
+		})
+	}
+
+	_, err = loadScenarios(t.TempDir())
+	assert.ErrorContains(t, err, "no scenarios found")
+}
+
+func TestRunScenarios(t *testing.T) {
+	dir := generateTestServer(t, "users.yaml")
+	scenarios := writeScenarios(t, map[string]string{
+		"1-passes.yaml": `name: fetch a user
+setup:
+  - tool: list_users
+steps:
+  - tool: get_user
+    arguments: {id: "7"}
+    expect:
+      matches: '^\{'
+      upstream: ["GET /users/7"]
+  - tool: list_users
+    arguments: {limit: 5}
+    expect:
+      upstream: ["GET /users?limit=5"]
+`,
+		"2-step-fails.yaml": `name: failing step
+steps:
+  - tool: get_user
+    arguments: {id: "7"}
+    expect:
+      error: true
+      contains: ["not found"]
+      upstream: ["GET /users/8"]
+  - tool: list_users
+teardown:
+  - tool: list_users
+`,
+		"3-setup-fails.yaml": `name: failing setup
+setup:
+  - tool: delete_user
+steps:
+  - tool: list_users
+teardown:
+  - tool: list_users
+`,
+	})
+
+	result, err := NewService().RunScenarios(context.Background(), ScenarioOptions{Dir: dir, ScenarioDir: scenarios})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 of 3 scenarios failed")
+	require.Len(t, result.Scenarios, 3)
+
+	passed := result.Scenarios[0]
+	assert.True(t, passed.Passed(), "%+v", passed)
+	require.Len(t, passed.Steps, 3)
+	assert.Equal(t, []string{"setup", "step", "step"}, []string{passed.Steps[0].Phase, passed.Steps[1].Phase, passed.Steps[2].Phase})
+	assert.Equal(t, []string{"GET /users/7"}, passed.Steps[1].Upstream, "upstream requests are attributed to their step")
+
+	failed := result.Scenarios[1]
+	assert.False(t, failed.Passed())
+	require.Len(t, failed.Steps, 2, "a failed step skips the rest of the steps, but not the teardown")
+	assert.Equal(t, []string{
+		"expected an error result, got success",
+		`result does not contain "not found"`,
+		`expected upstream requests ["GET /users/8"], got ["GET /users/7"]`,
+	}, failed.Steps[0].Failures)
+	assert.Equal(t, "teardown", failed.Steps[1].Phase)
+
+	setup := result.Scenarios[2]
+	assert.Equal(t, []string{"setup failed; steps were skipped"}, setup.Failures)
+	require.Len(t, setup.Steps, 2)
+	assert.Equal(t, []string{`the server has no tool "delete_user"`}, setup.Steps[0].Failures)
+	assert.Equal(t, "teardown", setup.Steps[1].Phase)
+	assert.Empty(t, setup.Steps[1].Failures)
+}
+
+func TestRunScenariosWithoutScenarios(t *testing.T) {
+	_, err := NewService().RunScenarios(context.Background(), ScenarioOptions{Dir: t.TempDir()})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no scenarios found")
+}
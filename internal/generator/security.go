@@ -0,0 +1,206 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Security scanners reported in findings
+const (
+	CheckGosec       = "gosec"
+	CheckGovulncheck = "govulncheck"
+)
+
+// scanOutput runs gosec and govulncheck in the output directory. Scanners
+// that are not installed or cannot run, e.g. without access to the
+// vulnerability database, are skipped with a warning.
+func scanOutput(dir string) ([]Finding, []string) {
+	var findings []Finding
+	var warnings []string
+	for _, scan := range []struct {
+		check string
+		args  []string
+		parse func(dir string, output []byte) ([]Finding, error)
+	}{
+		{CheckGosec, []string{"-fmt=json", "-quiet", "./..."}, gosecFindings},
+		{CheckGovulncheck, []string{"-json", "./..."}, govulncheckFindings},
+	} {
+		tool, err := exec.LookPath(scan.check)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s not found on PATH; skipped security scan", scan.check))
+			continue
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(tool, scan.args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOWORK=off")
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		runErr := cmd.Run()
+
+		// Both tools exit non-zero when they find something, so the exit
+		// status only matters when the output cannot be read
+		found, err := scan.parse(dir, stdout.Bytes())
+		if err == nil && runErr != nil && len(found) == 0 {
+			err = runErr
+		}
+		if err != nil {
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				err = errors.New(tail(message, 500))
+			}
+			warnings = append(warnings, fmt.Sprintf("%s could not be run: %v", scan.check, err))
+			continue
+		}
+		findings = append(findings, found...)
+	}
+	return findings, warnings
+}
+
+// gosecFindings reads the issues of gosec's JSON report
+func gosecFindings(dir string, output []byte) ([]Finding, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	var report struct {
+		Issues []struct {
+			Severity   string `json:"severity"`
+			Confidence string `json:"confidence"`
+			RuleID     string `json:"rule_id"`
+			Details    string `json:"details"`
+			File       string `json:"file"`
+			// Line is a number or a range such as "12-14"
+			Line   string `json:"line"`
+			Column string `json:"column"`
+		} `json:"Issues"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, issue := range report.Issues {
+		line, _ := strconv.Atoi(strings.SplitN(issue.Line, "-", 2)[0])
+		column, _ := strconv.Atoi(issue.Column)
+		findings = append(findings, Finding{
+			Check:  CheckGosec,
+			File:   relativeFile(dir, issue.File),
+			Line:   line,
+			Column: column,
+			Message: fmt.Sprintf("%s: %s (severity %s, confidence %s)",
+				issue.RuleID, issue.Details, strings.ToLower(issue.Severity), strings.ToLower(issue.Confidence)),
+		})
+	}
+	return findings, nil
+}
+
+// govulncheckFindings reads the stream of govulncheck's JSON messages. Only
+// vulnerabilities whose code the server calls are reported, at the call in
+// the server.
+func govulncheckFindings(dir string, output []byte) ([]Finding, error) {
+	type position struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+	}
+	type frame struct {
+		Module   string    `json:"module"`
+		Version  string    `json:"version"`
+		Package  string    `json:"package"`
+		Function string    `json:"function"`
+		Receiver string    `json:"receiver"`
+		Position *position `json:"position"`
+	}
+	type message struct {
+		OSV *struct {
+			ID      string `json:"id"`
+			Summary string `json:"summary"`
+		} `json:"osv"`
+		Finding *struct {
+			OSV          string  `json:"osv"`
+			FixedVersion string  `json:"fixed_version"`
+			Trace        []frame `json:"trace"`
+		} `json:"finding"`
+	}
+
+	summaries := map[string]string{}
+	var findings []Finding
+	// ids holds the vulnerability of each finding
+	var ids []string
+	seen := map[string]bool{}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var m message
+		if err := decoder.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if m.OSV != nil {
+			summaries[m.OSV.ID] = m.OSV.Summary
+		}
+		if m.Finding == nil || len(m.Finding.Trace) == 0 || m.Finding.Trace[0].Function == "" {
+			continue
+		}
+
+		// The trace runs from the vulnerable symbol to the server's own
+		// code; the last frame with a position is the call to report
+		vulnerable := m.Finding.Trace[0]
+		var at *position
+		for i := len(m.Finding.Trace) - 1; i >= 0; i-- {
+			if m.Finding.Trace[i].Position != nil {
+				at = m.Finding.Trace[i].Position
+				break
+			}
+		}
+		symbol := vulnerable.Package + "." + vulnerable.Function
+		if vulnerable.Receiver != "" {
+			symbol = vulnerable.Package + "." + strings.TrimPrefix(vulnerable.Receiver, "*") + "." + vulnerable.Function
+		}
+		finding := Finding{Check: CheckGovulncheck, Message: fmt.Sprintf("%s: calls %s in %s@%s",
+			m.Finding.OSV, symbol, vulnerable.Module, vulnerable.Version)}
+		if m.Finding.FixedVersion != "" {
+			finding.Message += ", fixed in " + m.Finding.FixedVersion
+		}
+		if at != nil {
+			finding.File = relativeFile(dir, at.Filename)
+			finding.Line, finding.Column = at.Line, at.Column
+		}
+		key := finding.String()
+		if !seen[key] {
+			seen[key] = true
+			findings = append(findings, finding)
+			ids = append(ids, m.Finding.OSV)
+		}
+	}
+	for i := range findings {
+		if summary := summaries[ids[i]]; summary != "" {
+			findings[i].Message += " (" + summary + ")"
+		}
+	}
+	return findings, nil
+}
+
+// relativeFile makes a scanner's file path relative to the output directory
+func relativeFile(dir, file string) string {
+	if !filepath.IsAbs(file) {
+		return filepath.ToSlash(file)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	rel, err := filepath.Rel(abs, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScanner installs a scanner into bin that prints a recorded report from
+// testdata/security, with @DIR@ standing for the directory scanned, and
+// exits with status
+func fakeScanner(t *testing.T, bin, name, fixture string, status int) {
+	t.Helper()
+	script := fmt.Sprintf("#!/usr/bin/env bash\necho 'scanner log' >&2\nsed \"s#@DIR@#$PWD#g\" %q\nexit %d\n", fixture, status)
+	if fixture == "" {
+		script = fmt.Sprintf("#!/usr/bin/env bash\necho 'no network access to vuln.go.dev' >&2\nexit %d\n", status)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(bin, name), []byte(script), 0755))
+}
+
+// securityFixture is the absolute path of a recorded report
+func securityFixture(t *testing.T, name string) string {
+	t.Helper()
+	path, err := filepath.Abs(filepath.Join("testdata", "security", name))
+	require.NoError(t, err)
+	return path
+}
+
+func TestScanOutput(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not on PATH")
+	}
+	sed, err := exec.LookPath("sed")
+	if err != nil {
+		t.Skip("sed not on PATH")
+	}
+
+	gosecIssues := []Finding{
+		{Check: CheckGosec, File: "config.go", Line: 41, Column: 15, Message: "G304: Potential file inclusion via variable (severity medium, confidence high)"},
+		{Check: CheckGosec, File: "main.go", Line: 88, Column: 2, Message: "G104: Errors unhandled. (severity low, confidence high)"},
+	}
+	govulncheckFindings := []Finding{
+		{Check: CheckGovulncheck, File: "main.go", Line: 21, Column: 11,
+			Message: "GO-2024-2687: calls net/http.Client.Do in stdlib@v1.22.1, fixed in v1.22.2 (HTTP/2 CONTINUATION flood in net/http)"},
+		{Check: CheckGovulncheck,
+			Message: "GO-2024-2600: calls net/http.NewRequestWithContext in stdlib@v1.22.1 (Incorrect forwarding of sensitive headers and cookies on HTTP redirect in net/http)"},
+	}
+	tests := []struct {
+		name        string
+		gosec       string
+		govulncheck string
+		// status is the exit status of both scanners
+		status   int
+		findings []Finding
+		warnings []string
+	}{
+		{
+			name:        "findings",
+			gosec:       "gosec-issues.json",
+			govulncheck: "govulncheck-findings.json",
+			status:      1,
+			findings:    append(append([]Finding{}, gosecIssues...), govulncheckFindings...),
+		},
+		{
+			name:        "no findings",
+			gosec:       "gosec-clean.json",
+			govulncheck: "govulncheck-clean.json",
+		},
+		{
+			name:        "malformed output",
+			gosec:       "gosec-malformed.json",
+			govulncheck: "govulncheck-malformed.json",
+			warnings:    []string{"gosec could not be run: scanner log", "govulncheck could not be run: scanner log"},
+		},
+		{
+			name:     "failed without output",
+			status:   2,
+			warnings: []string{"gosec could not be run: no network access to vuln.go.dev", "govulncheck could not be run: no network access to vuln.go.dev"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := t.TempDir()
+			for name, fixture := range map[string]string{CheckGosec: tt.gosec, CheckGovulncheck: tt.govulncheck} {
+				if fixture != "" {
+					fixture = securityFixture(t, fixture)
+				}
+				fakeScanner(t, bin, name, fixture, tt.status)
+			}
+			t.Setenv("PATH", bin+string(os.PathListSeparator)+filepath.Dir(sed))
+
+			findings, warnings := scanOutput(t.TempDir())
+			assert.Equal(t, tt.findings, findings)
+			assert.Equal(t, tt.warnings, warnings)
+		})
+	}
+
+	t.Run("scanners missing", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		findings, warnings := scanOutput(t.TempDir())
+		assert.Empty(t, findings)
+		assert.Equal(t, []string{
+			"gosec not found on PATH; skipped security scan",
+			"govulncheck not found on PATH; skipped security scan",
+		}, warnings)
+	})
+}
+
+func TestScannerReportParsing(t *testing.T) {
+	_, err := gosecFindings("/srv", []byte(`{"Issues": [`))
+	assert.Error(t, err)
+	findings, err := gosecFindings("/srv", nil)
+	assert.NoError(t, err, "gosec prints nothing for packages without Go files")
+	assert.Empty(t, findings)
+
+	_, err = govulncheckFindings("/srv", []byte(`{"config": {}}{"finding": `))
+	assert.Error(t, err)
+	findings, err = govulncheckFindings("/srv", []byte(`{"finding": {"osv": "GO-1", "trace": [{"module": "m", "version": "v1", "package": "p", "function": "F", "position": {"filename": "/elsewhere/x.go", "line": 3}}]}}`))
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "/elsewhere/x.go", findings[0].File, "files outside the output directory keep their path")
+}
@@ -0,0 +1,221 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/transformer"
+)
+
+// Service renders MCP server source code from the internal server model
+type Service struct{}
+
+// NewService creates a new generator service
+func NewService() *Service {
+	return &Service{}
+}
+
+// Generate renders the template set for the server, formats the Go sources
+// and writes the files to the output directory. Unless disabled, go vet is run
+// on the result; its diagnostics are reported as findings rather than errors.
+// With VerifyBuild, a server that does not compile is reported as a
+// *BuildError alongside the result, since the files have been written. A dry
+// run writes nothing and reports the changes as a unified diff instead.
+func (s *Service) Generate(server *transformer.MCPServer, opts Options) (*GenerationResult, error) {
+	start := time.Now()
+
+	if opts.OutputDir == "" {
+		opts.OutputDir = "."
+	}
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	opts, err := applyProfile(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.renderAll(server, opts)
+	if err != nil {
+		return nil, err
+	}
+	files, rendered := out.files, out.rendered
+
+	result := &GenerationResult{
+		OutputDir: opts.OutputDir,
+		ToolCount: len(server.Tools),
+		Warnings:  append(append([]string(nil), server.Warnings...), out.warnings...),
+		Findings:  out.findings,
+	}
+
+	var diff strings.Builder
+	for i, file := range files {
+		target := filepath.Join(opts.OutputDir, filepath.FromSlash(file.Output))
+		existing, err := os.ReadFile(target)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read existing file", err).
+				WithFile(target)
+		}
+
+		status := FileModified
+		switch {
+		case err != nil:
+			status = FileCreated
+		case bytes.Equal(existing, rendered[i]):
+			status = FileUnchanged
+		}
+		result.Files = append(result.Files, GeneratedFile{
+			Path:     file.Output,
+			Template: file.Template,
+			Size:     int64(len(rendered[i])),
+			Status:   status,
+		})
+
+		if opts.DryRun {
+			diff.WriteString(unifiedDiff(file.Output, existing, rendered[i], status != FileCreated))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to create output directory", err).
+				WithFile(filepath.Dir(target))
+		}
+		if err := os.WriteFile(target, rendered[i], 0644); err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to write generated file", err).
+				WithFile(target)
+		}
+	}
+	result.Diff = diff.String()
+
+	if !opts.SkipVet && !opts.DryRun {
+		findings, warnings := vetOutput(opts.OutputDir)
+		result.Findings = append(result.Findings, findings...)
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+
+	if opts.SecurityScan && !opts.DryRun {
+		findings, warnings := scanOutput(opts.OutputDir)
+		result.Findings = append(result.Findings, findings...)
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+
+	var buildErr error
+	if opts.VerifyBuild {
+		findings, warnings, err := buildOutput(files, rendered)
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to prepare build verification", err)
+		}
+		result.Findings = append(result.Findings, findings...)
+		result.Warnings = append(result.Warnings, warnings...)
+		if len(findings) > 0 {
+			buildErr = common.NewError(common.ErrorTypeGeneration, "generated server does not compile", &BuildError{Diagnostics: findings}).
+				WithFile(filepath.Join(opts.OutputDir, filepath.FromSlash(findings[0].File))).
+				WithLine(findings[0].Line).
+				WithSuggestion("The template produced invalid Go code; check the diagnostics against the template it was rendered from")
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result, buildErr
+}
+
+// rendering is the in-memory output of a template package
+type rendering struct {
+	files    []templateFile
+	rendered [][]byte
+	// durations holds how long each file took to render
+	durations []time.Duration
+	findings  []Finding
+	warnings  []string
+}
+
+// renderAll resolves the template package for the options and renders and
+// formats every file it generates, in memory
+func (s *Service) renderAll(server *transformer.MCPServer, opts Options) (*rendering, error) {
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := newTemplateData(server, opts)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to prepare template data", err)
+	}
+	// A missing manifest is reported when resolving the files
+	if m, _, err := source.manifest(); err == nil {
+		if data.Vars, err = resolveVariables(m.Variables, opts.Variables, opts.TemplateDir); err != nil {
+			return nil, err
+		}
+	}
+
+	out := &rendering{}
+	out.files, err = source.files(data)
+	if err != nil {
+		return nil, err
+	}
+	rendered, durations, err := s.render(source, out.files, data)
+	if err != nil {
+		return nil, err
+	}
+	out.durations = durations
+	out.rendered, out.findings, out.warnings = formatSources(out.files, rendered)
+	return out, nil
+}
+
+// render executes the given templates of the source, returning the output and
+// render time of each in the same order. Templates run with sandboxFuncs and
+// within the render limits of the options. Nothing is written if any
+// template fails.
+func (s *Service) render(source *templateSource, files []templateFile, data *TemplateData) ([][]byte, []time.Duration, error) {
+	partials, err := source.partials()
+	if err != nil {
+		return nil, nil, common.NewError(common.ErrorTypeGeneration, "failed to read template partials", err)
+	}
+	translations, err := source.translations(data.Options)
+	if err != nil {
+		return nil, nil, err
+	}
+	base := template.New(partialsDir).Funcs(sandboxFuncs).Funcs(translations)
+	for _, partial := range partials {
+		if _, err := base.New(partial.Path).Parse(partial.Content); err != nil {
+			return nil, nil, common.NewError(common.ErrorTypeGeneration, "failed to parse template partial", err).
+				WithFile(partial.Path)
+		}
+	}
+
+	timeout, maxSize := renderLimits(data.Options)
+	outputs := make([][]byte, 0, len(files))
+	durations := make([]time.Duration, 0, len(files))
+	for _, file := range files {
+		content, name, err := source.readFile(file.Template)
+		if err != nil {
+			return nil, nil, common.NewError(common.ErrorTypeGeneration, "failed to read template", err).
+				WithFile(name)
+		}
+
+		set, err := base.Clone()
+		if err != nil {
+			return nil, nil, common.NewError(common.ErrorTypeGeneration, "failed to prepare template", err).
+				WithFile(name)
+		}
+		tmpl, err := set.New(file.Template).Parse(string(content))
+		if err != nil {
+			return nil, nil, common.NewError(common.ErrorTypeGeneration, "failed to parse template", err).
+				WithFile(name)
+		}
+
+		start := time.Now()
+		output, err := execute(tmpl, data, timeout, maxSize)
+		if err != nil {
+			return nil, nil, common.NewError(common.ErrorTypeGeneration, "failed to render template", err).
+				WithFile(name)
+		}
+		outputs = append(outputs, output)
+		durations = append(durations, time.Since(start))
+	}
+	return outputs, durations, nil
+}
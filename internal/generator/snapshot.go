@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/transformer"
+)
+
+// SnapshotStatus compares a rendered file with its approved snapshot
+type SnapshotStatus string
+
+// Snapshot statuses
+const (
+	SnapshotMatched SnapshotStatus = "matched"
+	SnapshotDrifted SnapshotStatus = "drifted"
+	// SnapshotMissing marks a rendered file without an approved snapshot
+	SnapshotMissing SnapshotStatus = "missing"
+	// SnapshotStale marks an approved snapshot the templates no longer render
+	SnapshotStale SnapshotStatus = "stale"
+)
+
+// SnapshotReport is the result of comparing a render with its snapshots
+type SnapshotReport struct {
+	Dir   string
+	Files []SnapshotFile
+	// Updated is set when the snapshots were rewritten from the render
+	Updated bool
+}
+
+// SnapshotFile is the comparison of one file
+type SnapshotFile struct {
+	Path   string
+	Status SnapshotStatus
+	// Diff turns the approved snapshot into the new render; empty when they
+	// match
+	Diff string
+}
+
+// Drifted reports whether any file differs from its snapshot
+func (r *SnapshotReport) Drifted() bool {
+	for _, file := range r.Files {
+		if file.Status != SnapshotMatched {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSnapshots renders the template package for the server in memory and
+// compares every output with the approved snapshot of the same path in dir,
+// which holds one fixture's expected output. With update, dir is rewritten
+// to match the render after comparing, approving the changes.
+func (s *Service) CheckSnapshots(server *transformer.MCPServer, opts Options, dir string, update bool) (*SnapshotReport, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	opts, err := applyProfile(opts)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.renderAll(server, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	approved, err := snapshotDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to read snapshots", err).WithFile(dir)
+	}
+
+	report := &SnapshotReport{Dir: dir}
+	rendered := map[string]bool{}
+	for i, file := range out.files {
+		rendered[file.Output] = true
+		target := filepath.Join(dir, filepath.FromSlash(file.Output))
+		existing, err := os.ReadFile(target)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read snapshot", err).WithFile(target)
+		}
+
+		snapshot := SnapshotFile{Path: file.Output, Status: SnapshotMatched}
+		switch {
+		case err != nil:
+			snapshot.Status = SnapshotMissing
+			snapshot.Diff = unifiedDiff(file.Output, nil, out.rendered[i], false)
+		case !bytes.Equal(existing, out.rendered[i]):
+			snapshot.Status = SnapshotDrifted
+			snapshot.Diff = unifiedDiff(file.Output, existing, out.rendered[i], true)
+		}
+		report.Files = append(report.Files, snapshot)
+	}
+
+	var stale []string
+	for name := range approved {
+		if !rendered[name] {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	for _, name := range stale {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read snapshot", err).WithFile(name)
+		}
+		report.Files = append(report.Files, SnapshotFile{
+			Path:   name,
+			Status: SnapshotStale,
+			Diff:   "--- a/" + name + "\n+++ /dev/null\n" + formatHunks(content, nil),
+		})
+	}
+
+	if update && report.Drifted() {
+		for i, file := range out.files {
+			target := filepath.Join(dir, filepath.FromSlash(file.Output))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, common.NewError(common.ErrorTypeGeneration, "failed to create snapshot directory", err).
+					WithFile(filepath.Dir(target))
+			}
+			if err := os.WriteFile(target, out.rendered[i], 0644); err != nil {
+				return nil, common.NewError(common.ErrorTypeGeneration, "failed to write snapshot", err).WithFile(target)
+			}
+		}
+		for _, name := range stale {
+			target := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return nil, common.NewError(common.ErrorTypeGeneration, "failed to remove snapshot", err).WithFile(target)
+			}
+		}
+		report.Updated = true
+	}
+	return report, nil
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshotStatuses maps each file of a report to its status
+func snapshotStatuses(report *SnapshotReport) map[string]SnapshotStatus {
+	statuses := map[string]SnapshotStatus{}
+	for _, file := range report.Files {
+		statuses[file.Path] = file.Status
+	}
+	return statuses
+}
+
+func TestCheckSnapshots(t *testing.T) {
+	server := testServer(t, "users.yaml")
+	dir := filepath.Join(t.TempDir(), "users")
+
+	report, err := NewService().CheckSnapshots(server, Options{}, dir, false)
+	require.NoError(t, err)
+	assert.True(t, report.Drifted())
+	assert.Equal(t, SnapshotMissing, snapshotStatuses(report)["main.go"])
+	assert.NoDirExists(t, dir, "snapshots are only written with update")
+
+	report, err = NewService().CheckSnapshots(server, Options{}, dir, true)
+	require.NoError(t, err)
+	assert.True(t, report.Updated)
+	assert.FileExists(t, filepath.Join(dir, "main.go"))
+
+	report, err = NewService().CheckSnapshots(server, Options{}, dir, false)
+	require.NoError(t, err)
+	assert.False(t, report.Drifted())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Old\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "removed.go"), []byte("package main\n"), 0644))
+	report, err = NewService().CheckSnapshots(server, Options{}, dir, false)
+	require.NoError(t, err)
+	statuses := snapshotStatuses(report)
+	assert.Equal(t, SnapshotDrifted, statuses["README.md"])
+	assert.Equal(t, SnapshotStale, statuses["removed.go"])
+	assert.Equal(t, SnapshotMatched, statuses["main.go"])
+	for _, file := range report.Files {
+		if file.Path == "README.md" {
+			assert.Contains(t, file.Diff, "-# Old")
+		}
+	}
+
+	_, err = NewService().CheckSnapshots(server, Options{}, dir, true)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "removed.go"))
+	report, err = NewService().CheckSnapshots(server, Options{}, dir, false)
+	require.NoError(t, err)
+	assert.False(t, report.Drifted())
+}
@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"bytes"
+	"path"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// TemplateDiff is a line-level comparison of two template packages
+type TemplateDiff struct {
+	Files []TemplateFileDiff
+	// VariablesAdded and VariablesRemoved compare the template data fields
+	// referenced anywhere in the packages, e.g. ".Server.BaseURL"
+	VariablesAdded   []string
+	VariablesRemoved []string
+}
+
+// TemplateFileDiff compares one file of two template packages
+type TemplateFileDiff struct {
+	Path string
+	// Status is created, modified or removed; unchanged files are omitted
+	Status           FileStatus
+	Hunks            []DiffHunk
+	VariablesAdded   []string
+	VariablesRemoved []string
+}
+
+// DiffTemplates compares the template package selected by from with the one
+// selected by to, file by file. Each options value picks a package through
+// Template and TemplateDir, so built-in sets, custom directories and
+// extracted archives can be compared in any combination.
+func (s *Service) DiffTemplates(from, to Options) (*TemplateDiff, error) {
+	oldFiles, err := templatePackageFiles(from)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := templatePackageFiles(to)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range oldFiles {
+		names[name] = true
+	}
+	for name := range newFiles {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	diff := &TemplateDiff{}
+	oldVars, newVars := map[string]bool{}, map[string]bool{}
+	for _, name := range sorted {
+		before, inOld := oldFiles[name]
+		after, inNew := newFiles[name]
+		beforeVars, afterVars := templateVariables(name, before), templateVariables(name, after)
+		for v := range beforeVars {
+			oldVars[v] = true
+		}
+		for v := range afterVars {
+			newVars[v] = true
+		}
+		if inOld && inNew && bytes.Equal(before, after) {
+			continue
+		}
+
+		file := TemplateFileDiff{
+			Path:             name,
+			Status:           FileModified,
+			Hunks:            diffHunks(splitLines(before), splitLines(after)),
+			VariablesAdded:   setDifference(afterVars, beforeVars),
+			VariablesRemoved: setDifference(beforeVars, afterVars),
+		}
+		switch {
+		case !inOld:
+			file.Status = FileCreated
+		case !inNew:
+			file.Status = FileRemoved
+		}
+		diff.Files = append(diff.Files, file)
+	}
+	diff.VariablesAdded = setDifference(newVars, oldVars)
+	diff.VariablesRemoved = setDifference(oldVars, newVars)
+	return diff, nil
+}
+
+// templatePackageFiles returns the effective files of a package by path,
+// leaving out the README synthesized for exports
+func templatePackageFiles(opts Options) (map[string][]byte, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	files, err := packageFiles(source)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string][]byte{}
+	for _, file := range files {
+		if !file.Synthesized {
+			byName[file.Name] = file.Content
+		}
+	}
+	return byName, nil
+}
+
+// templateVariables lists the field chains a template file references. Files
+// that are not templates or fail to parse have none.
+func templateVariables(name string, content []byte) map[string]bool {
+	vars := map[string]bool{}
+	if path.Ext(name) != ".tmpl" || len(content) == 0 {
+		return vars
+	}
+	p, _ := parseTemplate(name, string(content))
+	if p == nil {
+		return vars
+	}
+	for _, tree := range p.trees {
+		walkTree(tree.Root, func(node parse.Node) {
+			if field, ok := node.(*parse.FieldNode); ok {
+				vars["."+strings.Join(field.Ident, ".")] = true
+			}
+		})
+	}
+	return vars
+}
+
+// setDifference returns the sorted members of a missing from b
+func setDifference(a, b map[string]bool) []string {
+	var out []string
+	for v := range a {
+		if !b[v] {
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
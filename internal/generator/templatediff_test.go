@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffHunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		before []string
+		after  []string
+		want   []DiffHunk
+	}{
+		{
+			name:   "equal",
+			before: []string{"a", "b"},
+			after:  []string{"a", "b"},
+		},
+		{
+			name:  "insertion into empty",
+			after: []string{"a"},
+			want: []DiffHunk{{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1, Lines: []DiffLine{
+				{Kind: DiffAdded, NewLine: 1, Text: "a"},
+			}}},
+		},
+		{
+			name:   "replacement with context",
+			before: []string{"a", "b", "c"},
+			after:  []string{"a", "x", "c"},
+			want: []DiffHunk{{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []DiffLine{
+				{Kind: DiffContext, OldLine: 1, NewLine: 1, Text: "a"},
+				{Kind: DiffRemoved, OldLine: 2, Text: "b"},
+				{Kind: DiffAdded, NewLine: 2, Text: "x"},
+				{Kind: DiffContext, OldLine: 3, NewLine: 3, Text: "c"},
+			}}},
+		},
+		{
+			name:   "distant changes make two hunks",
+			before: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"},
+			after:  []string{"one", "2", "3", "4", "5", "6", "7", "8", "9", "ten"},
+			want: []DiffHunk{
+				{OldStart: 1, OldLines: 4, NewStart: 1, NewLines: 4, Lines: []DiffLine{
+					{Kind: DiffRemoved, OldLine: 1, Text: "1"},
+					{Kind: DiffAdded, NewLine: 1, Text: "one"},
+					{Kind: DiffContext, OldLine: 2, NewLine: 2, Text: "2"},
+					{Kind: DiffContext, OldLine: 3, NewLine: 3, Text: "3"},
+					{Kind: DiffContext, OldLine: 4, NewLine: 4, Text: "4"},
+				}},
+				{OldStart: 7, OldLines: 4, NewStart: 7, NewLines: 4, Lines: []DiffLine{
+					{Kind: DiffContext, OldLine: 7, NewLine: 7, Text: "7"},
+					{Kind: DiffContext, OldLine: 8, NewLine: 8, Text: "8"},
+					{Kind: DiffContext, OldLine: 9, NewLine: 9, Text: "9"},
+					{Kind: DiffRemoved, OldLine: 10, Text: "10"},
+					{Kind: DiffAdded, NewLine: 10, Text: "ten"},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffHunks(tt.before, tt.after))
+		})
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]bool
+	}{
+		{
+			name:    "fields",
+			file:    "README.md.tmpl",
+			content: "{{ .Server.Name }} {{ range .Tools }}{{ .Name }}{{ end }}",
+			want:    map[string]bool{".Server.Name": true, ".Tools": true, ".Name": true},
+		},
+		{name: "not a template", file: "locales/en.json", content: `{"a": "{{ .X }}"}`, want: map[string]bool{}},
+		{name: "unparsable", file: "main.go.tmpl", content: "{{ .X ", want: map[string]bool{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, templateVariables(tt.file, []byte(tt.content)))
+		})
+	}
+}
+
+func TestDiffTemplates(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(oldDir, "README.md.tmpl"), []byte("# {{ .Server.Name }}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(newDir, "README.md.tmpl"), []byte("# {{ .Server.Name }}\n\n{{ .Server.BaseURL }}\n"), 0644))
+
+	diff, err := NewService().DiffTemplates(Options{TemplateDir: oldDir}, Options{TemplateDir: newDir})
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	assert.Equal(t, "README.md.tmpl", diff.Files[0].Path)
+	assert.Equal(t, FileModified, diff.Files[0].Status)
+	assert.Equal(t, []string{".Server.BaseURL"}, diff.Files[0].VariablesAdded)
+	assert.Empty(t, diff.Files[0].VariablesRemoved)
+
+	same, err := NewService().DiffTemplates(Options{TemplateDir: oldDir}, Options{TemplateDir: oldDir})
+	require.NoError(t, err)
+	assert.Empty(t, same.Files)
+}
@@ -0,0 +1,309 @@
+package generator
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"MCPWeaver/internal/common"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// TemplateSet is a built-in template set
+type TemplateSet struct {
+	Name        string
+	Description string
+}
+
+// TemplateSets lists the built-in template sets by name
+func TemplateSets() []TemplateSet {
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil
+	}
+	var sets []TemplateSet
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		set := TemplateSet{Name: entry.Name()}
+		if content, err := fs.ReadFile(templateFS, path.Join("templates", entry.Name(), manifestFile)); err == nil {
+			if m, err := parseManifest(content); err == nil {
+				set.Description = m.Description
+			}
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// manifestFile lists the files a template package generates. A custom
+// package without one inherits the manifest of the package below it.
+const manifestFile = "manifest.json"
+
+// manifest describes a template package
+type manifest struct {
+	Name        string          `json:"name"`
+	Version     string          `json:"version,omitempty"`
+	Description string          `json:"description"`
+	Files       []manifestEntry `json:"files"`
+	// Dependencies are installed packages whose partials this package uses
+	Dependencies []manifestDependency `json:"dependencies,omitempty"`
+	// Variables are the values the package asks the user for
+	Variables []TemplateVariable `json:"variables,omitempty"`
+}
+
+// manifestDependency names an installed package and the versions accepted,
+// e.g. "1.2.0", "^1.2.0", "~1.2.0" or ">=1.2.0"; empty accepts any version
+type manifestDependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// manifestEntry maps a template to its output path. Output and Condition are
+// evaluated against the template data, so paths can be interpolated, e.g.
+// "cmd/{{.Server.Name}}/main.go".
+type manifestEntry struct {
+	Template string `json:"template"`
+	Output   string `json:"output"`
+	// Condition is a template pipeline such as ".Options.Docker"; the file
+	// is skipped when it evaluates to false or empty
+	Condition string `json:"condition"`
+}
+
+// templateFile is a manifest entry resolved for one generation
+type templateFile struct {
+	Template string
+	Output   string
+}
+
+// partialsDir holds the shared {{define}} blocks of a template package. Every
+// partial is available to every template in the package.
+const partialsDir = "partials"
+
+// templateLayer is one template package in an inheritance chain
+type templateLayer struct {
+	fsys fs.FS
+	// location prefixes file names in error messages
+	location string
+	// onDisk marks a package read from the file system rather than embedded
+	onDisk bool
+	// dependency marks an installed package that only contributes partials
+	dependency bool
+}
+
+// templateSource resolves files across layered template packages. A custom
+// package only needs to provide the files and partials it changes; the rest
+// is inherited from the package below it.
+type templateSource struct {
+	// layers are ordered from the most specific to the built-in base
+	layers []templateLayer
+}
+
+// newTemplateSource layers the custom package in opts.TemplateDir, if any,
+// and the partials of the dependencies it declares over the built-in
+// template set. A missing dependency fails immediately.
+func newTemplateSource(opts Options) (*templateSource, error) {
+	source, err := openTemplateSource(opts.Template, opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unreadable manifest is reported by whatever loads it next
+	if m, _, err := source.manifest(); err == nil && len(m.Dependencies) > 0 {
+		deps, err := resolveDependencies(m, packagesDir(opts))
+		if err != nil {
+			return nil, err
+		}
+		base := source.layers[len(source.layers)-1]
+		source.layers = append(source.layers[:len(source.layers)-1], deps...)
+		source.layers = append(source.layers, base)
+	}
+	return source, nil
+}
+
+// openTemplateSource layers the custom package in dir, if any, over the
+// built-in template set without resolving dependencies
+func openTemplateSource(templateSet, dir string) (*templateSource, error) {
+	root := path.Join("templates", templateSet)
+	base, err := fs.Sub(templateFS, root)
+	if err == nil {
+		_, err = fs.Stat(base, ".")
+	}
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, fmt.Sprintf("unknown template %q", templateSet), err).
+			WithSuggestion(fmt.Sprintf("Use the built-in %q template", DefaultTemplate))
+	}
+
+	source := &templateSource{}
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			if err == nil {
+				err = fmt.Errorf("not a directory")
+			}
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to open template directory", err).
+				WithFile(dir)
+		}
+		source.layers = append(source.layers, templateLayer{fsys: os.DirFS(dir), location: dir, onDisk: true})
+	}
+	source.layers = append(source.layers, templateLayer{fsys: base, location: root})
+	return source, nil
+}
+
+// readFile returns the content of the first layer providing name, along with
+// the path to report in errors
+func (s *templateSource) readFile(name string) ([]byte, string, error) {
+	for _, layer := range s.layers {
+		if layer.dependency {
+			continue
+		}
+		content, err := fs.ReadFile(layer.fsys, name)
+		if err == nil {
+			return content, layer.path(name), nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, layer.path(name), err
+		}
+	}
+	return nil, name, fs.ErrNotExist
+}
+
+// partials returns the partial files of every layer, base first, so that
+// definitions in more specific layers replace inherited ones when parsed in
+// order
+func (s *templateSource) partials() ([]templatePartial, error) {
+	var partials []templatePartial
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		layer := s.layers[i]
+		names, err := fs.Glob(layer.fsys, path.Join(partialsDir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			content, err := fs.ReadFile(layer.fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			partials = append(partials, templatePartial{
+				Path:       layer.path(name),
+				Content:    string(content),
+				Dependency: layer.dependency,
+			})
+		}
+	}
+	return partials, nil
+}
+
+func (l templateLayer) path(name string) string {
+	if l.onDisk {
+		return filepath.Join(l.location, filepath.FromSlash(name))
+	}
+	return path.Join(l.location, name)
+}
+
+// manifest returns the manifest of the most specific layer that has one
+func (s *templateSource) manifest() (*manifest, string, error) {
+	content, name, err := s.readFile(manifestFile)
+	if err != nil {
+		return nil, name, err
+	}
+	m, err := parseManifest(content)
+	if err != nil {
+		return nil, name, err
+	}
+	if len(m.Files) == 0 {
+		return nil, name, fmt.Errorf("manifest lists no files")
+	}
+	return m, name, nil
+}
+
+func parseManifest(content []byte) (*manifest, error) {
+	var m manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// files resolves the manifest against the data, dropping entries whose
+// condition is false and interpolating output paths
+func (s *templateSource) files(data *TemplateData) ([]templateFile, error) {
+	m, name, err := s.manifest()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to load template manifest", err).
+			WithFile(name)
+	}
+
+	var files []templateFile
+	seen := map[string]string{}
+	for _, entry := range m.Files {
+		if entry.Template == "" || entry.Output == "" {
+			return nil, common.NewError(common.ErrorTypeGeneration, "manifest entries need a template and an output", nil).
+				WithFile(name)
+		}
+
+		if entry.Condition != "" {
+			enabled, err := evaluate("{{if "+entry.Condition+"}}true{{end}}", data)
+			if err != nil {
+				return nil, common.NewError(common.ErrorTypeGeneration,
+					fmt.Sprintf("invalid condition for %s", entry.Template), err).WithFile(name)
+			}
+			if enabled == "" {
+				continue
+			}
+		}
+
+		output, err := evaluate(entry.Output, data)
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration,
+				fmt.Sprintf("invalid output path for %s", entry.Template), err).WithFile(name)
+		}
+		output = path.Clean(output)
+		if output == "." || path.IsAbs(output) || output == ".." || strings.HasPrefix(output, "../") {
+			return nil, common.NewError(common.ErrorTypeGeneration,
+				fmt.Sprintf("output path %q of %s leaves the output directory", output, entry.Template), nil).
+				WithFile(name)
+		}
+		if previous, ok := seen[output]; ok {
+			return nil, common.NewError(common.ErrorTypeGeneration,
+				fmt.Sprintf("%s and %s both generate %s", previous, entry.Template, output), nil).
+				WithFile(name)
+		}
+		seen[output] = entry.Template
+
+		files = append(files, templateFile{Template: entry.Template, Output: output})
+	}
+	return files, nil
+}
+
+// evaluate renders a short inline template such as a manifest path. It
+// comes from the package manifest, so it runs sandboxed like the templates.
+func evaluate(text string, data *TemplateData) (string, error) {
+	tmpl, err := template.New("inline").Funcs(sandboxFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	timeout, maxSize := renderLimits(data.Options)
+	output, err := execute(tmpl, data, timeout, maxSize)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// templatePartial is a file of {{define}} blocks shared by a package's
+// templates
+type templatePartial struct {
+	Path    string
+	Content string
+	// Dependency marks a partial provided by an installed dependency
+	Dependency bool
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to a config file in a temporary directory
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("a missing file uses the defaults: %v", err)
+	}
+	if cfg.BaseURL != "https://api.example.com/v1" || cfg.Timeout != 30*time.Second || cfg.LogLevel != "info" {
+		t.Fatalf("defaults: %+v", cfg)
+	}
+	if cfg.Retry.MaxAttempts != 3 {
+		t.Fatalf("retry defaults: %+v", cfg.Retry)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `# Settings
+base_url: "https://staging.example.com/v1/"  # trailing slash is dropped
+timeout: 5s
+log_level: DEBUG
+
+rate_limit:
+  requests_per_second: 2.5
+  burst: 4
+  list_users: 1/2
+retry:
+  max_attempts: 5
+  backoff: '100ms'
+`)
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "https://staging.example.com/v1" {
+		t.Errorf("base_url: %q", cfg.BaseURL)
+	}
+	if cfg.Timeout != 5*time.Second || cfg.LogLevel != "debug" {
+		t.Errorf("timeout %s, log_level %q", cfg.Timeout, cfg.LogLevel)
+	}
+	if cfg.RateLimit.RequestsPerSecond != 2.5 || cfg.RateLimit.Burst != 4 {
+		t.Errorf("rate_limit: %+v", cfg.RateLimit)
+	}
+	if limit := cfg.RateLimit.Tools["list_users"]; limit.RequestsPerSecond != 1 || limit.Burst != 2 {
+		t.Errorf("list_users limit: %+v", limit)
+	}
+	if cfg.Retry.MaxAttempts != 5 || cfg.Retry.Backoff != 100*time.Millisecond {
+		t.Errorf("retry: %+v", cfg.Retry)
+	}
+}
+
+func TestLoadConfigEnvironment(t *testing.T) {
+	path := writeConfigFile(t, "base_url: https://file.example.com\ntimeout: 5s\nlog_level: warn\nrate_limit:\n  burst: 4\n")
+	t.Setenv(envPrefix+"_BASE_URL", "https://env.example.com/")
+	t.Setenv(envPrefix+"_TIMEOUT", "1m")
+	t.Setenv(envPrefix+"_LOG_LEVEL", "")
+	t.Setenv(envPrefix+"_RATE_LIMIT_BURST", "9")
+	t.Setenv(envPrefix+"_RETRY_MAX_ATTEMPTS", "1")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "https://env.example.com" || cfg.Timeout != time.Minute {
+		t.Errorf("the environment overrides the file: base_url %q, timeout %s", cfg.BaseURL, cfg.Timeout)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("an empty variable keeps the file's log_level, got %q", cfg.LogLevel)
+	}
+	if cfg.RateLimit.Burst != 9 || cfg.Retry.MaxAttempts != 1 {
+		t.Errorf("rate_limit %+v, retry %+v", cfg.RateLimit, cfg.Retry)
+	}
+}
+
+func TestLoadConfigAuth(t *testing.T) {
+	saved := authSettings
+	defer func() { authSettings = saved }()
+	authSettings = []authSetting{{Key: "auth.token", EnvVar: envPrefix + "_TOKEN"}}
+
+	path := writeConfigFile(t, "auth:\n  token: from-file\n")
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.credential(envPrefix + "_TOKEN"); got != "from-file" {
+		t.Errorf("credential from the file: %q", got)
+	}
+	t.Setenv(envPrefix+"_TOKEN", "from-env")
+	if cfg, err = loadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.credential(envPrefix + "_TOKEN"); got != "from-env" {
+		t.Errorf("credential from the environment: %q", got)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{"timeout", "timeout: soon\n", nil, `invalid timeout "soon"`},
+		{"log level", "log_level: verbose\n", nil, `invalid log_level "verbose"`},
+		{"unknown setting", "base_uri: https://example.com\n", nil, `unknown setting "base_uri"`},
+		{"unknown auth", "auth:\n  password: x\n", nil, `unknown auth setting "auth.password"`},
+		{"unknown tool", "rate_limit:\n  list_orders: 1/2\n", nil, `unknown rate_limit setting "list_orders"`},
+		{"burst", "rate_limit:\n  burst: 0\n", nil, `invalid rate_limit.burst "0"`},
+		{"attempts", "retry:\n  max_attempts: none\n", nil, `invalid retry.max_attempts "none"`},
+		{"not a pair", "base_url\n", nil, `:1: expected "key: value"`},
+		{"indentation", "  timeout: 5s\n", nil, ":1: unexpected indentation"},
+		{"environment", "", map[string]string{envPrefix + "_TIMEOUT": "-"}, `environment: invalid timeout "-"`},
+		{"environment rate", "", map[string]string{envPrefix + "_RATE_LIMIT": "fast"}, `environment: invalid rate_limit.requests_per_second "fast"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			path := writeConfigFile(t, tt.content)
+			_, err := loadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %v, want %q", err, tt.want)
+			}
+			if tt.env == nil && !strings.Contains(err.Error(), path) {
+				t.Errorf("errors in the file name it: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	savedArgs, savedFlags := os.Args, flag.CommandLine
+	defer func() { os.Args, flag.CommandLine = savedArgs, savedFlags }()
+	path := func(args ...string) string {
+		os.Args = append([]string{"server"}, args...)
+		flag.CommandLine = flag.NewFlagSet("server", flag.ContinueOnError)
+		return configPath()
+	}
+
+	t.Setenv(envPrefix+"_CONFIG", "")
+	if got := path(); got != "config.yaml" {
+		t.Errorf("default: %q", got)
+	}
+	t.Setenv(envPrefix+"_CONFIG", "/etc/server/env.yaml")
+	if got := path(); got != "/etc/server/env.yaml" {
+		t.Errorf("from the environment: %q", got)
+	}
+	if got := path("-config", "flag.yaml"); got != "flag.yaml" {
+		t.Errorf("the flag takes precedence over the environment: %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setLimiters replaces the limiters built from the configuration
+func setLimiters(global *tokenBucket, tools map[string]*tokenBucket) {
+	limiters.once.Do(func() {})
+	limiters.global = global
+	limiters.tools = tools
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(2, 3)
+	start := b.last
+	for i := 0; i < 3; i++ {
+		if wait, ok := b.reserve(start, 0); !ok || wait != 0 {
+			t.Fatalf("reservation %d within the burst: wait %s, ok %v", i, wait, ok)
+		}
+	}
+	if wait, ok := b.reserve(start, time.Second); !ok || wait != 500*time.Millisecond {
+		t.Fatalf("borrowed token: wait %s, ok %v; want 500ms", wait, ok)
+	}
+	if wait, ok := b.reserve(start, 600*time.Millisecond); ok || wait != time.Second {
+		t.Fatalf("reservation beyond the maximum wait: wait %s, ok %v; want rejected after 1s", wait, ok)
+	}
+	if wait, ok := b.reserve(start.Add(time.Second), 0); !ok || wait != 0 {
+		t.Fatalf("refilled token: wait %s, ok %v", wait, ok)
+	}
+
+	b.cancel()
+	b.cancel()
+	b.cancel()
+	b.cancel()
+	if b.tokens != b.burst {
+		t.Errorf("cancelled reservations refill up to the burst only: %v tokens", b.tokens)
+	}
+
+	// Times before the last reservation do not drain the bucket
+	before := b.tokens
+	if _, ok := b.reserve(start, 0); !ok || b.tokens != before-1 {
+		t.Errorf("reservation at an earlier time: ok %v, %v tokens left of %v", ok, b.tokens, before)
+	}
+
+	var unlimited *tokenBucket
+	if wait, ok := unlimited.reserve(start, 0); !ok || wait != 0 {
+		t.Errorf("a disabled limit never waits")
+	}
+	unlimited.cancel()
+}
+
+func TestWaitForRateLimit(t *testing.T) {
+	cfg = defaultConfig()
+	cfg.LogLevel = "error"
+	cfg.RateLimit.MaxWait = 0
+	tool := newTokenBucket(0.001, 2)
+	setLimiters(newTokenBucket(0.001, 1), map[string]*tokenBucket{"list": tool})
+
+	if err := waitForRateLimit("list"); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	err := waitForRateLimit("list")
+	if err == nil || !strings.Contains(err.Error(), "rate limit exceeded") {
+		t.Fatalf("call beyond the global limit: %v", err)
+	}
+	if tool.tokens < 0.99 {
+		t.Errorf("the tool's token is returned when the global limit rejects the call: %v tokens", tool.tokens)
+	}
+
+	setLimiters(newTokenBucket(0.001, 5), map[string]*tokenBucket{"list": newTokenBucket(0.001, 1)})
+	waitForRateLimit("list")
+	global := limiters.global.tokens
+	if err := waitForRateLimit("list"); err == nil {
+		t.Fatal("call beyond the tool's limit succeeded")
+	}
+	if limiters.global.tokens != global {
+		t.Errorf("calls the tool's limit rejects take no global token")
+	}
+}
+
+func TestRetriesWaitForRateLimit(t *testing.T) {
+	requests := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	cfg = defaultConfig()
+	cfg.LogLevel = "error"
+	cfg.RateLimit.MaxWait = 0
+	cfg.Retry = retryConfig{MaxAttempts: 5, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	httpClient = upstream.Client()
+	setLimiters(newTokenBucket(0.001, 2), map[string]*tokenBucket{})
+
+	// The first attempt takes a token in callTool, the first retry the
+	// second; the second retry exceeds the limit
+	if err := waitForRateLimit("list"); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, upstream.URL, nil)
+	_, err := doWithRetry(&toolSpec{Name: "list"}, req)
+	if err == nil || !strings.Contains(err.Error(), "rate limit exceeded") {
+		t.Fatalf("retry beyond the rate limit: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("%d upstream requests; want 2", requests)
+	}
+}
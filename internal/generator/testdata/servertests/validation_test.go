@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeSchema decodes a JSON schema written in a test
+func decodeSchema(t *testing.T, schema string) map[string]interface{} {
+	t.Helper()
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// decodeValue decodes a JSON value written in a test
+func decodeValue(t *testing.T, value string) interface{} {
+	t.Helper()
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		tool string
+		args string
+		want []validationError
+	}{
+		{"create_user", `{"body": {"name": "Ada", "email": "ada@example.com"}}`, nil},
+		{"create_user", `{}`, []validationError{{"$.body", "is required"}}},
+		{"create_user", `{"body": {"name": "Ada"}}`, []validationError{{"$.body.email", "is required"}}},
+		{"create_user", `{"body": {"name": "", "email": "ada"}}`, []validationError{
+			{"$.body.email", "must be an email address"},
+			{"$.body.name", "must be at least 1 characters"},
+		}},
+		{"create_user", `{"body": {"name": 42, "email": "ada@example.com"}}`, []validationError{{"$.body.name", "expected string, got integer"}}},
+		{"create_user", `{"body": "Ada"}`, []validationError{{"$.body", "expected object, got string"}}},
+		{"get_user", `{"id": 7}`, nil},
+		{"get_user", `{"id": 0}`, []validationError{{"$.id", "must be >= 1"}}},
+		{"get_user", `{"id": 1.5}`, []validationError{{"$.id", "expected integer, got number"}}},
+		{"list_users", `{"limit": 101, "offset": -1}`, []validationError{{"$.limit", "must be <= 100"}, {"$.offset", "must be >= 0"}}},
+		{"list_users", `{"unknown": true}`, nil},
+	}
+	for _, tt := range tests {
+		tool := findTool(tt.tool)
+		if tool == nil {
+			t.Fatalf("no tool %s", tt.tool)
+		}
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+			t.Fatal(err)
+		}
+		if got := validateArguments(tool, args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: got %v, want %v", tt.tool, tt.args, got, tt.want)
+		}
+	}
+	if got := validateArguments(findTool("get_user"), nil); len(got) != 1 || got[0].Path != "$.id" {
+		t.Errorf("no arguments miss the required ones: %v", got)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []string
+	}{
+		{"enum", `{"type": "string", "enum": ["small", "large"]}`, `"medium"`, []string{`$: must be one of "small", "large"`}},
+		{"nullable enum", `{"type": ["string", "null"], "enum": ["small", null]}`, `null`, nil},
+		{"null", `{"type": "string"}`, `null`, []string{"$: expected string, got null"}},
+		{"number accepts integers", `{"type": "number", "exclusiveMinimum": 0}`, `1`, nil},
+		{"exclusive bounds", `{"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1}`, `1`, []string{"$: must be < 1"}},
+		{"pattern", `{"type": "string", "pattern": "^[a-z]+$"}`, `"Ada"`, []string{"$: must match pattern ^[a-z]+$"}},
+		{"max length counts runes", `{"type": "string", "maxLength": 2}`, `"äö"`, nil},
+		{"date-time", `{"type": "string", "format": "date-time"}`, `"2024-01-02 03:04"`, []string{"$: must be an RFC 3339 date-time"}},
+		{"valid date-time", `{"type": "string", "format": "date-time"}`, `"2024-01-02T03:04:05Z"`, nil},
+		{"date", `{"type": "string", "format": "date"}`, `"02/01/2024"`, []string{"$: must be a date in YYYY-MM-DD format"}},
+		{"uuid", `{"type": "string", "format": "uuid"}`, `"1234"`, []string{"$: must be a UUID"}},
+		{"valid uuid", `{"type": "string", "format": "uuid"}`, `"123e4567-e89b-12d3-a456-426614174000"`, nil},
+		{"unknown format", `{"type": "string", "format": "hostname"}`, `"not a host!"`, nil},
+		{"items", `{"type": "array", "minItems": 1, "items": {"type": "integer"}}`, `[1, "two", 3.5]`, []string{
+			"$[1]: expected integer, got string", "$[2]: expected integer, got number",
+		}},
+		{"too few items", `{"type": "array", "minItems": 1}`, `[]`, []string{"$: must contain at least 1 items"}},
+		{"nested objects", `{"type": "object", "properties": {"address": {"type": "object", "required": ["city"],
+			"properties": {"zip": {"type": "string", "pattern": "^[0-9]{5}$"}}}}}`,
+			`{"address": {"zip": "ABC"}}`, []string{"$.address.city: is required", "$.address.zip: must match pattern ^[0-9]{5}$"}},
+		{"additional properties", `{"type": "object", "properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1, "b": 2}`, []string{"$.b: is not an allowed property"}},
+		{"additional property schema", `{"type": "object", "additionalProperties": {"type": "boolean"}}`, `{"on": "yes"}`, []string{"$.on: expected boolean, got string"}},
+		{"allOf", `{"allOf": [{"required": ["a"]}, {"required": ["b"]}]}`, `{"a": 1}`, []string{"$.b: is required"}},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, []string{"$: must match at least one of the allowed schemas"}},
+		{"oneOf", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, []string{"$: must match exactly one of the allowed schemas"}},
+		{"oneOf matches one", `{"oneOf": [{"type": "number"}, {"type": "string"}]}`, `1`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range validateValue(decodeSchema(t, tt.schema), decodeValue(t, tt.value), "$") {
+				got = append(got, err.Path+": "+err.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	tool := findTool("list_users")
+	if errs := validateResponse(tool, []byte(`{"users": [{"id": 1, "name": "Ada", "email": "ada@example.com"}], "total": 1}`)); errs != nil {
+		t.Errorf("a valid response: %v", errs)
+	}
+	errs := validateResponse(tool, []byte(`{"users": [{"id": 1, "name": "Ada"}], "total": "1"}`))
+	if got := summarizeErrors(errs); got != "$.total: expected integer, got string; $.users[0].email: is required" {
+		t.Errorf("got %q", got)
+	}
+	if got := summarizeErrors(validateResponse(tool, []byte("<html>"))); got != "$: response is not valid JSON" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestInvalidArgumentsRejected(t *testing.T) {
+	params, _ := json.Marshal(map[string]interface{}{"name": "get_user", "arguments": map[string]interface{}{"id": "seven"}})
+	resp := handle(rpcRequest{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: "tools/call", Params: params})
+	if resp == nil || resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("invalid arguments are a JSON-RPC error -32602: %+v", resp)
+	}
+	if !strings.Contains(resp.Error.Message, `invalid arguments for tool "get_user": $.id: expected integer, got string`) {
+		t.Errorf("message: %s", resp.Error.Message)
+	}
+	data, _ := json.Marshal(resp.Error.Data)
+	if string(data) != `{"errors":[{"path":"$.id","message":"expected integer, got string"}]}` {
+		t.Errorf("data: %s", data)
+	}
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultTemplate is the built-in template set used when none is specified
+const DefaultTemplate = "go-default"
+
+// Options controls how a server is generated
+type Options struct {
+	// OutputDir is the directory the generated files are written to
+	OutputDir string
+	// Template is the name of the template set to render
+	Template string
+	// TemplateDir is a custom template package layered over Template. Files
+	// and partials it provides replace the built-in ones; everything else is
+	// inherited.
+	TemplateDir string
+	// PackagesDir holds installed template packages that dependencies are
+	// resolved from; defaults to DefaultPackagesDir
+	PackagesDir string
+	// Profile names a bundle of features enabled in addition to the ones set
+	// below; defaults to DefaultProfile
+	Profile string
+	// ModuleName is the Go module path of the generated server; defaults to
+	// the server name
+	ModuleName string
+	// Validation generates runtime JSON Schema validation of tool arguments
+	// and upstream responses
+	Validation bool
+	// RateLimiting generates a configurable token-bucket limiter for
+	// upstream requests
+	RateLimiting bool
+	// Retries generates retrying of throttled and failed upstream requests
+	// with exponential backoff
+	Retries bool
+	// Docker generates a Dockerfile for the server
+	Docker bool
+	// SkipVet disables running go vet on the generated output. Sources are
+	// always formatted.
+	SkipVet bool
+	// SecurityScan runs gosec and govulncheck on the generated output when
+	// they are installed and reports their findings
+	SecurityScan bool
+	// VerifyBuild compiles the generated server after writing it and fails
+	// the generation if it does not build
+	VerifyBuild bool
+	// DryRun renders everything in memory and reports a diff against the
+	// output directory without writing any files
+	DryRun bool
+	// RenderTimeout bounds how long each template may render; defaults to
+	// DefaultRenderTimeout
+	RenderTimeout time.Duration
+	// MaxRenderSize caps the rendered size of each file in bytes; defaults
+	// to DefaultMaxRenderSize
+	MaxRenderSize int
+	// Lint configures the style rules checked by ValidateTemplates
+	Lint LintConfig
+	// Variables holds values for the variables the template package
+	// declares, as entered by the user
+	Variables map[string]string
+	// Language selects the translation of generated documentation, e.g.
+	// "de"; defaults to DefaultLanguage
+	Language string
+}
+
+// GenerationResult summarizes a completed generation
+type GenerationResult struct {
+	OutputDir string
+	Files     []GeneratedFile
+	ToolCount int
+	Warnings  []string
+	// Findings are issues reported by the formatting, vet and security checks
+	Findings []Finding
+	// Diff is a unified diff of the output directory before and after
+	// generation; only set for dry runs
+	Diff     string
+	Duration time.Duration
+}
+
+// FileStatus describes how generation changes a file
+type FileStatus string
+
+// File statuses
+const (
+	FileCreated   FileStatus = "created"
+	FileModified  FileStatus = "modified"
+	FileUnchanged FileStatus = "unchanged"
+	// FileRemoved only appears in template package diffs
+	FileRemoved FileStatus = "removed"
+)
+
+// BuildError lists the compiler diagnostics of a generated server that does
+// not build
+type BuildError struct {
+	Diagnostics []Finding
+}
+
+func (e *BuildError) Error() string {
+	messages := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		messages[i] = d.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// GeneratedFile is a single file written by the generator
+type GeneratedFile struct {
+	// Path is relative to the output directory
+	Path     string
+	Template string
+	Size     int64
+	Status   FileStatus
+}
@@ -0,0 +1,312 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"MCPWeaver/internal/common"
+)
+
+// UnitTestOptions controls a run of a server's own Go tests
+type UnitTestOptions struct {
+	// Dir is the directory containing the generated server
+	Dir string
+	// Env holds NAME=value variables for the tests
+	Env []string
+	// Image runs the tests in a disposable docker container of this image
+	// instead of with the host's Go toolchain
+	Image string
+}
+
+// UnitTest is the outcome of one test function
+type UnitTest struct {
+	// Name is the test function, qualified by its package when the server
+	// has several packages with tests
+	Name     string
+	Passed   bool
+	Skipped  bool
+	Duration time.Duration
+	// Output is what the test printed, kept for failed tests
+	Output string
+}
+
+// UnitTestResult is the outcome of go test -cover on a server
+type UnitTestResult struct {
+	Tests []UnitTest
+	// BuildFailures holds the output of packages that did not compile or
+	// failed outside any test, by package
+	BuildFailures map[string]string
+	// Statements and Covered count the statements of the server's packages
+	// and those the tests ran
+	Statements int
+	Covered    int
+	Duration   time.Duration
+}
+
+// Coverage returns the share of statements the tests ran, in percent
+func (r *UnitTestResult) Coverage() float64 {
+	if r.Statements == 0 {
+		return 0
+	}
+	return 100 * float64(r.Covered) / float64(r.Statements)
+}
+
+// HasUnitTests reports whether the server in dir has Go test files, outside
+// hidden, testdata and vendor directories
+func HasUnitTests(dir string) bool {
+	found := errors.New("found")
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "testdata" || d.Name() == "vendor") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), "_test.go") {
+			return found
+		}
+		return nil
+	})
+	return err == found
+}
+
+// RunUnitTests runs the Go tests of the server with go test -json
+// -coverprofile and measures the statement coverage of its packages. Failed
+// tests are reported in the result, not as an error.
+func (s *Service) RunUnitTests(ctx context.Context, opts UnitTestOptions) (*UnitTestResult, error) {
+	work, err := os.MkdirTemp("", "mcpweaver-unit-")
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeTest, "failed to create work directory", err)
+	}
+	defer os.RemoveAll(work)
+	profile := filepath.Join(work, "cover.out")
+
+	var cmd *exec.Cmd
+	if opts.Image != "" {
+		docker, err := dockerTool(opts.Image)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, docker, dockerTestArgs(opts.Image, opts.Dir, work, opts.Env)...)
+		cmd.Env = append(os.Environ(), opts.Env...)
+	} else {
+		goTool, err := exec.LookPath("go")
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeTest, "go toolchain not found on PATH", err).
+				WithSuggestion("Install Go or test with --docker-image")
+		}
+		cmd = exec.CommandContext(ctx, goTool, "test", "-json", "-covermode=set", "-coverprofile="+profile, "./...")
+		cmd.Dir = opts.Dir
+		cmd.Env = append(append(os.Environ(), "GOWORK=off"), opts.Env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	start := time.Now()
+	output, runErr := cmd.Output()
+	result := parseTestEvents(output)
+	result.Duration = time.Since(start)
+	if ctx.Err() != nil {
+		return nil, common.NewError(common.ErrorTypeTest, "unit tests interrupted", ctx.Err())
+	}
+
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) && len(result.Tests) == 0 && len(result.BuildFailures) == 0 {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = runErr.Error()
+		}
+		// Without events, go test failed before running anything, as when
+		// the module does not load
+		result.BuildFailures = map[string]string{".": tail(message, 2000)}
+	}
+	if content, err := os.ReadFile(profile); err == nil {
+		result.Statements, result.Covered = parseCoverProfile(content)
+	}
+	return result, nil
+}
+
+// dockerTestArgs runs go test on the server in dir inside a disposable
+// container, writing the coverage profile to work. The source is mounted
+// read-only and modules are downloaded into the container.
+func dockerTestArgs(image, dir, work string, env []string) []string {
+	args := []string{"run", "--rm",
+		"--volume", dir + ":" + containerSource + ":ro",
+		"--volume", work + ":" + containerWork,
+		"--workdir", containerSource,
+		"--env", "GOWORK=off",
+		"--env", "GOFLAGS=-buildvcs=false",
+	}
+	args = append(args, containerUser()...)
+	for _, kv := range env {
+		variable, _, _ := strings.Cut(kv, "=")
+		args = append(args, "--env", variable)
+	}
+	return append(args, image, "go", "test", "-json", "-covermode=set", "-coverprofile="+containerWork+"/cover.out", "./...")
+}
+
+// testEvent is a line of go test -json output
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// parseTestEvents collects the test outcomes from go test -json output.
+// Lines that are not events, such as build errors printed by older
+// toolchains, are attributed to no package.
+func parseTestEvents(output []byte) *UnitTestResult {
+	// Output is kept per package and top-level test, subtests reporting
+	// with their parent
+	type key struct{ pkg, test string }
+	type outcome struct {
+		key
+		test UnitTest
+	}
+	outputs := map[key]*strings.Builder{}
+	write := func(k key, text string) {
+		if outputs[k] == nil {
+			outputs[k] = &strings.Builder{}
+		}
+		outputs[k].WriteString(text)
+	}
+	var outcomes []outcome
+	packages := map[string]bool{}
+	failedTests := map[string]bool{}
+	var failedPackages []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Action == "" {
+			write(key{}, scanner.Text()+"\n")
+			continue
+		}
+		test, _, subtest := strings.Cut(event.Test, "/")
+		k := key{event.Package, test}
+		switch event.Action {
+		case "output", "build-output":
+			write(k, event.Output)
+		case "pass", "fail", "skip":
+			if test == "" {
+				if event.Action == "fail" {
+					failedPackages = append(failedPackages, event.Package)
+				}
+				continue
+			}
+			if subtest {
+				continue
+			}
+			packages[event.Package] = true
+			if event.Action == "fail" {
+				failedTests[event.Package] = true
+			}
+			outcomes = append(outcomes, outcome{k, UnitTest{
+				Name:     test,
+				Passed:   event.Action != "fail",
+				Skipped:  event.Action == "skip",
+				Duration: time.Duration(event.Elapsed * float64(time.Second)),
+			}})
+		}
+	}
+
+	result := &UnitTestResult{BuildFailures: map[string]string{}}
+	for _, o := range outcomes {
+		if !o.test.Passed && outputs[o.key] != nil {
+			o.test.Output = tail(strings.TrimSpace(outputs[o.key].String()), 2000)
+		}
+		if len(packages) > 1 {
+			o.test.Name = o.pkg + "." + o.test.Name
+		}
+		result.Tests = append(result.Tests, o.test)
+	}
+	for _, pkg := range failedPackages {
+		if failedTests[pkg] {
+			continue
+		}
+		var out strings.Builder
+		for _, k := range []key{{}, {pkg, ""}} {
+			if outputs[k] != nil {
+				out.WriteString(outputs[k].String())
+			}
+		}
+		result.BuildFailures[pkg] = tail(strings.TrimSpace(out.String()), 2000)
+	}
+	return result
+}
+
+// parseCoverProfile counts the statements of a coverage profile and those
+// run at least once. Blocks listed more than once, by tests of several
+// packages, are counted once.
+func parseCoverProfile(profile []byte) (statements, covered int) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]*block{}
+	for _, line := range strings.Split(string(profile), "\n") {
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// file.go:12.34,15.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		n, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		b := blocks[fields[0]]
+		if b == nil {
+			b = &block{statements: n}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	for _, b := range blocks {
+		statements += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	return statements, covered
+}
+
+// String summarizes the result, e.g. "12 tests, 1 failed, 78.4% of
+// statements covered"
+func (r *UnitTestResult) String() string {
+	failed, skipped := 0, 0
+	for _, test := range r.Tests {
+		if !test.Passed {
+			failed++
+		} else if test.Skipped {
+			skipped++
+		}
+	}
+	summary := fmt.Sprintf("%d tests", len(r.Tests))
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d failed", failed)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	if len(r.BuildFailures) > 0 {
+		summary += fmt.Sprintf(", %d packages failed to build", len(r.BuildFailures))
+	}
+	return summary + fmt.Sprintf(", %.1f%% of statements covered", r.Coverage())
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeModule writes the files of a Go module to a new directory
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/server\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestHasUnitTests(t *testing.T) {
+	dir := writeModule(t, map[string]string{"main.go": "package main\n", "testdata/x_test.go": "package x\n"})
+	assert.False(t, HasUnitTests(dir), "test files in testdata are not the server's")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main_test.go"), []byte("package main\n"), 0644))
+	assert.True(t, HasUnitTests(dir))
+}
+
+func TestRunUnitTests(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+	dir := writeModule(t, map[string]string{
+		"main.go": `package main
+
+func main() {}
+
+func add(a, b int) int { return a + b }
+
+func sub(a, b int) int {
+	if a < b {
+		return b - a
+	}
+	return a - b
+}
+`,
+		"main_test.go": `package main
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if add(1, 2) != 3 {
+		t.Fatal("wrong sum")
+	}
+}
+
+func TestSub(t *testing.T) {
+	t.Run("smaller", func(t *testing.T) {
+		if sub(3, 1) != 3 {
+			t.Fatal("wrong difference")
+		}
+	})
+}
+
+func TestLater(t *testing.T) {
+	t.Skip("not yet")
+}
+`,
+	})
+
+	result, err := NewService().RunUnitTests(context.Background(), UnitTestOptions{Dir: dir})
+	require.NoError(t, err)
+	require.Len(t, result.Tests, 3)
+	assert.Equal(t, "TestAdd", result.Tests[0].Name)
+	assert.True(t, result.Tests[0].Passed)
+	assert.Empty(t, result.Tests[0].Output, "the output of passed tests is not kept")
+	assert.Equal(t, "TestSub", result.Tests[1].Name, "subtests are reported with their parent")
+	assert.False(t, result.Tests[1].Passed)
+	assert.Contains(t, result.Tests[1].Output, "wrong difference")
+	assert.True(t, result.Tests[2].Skipped)
+	assert.Empty(t, result.BuildFailures)
+	assert.Positive(t, result.Statements)
+	assert.Less(t, result.Covered, result.Statements, "main and a branch of sub are not run")
+	assert.Contains(t, result.String(), "3 tests, 1 failed, 1 skipped, ")
+
+	// A package that does not compile is a build failure
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main_test.go"), []byte("package main\n\nfunc TestBroken(t *testing.T) {}\n"), 0644))
+	result, err = NewService().RunUnitTests(context.Background(), UnitTestOptions{Dir: dir})
+	require.NoError(t, err)
+	assert.Empty(t, result.Tests)
+	require.Len(t, result.BuildFailures, 1)
+	for _, output := range result.BuildFailures {
+		assert.Contains(t, output, "undefined: testing")
+	}
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	profile := `mode: set
+example.com/server/main.go:3.13,3.14 0 0
+example.com/server/main.go:5.32,5.50 1 1
+example.com/server/main.go:7.25,8.11 2 0
+example.com/server/main.go:7.25,8.11 2 1
+example.com/server/main.go:11.2,11.14 1 0
+not a block
+`
+	statements, covered := parseCoverProfile([]byte(profile))
+	assert.Equal(t, 4, statements, "blocks listed twice are counted once")
+	assert.Equal(t, 3, covered, "a block run by any package's tests is covered")
+	assert.Equal(t, 75.0, (&UnitTestResult{Statements: statements, Covered: covered}).Coverage())
+}
+
+func TestParseTestEvents(t *testing.T) {
+	output := `{"Action":"run","Package":"example.com/server","Test":"TestA"}
+{"Action":"pass","Package":"example.com/server","Test":"TestA","Elapsed":0.5}
+{"Action":"output","Package":"example.com/server/store","Test":"TestB","Output":"store_test.go:9: no rows\n"}
+{"Action":"fail","Package":"example.com/server/store","Test":"TestB","Elapsed":0}
+{"Action":"fail","Package":"example.com/server/store","Elapsed":0.1}
+# example.com/server/api
+api/api.go:3:1: syntax error
+{"Action":"output","Package":"example.com/server/api","Output":"FAIL\texample.com/server/api [build failed]\n"}
+{"Action":"fail","Package":"example.com/server/api","Elapsed":0}
+`
+	result := parseTestEvents([]byte(output))
+	require.Len(t, result.Tests, 2)
+	assert.Equal(t, "example.com/server.TestA", result.Tests[0].Name, "tests are qualified by package when several have tests")
+	assert.Equal(t, 500_000_000, int(result.Tests[0].Duration))
+	assert.Equal(t, "store_test.go:9: no rows", result.Tests[1].Output)
+	assert.Equal(t, map[string]string{
+		"example.com/server/api": "# example.com/server/api\napi/api.go:3:1: syntax error\nFAIL\texample.com/server/api [build failed]",
+	}, result.BuildFailures, "a package failing with failed tests is not a build failure")
+}
@@ -0,0 +1,401 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	"MCPWeaver/internal/common"
+)
+
+// Template issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// TemplateIssue is a problem found while validating a template package
+type TemplateIssue struct {
+	File     string
+	Line     int
+	Severity string
+	Message  string
+	// Rule names the lint rule that reported the issue, if any
+	Rule string
+}
+
+// String formats the issue like compiler output
+func (i TemplateIssue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location += ":" + strconv.Itoa(i.Line)
+	}
+	if i.Rule != "" {
+		return fmt.Sprintf("%s: %s: %s [%s]", location, i.Severity, i.Message, i.Rule)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, i.Severity, i.Message)
+}
+
+// builtinFuncs are the functions text/template predefines
+var builtinFuncs = map[string]bool{
+	"and": true, "call": true, "html": true, "index": true, "slice": true, "js": true,
+	"len": true, "not": true, "or": true, "print": true, "printf": true, "println": true,
+	"urlquery": true, "eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+}
+
+// parseErrorPattern matches "template: name:line: message" parse errors
+var parseErrorPattern = regexp.MustCompile(`^template: (.*?):(\d+): (.*)$`)
+
+// parsedTemplate is a template file parsed into its trees
+type parsedTemplate struct {
+	file  string
+	trees map[string]*parse.Tree
+	// dependency marks partials from an installed dependency, which may
+	// define more than the package uses
+	dependency bool
+}
+
+// ValidateTemplates checks the template package selected by the options
+// without rendering it. Files are parsed into syntax trees to report parse
+// errors with line numbers, calls to undefined functions, references to
+// undefined templates, unreachable branches and unused partials. A separate
+// walk flags constructs that are unsafe in shared templates, and the lint
+// rules configured by opts.Lint check style. The error is only set when the
+// package cannot be read at all or the lint configuration is invalid.
+func (s *Service) ValidateTemplates(opts Options) ([]TemplateIssue, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	lint, err := newLinter(opts.Lint)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []TemplateIssue
+	if opts.TemplateDir != "" {
+		symlinkIssues, err := checkSymlinks(opts.TemplateDir)
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "failed to read template directory", err).
+				WithFile(opts.TemplateDir)
+		}
+		issues = append(issues, symlinkIssues...)
+	}
+
+	m, manifestName, err := source.manifest()
+	if err != nil {
+		return append(issues, TemplateIssue{File: manifestName, Severity: SeverityError, Message: err.Error()}), nil
+	}
+
+	partials, err := source.partials()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to read template partials", err)
+	}
+
+	var parsed []parsedTemplate
+	for _, partial := range partials {
+		p, parseIssues := parseTemplate(partial.Path, partial.Content)
+		issues = append(issues, parseIssues...)
+		if p != nil {
+			p.dependency = partial.Dependency
+			parsed = append(parsed, *p)
+			if !p.dependency {
+				issues = append(issues, lint.lint(*p, "")...)
+			}
+		}
+	}
+
+	for _, v := range m.Variables {
+		if err := v.check(); err != nil {
+			issues = append(issues, TemplateIssue{File: manifestName, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+		if v.Type == VariablePath && v.Default != "" && opts.TemplateDir != "" && !filepath.IsAbs(v.Default) {
+			if _, err := os.Stat(filepath.Join(opts.TemplateDir, v.Default)); err != nil {
+				issues = append(issues, TemplateIssue{File: manifestName, Severity: SeverityError,
+					Message: fmt.Sprintf("default of variable %s: %s does not exist in the template package", v.Name, v.Default)})
+			}
+		}
+	}
+	for _, entry := range m.Files {
+		issues = append(issues, checkManifestEntry(manifestName, entry)...)
+		content, name, err := source.readFile(entry.Template)
+		if err != nil {
+			issues = append(issues, TemplateIssue{
+				File:     manifestName,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("template %s: %v", entry.Template, err),
+			})
+			continue
+		}
+		p, parseIssues := parseTemplate(name, string(content))
+		issues = append(issues, parseIssues...)
+		if p != nil {
+			parsed = append(parsed, *p)
+			issues = append(issues, lint.lint(*p, entry.Output)...)
+		}
+	}
+
+	issues = append(issues, checkReferences(parsed)...)
+	issues = append(issues, checkMessages(source, parsed)...)
+	for _, p := range parsed {
+		for _, tree := range p.trees {
+			issues = append(issues, checkSecurity(p.file, tree)...)
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Message < issues[j].Message
+	})
+	return issues, nil
+}
+
+// parseTemplate parses a file without checking functions, so that every
+// undefined function can be reported with its position instead of only the
+// first
+func parseTemplate(file, content string) (*parsedTemplate, []TemplateIssue) {
+	trees := map[string]*parse.Tree{}
+	tree := parse.New(file)
+	tree.Mode = parse.SkipFuncCheck | parse.ParseComments
+	if _, err := tree.Parse(content, "", "", trees); err != nil {
+		issue := TemplateIssue{File: file, Severity: SeverityError, Message: err.Error()}
+		if m := parseErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+			issue.Line, _ = strconv.Atoi(m[2])
+			issue.Message = m[3]
+		}
+		return nil, []TemplateIssue{issue}
+	}
+
+	p := &parsedTemplate{file: file, trees: trees}
+	var issues []TemplateIssue
+	for _, t := range trees {
+		walkTree(t.Root, func(node parse.Node) {
+			switch n := node.(type) {
+			case *parse.IdentifierNode:
+				if _, ok := funcMap[n.Ident]; !ok && !builtinFuncs[n.Ident] {
+					issues = append(issues, nodeIssue(file, t, n, SeverityError,
+						fmt.Sprintf("function %q not defined", n.Ident)))
+				}
+			case *parse.IfNode:
+				issues = append(issues, checkConstantBranch(file, t, &n.BranchNode, "if")...)
+			case *parse.WithNode:
+				issues = append(issues, checkConstantBranch(file, t, &n.BranchNode, "with")...)
+			}
+		})
+	}
+	return p, issues
+}
+
+// checkConstantBranch reports branches that can never run because the
+// condition is a literal
+func checkConstantBranch(file string, tree *parse.Tree, branch *parse.BranchNode, keyword string) []TemplateIssue {
+	if branch.Pipe == nil || len(branch.Pipe.Decl) > 0 || len(branch.Pipe.Cmds) != 1 || len(branch.Pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+
+	var truth bool
+	switch arg := branch.Pipe.Cmds[0].Args[0].(type) {
+	case *parse.BoolNode:
+		truth = arg.True
+	case *parse.NilNode:
+		truth = false
+	case *parse.StringNode:
+		truth = arg.Text != ""
+	case *parse.NumberNode:
+		truth = arg.Text != "0"
+	default:
+		return nil
+	}
+
+	if truth && branch.ElseList != nil {
+		return []TemplateIssue{nodeIssue(file, tree, branch.ElseList, SeverityWarning,
+			fmt.Sprintf("else branch is unreachable; the %s condition is always true", keyword))}
+	}
+	if !truth {
+		return []TemplateIssue{nodeIssue(file, tree, branch, SeverityWarning,
+			fmt.Sprintf("%s block is unreachable; its condition is always false", keyword))}
+	}
+	return nil
+}
+
+// checkReferences reports {{template}} calls to names defined nowhere in the
+// package and partial definitions no template uses
+func checkReferences(parsed []parsedTemplate) []TemplateIssue {
+	defined := map[string]string{}
+	used := map[string]bool{}
+	for _, p := range parsed {
+		for name := range p.trees {
+			if name != p.file {
+				defined[name] = p.file
+				// Unused definitions of dependencies are not reported
+				used[name] = used[name] || p.dependency
+			}
+		}
+	}
+
+	var issues []TemplateIssue
+	for _, p := range parsed {
+		for _, tree := range p.trees {
+			walkTree(tree.Root, func(node parse.Node) {
+				n, ok := node.(*parse.TemplateNode)
+				if !ok {
+					return
+				}
+				used[n.Name] = true
+				if _, ok := defined[n.Name]; !ok {
+					issues = append(issues, nodeIssue(p.file, tree, n, SeverityError,
+						fmt.Sprintf("template %q is not defined", n.Name)))
+				}
+			})
+		}
+	}
+
+	for name, file := range defined {
+		if !used[name] {
+			issues = append(issues, TemplateIssue{
+				File:     file,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("template %q is defined but never used", name),
+			})
+		}
+	}
+	return issues
+}
+
+// checkSecurity flags constructs that let a shared template run code it does
+// not declare. It is kept apart from the correctness checks so the policy
+// can evolve on its own.
+func checkSecurity(file string, tree *parse.Tree) []TemplateIssue {
+	var issues []TemplateIssue
+	walkTree(tree.Root, func(node parse.Node) {
+		if n, ok := node.(*parse.IdentifierNode); ok && n.Ident == "call" {
+			issues = append(issues, nodeIssue(file, tree, n, SeverityError,
+				"call is not available in templates"))
+		}
+	})
+	return issues
+}
+
+// checkManifestEntry validates the static parts of a manifest entry
+func checkManifestEntry(manifestName string, entry manifestEntry) []TemplateIssue {
+	issue := func(message string) []TemplateIssue {
+		return []TemplateIssue{{File: manifestName, Severity: SeverityError, Message: message}}
+	}
+	if entry.Template == "" || entry.Output == "" {
+		return issue("manifest entries need a template and an output")
+	}
+	if !fs.ValidPath(entry.Template) {
+		return issue(fmt.Sprintf("template path %q must be relative to the package", entry.Template))
+	}
+	// Interpolated outputs are checked when generating
+	if !strings.Contains(entry.Output, "{{") {
+		output := path.Clean(entry.Output)
+		if path.IsAbs(output) || output == ".." || strings.HasPrefix(output, "../") {
+			return issue(fmt.Sprintf("output path %q of %s leaves the output directory", entry.Output, entry.Template))
+		}
+	}
+	return nil
+}
+
+// checkSymlinks reports links in a custom package that point outside it,
+// which would let a shared package read arbitrary files
+func checkSymlinks(dir string) ([]TemplateIssue, error) {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []TemplateIssue
+	err = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.Type()&os.ModeSymlink == 0 {
+			return err
+		}
+		target, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			issues = append(issues, TemplateIssue{File: p, Severity: SeverityError, Message: "broken symbolic link"})
+			return nil
+		}
+		if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			issues = append(issues, TemplateIssue{
+				File:     p,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("symbolic link points outside the template package (%s)", target),
+			})
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// walkTree calls visit for node and every node below it
+func walkTree(node parse.Node, visit func(parse.Node)) {
+	if node == nil {
+		return
+	}
+	visit(node)
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			walkTree(child, visit)
+		}
+	case *parse.ActionNode:
+		walkTree(n.Pipe, visit)
+	case *parse.PipeNode:
+		for _, cmd := range n.Cmds {
+			walkTree(cmd, visit)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTree(arg, visit)
+		}
+	case *parse.ChainNode:
+		walkTree(n.Node, visit)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			walkTree(n.Pipe, visit)
+		}
+	}
+}
+
+func walkBranch(branch *parse.BranchNode, visit func(parse.Node)) {
+	walkTree(branch.Pipe, visit)
+	walkTree(branch.List, visit)
+	if branch.ElseList != nil {
+		walkTree(branch.ElseList, visit)
+	}
+}
+
+// nodeIssue builds an issue positioned at the node
+func nodeIssue(file string, tree *parse.Tree, node parse.Node, severity, message string) TemplateIssue {
+	issue := TemplateIssue{File: file, Severity: severity, Message: message}
+	location, _ := tree.ErrorContext(node)
+	// location is "name:line:col"; the name may itself contain colons
+	parts := strings.Split(location, ":")
+	if len(parts) >= 3 {
+		issue.Line, _ = strconv.Atoi(parts[len(parts)-2])
+	}
+	return issue
+}
@@ -0,0 +1,247 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"MCPWeaver/internal/common"
+)
+
+// VariableType is the type of a template variable
+type VariableType string
+
+// Template variable types
+const (
+	VariableString  VariableType = "string"
+	VariableBoolean VariableType = "boolean"
+	VariableInteger VariableType = "integer"
+	// VariableEnum accepts one of the variable's Options
+	VariableEnum VariableType = "enum"
+	// VariablePath accepts the path of an existing file or directory
+	VariablePath VariableType = "path"
+	// VariableSecret is a string never included in messages; it is best
+	// provided through Env
+	VariableSecret VariableType = "secret"
+)
+
+// TemplateVariable is a value a template package asks the user for. It is
+// declared in the manifest and available to templates as .Vars.{name},
+// typed as bool, int or string.
+type TemplateVariable struct {
+	Name        string       `json:"name"`
+	Type        VariableType `json:"type"`
+	Description string       `json:"description,omitempty"`
+	Required    bool         `json:"required,omitempty"`
+	// Default is used when no value is given, written like user input. A
+	// relative path is relative to the template package directory.
+	Default string `json:"default,omitempty"`
+	// Env names an environment variable read when no value is given
+	Env string `json:"env,omitempty"`
+	// Options are the values an enum accepts
+	Options []string `json:"options,omitempty"`
+	// Min and Max bound integers
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+	// Pattern is a regular expression strings and secrets must match
+	Pattern string `json:"pattern,omitempty"`
+}
+
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// check validates the declaration itself
+func (v TemplateVariable) check() error {
+	if !variableNamePattern.MatchString(v.Name) {
+		return fmt.Errorf("invalid variable name %q", v.Name)
+	}
+	switch v.Type {
+	case VariableString, VariableBoolean, VariableInteger, VariablePath, VariableSecret:
+	case VariableEnum:
+		if len(v.Options) == 0 {
+			return fmt.Errorf("enum variable %s has no options", v.Name)
+		}
+	default:
+		return fmt.Errorf("variable %s has unknown type %q", v.Name, v.Type)
+	}
+	if v.Pattern != "" {
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return fmt.Errorf("variable %s has an invalid pattern: %v", v.Name, err)
+		}
+	}
+	if v.Default != "" {
+		// Whether a default path exists depends on where the package is
+		if _, err := v.parse(v.Default, false); err != nil {
+			return fmt.Errorf("default of variable %s: %v", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// Parse validates a value entered for the variable and converts it to the
+// variable's type. Errors never contain the value of a secret.
+func (v TemplateVariable) Parse(value string) (interface{}, error) {
+	return v.parse(value, true)
+}
+
+// parse is Parse, checking that paths exist only when statPaths is set
+func (v TemplateVariable) parse(value string, statPaths bool) (interface{}, error) {
+	switch v.Type {
+	case VariableBoolean:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", value)
+		}
+		return b, nil
+	case VariableInteger:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		if v.Min != nil && n < *v.Min {
+			return nil, fmt.Errorf("%d is less than %d", n, *v.Min)
+		}
+		if v.Max != nil && n > *v.Max {
+			return nil, fmt.Errorf("%d is greater than %d", n, *v.Max)
+		}
+		return n, nil
+	case VariableEnum:
+		for _, option := range v.Options {
+			if value == option {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", value, strings.Join(v.Options, ", "))
+	case VariablePath:
+		if strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("no path given")
+		}
+		if _, err := os.Stat(value); err != nil && statPaths {
+			return nil, fmt.Errorf("%s does not exist", value)
+		}
+		return filepath.Clean(value), nil
+	}
+
+	if v.Pattern != "" {
+		pattern, err := regexp.Compile(v.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if !pattern.MatchString(value) {
+			if v.Type == VariableSecret {
+				return nil, fmt.Errorf("value does not match %s", v.Pattern)
+			}
+			return nil, fmt.Errorf("%q does not match %s", value, v.Pattern)
+		}
+	}
+	return value, nil
+}
+
+// TemplateVariables returns the variables declared by the template package
+// selected by the options
+func (s *Service) TemplateVariables(opts Options) ([]TemplateVariable, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	source, err := newTemplateSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	m, name, err := source.manifest()
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeGeneration, "failed to load template manifest", err).WithFile(name)
+	}
+	return m.Variables, nil
+}
+
+// resolveVariables validates the given values against the declarations and
+// returns the typed values of every declared variable. Values come from the
+// options, then the variable's environment variable, then its default;
+// optional variables without any are the zero value of their type, and an
+// empty value does not count for a required one. Default paths are relative
+// to packageDir, the directory of a custom template package, and are not
+// checked for the built-in packages, which have none.
+func resolveVariables(declared []TemplateVariable, values map[string]string, packageDir string) (map[string]interface{}, error) {
+	known := map[string]bool{}
+	for _, v := range declared {
+		known[v.Name] = true
+	}
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		names := make([]string, 0, len(declared))
+		for _, v := range declared {
+			names = append(names, v.Name)
+		}
+		err := common.NewError(common.ErrorTypeValidation,
+			fmt.Sprintf("the template does not declare %s", strings.Join(unknown, ", ")), nil)
+		if len(names) == 0 {
+			return nil, err.WithSuggestion("The template takes no variables")
+		}
+		return nil, err.WithSuggestion("Declared variables: " + strings.Join(names, ", "))
+	}
+
+	vars := map[string]interface{}{}
+	for _, v := range declared {
+		if err := v.check(); err != nil {
+			return nil, common.NewError(common.ErrorTypeGeneration, "invalid template variable", err).WithFile(manifestFile)
+		}
+
+		given := func(value string, ok bool) bool {
+			return ok && !(v.Required && strings.TrimSpace(value) == "")
+		}
+		value, ok := values[v.Name]
+		ok = given(value, ok)
+		if !ok && v.Env != "" {
+			value, ok = os.LookupEnv(v.Env)
+			ok = given(value, ok)
+		}
+		statPaths := true
+		if !ok && v.Default != "" {
+			value, ok = v.Default, true
+			if v.Type == VariablePath {
+				statPaths = packageDir != ""
+				if statPaths && !filepath.IsAbs(value) {
+					value = filepath.Join(packageDir, value)
+				}
+			}
+		}
+		if !ok {
+			if v.Required {
+				message := fmt.Sprintf("template variable %s is required", v.Name)
+				if v.Env != "" {
+					message += fmt.Sprintf(" (or set %s)", v.Env)
+				}
+				return nil, common.NewError(common.ErrorTypeValidation, message, nil).WithSuggestion(v.Description)
+			}
+			vars[v.Name] = zeroValue(v.Type)
+			continue
+		}
+
+		parsed, err := v.parse(value, statPaths)
+		if err != nil {
+			return nil, common.NewError(common.ErrorTypeValidation,
+				fmt.Sprintf("invalid value for template variable %s", v.Name), err).WithSuggestion(v.Description)
+		}
+		vars[v.Name] = parsed
+	}
+	return vars, nil
+}
+
+func zeroValue(t VariableType) interface{} {
+	switch t {
+	case VariableBoolean:
+		return false
+	case VariableInteger:
+		return 0
+	}
+	return ""
+}
@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/common"
+)
+
+func TestParseVariable(t *testing.T) {
+	min, max := 1, 5
+	existing := t.TempDir()
+	tests := []struct {
+		name     string
+		variable TemplateVariable
+		value    string
+		want     interface{}
+		err      string
+	}{
+		{"string", TemplateVariable{Type: VariableString}, "payments", "payments", ""},
+		{"pattern", TemplateVariable{Type: VariableString, Pattern: `^[a-z]+$`}, "Payments", nil, `"Payments" does not match ^[a-z]+$`},
+		{"secret pattern", TemplateVariable{Type: VariableSecret, Pattern: `^sk_`}, "hunter2", nil, "value does not match ^sk_"},
+		{"secret", TemplateVariable{Type: VariableSecret, Pattern: `^sk_`}, "sk_live", "sk_live", ""},
+		{"boolean", TemplateVariable{Type: VariableBoolean}, " true ", true, ""},
+		{"not a boolean", TemplateVariable{Type: VariableBoolean}, "yes", nil, `"yes" is not true or false`},
+		{"integer", TemplateVariable{Type: VariableInteger, Min: &min, Max: &max}, "3", 3, ""},
+		{"below min", TemplateVariable{Type: VariableInteger, Min: &min}, "0", nil, "0 is less than 1"},
+		{"above max", TemplateVariable{Type: VariableInteger, Max: &max}, "6", nil, "6 is greater than 5"},
+		{"not an integer", TemplateVariable{Type: VariableInteger}, "three", nil, `"three" is not an integer`},
+		{"option", TemplateVariable{Type: VariableEnum, Options: []string{"small", "large"}}, "large", "large", ""},
+		{"not an option", TemplateVariable{Type: VariableEnum, Options: []string{"small", "large"}}, "Large", nil, `"Large" is not one of small, large`},
+		{"path", TemplateVariable{Type: VariablePath}, existing + "/", existing, ""},
+		{"missing path", TemplateVariable{Type: VariablePath}, "/does/not/exist", nil, "/does/not/exist does not exist"},
+		{"empty path", TemplateVariable{Type: VariablePath}, "", nil, "no path given"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.variable.Parse(tt.value)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckVariable(t *testing.T) {
+	tests := []struct {
+		variable TemplateVariable
+		err      string
+	}{
+		{TemplateVariable{Name: "team", Type: VariableString}, ""},
+		{TemplateVariable{Name: "assets", Type: VariablePath, Default: "assets"}, ""},
+		{TemplateVariable{Name: "2team", Type: VariableString}, `invalid variable name "2team"`},
+		{TemplateVariable{Name: "size", Type: VariableEnum}, "enum variable size has no options"},
+		{TemplateVariable{Name: "size", Type: "float"}, `variable size has unknown type "float"`},
+		{TemplateVariable{Name: "team", Type: VariableString, Pattern: "("}, "variable team has an invalid pattern"},
+		{TemplateVariable{Name: "replicas", Type: VariableInteger, Default: "many"}, `default of variable replicas: "many" is not an integer`},
+	}
+	for _, tt := range tests {
+		err := tt.variable.check()
+		if tt.err == "" {
+			assert.NoError(t, err, "%+v", tt.variable)
+		} else {
+			assert.ErrorContains(t, err, tt.err)
+		}
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	declared := []TemplateVariable{
+		{Name: "team", Type: VariableString, Required: true, Env: "MCPWEAVER_TEST_TEAM"},
+		{Name: "replicas", Type: VariableInteger, Default: "2"},
+		{Name: "size", Type: VariableEnum, Options: []string{"small", "large"}, Default: "small"},
+		{Name: "debug", Type: VariableBoolean},
+		{Name: "token", Type: VariableSecret, Env: "MCPWEAVER_TEST_TOKEN"},
+	}
+	t.Setenv("MCPWEAVER_TEST_TOKEN", "")
+
+	vars, err := resolveVariables(declared, map[string]string{"team": "payments", "size": "large"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"team":     "payments",
+		"replicas": 2,
+		"size":     "large",
+		"debug":    false,
+		"token":    "",
+	}, vars, "defaults and zero values fill in what is not given")
+
+	t.Setenv("MCPWEAVER_TEST_TEAM", "search")
+	t.Setenv("MCPWEAVER_TEST_TOKEN", "sk_123")
+	vars, err = resolveVariables(declared, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, "search", vars["team"], "the environment is read when no value is given")
+	assert.Equal(t, "sk_123", vars["token"])
+	vars, err = resolveVariables(declared, map[string]string{"team": "payments"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "payments", vars["team"], "given values take precedence over the environment")
+	vars, err = resolveVariables(declared, map[string]string{"team": ""}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "search", vars["team"], "an empty value falls back to the environment")
+
+	tests := []struct {
+		name   string
+		values map[string]string
+		env    string
+		err    string
+	}{
+		{"required missing", nil, "", "template variable team is required (or set MCPWEAVER_TEST_TEAM)"},
+		{"required empty", map[string]string{"team": " "}, "", "template variable team is required"},
+		{"required empty in the environment", nil, " ", "template variable team is required"},
+		{"invalid option", map[string]string{"team": "a", "size": "huge"}, "", "invalid value for template variable size"},
+		{"invalid integer", map[string]string{"team": "a", "replicas": ""}, "", "invalid value for template variable replicas"},
+		{"undeclared", map[string]string{"team": "a", "region": "eu", "owner": "x"}, "", "the template does not declare owner, region"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MCPWEAVER_TEST_TEAM", tt.env)
+			if tt.env == "" {
+				os.Unsetenv("MCPWEAVER_TEST_TEAM")
+			}
+			_, err := resolveVariables(declared, tt.values, "")
+			var pipelineErr *common.Error
+			require.ErrorAs(t, err, &pipelineErr)
+			assert.Equal(t, common.ErrorTypeValidation, pipelineErr.Type)
+			assert.Contains(t, pipelineErr.Error(), tt.err)
+		})
+	}
+
+	_, err = resolveVariables(nil, map[string]string{"team": "a"}, "")
+	var pipelineErr *common.Error
+	require.ErrorAs(t, err, &pipelineErr)
+	assert.Equal(t, "The template takes no variables", pipelineErr.Suggestion)
+}
+
+func TestResolvePathVariables(t *testing.T) {
+	pkg := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(pkg, "assets"), 0755))
+	declared := []TemplateVariable{{Name: "assets", Type: VariablePath, Default: "assets"}}
+
+	// Run somewhere without an assets directory: the default is relative to
+	// the package, not the working directory
+	chdir(t, t.TempDir())
+	vars, err := resolveVariables(declared, nil, pkg)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(pkg, "assets"), vars["assets"])
+
+	vars, err = resolveVariables(declared, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, "assets", vars["assets"], "the built-in packages have no directory to check defaults in")
+
+	_, err = resolveVariables([]TemplateVariable{{Name: "assets", Type: VariablePath, Default: "missing"}}, nil, pkg)
+	assert.ErrorContains(t, err, "does not exist")
+
+	_, err = resolveVariables(declared, map[string]string{"assets": "assets"}, pkg)
+	assert.ErrorContains(t, err, "assets does not exist", "given paths are relative to the working directory")
+}
+
+// chdir changes the working directory for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestValidateTemplatesPathDefaults(t *testing.T) {
+	pkg := t.TempDir()
+	manifest := `{"name": "acme", "version": "1.0.0",
+		"files": [{"template": "main.go.tmpl", "output": "main.go"}],
+		"variables": [{"name": "assets", "type": "path", "default": "assets"}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(pkg, manifestFile), []byte(manifest), 0644))
+
+	missing := "default of variable assets: assets does not exist in the template package"
+	messages := func() []string {
+		issues, err := NewService().ValidateTemplates(Options{TemplateDir: pkg})
+		require.NoError(t, err)
+		var messages []string
+		for _, issue := range issues {
+			messages = append(messages, issue.Message)
+		}
+		return messages
+	}
+	assert.Contains(t, messages(), missing)
+	require.NoError(t, os.Mkdir(filepath.Join(pkg, "assets"), 0755))
+	assert.NotContains(t, messages(), missing)
+}
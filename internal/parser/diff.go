@@ -0,0 +1,328 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SpecDiff lists the differences between two versions of a specification.
+// Operations are named "METHOD /path".
+type SpecDiff struct {
+	Added   []string        `json:"added"`
+	Removed []string        `json:"removed"`
+	Changed []OperationDiff `json:"changed"`
+}
+
+// OperationDiff lists the changes to an operation present in both versions
+type OperationDiff struct {
+	Operation string       `json:"operation"`
+	Changes   []SpecChange `json:"changes"`
+}
+
+// SpecChange is one difference. Breaking changes can fail requests or
+// break tool calls that worked against the old version.
+type SpecChange struct {
+	Description string `json:"description"`
+	Breaking    bool   `json:"breaking"`
+}
+
+// Empty reports whether the versions have the same operations, unchanged
+func (d *SpecDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Breaking lists the breaking changes, each prefixed with its operation.
+// Removed operations are always breaking.
+func (d *SpecDiff) Breaking() []string {
+	var breaking []string
+	for _, op := range d.Removed {
+		breaking = append(breaking, op+": operation removed")
+	}
+	for _, op := range d.Changed {
+		for _, change := range op.Changes {
+			if change.Breaking {
+				breaking = append(breaking, op.Operation+": "+change.Description)
+			}
+		}
+	}
+	return breaking
+}
+
+// Compare reports the operations added to, removed from and changed in the
+// new version of a specification. Changes are compared at the level MCP
+// tools see them: operation IDs, parameters, top-level request body
+// properties and success responses.
+func Compare(old, updated *ParsedSpec) *SpecDiff {
+	diff := &SpecDiff{Added: []string{}, Removed: []string{}, Changed: []OperationDiff{}}
+	before := map[string]Operation{}
+	for _, op := range old.Operations() {
+		before[operationKey(op)] = op
+	}
+	after := map[string]bool{}
+	for _, op := range updated.Operations() {
+		key := operationKey(op)
+		after[key] = true
+		previous, ok := before[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+			continue
+		}
+		if changes := compareOperation(previous, op); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, OperationDiff{Operation: key, Changes: changes})
+		}
+	}
+	for _, op := range old.Operations() {
+		if key := operationKey(op); !after[key] {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+	return diff
+}
+
+func operationKey(op Operation) string {
+	return op.Method + " " + op.Path
+}
+
+// compareOperation lists the changes between two versions of an operation
+func compareOperation(old, updated Operation) []SpecChange {
+	var changes []SpecChange
+	add := func(breaking bool, format string, args ...interface{}) {
+		changes = append(changes, SpecChange{Description: fmt.Sprintf(format, args...), Breaking: breaking})
+	}
+
+	// Tool names derive from operation IDs
+	if old.Operation.OperationID != updated.Operation.OperationID {
+		add(true, "operation ID changed from %q to %q", old.Operation.OperationID, updated.Operation.OperationID)
+	}
+	if old.Operation.Deprecated != updated.Operation.Deprecated && updated.Operation.Deprecated {
+		add(false, "operation deprecated")
+	}
+	if old.Operation.Summary != updated.Operation.Summary || old.Operation.Description != updated.Operation.Description {
+		add(false, "description changed")
+	}
+
+	oldParams, newParams := operationParameters(old), operationParameters(updated)
+	for _, key := range sortedKeys(newParams) {
+		param := newParams[key]
+		previous, ok := oldParams[key]
+		switch {
+		case !ok && param.Required:
+			add(true, "required %s parameter %q added", param.In, param.Name)
+		case !ok:
+			add(false, "optional %s parameter %q added", param.In, param.Name)
+		default:
+			if param.Required && !previous.Required {
+				add(true, "%s parameter %q is now required", param.In, param.Name)
+			}
+			if !param.Required && previous.Required {
+				add(false, "%s parameter %q is now optional", param.In, param.Name)
+			}
+			if oldType, newType := schemaTypeName(previous.Schema), schemaTypeName(param.Schema); oldType != newType {
+				add(true, "%s parameter %q changed type from %s to %s", param.In, param.Name, oldType, newType)
+			}
+		}
+	}
+	for _, key := range sortedKeys(oldParams) {
+		if _, ok := newParams[key]; !ok {
+			add(true, "%s parameter %q removed", oldParams[key].In, oldParams[key].Name)
+		}
+	}
+
+	changes = append(changes, compareRequestBody(old.Operation.RequestBody, updated.Operation.RequestBody)...)
+	changes = append(changes, compareResponses(old.Operation.Responses, updated.Operation.Responses)...)
+	return changes
+}
+
+// operationParameters merges path-level and operation parameters, keyed by
+// location and name
+func operationParameters(op Operation) map[string]*openapi3.Parameter {
+	params := map[string]*openapi3.Parameter{}
+	var refs openapi3.Parameters
+	if op.PathItem != nil {
+		refs = append(refs, op.PathItem.Parameters...)
+	}
+	refs = append(refs, op.Operation.Parameters...)
+	for _, ref := range refs {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		params[ref.Value.In+" "+ref.Value.Name] = ref.Value
+	}
+	return params
+}
+
+// compareRequestBody compares whether a body is sent and its top-level
+// properties
+func compareRequestBody(old, updated *openapi3.RequestBodyRef) []SpecChange {
+	oldBody, newBody := requestBody(old), requestBody(updated)
+	switch {
+	case oldBody == nil && newBody == nil:
+		return nil
+	case oldBody == nil && newBody.Required:
+		return []SpecChange{{Description: "required request body added", Breaking: true}}
+	case oldBody == nil:
+		return []SpecChange{{Description: "optional request body added"}}
+	case newBody == nil:
+		return []SpecChange{{Description: "request body removed", Breaking: true}}
+	}
+
+	var changes []SpecChange
+	if newBody.Required && !oldBody.Required {
+		changes = append(changes, SpecChange{Description: "request body is now required", Breaking: true})
+	}
+	oldSchema, newSchema := contentSchema(oldBody.Content), contentSchema(newBody.Content)
+	oldProps, newProps := schemaProperties(oldSchema), schemaProperties(newSchema)
+	oldRequired, newRequired := requiredSet(oldSchema), requiredSet(newSchema)
+	for _, name := range sortedKeys(newProps) {
+		previous, ok := oldProps[name]
+		switch {
+		case !ok && newRequired[name]:
+			changes = append(changes, SpecChange{Description: fmt.Sprintf("required body property %q added", name), Breaking: true})
+		case !ok:
+			changes = append(changes, SpecChange{Description: fmt.Sprintf("optional body property %q added", name)})
+		default:
+			if newRequired[name] && !oldRequired[name] {
+				changes = append(changes, SpecChange{Description: fmt.Sprintf("body property %q is now required", name), Breaking: true})
+			}
+			if oldType, newType := schemaTypeName(previous), schemaTypeName(newProps[name]); oldType != newType {
+				changes = append(changes, SpecChange{
+					Description: fmt.Sprintf("body property %q changed type from %s to %s", name, oldType, newType),
+					Breaking:    true,
+				})
+			}
+		}
+	}
+	for _, name := range sortedKeys(oldProps) {
+		if _, ok := newProps[name]; !ok {
+			changes = append(changes, SpecChange{Description: fmt.Sprintf("body property %q removed", name), Breaking: true})
+		}
+	}
+	return changes
+}
+
+// compareResponses compares the status codes and the top-level properties
+// of the success response. Removing a success code or a property clients
+// may read is breaking.
+func compareResponses(old, updated *openapi3.Responses) []SpecChange {
+	oldCodes, newCodes := responseMap(old), responseMap(updated)
+	var changes []SpecChange
+	for _, code := range sortedKeys(newCodes) {
+		if _, ok := oldCodes[code]; !ok {
+			changes = append(changes, SpecChange{Description: fmt.Sprintf("response %s added", code)})
+		}
+	}
+	for _, code := range sortedKeys(oldCodes) {
+		if _, ok := newCodes[code]; !ok {
+			changes = append(changes, SpecChange{
+				Description: fmt.Sprintf("response %s removed", code),
+				Breaking:    strings.HasPrefix(code, "2"),
+			})
+		}
+	}
+
+	oldProps := schemaProperties(successSchema(oldCodes))
+	newProps := schemaProperties(successSchema(newCodes))
+	for _, name := range sortedKeys(newProps) {
+		previous, ok := oldProps[name]
+		if !ok {
+			changes = append(changes, SpecChange{Description: fmt.Sprintf("response property %q added", name)})
+			continue
+		}
+		if oldType, newType := schemaTypeName(previous), schemaTypeName(newProps[name]); oldType != newType {
+			changes = append(changes, SpecChange{
+				Description: fmt.Sprintf("response property %q changed type from %s to %s", name, oldType, newType),
+				Breaking:    true,
+			})
+		}
+	}
+	for _, name := range sortedKeys(oldProps) {
+		if _, ok := newProps[name]; !ok {
+			changes = append(changes, SpecChange{Description: fmt.Sprintf("response property %q removed", name), Breaking: true})
+		}
+	}
+	return changes
+}
+
+func requestBody(ref *openapi3.RequestBodyRef) *openapi3.RequestBody {
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+func responseMap(responses *openapi3.Responses) map[string]*openapi3.Response {
+	codes := map[string]*openapi3.Response{}
+	if responses == nil {
+		return codes
+	}
+	for code, ref := range responses.Map() {
+		if ref != nil && ref.Value != nil {
+			codes[code] = ref.Value
+		}
+	}
+	return codes
+}
+
+// successSchema returns the schema of the lowest 2xx response
+func successSchema(codes map[string]*openapi3.Response) *openapi3.SchemaRef {
+	for _, code := range sortedKeys(codes) {
+		if strings.HasPrefix(code, "2") {
+			return contentSchema(codes[code].Content)
+		}
+	}
+	return nil
+}
+
+// contentSchema returns the JSON schema of content, or of its first media
+// type when it has no JSON one
+func contentSchema(content openapi3.Content) *openapi3.SchemaRef {
+	if media := content.Get("application/json"); media != nil {
+		return media.Schema
+	}
+	for _, mediaType := range sortedKeys(content) {
+		return content[mediaType].Schema
+	}
+	return nil
+}
+
+func schemaProperties(ref *openapi3.SchemaRef) map[string]*openapi3.SchemaRef {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	return ref.Value.Properties
+}
+
+func requiredSet(ref *openapi3.SchemaRef) map[string]bool {
+	set := map[string]bool{}
+	if ref != nil && ref.Value != nil {
+		for _, name := range ref.Value.Required {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// schemaTypeName describes the type of a schema, with the item type of
+// arrays, e.g. "array of integer"
+func schemaTypeName(ref *openapi3.SchemaRef) string {
+	if ref == nil || ref.Value == nil || ref.Value.Type == nil || len(ref.Value.Type.Slice()) == 0 {
+		return "any"
+	}
+	name := strings.Join(ref.Value.Type.Slice(), "|")
+	if ref.Value.Type.Is("array") {
+		name += " of " + schemaTypeName(ref.Value.Items)
+	}
+	return name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diffSpec parses a specification with the given paths, written as YAML
+// indented by two spaces
+func diffSpec(t *testing.T, paths string) *ParsedSpec {
+	t.Helper()
+	spec, err := NewService().ParseData([]byte("openapi: 3.0.3\ninfo: {title: Items, version: '1'}\npaths:\n"+paths), "items.yaml")
+	require.NoError(t, err)
+	return spec
+}
+
+// itemsPath is the path of the operation the change cases compare
+func itemsPath(operation string) string {
+	return "  /items:\n    post: " + operation + "\n"
+}
+
+func TestCompareOperationChanges(t *testing.T) {
+	const (
+		base        = `{operationId: createItem, responses: {'200': {description: ok}}}`
+		query       = `{operationId: createItem, parameters: [{name: q, in: query, schema: {type: string}}], responses: {'200': {description: ok}}}`
+		required    = `{operationId: createItem, parameters: [{name: q, in: query, required: true, schema: {type: string}}], responses: {'200': {description: ok}}}`
+		integer     = `{operationId: createItem, parameters: [{name: q, in: query, schema: {type: integer}}], responses: {'200': {description: ok}}}`
+		stringList  = `{operationId: createItem, parameters: [{name: q, in: query, schema: {type: array, items: {type: string}}}], responses: {'200': {description: ok}}}`
+		integerList = `{operationId: createItem, parameters: [{name: q, in: query, schema: {type: array, items: {type: integer}}}], responses: {'200': {description: ok}}}`
+
+		body         = `{operationId: createItem, requestBody: {content: {application/json: {schema: {type: object, properties: {name: {type: string}}}}}}, responses: {'200': {description: ok}}}`
+		requiredBody = `{operationId: createItem, requestBody: {required: true, content: {application/json: {schema: {type: object, properties: {name: {type: string}}}}}}, responses: {'200': {description: ok}}}`
+		optionalProp = `{operationId: createItem, requestBody: {content: {application/json: {schema: {type: object, properties: {name: {type: string}, note: {type: string}}}}}}, responses: {'200': {description: ok}}}`
+		requiredProp = `{operationId: createItem, requestBody: {content: {application/json: {schema: {type: object, required: [name], properties: {name: {type: string}}}}}}, responses: {'200': {description: ok}}}`
+		numberProp   = `{operationId: createItem, requestBody: {content: {application/json: {schema: {type: object, properties: {name: {type: number}}}}}}, responses: {'200': {description: ok}}}`
+		newRequired  = `{operationId: createItem, requestBody: {content: {application/json: {schema: {type: object, required: [size], properties: {name: {type: string}, size: {type: integer}}}}}}, responses: {'200': {description: ok}}}`
+
+		result     = `{operationId: createItem, responses: {'200': {description: ok, content: {application/json: {schema: {type: object, properties: {id: {type: string}}}}}}}}`
+		resultMore = `{operationId: createItem, responses: {'200': {description: ok, content: {application/json: {schema: {type: object, properties: {id: {type: string}, created: {type: string}}}}}}}}`
+		resultInt  = `{operationId: createItem, responses: {'200': {description: ok, content: {application/json: {schema: {type: object, properties: {id: {type: integer}}}}}}}}`
+		notFound   = `{operationId: createItem, responses: {'200': {description: ok}, '404': {description: missing}}}`
+		created    = `{operationId: createItem, responses: {'201': {description: created}}}`
+		renamed    = `{operationId: addItem, responses: {'200': {description: ok}}}`
+		deprecated = `{operationId: createItem, deprecated: true, responses: {'200': {description: ok}}}`
+		described  = `{operationId: createItem, summary: Create an item, responses: {'200': {description: ok}}}`
+		pathLevel  = "  /items:\n    parameters: [{name: q, in: query, required: true, schema: {type: string}}]\n    post: " + base + "\n"
+	)
+	tests := []struct {
+		name     string
+		old, new string
+		changes  []SpecChange
+	}{
+		{"unchanged", base, base, nil},
+		{"optional parameter added", base, query, []SpecChange{{`optional query parameter "q" added`, false}}},
+		{"required parameter added", base, required, []SpecChange{{`required query parameter "q" added`, true}}},
+		{"parameter now required", query, required, []SpecChange{{`query parameter "q" is now required`, true}}},
+		{"parameter now optional", required, query, []SpecChange{{`query parameter "q" is now optional`, false}}},
+		{"parameter type changed", query, integer, []SpecChange{{`query parameter "q" changed type from string to integer`, true}}},
+		{"array item type changed", stringList, integerList, []SpecChange{{`query parameter "q" changed type from array of string to array of integer`, true}}},
+		{"parameter removed", query, base, []SpecChange{{`query parameter "q" removed`, true}}},
+		{"operation ID changed", base, renamed, []SpecChange{{`operation ID changed from "createItem" to "addItem"`, true}}},
+		{"deprecated", base, deprecated, []SpecChange{{"operation deprecated", false}}},
+		{"description changed", base, described, []SpecChange{{"description changed", false}}},
+		{"optional body added", base, body, []SpecChange{{"optional request body added", false}}},
+		{"required body added", base, requiredBody, []SpecChange{{"required request body added", true}}},
+		{"body now required", body, requiredBody, []SpecChange{{"request body is now required", true}}},
+		{"body removed", body, base, []SpecChange{{"request body removed", true}}},
+		{"optional property added", body, optionalProp, []SpecChange{{`optional body property "note" added`, false}}},
+		{"required property added", body, newRequired, []SpecChange{{`required body property "size" added`, true}}},
+		{"property now required", body, requiredProp, []SpecChange{{`body property "name" is now required`, true}}},
+		{"property type changed", body, numberProp, []SpecChange{{`body property "name" changed type from string to number`, true}}},
+		{"property removed", optionalProp, body, []SpecChange{{`body property "note" removed`, true}}},
+		{"error response added", base, notFound, []SpecChange{{"response 404 added", false}}},
+		{"error response removed", notFound, base, []SpecChange{{"response 404 removed", false}}},
+		{"success response changed", base, created, []SpecChange{{"response 201 added", false}, {"response 200 removed", true}}},
+		{"response property added", result, resultMore, []SpecChange{{`response property "created" added`, false}}},
+		{"response property removed", resultMore, result, []SpecChange{{`response property "created" removed`, true}}},
+		{"response property type changed", result, resultInt, []SpecChange{{`response property "id" changed type from string to integer`, true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := Compare(diffSpec(t, itemsPath(tt.old)), diffSpec(t, itemsPath(tt.new)))
+			assert.Empty(t, diff.Added)
+			assert.Empty(t, diff.Removed)
+			if tt.changes == nil {
+				assert.True(t, diff.Empty())
+				assert.Empty(t, diff.Breaking())
+				return
+			}
+			require.Len(t, diff.Changed, 1)
+			assert.Equal(t, "POST /items", diff.Changed[0].Operation)
+			assert.Equal(t, tt.changes, diff.Changed[0].Changes)
+
+			var breaking []string
+			for _, change := range tt.changes {
+				if change.Breaking {
+					breaking = append(breaking, "POST /items: "+change.Description)
+				}
+			}
+			assert.Equal(t, breaking, diff.Breaking())
+		})
+	}
+
+	t.Run("path-level parameter", func(t *testing.T) {
+		diff := Compare(diffSpec(t, itemsPath(base)), diffSpec(t, pathLevel))
+		require.Len(t, diff.Changed, 1)
+		assert.Equal(t, []SpecChange{{`required query parameter "q" added`, true}}, diff.Changed[0].Changes)
+	})
+}
+
+func TestCompareOperations(t *testing.T) {
+	old := diffSpec(t, `  /items:
+    get: {operationId: listItems, responses: {'200': {description: ok}}}
+    delete: {operationId: clearItems, responses: {'204': {description: cleared}}}
+`)
+	updated := diffSpec(t, `  /items:
+    get: {operationId: listItems, responses: {'200': {description: ok}}}
+  /items/{id}:
+    get: {operationId: getItem, parameters: [{name: id, in: path, required: true, schema: {type: string}}], responses: {'200': {description: ok}}}
+`)
+	diff := Compare(old, updated)
+	assert.Equal(t, []string{"GET /items/{id}"}, diff.Added)
+	assert.Equal(t, []string{"DELETE /items"}, diff.Removed)
+	assert.Empty(t, diff.Changed)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []string{"DELETE /items: operation removed"}, diff.Breaking(), "added operations are not breaking")
+
+	diff = Compare(updated, updated)
+	assert.True(t, diff.Empty())
+	assert.NotNil(t, diff.Added, "empty lists encode as [] in JSON")
+	assert.NotNil(t, diff.Removed)
+	assert.NotNil(t, diff.Changed)
+}
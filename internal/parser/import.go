@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"MCPWeaver/internal/common"
+)
+
+// maxSpecSize bounds a downloaded specification
+const maxSpecSize = 32 << 20
+
+// IsURL reports whether source is an http or https URL
+func IsURL(source string) bool {
+	u, err := url.Parse(source)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// FetchOptions authenticate and check the download of a specification
+type FetchOptions struct {
+	// Token is sent as a bearer token when set
+	Token string
+	// Username and Password are sent with basic authentication when
+	// Username is set
+	Username string
+	Password string
+	// Header holds extra request headers
+	Header http.Header
+	// Cookies are sent with the request
+	Cookies []*http.Cookie
+	// MaxSize bounds the download, in bytes; 0 allows 32 MiB
+	MaxSize int64
+	// Attempts is how often the download is tried; 0 uses
+	// common.DefaultAttempts
+	Attempts int
+	// SHA256 is the hex checksum the specification must have, checked for
+	// files too, if set
+	SHA256 string
+	// Retry is called before the download is tried again, if set
+	Retry func(attempt int, err error)
+}
+
+// authenticated reports whether any credentials are set
+func (o FetchOptions) authenticated() bool {
+	return o.Token != "" || o.Username != "" || len(o.Header) > 0 || len(o.Cookies) > 0
+}
+
+// Fetch reads a specification from an http or https URL or from a file. It
+// returns the content and the location to parse it with: the URL, or the
+// absolute path of the file. Credentials are only sent to the host of the
+// URL, not to hosts it redirects to.
+func (s *Service) Fetch(ctx context.Context, source string, opts FetchOptions) ([]byte, string, error) {
+	if !IsURL(source) {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, source, common.NewError(common.ErrorTypeParse, "failed to read specification", err).
+				WithFile(source)
+		}
+		if err := common.VerifySHA256(data, opts.SHA256, "specification"); err != nil {
+			return nil, source, err.WithFile(source)
+		}
+		absPath, err := filepath.Abs(source)
+		if err != nil {
+			absPath = source
+		}
+		return data, absPath, nil
+	}
+
+	// Passwords in the URL are kept out of messages
+	display := RedactURL(source)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, display, common.NewError(common.ErrorTypeNetwork, "failed to create request", err).WithFile(display)
+	}
+	for name, values := range opts.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.8")
+	}
+	if opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.Token)
+	} else if opts.Username != "" {
+		req.SetBasicAuth(opts.Username, opts.Password)
+	}
+	for _, cookie := range opts.Cookies {
+		req.AddCookie(cookie)
+	}
+
+	client := &http.Client{CheckRedirect: func(redirect *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		// The client drops Authorization and Cookie on its own
+		if redirect.URL.Host != via[0].URL.Host {
+			for name := range opts.Header {
+				redirect.Header.Del(name)
+			}
+		}
+		return nil
+	}}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = maxSpecSize
+	}
+	resp, data, dlErr := common.Download(ctx, client, req, common.DownloadOptions{
+		Description: "specification",
+		MaxSize:     maxSize,
+		Attempts:    opts.Attempts,
+		SHA256:      opts.SHA256,
+		Retry:       opts.Retry,
+	})
+	if dlErr != nil {
+		return nil, display, dlErr.WithFile(display)
+	}
+	switch {
+	case resp.StatusCode == http.StatusProxyAuthRequired:
+		return nil, display, common.NewError(common.ErrorTypeNetwork, fmt.Sprintf("proxy returned %s", resp.Status), nil).
+			WithFile(display).
+			WithSuggestion(common.ProxyAuthSuggestion)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		suggestion := "The specification requires credentials; pass a token, basic auth, headers or cookies"
+		if opts.authenticated() {
+			suggestion = "Check that the credentials sent for this URL are valid and allow reading the specification"
+		}
+		return nil, display, common.NewError(common.ErrorTypeNetwork, fmt.Sprintf("server returned %s", resp.Status), nil).
+			WithFile(display).
+			WithSuggestion(suggestion)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, display, common.NewError(common.ErrorTypeNetwork, fmt.Sprintf("server returned %s", resp.Status), nil).
+			WithFile(display)
+	}
+	// Relative $ref values resolve against the URL redirected to
+	return data, RedactURL(resp.Request.URL.String()), nil
+}
+
+// RedactURL replaces the password of a URL, if any, with "xxxxx"
+func RedactURL(source string) string {
+	u, err := url.Parse(source)
+	if err != nil {
+		return source
+	}
+	return u.Redacted()
+}
+
+// ParseImport parses specification content or converts a Postman collection,
+// as read by Fetch
+func (s *Service) ParseImport(data []byte, location string) (*ParsedSpec, error) {
+	if IsPostmanCollection(data) {
+		return s.ConvertPostman(data, location)
+	}
+	return s.ParseData(data, location)
+}
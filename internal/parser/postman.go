@@ -0,0 +1,594 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"MCPWeaver/internal/common"
+)
+
+// postmanSchemaPrefix starts the schema URL of Postman collections
+const postmanSchemaPrefix = "https://schema.getpostman.com/json/collection/"
+
+// postmanCollection is the part of a Postman v2.0 or v2.1 collection that
+// describes requests
+type postmanCollection struct {
+	Info struct {
+		Name        string      `json:"name"`
+		Description postmanText `json:"description"`
+		Schema      string      `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable"`
+	Auth     *postmanAuth      `json:"auth"`
+}
+
+// postmanItem is a folder, when it has items, or a request
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"`
+	Request  *postmanRequest   `json:"request"`
+	Response []postmanResponse `json:"response"`
+	Auth     *postmanAuth      `json:"auth"`
+}
+
+type postmanRequest struct {
+	Method      string         `json:"method"`
+	URL         postmanURL     `json:"url"`
+	Header      []postmanValue `json:"header"`
+	Body        *postmanBody   `json:"body"`
+	Description postmanText    `json:"description"`
+	Auth        *postmanAuth   `json:"auth"`
+}
+
+type postmanBody struct {
+	Mode       string         `json:"mode"`
+	Raw        string         `json:"raw"`
+	URLEncoded []postmanValue `json:"urlencoded"`
+	FormData   []postmanValue `json:"formdata"`
+}
+
+type postmanResponse struct {
+	Name string `json:"name"`
+	Code int    `json:"code"`
+	Body string `json:"body"`
+}
+
+// postmanValue is a header, query parameter, form field or path variable
+type postmanValue struct {
+	Key         string      `json:"key"`
+	Value       string      `json:"value"`
+	Type        string      `json:"type"`
+	Description postmanText `json:"description"`
+	Disabled    bool        `json:"disabled"`
+}
+
+type postmanVariable struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+type postmanAuth struct {
+	Type   string         `json:"type"`
+	APIKey []postmanValue `json:"apikey"`
+}
+
+// postmanURL is written either as a string or as its parts
+type postmanURL struct {
+	Raw      string         `json:"raw"`
+	Protocol string         `json:"protocol"`
+	Host     postmanParts   `json:"host"`
+	Path     postmanParts   `json:"path"`
+	Query    []postmanValue `json:"query"`
+	Variable []postmanValue `json:"variable"`
+}
+
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &u.Raw); err == nil {
+		return nil
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+// postmanParts is a host or path, written either joined or as segments
+type postmanParts []string
+
+func (p *postmanParts) UnmarshalJSON(data []byte) error {
+	var joined string
+	if err := json.Unmarshal(data, &joined); err == nil {
+		*p = strings.Split(strings.Trim(joined, "/"), "/")
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(p))
+}
+
+// postmanText is a description, written either as a string or as an object
+// with its content
+type postmanText string
+
+func (t *postmanText) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*t = postmanText(text)
+		return nil
+	}
+	var object struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*t = postmanText(object.Content)
+	return nil
+}
+
+// variablePattern matches {{name}} references to collection variables
+var variablePattern = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// IsPostmanCollection reports whether content is a Postman v2 collection
+func IsPostmanCollection(data []byte) bool {
+	var collection struct {
+		Info struct {
+			Schema string `json:"schema"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return false
+	}
+	return strings.HasPrefix(collection.Info.Schema, postmanSchemaPrefix)
+}
+
+// ConvertPostman converts a Postman v2.0 or v2.1 collection to an OpenAPI 3
+// document. Folders become tags, request names operation IDs, and schemas
+// are inferred from the example bodies and saved responses. The servers are
+// the origins of the request URLs, with collection variables substituted.
+func (s *Service) ConvertPostman(data []byte, location string) (*ParsedSpec, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "failed to parse Postman collection", err).
+			WithFile(location)
+	}
+
+	converter := &postmanConverter{
+		doc: &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info: &openapi3.Info{
+				Title:       collection.Info.Name,
+				Description: string(collection.Info.Description),
+				Version:     "1.0.0",
+			},
+			Paths:      openapi3.NewPaths(),
+			Components: &openapi3.Components{SecuritySchemes: openapi3.SecuritySchemes{}},
+		},
+		variables:    map[string]string{},
+		servers:      map[string]bool{},
+		operationIDs: map[string]bool{},
+		paths:        map[string]string{},
+	}
+	for _, variable := range collection.Variable {
+		converter.variables[variable.Key] = fmt.Sprint(variable.Value)
+	}
+	if name := converter.securityScheme(collection.Auth); name != "" {
+		converter.doc.Security = openapi3.SecurityRequirements{{name: []string{}}}
+	}
+	converter.items(collection.Item, "", nil)
+
+	if len(converter.doc.Paths.Map()) == 0 {
+		return nil, common.NewError(common.ErrorTypeParse, "Postman collection has no requests", nil).
+			WithFile(location)
+	}
+	if len(converter.doc.Components.SecuritySchemes) == 0 {
+		converter.doc.Components = nil
+	}
+	version := strings.TrimSuffix(strings.TrimPrefix(collection.Info.Schema, postmanSchemaPrefix), "/collection.json")
+	return &ParsedSpec{Location: location, OriginalVersion: "postman " + version, Document: converter.doc}, nil
+}
+
+// postmanConverter accumulates the document while walking the collection
+type postmanConverter struct {
+	doc          *openapi3.T
+	variables    map[string]string
+	servers      map[string]bool
+	operationIDs map[string]bool
+	// paths maps the shape of each path, its parameters unnamed, to the path
+	paths map[string]string
+}
+
+// items converts the requests below a folder, tagged with the name of the
+// top-level folder and using the nearest auth set on a folder
+func (c *postmanConverter) items(items []postmanItem, tag string, auth *postmanAuth) {
+	for _, item := range items {
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+		if item.Request == nil {
+			folderTag := tag
+			if folderTag == "" {
+				folderTag = item.Name
+			}
+			c.items(item.Item, folderTag, itemAuth)
+			continue
+		}
+		if item.Request.Auth != nil {
+			itemAuth = item.Request.Auth
+		}
+		c.request(item, tag, itemAuth)
+	}
+}
+
+// request adds the operation for one request
+func (c *postmanConverter) request(item postmanItem, tag string, auth *postmanAuth) {
+	request := item.Request
+	path, pathParams := c.path(&request.URL)
+
+	op := openapi3.NewOperation()
+	op.Summary = item.Name
+	op.Description = string(request.Description)
+	op.OperationID = c.operationID(item.Name)
+	if tag != "" {
+		op.Tags = []string{tag}
+	}
+	op.Parameters = pathParams
+	for _, query := range request.URL.Query {
+		if query.Disabled || query.Key == "" {
+			continue
+		}
+		param := openapi3.NewQueryParameter(query.Key).WithSchema(valueSchema(query.Value))
+		param.Description = string(query.Description)
+		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: param})
+	}
+	for _, header := range request.Header {
+		if header.Disabled || header.Key == "" || reservedHeader(header.Key) {
+			continue
+		}
+		param := openapi3.NewHeaderParameter(header.Key).WithSchema(openapi3.NewStringSchema())
+		param.Description = string(header.Description)
+		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: param})
+	}
+	if body := postmanRequestBody(request.Body); body != nil {
+		op.RequestBody = &openapi3.RequestBodyRef{Value: body}
+	}
+	op.Responses = postmanResponses(item.Response)
+
+	if auth != nil {
+		if name := c.securityScheme(auth); name != "" {
+			op.Security = &openapi3.SecurityRequirements{{name: []string{}}}
+		} else if auth.Type == "noauth" {
+			op.Security = &openapi3.SecurityRequirements{}
+		}
+	}
+
+	pathItem := c.doc.Paths.Value(path)
+	if pathItem == nil {
+		pathItem = &openapi3.PathItem{}
+		c.doc.Paths.Set(path, pathItem)
+	}
+	method := strings.ToUpper(request.Method)
+	if method == "" {
+		method = "GET"
+	}
+	// Requests sharing a method and path are examples of one operation
+	if pathItem.GetOperation(method) == nil {
+		pathItem.SetOperation(method, op)
+	}
+}
+
+// path returns the OpenAPI path of a request URL and its path parameters,
+// recording the URL's origin as a server. A URL written as a string is split
+// into its parts first. Path segments written :name and {{name}} variables
+// without a value become parameters.
+func (c *postmanConverter) path(u *postmanURL) (string, openapi3.Parameters) {
+	host, segments := strings.Join(u.Host, "."), []string(u.Path)
+	if u.Raw != "" && len(u.Host) == 0 {
+		raw := strings.SplitN(u.Raw, "?", 2)[0]
+		scheme, rest, ok := strings.Cut(raw, "://")
+		if !ok {
+			scheme, rest = "", raw
+		}
+		parts := strings.Split(rest, "/")
+		host, segments = parts[0], parts[1:]
+		if scheme != "" {
+			u.Protocol = scheme
+		}
+		if _, query, ok := strings.Cut(u.Raw, "?"); ok && len(u.Query) == 0 {
+			for _, pair := range strings.Split(query, "&") {
+				key, value, _ := strings.Cut(pair, "=")
+				if key, err := url.QueryUnescape(key); err == nil {
+					u.Query = append(u.Query, postmanValue{Key: key, Value: value})
+				}
+			}
+		}
+	}
+	c.addServer(u.Protocol, host)
+
+	descriptions := map[string]postmanValue{}
+	for _, variable := range u.Variable {
+		descriptions[variable.Key] = variable
+	}
+	var params openapi3.Parameters
+	param := func(name string) string {
+		for _, existing := range params {
+			if existing.Value.Name == name {
+				return "{" + name + "}"
+			}
+		}
+		variable := descriptions[name]
+		p := openapi3.NewPathParameter(name).WithSchema(valueSchema(variable.Value))
+		p.Description = string(variable.Description)
+		params = append(params, &openapi3.ParameterRef{Value: p})
+		return "{" + name + "}"
+	}
+	var path strings.Builder
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		path.WriteString("/")
+		if name, ok := strings.CutPrefix(segment, ":"); ok && name != "" {
+			path.WriteString(param(name))
+			continue
+		}
+		// Variables without a value are parameters, keeping the rest of the
+		// segment, as in users-{{id}}
+		path.WriteString(variablePattern.ReplaceAllStringFunc(c.substitute(segment), func(ref string) string {
+			return param(variablePattern.FindStringSubmatch(ref)[1])
+		}))
+	}
+	if path.Len() == 0 {
+		return "/", params
+	}
+	return c.canonicalPath(path.String(), params)
+}
+
+// pathParamPattern matches the {name} parameters of an OpenAPI path
+var pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// canonicalPath renames the parameters of a path to those of the first path
+// seen with the same shape, since OpenAPI treats /users/{id} and
+// /users/{userId} as one conflicting path. Requests of collections often
+// name the same parameter differently.
+func (c *postmanConverter) canonicalPath(path string, params openapi3.Parameters) (string, openapi3.Parameters) {
+	shape := pathParamPattern.ReplaceAllString(path, "{}")
+	canonical, ok := c.paths[shape]
+	if !ok {
+		c.paths[shape] = path
+		return path, params
+	}
+	names := pathParamPattern.FindAllStringSubmatch(canonical, -1)
+	renamed := map[string]string{}
+	for i, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		renamed[match[1]] = names[i][1]
+	}
+	var canonicalParams openapi3.Parameters
+	seen := map[string]bool{}
+	for _, param := range params {
+		name := renamed[param.Value.Name]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		param.Value.Name = name
+		canonicalParams = append(canonicalParams, param)
+	}
+	return canonical, canonicalParams
+}
+
+// addServer records the origin of a request URL, once variables are
+// substituted, in the order origins are first seen
+func (c *postmanConverter) addServer(protocol, host string) {
+	host = c.substitute(host)
+	if host == "" || variablePattern.MatchString(host) {
+		return
+	}
+	origin := host
+	if !strings.Contains(host, "://") {
+		if protocol == "" {
+			protocol = "https"
+		}
+		origin = protocol + "://" + host
+	}
+	origin = strings.TrimRight(origin, "/")
+	if !c.servers[origin] {
+		c.servers[origin] = true
+		c.doc.Servers = append(c.doc.Servers, &openapi3.Server{URL: origin})
+	}
+}
+
+// substitute replaces the collection variables referenced in s
+func (c *postmanConverter) substitute(s string) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := c.variables[variablePattern.FindStringSubmatch(ref)[1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// operationID derives a unique camelCase operation ID from a request name
+func (c *postmanConverter) operationID(name string) string {
+	var id strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			upper = id.Len() > 0
+		case upper:
+			id.WriteRune(unicode.ToUpper(r))
+			upper = false
+		case id.Len() == 0:
+			id.WriteRune(unicode.ToLower(r))
+		default:
+			id.WriteRune(r)
+		}
+	}
+	base := id.String()
+	if base == "" {
+		base = "request"
+	}
+	unique := base
+	for i := 2; c.operationIDs[unique]; i++ {
+		unique = base + strconv.Itoa(i)
+	}
+	c.operationIDs[unique] = true
+	return unique
+}
+
+// securityScheme adds the scheme for a Postman auth type and returns its
+// name, or "" for types without an OpenAPI equivalent
+func (c *postmanConverter) securityScheme(auth *postmanAuth) string {
+	if auth == nil {
+		return ""
+	}
+	var name string
+	var scheme *openapi3.SecurityScheme
+	switch auth.Type {
+	case "bearer", "oauth2":
+		name, scheme = "bearerAuth", &openapi3.SecurityScheme{Type: "http", Scheme: "bearer"}
+	case "basic":
+		name, scheme = "basicAuth", &openapi3.SecurityScheme{Type: "http", Scheme: "basic"}
+	case "apikey":
+		scheme = &openapi3.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}
+		for _, option := range auth.APIKey {
+			switch option.Key {
+			case "key":
+				scheme.Name = option.Value
+			case "in":
+				scheme.In = option.Value
+			}
+		}
+		name = "apiKeyAuth"
+	default:
+		return ""
+	}
+	c.doc.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: scheme}
+	return name
+}
+
+// reservedHeader reports whether a header is described elsewhere in OpenAPI
+func reservedHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "accept", "content-type", "authorization":
+		return true
+	}
+	return false
+}
+
+// postmanRequestBody describes a raw JSON, URL-encoded or multipart request body
+func postmanRequestBody(body *postmanBody) *openapi3.RequestBody {
+	if body == nil {
+		return nil
+	}
+	switch body.Mode {
+	case "raw":
+		var example any
+		if strings.TrimSpace(body.Raw) == "" || json.Unmarshal([]byte(body.Raw), &example) != nil {
+			return nil
+		}
+		schema := exampleSchema(example)
+		schema.Example = example
+		return openapi3.NewRequestBody().WithJSONSchema(schema)
+	case "urlencoded", "formdata":
+		fields := body.URLEncoded
+		contentType := "application/x-www-form-urlencoded"
+		if body.Mode == "formdata" {
+			fields, contentType = body.FormData, "multipart/form-data"
+		}
+		schema := openapi3.NewObjectSchema()
+		for _, field := range fields {
+			if field.Disabled || field.Key == "" {
+				continue
+			}
+			property := openapi3.NewStringSchema()
+			if field.Type == "file" {
+				property.Format = "binary"
+			}
+			property.Description = string(field.Description)
+			schema.WithProperty(field.Key, property)
+		}
+		if len(schema.Properties) == 0 {
+			return nil
+		}
+		return openapi3.NewRequestBody().WithSchema(schema, []string{contentType})
+	}
+	return nil
+}
+
+// postmanResponses describes the saved example responses, or a plain success
+// response when there are none
+func postmanResponses(saved []postmanResponse) *openapi3.Responses {
+	responses := openapi3.NewResponsesWithCapacity(len(saved))
+	for _, example := range saved {
+		code := example.Code
+		if code == 0 {
+			code = 200
+		}
+		status := strconv.Itoa(code)
+		if responses.Value(status) != nil {
+			continue
+		}
+		description := example.Name
+		if description == "" {
+			description = "Response"
+		}
+		response := openapi3.NewResponse().WithDescription(description)
+		var body any
+		if strings.TrimSpace(example.Body) != "" && json.Unmarshal([]byte(example.Body), &body) == nil {
+			response.WithJSONSchema(exampleSchema(body))
+		}
+		responses.Set(status, &openapi3.ResponseRef{Value: response})
+	}
+	if responses.Len() == 0 {
+		responses.Set("200", &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("Successful response")})
+	}
+	return responses
+}
+
+// valueSchema infers the schema of a parameter from its example value
+func valueSchema(value string) *openapi3.Schema {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return openapi3.NewIntegerSchema()
+	}
+	if value == "true" || value == "false" {
+		return openapi3.NewBoolSchema()
+	}
+	return openapi3.NewStringSchema()
+}
+
+// exampleSchema infers a schema from a decoded JSON example
+func exampleSchema(example any) *openapi3.Schema {
+	switch value := example.(type) {
+	case map[string]any:
+		schema := openapi3.NewObjectSchema()
+		for key, property := range value {
+			schema.WithProperty(key, exampleSchema(property))
+		}
+		return schema
+	case []any:
+		schema := openapi3.NewArraySchema()
+		if len(value) > 0 {
+			schema.Items = openapi3.NewSchemaRef("", exampleSchema(value[0]))
+		} else {
+			schema.Items = openapi3.NewSchemaRef("", openapi3.NewSchema())
+		}
+		return schema
+	case float64:
+		if value == float64(int64(value)) {
+			return openapi3.NewIntegerSchema()
+		}
+		return openapi3.NewFloat64Schema()
+	case bool:
+		return openapi3.NewBoolSchema()
+	case string:
+		return openapi3.NewStringSchema()
+	}
+	return openapi3.NewSchema()
+}
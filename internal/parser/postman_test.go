@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	postmanV20 = postmanSchemaPrefix + "v2.0.0/collection.json"
+	postmanV21 = postmanSchemaPrefix + "v2.1.0/collection.json"
+)
+
+// convertPostman converts a collection of the given schema with items and
+// collection-level fields written as JSON
+func convertPostman(t *testing.T, schema, items, extra string) *openapi3.T {
+	t.Helper()
+	collection := `{"info": {"name": "Users", "schema": "` + schema + `"}, "item": ` + items + extra + `}`
+	require.True(t, IsPostmanCollection([]byte(collection)))
+	spec, err := NewService().ConvertPostman([]byte(collection), "users.postman_collection.json")
+	require.NoError(t, err)
+	require.NoError(t, spec.Document.Validate(openapi3.NewLoader().Context), "the converted document is valid OpenAPI")
+	return spec.Document
+}
+
+// operations lists the "METHOD path" of every operation of doc
+func operations(doc *openapi3.T) []string {
+	var ops []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			ops = append(ops, method+" "+path)
+		}
+	}
+	return ops
+}
+
+// pathParams lists the names of the path parameters of an operation
+func pathParams(op *openapi3.Operation) []string {
+	var names []string
+	for _, param := range op.Parameters {
+		if param.Value.In == openapi3.ParameterInPath {
+			names = append(names, param.Value.Name)
+		}
+	}
+	return names
+}
+
+func TestConvertPostmanURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		url    string
+		path   string
+		params []string
+		query  []string
+	}{
+		{"v2.0 string", postmanV20, `"https://api.example.com/users?limit=10"`, "/users", nil, []string{"limit"}},
+		{"v2.1 parts", postmanV21,
+			`{"raw": "https://api.example.com/users/:id", "protocol": "https", "host": ["api", "example", "com"], "path": ["users", ":id"], "query": [{"key": "expand", "value": "true"}, {"key": "off", "disabled": true}]}`,
+			"/users/{id}", []string{"id"}, []string{"expand"}},
+		{"v2.1 joined path", postmanV21, `{"host": "api.example.com", "path": "/users/:id/orders"}`, "/users/{id}/orders", []string{"id"}, nil},
+		{"unresolved variable", postmanV21, `"https://api.example.com/users/{{userId}}"`, "/users/{userId}", []string{"userId"}, nil},
+		{"variable within a segment", postmanV21, `"https://api.example.com/users-{{id}}/profile"`, "/users-{id}/profile", []string{"id"}, nil},
+		{"resolved variable", postmanV21, `"https://api.example.com/{{version}}/users"`, "/v1/users", nil, nil},
+		{"root", postmanV20, `"https://api.example.com"`, "/", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := `[{"name": "Get users", "request": {"method": "GET", "url": ` + tt.url + `}}]`
+			doc := convertPostman(t, tt.schema, items, `, "variable": [{"key": "version", "value": "v1"}]`)
+			item := doc.Paths.Value(tt.path)
+			require.NotNil(t, item, "paths: %v", operations(doc))
+			assert.Equal(t, tt.params, pathParams(item.Get))
+			var query []string
+			for _, param := range item.Get.Parameters {
+				if param.Value.In == openapi3.ParameterInQuery {
+					query = append(query, param.Value.Name)
+				}
+			}
+			assert.Equal(t, tt.query, query)
+			require.Len(t, doc.Servers, 1)
+			assert.Equal(t, "https://api.example.com", doc.Servers[0].URL)
+		})
+	}
+}
+
+func TestConvertPostmanPathParameterNames(t *testing.T) {
+	items := `[
+		{"name": "Get user", "request": {"method": "GET", "url": "{{baseUrl}}/users/:id"}},
+		{"name": "Delete user", "request": {"method": "DELETE", "url": "{{baseUrl}}/users/:userId"}},
+		{"name": "Get order", "request": {"method": "GET", "url": "{{baseUrl}}/users/{{userId}}/orders/:orderId"}},
+		{"name": "Cancel order", "request": {"method": "DELETE", "url": "{{baseUrl}}/users/:id/orders/:order"}}
+	]`
+	doc := convertPostman(t, postmanV21, items, `, "variable": [{"key": "baseUrl", "value": "https://api.example.com/v2"}]`)
+	assert.ElementsMatch(t, []string{"GET /users/{id}", "DELETE /users/{id}", "GET /users/{userId}/orders/{orderId}", "DELETE /users/{userId}/orders/{orderId}"}, operations(doc),
+		"paths of one shape share the parameter names of the first")
+	assert.Equal(t, []string{"id"}, pathParams(doc.Paths.Value("/users/{id}").Delete))
+	assert.Equal(t, []string{"userId", "orderId"}, pathParams(doc.Paths.Value("/users/{userId}/orders/{orderId}").Delete))
+	require.Len(t, doc.Servers, 1)
+	assert.Equal(t, "https://api.example.com/v2", doc.Servers[0].URL, "variables resolve into the servers")
+}
+
+func TestConvertPostmanFolders(t *testing.T) {
+	items := `[
+		{"name": "Admin", "item": [
+			{"name": "Users", "item": [
+				{"name": "List users", "request": {"method": "GET", "url": "https://api.example.com/admin/users"}},
+				{"name": "list-users", "request": {"method": "GET", "url": "https://api.example.com/admin/users/active"}}
+			]}
+		]},
+		{"name": "ping", "request": {"url": "https://status.example.com/ping"}}
+	]`
+	doc := convertPostman(t, postmanV21, items, "")
+
+	list := doc.Paths.Value("/admin/users").Get
+	assert.Equal(t, []string{"Admin"}, list.Tags, "requests are tagged with their top-level folder")
+	assert.Equal(t, "listUsers", list.OperationID)
+	assert.Equal(t, "listUsers2", doc.Paths.Value("/admin/users/active").Get.OperationID)
+	ping := doc.Paths.Value("/ping").Get
+	require.NotNil(t, ping, "requests without a method are GET")
+	assert.Empty(t, ping.Tags)
+	require.Len(t, doc.Servers, 2)
+	assert.Equal(t, "https://status.example.com", doc.Servers[1].URL)
+}
+
+func TestConvertPostmanAuth(t *testing.T) {
+	items := `[
+		{"name": "Keys", "auth": {"type": "apikey", "apikey": [{"key": "key", "value": "X-Key"}, {"key": "in", "value": "query"}]}, "item": [
+			{"name": "List keys", "request": {"method": "GET", "url": "https://api.example.com/keys"}},
+			{"name": "Login", "request": {"method": "POST", "url": "https://api.example.com/keys/login", "auth": {"type": "basic"}}}
+		]},
+		{"name": "Health", "request": {"method": "GET", "url": "https://api.example.com/health", "auth": {"type": "noauth"}}},
+		{"name": "Me", "request": {"method": "GET", "url": "https://api.example.com/me"}}
+	]`
+	doc := convertPostman(t, postmanV21, items, `, "auth": {"type": "bearer"}`)
+
+	require.NotNil(t, doc.Components)
+	schemes := doc.Components.SecuritySchemes
+	assert.Equal(t, &openapi3.SecurityScheme{Type: "http", Scheme: "bearer"}, schemes["bearerAuth"].Value)
+	assert.Equal(t, &openapi3.SecurityScheme{Type: "http", Scheme: "basic"}, schemes["basicAuth"].Value)
+	assert.Equal(t, &openapi3.SecurityScheme{Type: "apiKey", In: "query", Name: "X-Key"}, schemes["apiKeyAuth"].Value)
+	assert.Equal(t, openapi3.SecurityRequirements{{"bearerAuth": []string{}}}, doc.Security)
+
+	assert.Equal(t, &openapi3.SecurityRequirements{{"apiKeyAuth": []string{}}}, doc.Paths.Value("/keys").Get.Security, "folders set the auth of their requests")
+	assert.Equal(t, &openapi3.SecurityRequirements{{"basicAuth": []string{}}}, doc.Paths.Value("/keys/login").Post.Security)
+	assert.Equal(t, &openapi3.SecurityRequirements{}, doc.Paths.Value("/health").Get.Security, "noauth disables security")
+	assert.Nil(t, doc.Paths.Value("/me").Get.Security, "the collection auth applies")
+}
+
+func TestConvertPostmanBodies(t *testing.T) {
+	items := `[
+		{"name": "Create user", "request": {"method": "POST", "url": "https://api.example.com/users",
+			"header": [{"key": "Content-Type", "value": "application/json"}, {"key": "X-Request-Id", "value": "1"}],
+			"body": {"mode": "raw", "raw": "{\"name\": \"Ada\", \"age\": 36, \"score\": 9.5, \"admin\": false, \"tags\": [\"a\"], \"address\": {\"city\": \"London\"}}"}},
+			"response": [{"name": "Created", "code": 201, "body": "{\"id\": 1}"}, {"name": "Again", "code": 201, "body": "{}"}]},
+		{"name": "Upload", "request": {"method": "POST", "url": "https://api.example.com/files",
+			"body": {"mode": "formdata", "formdata": [{"key": "file", "type": "file"}, {"key": "note", "value": "x"}, {"key": "off", "disabled": true}]}}},
+		{"name": "Bad body", "request": {"method": "PUT", "url": "https://api.example.com/users", "body": {"mode": "raw", "raw": "not json"}}}
+	]`
+	doc := convertPostman(t, postmanV21, items, "")
+
+	create := doc.Paths.Value("/users").Post
+	schema := create.RequestBody.Value.Content.Get("application/json").Schema.Value
+	assert.Equal(t, openapi3.TypeObject, schema.Type.Slice()[0])
+	types := map[string]string{}
+	for name, property := range schema.Properties {
+		types[name] = property.Value.Type.Slice()[0]
+	}
+	assert.Equal(t, map[string]string{"name": "string", "age": "integer", "score": "number", "admin": "boolean", "tags": "array", "address": "object"}, types)
+	assert.Equal(t, "string", schema.Properties["tags"].Value.Items.Value.Type.Slice()[0])
+	assert.Equal(t, "string", schema.Properties["address"].Value.Properties["city"].Value.Type.Slice()[0])
+
+	var headers []string
+	for _, param := range create.Parameters {
+		headers = append(headers, param.Value.Name)
+	}
+	assert.Equal(t, []string{"X-Request-Id"}, headers, "Content-Type is described by the body")
+	created := create.Responses.Value("201")
+	require.NotNil(t, created)
+	assert.Equal(t, "Created", *created.Value.Description, "the first example of a status describes it")
+	assert.Equal(t, 1, create.Responses.Len())
+
+	upload := doc.Paths.Value("/files").Post.RequestBody.Value.Content.Get("multipart/form-data").Schema.Value
+	assert.Len(t, upload.Properties, 2)
+	assert.Equal(t, "binary", upload.Properties["file"].Value.Format)
+
+	bad := doc.Paths.Value("/users").Put
+	assert.Nil(t, bad.RequestBody, "bodies that are not JSON are left out")
+	assert.NotNil(t, bad.Responses.Value("200"), "requests without examples succeed with 200")
+}
+
+func TestConvertPostmanErrors(t *testing.T) {
+	_, err := NewService().ConvertPostman([]byte(`{"info": {"schema": "`+postmanV21+`"}, "item": []}`), "empty.json")
+	assert.ErrorContains(t, err, "Postman collection has no requests")
+	_, err = NewService().ConvertPostman([]byte(`{"info": `), "broken.json")
+	assert.ErrorContains(t, err, "failed to parse Postman collection")
+	assert.False(t, IsPostmanCollection([]byte(`{"openapi": "3.0.3"}`)))
+}
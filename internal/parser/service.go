@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+
+	"MCPWeaver/internal/common"
+)
+
+// Service parses and validates OpenAPI specifications
+type Service struct{}
+
+// NewService creates a new parser service
+func NewService() *Service {
+	return &Service{}
+}
+
+// ParseFile loads an OpenAPI 2.0 or 3.x specification from disk
+func (s *Service) ParseFile(path string) (*ParsedSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "failed to read specification", err).
+			WithFile(path)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	return s.ParseData(data, absPath)
+}
+
+// specHeader holds the version fields that identify a specification
+type specHeader struct {
+	Swagger string `yaml:"swagger"`
+	OpenAPI string `yaml:"openapi"`
+}
+
+// IsSpecification reports whether content is YAML or JSON declaring an
+// OpenAPI or Swagger version, without parsing the rest of it
+func IsSpecification(data []byte) bool {
+	var header specHeader
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return false
+	}
+	return header.Swagger != "" || header.OpenAPI != ""
+}
+
+// ParseData parses specification content. The location is used to resolve
+// relative $ref values and in error messages.
+func (s *Service) ParseData(data []byte, location string) (*ParsedSpec, error) {
+	var header specHeader
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "specification is not valid YAML or JSON", err).
+			WithFile(location).
+			WithLine(yamlErrorLine(err))
+	}
+
+	switch {
+	case header.Swagger != "":
+		doc, err := s.parseSwagger(data, location)
+		if err != nil {
+			return nil, err
+		}
+		return &ParsedSpec{Location: location, OriginalVersion: header.Swagger, Document: doc}, nil
+	case header.OpenAPI != "":
+		doc, err := s.parseOpenAPI3(data, location)
+		if err != nil {
+			return nil, err
+		}
+		return &ParsedSpec{Location: location, OriginalVersion: header.OpenAPI, Document: doc}, nil
+	default:
+		return nil, common.NewError(common.ErrorTypeParse, "missing 'openapi' or 'swagger' version field", nil).
+			WithFile(location).
+			WithSuggestion("Add 'openapi: 3.0.0' (or 'swagger: \"2.0\"') at the top of the document")
+	}
+}
+
+// Validate checks the specification against the OpenAPI schema
+func (s *Service) Validate(ctx context.Context, spec *ParsedSpec) error {
+	if err := spec.Document.Validate(ctx); err != nil {
+		return common.NewError(common.ErrorTypeValidation, "invalid OpenAPI specification", err).
+			WithFile(spec.Location)
+	}
+	return nil
+}
+
+func (s *Service) parseOpenAPI3(data []byte, location string) (*openapi3.T, error) {
+	loader := newLoader()
+	doc, err := loader.LoadFromDataWithPath(data, locationURL(location))
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "failed to parse OpenAPI 3 document", err).
+			WithFile(location)
+	}
+	return doc, nil
+}
+
+func (s *Service) parseSwagger(data []byte, location string) (*openapi3.T, error) {
+	// openapi2.T only understands JSON, so YAML documents are converted first
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "failed to read Swagger document", err).
+			WithFile(location)
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "failed to convert Swagger document to JSON", err).
+			WithFile(location)
+	}
+
+	var doc2 openapi2.T
+	if err := json.Unmarshal(jsonData, &doc2); err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "failed to parse Swagger 2.0 document", err).
+			WithFile(location)
+	}
+
+	doc, err := openapi2conv.ToV3WithLoader(&doc2, newLoader(), locationURL(location))
+	if err != nil {
+		return nil, common.NewError(common.ErrorTypeParse, "failed to convert Swagger 2.0 document to OpenAPI 3", err).
+			WithFile(location)
+	}
+	return doc, nil
+}
+
+func newLoader() *openapi3.Loader {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	return loader
+}
+
+func locationURL(location string) *url.URL {
+	if u, err := url.Parse(location); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		return u
+	}
+	return &url.URL{Path: filepath.ToSlash(location)}
+}
+
+// yamlErrorLine extracts the line number from a yaml.v3 error, if present
+func yamlErrorLine(err error) int {
+	if typeErr, ok := err.(*yaml.TypeError); ok && len(typeErr.Errors) > 0 {
+		var line int
+		if _, scanErr := fmt.Sscanf(typeErr.Errors[0], "line %d:", &line); scanErr == nil {
+			return line
+		}
+	}
+	var line int
+	if _, scanErr := fmt.Sscanf(err.Error(), "yaml: line %d:", &line); scanErr == nil {
+		return line
+	}
+	return 0
+}
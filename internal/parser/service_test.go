@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/common"
+)
+
+const openAPISpec = `openapi: 3.0.3
+info: {title: Users API, version: 1.0.0}
+paths:
+  /users/{id}:
+    parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+    delete: {operationId: deleteUser, responses: {'204': {description: deleted}}}
+    get: {operationId: getUser, responses: {'200': {description: ok}}}
+  /users:
+    post: {operationId: createUser, responses: {'201': {description: created}}}
+    get: {operationId: listUsers, responses: {'200': {description: ok}}}
+`
+
+const swaggerSpec = `swagger: "2.0"
+info: {title: Pets, version: "2"}
+host: pets.example.com
+basePath: /v1
+schemes: [https]
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - {name: limit, in: query, type: integer}
+      responses:
+        "200": {description: ok, schema: {type: array, items: {type: string}}}
+`
+
+// parseError returns the pipeline error of parsing data
+func parseError(t *testing.T, data string) *common.Error {
+	t.Helper()
+	_, err := NewService().ParseData([]byte(data), "spec.yaml")
+	var pipelineErr *common.Error
+	require.ErrorAs(t, err, &pipelineErr)
+	assert.Equal(t, common.ErrorTypeParse, pipelineErr.Type)
+	assert.Equal(t, "spec.yaml", pipelineErr.File)
+	return pipelineErr
+}
+
+func TestParseData(t *testing.T) {
+	spec, err := NewService().ParseData([]byte(openAPISpec), "spec.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.3", spec.OriginalVersion)
+	assert.Equal(t, "Users API", spec.Title())
+	require.NoError(t, NewService().Validate(context.Background(), spec))
+
+	var ops []string
+	for _, op := range spec.Operations() {
+		ops = append(ops, op.Method+" "+op.Path+" "+op.Operation.OperationID)
+	}
+	assert.Equal(t, []string{
+		"GET /users listUsers",
+		"POST /users createUser",
+		"GET /users/{id} getUser",
+		"DELETE /users/{id} deleteUser",
+	}, ops, "operations are sorted by path and then by method")
+
+	json := `{"openapi": "3.1.0", "info": {"title": "JSON", "version": "1"}, "paths": {}}`
+	spec, err = NewService().ParseData([]byte(json), "spec.json")
+	require.NoError(t, err)
+	assert.Equal(t, "3.1.0", spec.OriginalVersion)
+	assert.Empty(t, spec.Operations())
+}
+
+func TestParseSwagger(t *testing.T) {
+	spec, err := NewService().ParseData([]byte(swaggerSpec), "pets.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0", spec.OriginalVersion)
+	doc := spec.Document
+	require.Len(t, doc.Servers, 1)
+	assert.Equal(t, "https://pets.example.com/v1", doc.Servers[0].URL)
+	op := doc.Paths.Find("/pets").Get
+	require.NotNil(t, op)
+	assert.Equal(t, "listPets", op.OperationID)
+	assert.Equal(t, "limit", op.Parameters[0].Value.Name)
+	assert.True(t, op.Parameters[0].Value.Schema.Value.Type.Is("integer"))
+	require.NoError(t, NewService().Validate(context.Background(), spec))
+}
+
+func TestParseErrors(t *testing.T) {
+	err := parseError(t, "info: {title: x}\npaths: {}\n")
+	assert.Equal(t, "missing 'openapi' or 'swagger' version field", err.Message)
+	assert.Contains(t, err.Suggestion, "openapi: 3.0.0")
+
+	err = parseError(t, "openapi: 3.0.3\ninfo: [unclosed\n")
+	assert.Equal(t, "specification is not valid YAML or JSON", err.Message)
+	assert.Positive(t, err.Line)
+
+	err = parseError(t, "info: {title: x}\nopenapi: [3]\n")
+	assert.Equal(t, "specification is not valid YAML or JSON", err.Message)
+	assert.Equal(t, 2, err.Line, "the line comes from the YAML error")
+
+	err = parseError(t, "openapi: 3.0.3\ninfo: {title: x, version: '1'}\npaths:\n  /x:\n    get: {responses: {'200': {$ref: '#/components/responses/Missing'}}}\n")
+	assert.Equal(t, "failed to parse OpenAPI 3 document", err.Message)
+
+	_, parseErr := NewService().ParseFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, parseErr, "failed to read specification")
+}
+
+func TestParseFileRelativeRefs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "schemas"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "user.yaml"), []byte("type: object\nproperties:\n  name: {type: string}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.yaml"), []byte(`openapi: 3.0.3
+info: {title: Users, version: '1'}
+paths:
+  /users:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'schemas/user.yaml'}
+`), 0644))
+
+	spec, err := NewService().ParseFile(filepath.Join(dir, "api.yaml"))
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(spec.Location))
+	schema := spec.Document.Paths.Find("/users").Get.Responses.Value("200").Value.Content.Get("application/json").Schema.Value
+	require.NotNil(t, schema, "the reference is resolved against the file")
+	assert.Contains(t, schema.Properties, "name")
+}
+
+func TestValidate(t *testing.T) {
+	spec, err := NewService().ParseData([]byte("openapi: 3.0.3\ninfo: {title: x}\npaths: {}\n"), "spec.yaml")
+	require.NoError(t, err)
+	err = NewService().Validate(context.Background(), spec)
+	var pipelineErr *common.Error
+	require.ErrorAs(t, err, &pipelineErr)
+	assert.Equal(t, common.ErrorTypeValidation, pipelineErr.Type)
+	assert.Contains(t, pipelineErr.Error(), "version")
+}
+
+func TestIsSpecification(t *testing.T) {
+	assert.True(t, IsSpecification([]byte(openAPISpec)))
+	assert.True(t, IsSpecification([]byte(swaggerSpec)))
+	assert.True(t, IsSpecification([]byte(`{"openapi": "3.0.0"}`)))
+	assert.False(t, IsSpecification([]byte("name: a compose file\n")))
+	assert.False(t, IsSpecification([]byte("[not: yaml")))
+}
+
+func TestParsedSpecWithoutDocument(t *testing.T) {
+	spec := &ParsedSpec{}
+	assert.Empty(t, spec.Title())
+	assert.Nil(t, spec.Operations())
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ParsedSpec is an OpenAPI specification normalized to OpenAPI 3
+type ParsedSpec struct {
+	// Location is the file path or URL the specification was loaded from
+	Location string
+	// OriginalVersion is the version declared by the source document
+	// ("2.0" for Swagger documents)
+	OriginalVersion string
+	// Document is the normalized OpenAPI 3 document
+	Document *openapi3.T
+}
+
+// Title returns the API title declared in the specification
+func (s *ParsedSpec) Title() string {
+	if s.Document == nil || s.Document.Info == nil {
+		return ""
+	}
+	return s.Document.Info.Title
+}
+
+// Operation is a single path/method combination in the specification
+type Operation struct {
+	Method    string
+	Path      string
+	Operation *openapi3.Operation
+	PathItem  *openapi3.PathItem
+}
+
+// Operations returns every operation in the specification in a stable order
+func (s *ParsedSpec) Operations() []Operation {
+	if s.Document == nil || s.Document.Paths == nil {
+		return nil
+	}
+
+	pathItems := s.Document.Paths.Map()
+	paths := make([]string, 0, len(pathItems))
+	for path := range pathItems {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var operations []Operation
+	for _, path := range paths {
+		item := pathItems[path]
+		if item == nil {
+			continue
+		}
+		for _, method := range httpMethods {
+			op := item.GetOperation(method)
+			if op == nil {
+				continue
+			}
+			operations = append(operations, Operation{
+				Method:    method,
+				Path:      path,
+				Operation: op,
+				PathItem:  item,
+			})
+		}
+	}
+	return operations
+}
+
+// httpMethods lists the methods considered for tool generation, in output order
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
@@ -0,0 +1,210 @@
+package transformer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxSchemaDepth bounds schema inlining so recursive schemas terminate
+const maxSchemaDepth = 8
+
+// schemaToJSON converts an OpenAPI schema into a self-contained JSON Schema
+// with all references inlined
+func schemaToJSON(ref *openapi3.SchemaRef) map[string]interface{} {
+	return convertSchema(ref, 0, map[*openapi3.Schema]bool{})
+}
+
+func convertSchema(ref *openapi3.SchemaRef, depth int, visiting map[*openapi3.Schema]bool) map[string]interface{} {
+	if ref == nil || ref.Value == nil {
+		return map[string]interface{}{}
+	}
+	schema := ref.Value
+	if depth >= maxSchemaDepth || visiting[schema] {
+		// Recursive or deeply nested schemas are simplified to a generic object
+		return map[string]interface{}{"type": "object"}
+	}
+	visiting[schema] = true
+	defer delete(visiting, schema)
+
+	out := map[string]interface{}{}
+	if schema.Type != nil && len(*schema.Type) > 0 {
+		types := schema.Type.Slice()
+		if schema.Nullable && !schema.Type.Is("null") {
+			types = append(types, "null")
+		}
+		if len(types) == 1 {
+			out["type"] = types[0]
+		} else {
+			out["type"] = types
+		}
+	}
+	if schema.Format != "" {
+		out["format"] = schema.Format
+	}
+	if schema.Description != "" {
+		out["description"] = schema.Description
+	}
+	if len(schema.Enum) > 0 {
+		enum := schema.Enum
+		if schema.Nullable && !containsNil(enum) {
+			// null must pass the enum as well as the type
+			enum = append(append([]interface{}{}, enum...), nil)
+		}
+		out["enum"] = enum
+	}
+	if schema.Default != nil {
+		out["default"] = schema.Default
+	}
+	if schema.Pattern != "" {
+		out["pattern"] = schema.Pattern
+	}
+	if schema.Min != nil {
+		if schema.ExclusiveMin {
+			out["exclusiveMinimum"] = *schema.Min
+		} else {
+			out["minimum"] = *schema.Min
+		}
+	}
+	if schema.Max != nil {
+		if schema.ExclusiveMax {
+			out["exclusiveMaximum"] = *schema.Max
+		} else {
+			out["maximum"] = *schema.Max
+		}
+	}
+	if schema.MinLength > 0 {
+		out["minLength"] = schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		out["maxLength"] = *schema.MaxLength
+	}
+	if schema.MinItems > 0 {
+		out["minItems"] = schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		out["maxItems"] = *schema.MaxItems
+	}
+	if schema.Items != nil {
+		out["items"] = convertSchema(schema.Items, depth+1, visiting)
+	}
+	if len(schema.Properties) > 0 {
+		properties := map[string]interface{}{}
+		for name, prop := range schema.Properties {
+			properties[name] = convertSchema(prop, depth+1, visiting)
+		}
+		out["properties"] = properties
+	}
+	if len(schema.Required) > 0 {
+		out["required"] = schema.Required
+	}
+	if schema.AdditionalProperties.Schema != nil {
+		out["additionalProperties"] = convertSchema(schema.AdditionalProperties.Schema, depth+1, visiting)
+	} else if schema.AdditionalProperties.Has != nil {
+		out["additionalProperties"] = *schema.AdditionalProperties.Has
+	}
+	for keyword, refs := range map[string]openapi3.SchemaRefs{
+		"oneOf": schema.OneOf,
+		"anyOf": schema.AnyOf,
+		"allOf": schema.AllOf,
+	} {
+		if len(refs) == 0 {
+			continue
+		}
+		variants := make([]interface{}, 0, len(refs))
+		for _, variant := range refs {
+			variants = append(variants, convertSchema(variant, depth+1, visiting))
+		}
+		out[keyword] = variants
+	}
+	return out
+}
+
+// containsNil reports whether values has a nil (JSON null) element
+func containsNil(values []interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaType returns the primary JSON type of a schema
+func schemaType(ref *openapi3.SchemaRef) string {
+	if ref == nil || ref.Value == nil || ref.Value.Type == nil {
+		return "string"
+	}
+	for _, t := range ref.Value.Type.Slice() {
+		if t != "null" {
+			return t
+		}
+	}
+	return "string"
+}
+
+// toolName derives a snake_case tool name from the operation ID or, when
+// absent, from the method and path
+func toolName(method, path, operationID string) string {
+	if operationID != "" {
+		return toSnakeCase(operationID)
+	}
+
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = "by_" + strings.Trim(segment, "{}")
+		}
+		parts = append(parts, segment)
+	}
+	return toSnakeCase(strings.Join(parts, "_"))
+}
+
+// toSnakeCase converts camelCase, kebab-case and other separators to
+// snake_case containing only [a-z0-9_]
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	// Collapse repeated separators and trim the ends
+	result := b.String()
+	for strings.Contains(result, "__") {
+		result = strings.ReplaceAll(result, "__", "_")
+	}
+	result = strings.Trim(result, "_")
+	if result == "" {
+		return "tool"
+	}
+	if unicode.IsDigit(rune(result[0])) {
+		result = "_" + result
+	}
+	return result
+}
+
+// slugify converts a title into a lowercase, dash-separated name
+func slugify(s string) string {
+	return strings.ReplaceAll(toSnakeCase(s), "_", "-")
+}
+
+// cleanDescription collapses whitespace so descriptions fit on one line
+func cleanDescription(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
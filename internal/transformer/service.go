@@ -0,0 +1,356 @@
+package transformer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/parser"
+)
+
+// Service maps parsed OpenAPI specifications to the MCP server model
+type Service struct {
+	filter Filter
+}
+
+// NewService creates a new transformer service
+func NewService() *Service {
+	return &Service{}
+}
+
+// WithFilter maps only the operations filter selects
+func (s *Service) WithFilter(filter Filter) *Service {
+	s.filter = filter
+	return s
+}
+
+// Transform converts a parsed specification into an MCP server definition
+func (s *Service) Transform(spec *parser.ParsedSpec) (*MCPServer, error) {
+	doc := spec.Document
+	if doc == nil || doc.Info == nil {
+		return nil, common.NewError(common.ErrorTypeTransformation, "specification has no info section", nil).
+			WithFile(spec.Location)
+	}
+
+	name := slugify(doc.Info.Title)
+	if name == "tool" {
+		name = "mcp-server"
+	}
+
+	server := &MCPServer{
+		Name:        name,
+		Title:       doc.Info.Title,
+		Description: strings.TrimSpace(doc.Info.Description),
+		Version:     doc.Info.Version,
+		BaseURL:     baseURL(doc),
+		EnvPrefix:   strings.ToUpper(strings.ReplaceAll(name, "-", "_")),
+	}
+	server.Auth = mapAuth(doc, server.EnvPrefix)
+
+	usedNames := map[string]bool{}
+	for _, op := range spec.Operations() {
+		if !s.filter.Selects(op.Operation.Tags) {
+			continue
+		}
+		if op.Operation.Deprecated {
+			server.Warnings = append(server.Warnings,
+				fmt.Sprintf("Endpoint %s %s marked as deprecated - skipping", op.Method, op.Path))
+			continue
+		}
+
+		tool, warnings := mapOperation(op)
+		server.Warnings = append(server.Warnings, warnings...)
+
+		// Operation IDs are optional, so generated names may collide
+		base := tool.Name
+		for i := 2; usedNames[tool.Name]; i++ {
+			tool.Name = fmt.Sprintf("%s_%d", base, i)
+		}
+		usedNames[tool.Name] = true
+
+		server.Tools = append(server.Tools, tool)
+	}
+
+	if len(server.Tools) == 0 {
+		suggestion := "Add at least one non-deprecated operation under 'paths'"
+		if !s.filter.Empty() {
+			suggestion = "Check the included and excluded tags against the tags of the operations"
+		}
+		return nil, common.NewError(common.ErrorTypeTransformation, "specification contains no usable operations", nil).
+			WithFile(spec.Location).
+			WithSuggestion(suggestion)
+	}
+	return server, nil
+}
+
+func mapOperation(op parser.Operation) (MCPTool, []string) {
+	var warnings []string
+
+	tool := MCPTool{
+		Name:        toolName(op.Method, op.Path, op.Operation.OperationID),
+		Description: toolDescription(op),
+		HTTPConfig: HTTPOperation{
+			Method:      op.Method,
+			Path:        op.Path,
+			OperationID: op.Operation.OperationID,
+		},
+	}
+
+	// Operation-level parameters override path-level ones with the same name and location
+	params := map[string]*openapi3.Parameter{}
+	var order []string
+	for _, list := range []openapi3.Parameters{op.PathItem.Parameters, op.Operation.Parameters} {
+		for _, ref := range list {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			key := ref.Value.In + ":" + ref.Value.Name
+			if _, exists := params[key]; !exists {
+				order = append(order, key)
+			}
+			params[key] = ref.Value
+		}
+	}
+
+	usedNames := map[string]bool{}
+	for _, key := range order {
+		param := params[key]
+		if param.In == openapi3.ParameterInCookie {
+			warnings = append(warnings, fmt.Sprintf("Cookie parameter %q in %s %s is not supported - skipping",
+				param.Name, op.Method, op.Path))
+			continue
+		}
+
+		argName := toSnakeCase(param.Name)
+		if usedNames[argName] {
+			argName = param.In + "_" + argName
+		}
+		usedNames[argName] = true
+
+		p := Parameter{
+			Name:         argName,
+			OriginalName: param.Name,
+			In:           param.In,
+			Type:         schemaType(param.Schema),
+			Description:  cleanDescription(param.Description),
+			Required:     param.Required || param.In == openapi3.ParameterInPath,
+			Example:      param.Example,
+			Schema:       schemaToJSON(param.Schema),
+		}
+		if param.Schema != nil && param.Schema.Value != nil {
+			p.Format = param.Schema.Value.Format
+			p.Enum = param.Schema.Value.Enum
+			p.Default = param.Schema.Value.Default
+			if p.Example == nil {
+				p.Example = param.Schema.Value.Example
+			}
+		}
+		tool.Parameters = append(tool.Parameters, p)
+	}
+
+	if body := op.Operation.RequestBody; body != nil && body.Value != nil {
+		contentType, media := pickMediaType(body.Value.Content)
+		if media != nil {
+			tool.HTTPConfig.ContentType = contentType
+			p := Parameter{
+				Name:         "body",
+				OriginalName: "body",
+				In:           "body",
+				Type:         schemaType(media.Schema),
+				Description:  cleanDescription(body.Value.Description),
+				Required:     body.Value.Required,
+				Example:      media.Example,
+				Schema:       schemaToJSON(media.Schema),
+			}
+			if p.Description == "" {
+				p.Description = "Request body"
+			}
+			if p.Example == nil && media.Schema != nil && media.Schema.Value != nil {
+				p.Example = media.Schema.Value.Example
+			}
+			if usedNames["body"] {
+				p.Name = "request_body"
+			}
+			tool.Parameters = append(tool.Parameters, p)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Request body of %s %s has no supported media type - skipping body",
+				op.Method, op.Path))
+		}
+	}
+
+	tool.InputSchema = inputSchema(tool.Parameters)
+	tool.ResponseSchema = responseSchema(op.Operation)
+	return tool, warnings
+}
+
+// responseSchema returns the JSON schema of the first documented 2xx response,
+// falling back to the default response
+func responseSchema(op *openapi3.Operation) map[string]interface{} {
+	if op.Responses == nil {
+		return nil
+	}
+
+	responses := op.Responses.Map()
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	codes = append(codes, "default")
+
+	for _, code := range codes {
+		ref := responses[code]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		for contentType, media := range ref.Value.Content {
+			if media == nil || media.Schema == nil {
+				continue
+			}
+			if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+				return schemaToJSON(media.Schema)
+			}
+		}
+		// Only the first documented success response is considered
+		return nil
+	}
+	return nil
+}
+
+// inputSchema builds the JSON Schema object describing all tool arguments
+func inputSchema(params []Parameter) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for _, p := range params {
+		schema := map[string]interface{}{}
+		for k, v := range p.Schema {
+			schema[k] = v
+		}
+		if _, ok := schema["type"]; !ok && p.In != "body" {
+			schema["type"] = p.Type
+		}
+		if p.Description != "" {
+			schema["description"] = p.Description
+		}
+		properties[p.Name] = schema
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+
+	out := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+// supportedMediaTypes lists request body encodings in order of preference
+var supportedMediaTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+}
+
+func pickMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	for _, contentType := range supportedMediaTypes {
+		if media := content.Get(contentType); media != nil {
+			return contentType, media
+		}
+	}
+	// Fall back to any JSON-like media type such as application/vnd.api+json
+	keys := make([]string, 0, len(content))
+	for contentType := range content {
+		keys = append(keys, contentType)
+	}
+	sort.Strings(keys)
+	for _, contentType := range keys {
+		if strings.HasSuffix(contentType, "+json") {
+			return contentType, content[contentType]
+		}
+	}
+	return "", nil
+}
+
+func toolDescription(op parser.Operation) string {
+	summary := strings.TrimSpace(op.Operation.Summary)
+	description := strings.TrimSpace(op.Operation.Description)
+	switch {
+	case summary != "" && description != "" && summary != description:
+		return summary + "\n\n" + description
+	case summary != "":
+		return summary
+	case description != "":
+		return description
+	default:
+		return fmt.Sprintf("%s %s", op.Method, op.Path)
+	}
+}
+
+// baseURL returns the first server URL with variables replaced by their defaults
+func baseURL(doc *openapi3.T) string {
+	if len(doc.Servers) == 0 || doc.Servers[0] == nil {
+		return "http://localhost"
+	}
+	server := doc.Servers[0]
+	u := server.URL
+	for name, variable := range server.Variables {
+		if variable != nil {
+			u = strings.ReplaceAll(u, "{"+name+"}", variable.Default)
+		}
+	}
+	return strings.TrimRight(u, "/")
+}
+
+func mapAuth(doc *openapi3.T, envPrefix string) AuthConfig {
+	var config AuthConfig
+	if doc.Components == nil {
+		return config
+	}
+
+	names := make([]string, 0, len(doc.Components.SecuritySchemes))
+	for name := range doc.Components.SecuritySchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		scheme := ref.Value
+		envBase := envPrefix + "_" + strings.ToUpper(toSnakeCase(name))
+
+		mapped := AuthScheme{
+			Name:        name,
+			Description: cleanDescription(scheme.Description),
+		}
+		switch {
+		case scheme.Type == "apiKey":
+			mapped.Type = AuthTypeAPIKey
+			mapped.In = scheme.In
+			mapped.ParamName = scheme.Name
+			mapped.EnvVar = envBase
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+			mapped.Type = AuthTypeBasic
+			mapped.UsernameEnvVar = envBase + "_USERNAME"
+			mapped.PasswordEnvVar = envBase + "_PASSWORD"
+		case scheme.Type == "http" || scheme.Type == "oauth2" || scheme.Type == "openIdConnect":
+			// OAuth flows are not run by the server; a pre-issued token is expected
+			mapped.Type = AuthTypeBearer
+			mapped.EnvVar = envBase + "_TOKEN"
+		default:
+			continue
+		}
+		config.Schemes = append(config.Schemes, mapped)
+	}
+	return config
+}
@@ -0,0 +1,241 @@
+package transformer
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"MCPWeaver/internal/common"
+	"MCPWeaver/internal/parser"
+)
+
+// taggedSpec has operations tagged users, admin, both and neither
+const taggedSpec = `openapi: 3.0.3
+info: {title: Store API, version: '1'}
+paths:
+  /users:
+    get: {operationId: listUsers, tags: [Users], responses: {'200': {description: ok}}}
+  /admin/users:
+    delete: {operationId: purgeUsers, tags: [users, admin], responses: {'204': {description: purged}}}
+  /settings:
+    get: {operationId: getSettings, tags: [admin], responses: {'200': {description: ok}}}
+  /health:
+    get: {operationId: health, responses: {'200': {description: ok}}}
+`
+
+// transformSpec maps a specification written as YAML
+func transformSpec(t *testing.T, service *Service, spec string) (*MCPServer, error) {
+	t.Helper()
+	parsed, err := parser.NewService().ParseData([]byte(spec), "spec.yaml")
+	require.NoError(t, err)
+	return service.Transform(parsed)
+}
+
+func TestTransformFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		tools  []string
+	}{
+		{"no filter", Filter{}, []string{"list_users", "purge_users", "get_settings", "health"}},
+		{"include", Filter{IncludeTags: []string{"users"}}, []string{"list_users", "purge_users"}},
+		{"include several", Filter{IncludeTags: []string{"USERS", "admin"}}, []string{"list_users", "purge_users", "get_settings"}},
+		{"exclude", Filter{ExcludeTags: []string{"admin"}}, []string{"list_users", "health"}},
+		{"include and exclude", Filter{IncludeTags: []string{"users"}, ExcludeTags: []string{"admin"}}, []string{"list_users"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := transformSpec(t, NewService().WithFilter(tt.filter), taggedSpec)
+			require.NoError(t, err)
+			var names []string
+			for _, tool := range server.Tools {
+				names = append(names, tool.Name)
+			}
+			assert.ElementsMatch(t, tt.tools, names)
+		})
+	}
+
+	_, err := transformSpec(t, NewService().WithFilter(Filter{IncludeTags: []string{"billing"}}), taggedSpec)
+	var pipelineErr *common.Error
+	require.ErrorAs(t, err, &pipelineErr)
+	assert.Equal(t, "specification contains no usable operations", pipelineErr.Message)
+	assert.Contains(t, pipelineErr.Suggestion, "tags", "an empty selection points at the filter")
+}
+
+// usersSpec exercises the parts of an operation mapped to a tool
+const usersSpec = `openapi: 3.0.3
+info:
+  title: User Management API
+  version: 2.1.0
+  description: "  Manages users.  "
+servers:
+  - url: https://{region}.example.com/v1/
+    variables:
+      region: {default: eu}
+components:
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+    basicAuth: {type: http, scheme: basic}
+    oauth: {type: oauth2, flows: {clientCredentials: {tokenUrl: 'https://example.com/token', scopes: {}}}}
+  schemas:
+    User:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, minLength: 1}
+        manager: {$ref: '#/components/schemas/User'}
+        role: {type: string, enum: [admin, member], nullable: true}
+paths:
+  /users/{userId}:
+    parameters:
+      - {name: userId, in: path, required: true, schema: {type: string}, description: "The user's\n  ID"}
+      - {name: verbose, in: query, schema: {type: boolean}}
+    put:
+      operationId: updateUser
+      summary: Update a user
+      description: Replaces the user.
+      parameters:
+        - {name: verbose, in: query, required: true, schema: {type: integer, default: 1}}
+        - {name: session, in: cookie, schema: {type: string}}
+        - {name: body, in: query, schema: {type: string}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/User'}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+    delete:
+      deprecated: true
+      responses: {'204': {description: deleted}}
+  /users:
+    get:
+      summary: List users
+      responses: {'200': {description: ok}}
+    post:
+      operationId: get_users
+      requestBody:
+        content:
+          text/plain:
+            schema: {type: string}
+      responses: {'201': {description: created}}
+`
+
+func TestTransform(t *testing.T) {
+	server, err := transformSpec(t, NewService(), usersSpec)
+	require.NoError(t, err)
+	assert.Equal(t, "user-management-api", server.Name)
+	assert.Equal(t, "User Management API", server.Title)
+	assert.Equal(t, "Manages users.", server.Description)
+	assert.Equal(t, "2.1.0", server.Version)
+	assert.Equal(t, "https://eu.example.com/v1", server.BaseURL, "variables take their defaults")
+	assert.Equal(t, "USER_MANAGEMENT_API", server.EnvPrefix)
+
+	var names []string
+	for _, tool := range server.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"get_users", "get_users_2", "update_user"}, names, "colliding names are numbered")
+	assert.Equal(t, "List users", server.Tools[0].Description)
+	assert.Equal(t, "POST /users", server.Tools[1].Description, "operations without a summary are described by their route")
+	assert.Equal(t, []string{
+		"Request body of POST /users has no supported media type - skipping body",
+		`Cookie parameter "session" in PUT /users/{userId} is not supported - skipping`,
+		"Endpoint DELETE /users/{userId} marked as deprecated - skipping",
+	}, server.Warnings)
+
+	update := server.Tools[2]
+	assert.Equal(t, "Update a user\n\nReplaces the user.", update.Description)
+	assert.Equal(t, HTTPOperation{Method: "PUT", Path: "/users/{userId}", OperationID: "updateUser", ContentType: "application/json"}, update.HTTPConfig)
+	require.Len(t, update.Parameters, 4)
+	userID, verbose, query, body := update.Parameters[0], update.Parameters[1], update.Parameters[2], update.Parameters[3]
+	assert.Equal(t, Parameter{
+		Name: "user_id", OriginalName: "userId", In: "path", Type: "string",
+		Description: "The user's ID", Required: true, Schema: map[string]interface{}{"type": "string"},
+	}, userID)
+	assert.Equal(t, "integer", verbose.Type, "operation parameters override path parameters")
+	assert.True(t, verbose.Required)
+	assert.Equal(t, float64(1), verbose.Default)
+	assert.Equal(t, "body", query.Name)
+	assert.Equal(t, "request_body", body.Name, "the body is renamed when a parameter is called body")
+	assert.Equal(t, "Request body", body.Description)
+	assert.Equal(t, []string{"user_id", "verbose", "request_body"}, update.InputSchema["required"])
+	assert.Equal(t, []Parameter{userID, verbose, body}, update.RequiredParameters())
+	assert.Equal(t, "object", update.ResponseSchema["type"])
+
+	assert.Equal(t, []AuthScheme{
+		{Name: "apiKey", Type: AuthTypeAPIKey, In: "header", ParamName: "X-API-Key", EnvVar: "USER_MANAGEMENT_API_API_KEY"},
+		{Name: "basicAuth", Type: AuthTypeBasic, UsernameEnvVar: "USER_MANAGEMENT_API_BASIC_AUTH_USERNAME", PasswordEnvVar: "USER_MANAGEMENT_API_BASIC_AUTH_PASSWORD"},
+		{Name: "oauth", Type: AuthTypeBearer, EnvVar: "USER_MANAGEMENT_API_OAUTH_TOKEN"},
+	}, server.Auth.Schemes)
+	assert.True(t, server.Auth.HasType(AuthTypeBasic))
+	assert.Len(t, server.Auth.EnvVars(), 4)
+}
+
+func TestTransformErrors(t *testing.T) {
+	_, err := NewService().Transform(&parser.ParsedSpec{Location: "spec.yaml", Document: &openapi3.T{}})
+	var pipelineErr *common.Error
+	require.ErrorAs(t, err, &pipelineErr)
+	assert.Equal(t, common.ErrorTypeTransformation, pipelineErr.Type)
+	assert.Equal(t, "specification has no info section", pipelineErr.Message)
+
+	_, err = transformSpec(t, NewService(), `openapi: 3.0.3
+info: {title: Old, version: '1'}
+paths:
+  /users:
+    get: {deprecated: true, responses: {'200': {description: ok}}}
+`)
+	require.ErrorAs(t, err, &pipelineErr)
+	assert.Equal(t, "specification contains no usable operations", pipelineErr.Message)
+	assert.Contains(t, pipelineErr.Suggestion, "non-deprecated")
+
+	server, err := transformSpec(t, NewService(), `openapi: 3.0.3
+info: {title: '', version: '1'}
+paths:
+  /ping:
+    get: {responses: {'200': {description: ok}}}
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "mcp-server", server.Name, "an API without a usable title gets a generic name")
+	assert.Equal(t, "http://localhost", server.BaseURL)
+}
+
+func TestToolName(t *testing.T) {
+	tests := []struct {
+		method, path, operationID, want string
+	}{
+		{"GET", "/users", "listUsers", "list_users"},
+		{"GET", "/users", "HTTPGetUser", "http_get_user"},
+		{"GET", "/users", "get-user.v2", "get_user_v2"},
+		{"GET", "/users/{userId}/posts", "", "get_users_by_user_id_posts"},
+		{"DELETE", "/", "", "delete"},
+		{"GET", "/", "2fa", "_2fa"},
+		{"GET", "/", "!!", "tool"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, toolName(tt.method, tt.path, tt.operationID), "%s %s %q", tt.method, tt.path, tt.operationID)
+	}
+}
+
+func TestSchemaToJSON(t *testing.T) {
+	parsed, err := parser.NewService().ParseData([]byte(usersSpec), "spec.yaml")
+	require.NoError(t, err)
+	user := schemaToJSON(parsed.Document.Components.Schemas["User"])
+
+	assert.Equal(t, "object", user["type"])
+	assert.Equal(t, []string{"name"}, user["required"])
+	properties := user["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "minLength": uint64(1)}, properties["name"])
+	assert.Equal(t, map[string]interface{}{"type": []string{"string", "null"}, "enum": []interface{}{"admin", "member", nil}}, properties["role"], "nullable adds null")
+	manager := properties["manager"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "object"}, manager, "recursion stops at the schema being converted")
+
+	assert.Equal(t, map[string]interface{}{}, schemaToJSON(nil))
+	assert.Equal(t, "string", schemaType(nil))
+}
@@ -0,0 +1,156 @@
+package transformer
+
+import "strings"
+
+// MCPServer is the internal model of the MCP server to generate
+type MCPServer struct {
+	// Name is the slug used for the binary, module and client registration
+	Name        string
+	Title       string
+	Description string
+	Version     string
+	BaseURL     string
+	// EnvPrefix prefixes every environment variable the server reads
+	EnvPrefix string
+	Tools     []MCPTool
+	Auth      AuthConfig
+	// Warnings lists operations or features that were skipped or simplified
+	Warnings []string
+}
+
+// MCPTool is a single MCP tool backed by one HTTP operation
+type MCPTool struct {
+	Name        string
+	Description string
+	Parameters  []Parameter
+	HTTPConfig  HTTPOperation
+	// InputSchema is the JSON Schema advertised through tools/list
+	InputSchema map[string]interface{}
+	// ResponseSchema is the JSON Schema of a successful response, if documented
+	ResponseSchema map[string]interface{}
+}
+
+// RequiredParameters returns the parameters a caller must supply
+func (t MCPTool) RequiredParameters() []Parameter {
+	var required []Parameter
+	for _, p := range t.Parameters {
+		if p.Required {
+			required = append(required, p)
+		}
+	}
+	return required
+}
+
+// Parameter is a tool argument and where it goes in the HTTP request
+type Parameter struct {
+	// Name is the argument name exposed to MCP clients
+	Name string
+	// OriginalName is the parameter name used in the HTTP request
+	OriginalName string
+	// In is one of "path", "query", "header" or "body"
+	In          string
+	Type        string
+	Format      string
+	Description string
+	Required    bool
+	Enum        []interface{}
+	Default     interface{}
+	Example     interface{}
+	Schema      map[string]interface{}
+}
+
+// HTTPOperation describes the upstream request made by a tool
+type HTTPOperation struct {
+	Method      string
+	Path        string
+	OperationID string
+	ContentType string
+	Deprecated  bool
+}
+
+// AuthConfig lists the authentication schemes the server supports
+type AuthConfig struct {
+	Schemes []AuthScheme
+}
+
+// HasType reports whether any scheme of the given type is configured
+func (a AuthConfig) HasType(schemeType string) bool {
+	for _, scheme := range a.Schemes {
+		if scheme.Type == schemeType {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvVars returns every environment variable used for authentication
+func (a AuthConfig) EnvVars() []string {
+	var vars []string
+	for _, scheme := range a.Schemes {
+		vars = append(vars, scheme.EnvVars()...)
+	}
+	return vars
+}
+
+// Authentication scheme types
+const (
+	AuthTypeAPIKey = "apiKey"
+	AuthTypeBearer = "bearer"
+	AuthTypeBasic  = "basic"
+)
+
+// AuthScheme is a security scheme mapped to environment variables
+type AuthScheme struct {
+	Name        string
+	Type        string
+	Description string
+	// In and ParamName locate API keys ("header", "query" or "cookie")
+	In        string
+	ParamName string
+	// EnvVar holds the API key or bearer token
+	EnvVar string
+	// UsernameEnvVar and PasswordEnvVar hold basic auth credentials
+	UsernameEnvVar string
+	PasswordEnvVar string
+}
+
+// EnvVars returns the environment variables read for this scheme
+func (s AuthScheme) EnvVars() []string {
+	if s.Type == AuthTypeBasic {
+		return []string{s.UsernameEnvVar, s.PasswordEnvVar}
+	}
+	return []string{s.EnvVar}
+}
+
+// Filter selects the operations mapped to tools by their tags. Tags are
+// compared without regard to case.
+type Filter struct {
+	// IncludeTags keeps only the operations with one of these tags, unless
+	// it is empty
+	IncludeTags []string
+	// ExcludeTags drops the operations with any of these tags
+	ExcludeTags []string
+}
+
+// Empty reports whether the filter keeps every operation
+func (f Filter) Empty() bool {
+	return len(f.IncludeTags) == 0 && len(f.ExcludeTags) == 0
+}
+
+// Selects reports whether the filter keeps an operation with the given tags
+func (f Filter) Selects(tags []string) bool {
+	hasAny := func(names []string) bool {
+		for _, tag := range tags {
+			for _, name := range names {
+				if strings.EqualFold(tag, name) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if len(f.IncludeTags) > 0 && !hasAny(f.IncludeTags) {
+		return false
+	}
+	return !hasAny(f.ExcludeTags)
+}
@@ -20,7 +20,7 @@ func main() {
 
 	// Execute the root command
 	if err := cmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprint(os.Stderr, cmd.FormatError(err))
+		os.Exit(cmd.ExitCode(err))
 	}
-}
\ No newline at end of file
+}